@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/account/services"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/helpers"
+)
+
+// AccountController handles HTTP requests for account export and deletion
+type AccountController struct {
+	service *services.AccountService
+}
+
+// NewAccountController creates a new account controller instance
+func NewAccountController(service *services.AccountService) *AccountController {
+	return &AccountController{service: service}
+}
+
+// writeAccountError maps account service errors onto HTTP responses
+func writeAccountError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, services.ErrUserNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+	case errors.Is(err, services.ErrNoDeletionScheduled):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+// ExportAccount godoc
+// @Summary      Export account data
+// @Description  Download a zip archive of everything stored about the authenticated user: profile, meal logs with their items, biometrics, daily nutrition totals, recipes, meal templates, targets, exercise logs, workout imports and the foods the user created, one JSON file each, with a manifest.json listing them
+// @Tags         account
+// @Produce      application/zip
+// @Success      200  {file}    file               "Zip archive"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      404  {object}  map[string]string  "User not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /account/export [get]
+func (c *AccountController) ExportAccount(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	now := time.Now()
+	var archive bytes.Buffer
+	if err := c.service.ExportAccount(userClaims.UserID, &archive, now); err != nil {
+		writeAccountError(ctx, err, "Failed to export account")
+		return
+	}
+
+	fileName := fmt.Sprintf("account_%d_%s.zip", userClaims.UserID, now.Format("2006-01-02"))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName))
+	ctx.Data(http.StatusOK, "application/zip", archive.Bytes())
+}
+
+// GetDeletion godoc
+// @Summary      Get account deletion status
+// @Description  Report whether a deletion of the authenticated user's account is pending and when it takes effect
+// @Tags         account
+// @Produce      json
+// @Success      200  {object}  dto.AccountDeletionDTO  "Deletion status"
+// @Failure      401  {object}  map[string]string       "Unauthorized"
+// @Failure      404  {object}  map[string]string       "User not found"
+// @Failure      500  {object}  map[string]string       "Internal server error"
+// @Security     BearerAuth
+// @Router       /account/deletion [get]
+func (c *AccountController) GetDeletion(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	deletion, err := c.service.GetDeletion(userClaims.UserID)
+	if err != nil {
+		writeAccountError(ctx, err, "Failed to get account deletion")
+		return
+	}
+	ctx.JSON(http.StatusOK, deletion)
+}
+
+// DeleteAccount godoc
+// @Summary      Delete user account
+// @Description  Schedule the deletion of the authenticated user's account. Once the grace period has passed the account is purged with all its meal logs, biometrics, recipes, meal templates, targets and exercise logs in one transaction; foods the user created that others still use are kept without an owner. The deletion can be cancelled until then.
+// @Tags         account
+// @Produce      json
+// @Success      202  {object}  dto.AccountDeletionDTO  "Deletion scheduled"
+// @Failure      401  {object}  map[string]string       "Unauthorized"
+// @Failure      404  {object}  map[string]string       "User not found"
+// @Failure      500  {object}  map[string]string       "Internal server error"
+// @Security     BearerAuth
+// @Router       /account [delete]
+func (c *AccountController) DeleteAccount(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	deletion, err := c.service.RequestDeletion(userClaims.UserID, time.Now())
+	if err != nil {
+		writeAccountError(ctx, err, "Failed to delete account")
+		return
+	}
+	ctx.JSON(http.StatusAccepted, deletion)
+}
+
+// CancelDeletion godoc
+// @Summary      Cancel account deletion
+// @Description  Cancel the pending deletion of the authenticated user's account
+// @Tags         account
+// @Produce      json
+// @Success      200  {object}  dto.AccountDeletionDTO  "Deletion cancelled"
+// @Failure      401  {object}  map[string]string       "Unauthorized"
+// @Failure      404  {object}  map[string]string       "User not found or no deletion scheduled"
+// @Failure      500  {object}  map[string]string       "Internal server error"
+// @Security     BearerAuth
+// @Router       /account/deletion/cancel [post]
+func (c *AccountController) CancelDeletion(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	deletion, err := c.service.CancelDeletion(userClaims.UserID)
+	if err != nil {
+		writeAccountError(ctx, err, "Failed to cancel account deletion")
+		return
+	}
+	ctx.JSON(http.StatusOK, deletion)
+}
@@ -0,0 +1,54 @@
+package models
+
+import (
+	dailyNutritionModels "github.com/momokapoolz/caloriesapp/daily_nutrition/models"
+	exerciseLogModels "github.com/momokapoolz/caloriesapp/exercise_log/models"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	foodPortionModels "github.com/momokapoolz/caloriesapp/food_portion/models"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealTemplateModels "github.com/momokapoolz/caloriesapp/meal_template/models"
+	recipeModels "github.com/momokapoolz/caloriesapp/recipe/models"
+	targetsModels "github.com/momokapoolz/caloriesapp/targets/models"
+	userModels "github.com/momokapoolz/caloriesapp/user/models"
+	userBiometricsModels "github.com/momokapoolz/caloriesapp/user_biometrics/models"
+)
+
+// AccountData is everything stored about a user, loaded for an account export
+type AccountData struct {
+	User           userModels.User
+	MealLogs       []mealLogModels.MealLogWithItems
+	Biometrics     []userBiometricsModels.UserBiometric
+	DailyNutrition []dailyNutritionModels.DailyNutritionTotal
+	Recipes        []RecipeWithIngredients
+	MealTemplates  []MealTemplateWithItems
+	TargetSets     []targetsModels.TargetSet
+	ExerciseLogs   []exerciseLogModels.ExerciseLog
+	WorkoutImports []exerciseLogModels.WorkoutImport
+	Foods          []OwnedFood
+}
+
+// RecipeWithIngredients is a recipe of the user with its ingredient lines
+type RecipeWithIngredients struct {
+	Recipe      recipeModels.Recipe             `json:"recipe"`
+	Ingredients []recipeModels.RecipeIngredient `json:"ingredients"`
+}
+
+// MealTemplateWithItems is a meal template of the user with its items
+type MealTemplateWithItems struct {
+	MealTemplate mealTemplateModels.MealTemplate       `json:"meal_template"`
+	Items        []mealTemplateModels.MealTemplateItem `json:"items"`
+}
+
+// OwnedFood is a food the user created, with its nutrient profile and portions
+type OwnedFood struct {
+	Food      foodModels.Food                    `json:"food"`
+	Nutrients []foodNutrientsModels.FoodNutrient `json:"nutrients"`
+	Portions  []foodPortionModels.FoodPortion    `json:"portions"`
+}
+
+// DeletionResult counts the rows removed or anonymized when an account is purged, by table
+type DeletionResult struct {
+	Deleted    map[string]int64
+	Anonymized map[string]int64
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/account/models"
+	dailyNutritionModels "github.com/momokapoolz/caloriesapp/daily_nutrition/models"
+	exerciseLogModels "github.com/momokapoolz/caloriesapp/exercise_log/models"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	foodPortionModels "github.com/momokapoolz/caloriesapp/food_portion/models"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	mealTemplateModels "github.com/momokapoolz/caloriesapp/meal_template/models"
+	recipeModels "github.com/momokapoolz/caloriesapp/recipe/models"
+	targetsModels "github.com/momokapoolz/caloriesapp/targets/models"
+	userModels "github.com/momokapoolz/caloriesapp/user/models"
+	userBiometricsModels "github.com/momokapoolz/caloriesapp/user_biometrics/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// foodReferenced matches foods still used by a meal, recipe or meal template, which are kept when
+// their owner is deleted so other users' diaries keep their nutrients
+const foodReferenced = `EXISTS (SELECT 1 FROM meal_log_items WHERE meal_log_items.food_id = food.id)
+	OR EXISTS (SELECT 1 FROM recipe_ingredients WHERE recipe_ingredients.food_id = food.id)
+	OR EXISTS (SELECT 1 FROM recipe WHERE recipe.food_id = food.id)
+	OR EXISTS (SELECT 1 FROM meal_template_items WHERE meal_template_items.food_id = food.id)`
+
+// tabler is a model with an explicit table name
+type tabler interface {
+	TableName() string
+}
+
+// AccountRepository handles the database operations that span every table holding a user's data
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+// NewAccountRepository creates a new account repository instance
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	return &AccountRepository{db: db}
+}
+
+// GetUser retrieves a user by ID
+func (r *AccountRepository) GetUser(userID uint) (*userModels.User, error) {
+	var user userModels.User
+	if err := r.db.First(&user, userID).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// GetAccountData loads every row stored about a user
+func (r *AccountRepository) GetAccountData(userID uint) (*models.AccountData, error) {
+	user, err := r.GetUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	data := &models.AccountData{User: *user}
+
+	var mealLogs []mealLogModels.MealLog
+	if err := r.db.Where("user_id = ?", userID).Order("consumed_at, id").Find(&mealLogs).Error; err != nil {
+		return nil, err
+	}
+	var items []mealLogItemsModels.MealLogItem
+	if err := r.db.Where("meal_log_id IN (?)", r.db.Model(&mealLogModels.MealLog{}).Select("id").Where("user_id = ?", userID)).
+		Order("id").Find(&items).Error; err != nil {
+		return nil, err
+	}
+	itemsByMealLog := make(map[uint][]mealLogItemsModels.MealLogItem, len(mealLogs))
+	for _, item := range items {
+		itemsByMealLog[item.MealLogID] = append(itemsByMealLog[item.MealLogID], item)
+	}
+	data.MealLogs = make([]mealLogModels.MealLogWithItems, 0, len(mealLogs))
+	for _, mealLog := range mealLogs {
+		mealItems := itemsByMealLog[mealLog.ID]
+		if mealItems == nil {
+			mealItems = []mealLogItemsModels.MealLogItem{}
+		}
+		data.MealLogs = append(data.MealLogs, mealLogModels.MealLogWithItems{MealLog: mealLog, Items: mealItems})
+	}
+
+	if err := r.db.Where("user_id = ?", userID).Order("created_at, id").Find(&data.Biometrics).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("date, nutrient_id").Find(&data.DailyNutrition).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Preload("Nutrients").Where("user_id = ?", userID).Order("effective_from").Find(&data.TargetSets).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Preload("Activity").Where("user_id = ?", userID).Order("performed_at, id").Find(&data.ExerciseLogs).Error; err != nil {
+		return nil, err
+	}
+	if err := r.db.Where("user_id = ?", userID).Order("created_at, id").Find(&data.WorkoutImports).Error; err != nil {
+		return nil, err
+	}
+
+	var recipes []recipeModels.Recipe
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&recipes).Error; err != nil {
+		return nil, err
+	}
+	data.Recipes = make([]models.RecipeWithIngredients, 0, len(recipes))
+	for _, recipe := range recipes {
+		ingredients := []recipeModels.RecipeIngredient{}
+		if err := r.db.Where("recipe_id = ?", recipe.ID).Order("id").Find(&ingredients).Error; err != nil {
+			return nil, err
+		}
+		data.Recipes = append(data.Recipes, models.RecipeWithIngredients{Recipe: recipe, Ingredients: ingredients})
+	}
+
+	var templates []mealTemplateModels.MealTemplate
+	if err := r.db.Where("user_id = ?", userID).Order("id").Find(&templates).Error; err != nil {
+		return nil, err
+	}
+	data.MealTemplates = make([]models.MealTemplateWithItems, 0, len(templates))
+	for _, template := range templates {
+		templateItems := []mealTemplateModels.MealTemplateItem{}
+		if err := r.db.Where("meal_template_id = ?", template.ID).Order("id").Find(&templateItems).Error; err != nil {
+			return nil, err
+		}
+		data.MealTemplates = append(data.MealTemplates, models.MealTemplateWithItems{MealTemplate: template, Items: templateItems})
+	}
+
+	var foods []foodModels.Food
+	if err := r.db.Where("owner_id = ?", userID).Order("id").Find(&foods).Error; err != nil {
+		return nil, err
+	}
+	data.Foods = make([]models.OwnedFood, 0, len(foods))
+	for _, food := range foods {
+		owned := models.OwnedFood{Food: food, Nutrients: []foodNutrientsModels.FoodNutrient{}, Portions: []foodPortionModels.FoodPortion{}}
+		if err := r.db.Where("food_id = ?", food.ID).Order("nutrient_id").Find(&owned.Nutrients).Error; err != nil {
+			return nil, err
+		}
+		if err := r.db.Where("food_id = ?", food.ID).Order("id").Find(&owned.Portions).Error; err != nil {
+			return nil, err
+		}
+		data.Foods = append(data.Foods, owned)
+	}
+
+	return data, nil
+}
+
+// ScheduleDeletion records that a user asked for their account to be deleted at scheduledAt
+func (r *AccountRepository) ScheduleDeletion(userID uint, requestedAt, scheduledAt time.Time) error {
+	return r.db.Model(&userModels.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"deletion_requested_at": requestedAt,
+		"deletion_scheduled_at": scheduledAt,
+	}).Error
+}
+
+// CancelDeletion clears a scheduled deletion. It reports false when none was scheduled.
+func (r *AccountRepository) CancelDeletion(userID uint) (bool, error) {
+	result := r.db.Model(&userModels.User{}).
+		Where("id = ? AND deletion_scheduled_at IS NOT NULL", userID).
+		Updates(map[string]interface{}{
+			"deletion_requested_at": gorm.Expr("NULL"),
+			"deletion_scheduled_at": gorm.Expr("NULL"),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+// GetUserIDsDueForDeletion lists the users whose grace period ended by now
+func (r *AccountRepository) GetUserIDsDueForDeletion(now time.Time) ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Model(&userModels.User{}).Where("deletion_scheduled_at <= ?", now).Order("id").Pluck("id", &userIDs).Error
+	return userIDs, err
+}
+
+// PurgeUser deletes a user whose deletion is due by now, with every row that depends on them, in a
+// single transaction. Foods the user created that other diaries or recipes still use are kept and
+// detached from the user instead. The user row is locked first, so a deletion cancelled meanwhile
+// is honored; gorm.ErrRecordNotFound is returned when the user is gone or no longer due.
+func (r *AccountRepository) PurgeUser(userID uint, now time.Time) (*models.DeletionResult, error) {
+	result := &models.DeletionResult{Deleted: map[string]int64{}, Anonymized: map[string]int64{}}
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user userModels.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("id = ? AND deletion_scheduled_at <= ?", userID, now).
+			First(&user).Error; err != nil {
+			return err
+		}
+
+		remove := func(model tabler, query string, args ...interface{}) error {
+			deleted := tx.Where(query, args...).Delete(model)
+			if deleted.Error != nil {
+				return deleted.Error
+			}
+			result.Deleted[model.TableName()] += deleted.RowsAffected
+			return nil
+		}
+
+		mealLogIDs := tx.Model(&mealLogModels.MealLog{}).Select("id").Where("user_id = ?", userID)
+		recipeIDs := tx.Model(&recipeModels.Recipe{}).Select("id").Where("user_id = ?", userID)
+		templateIDs := tx.Model(&mealTemplateModels.MealTemplate{}).Select("id").Where("user_id = ?", userID)
+		targetSetIDs := tx.Model(&targetsModels.TargetSet{}).Select("id").Where("user_id = ?", userID)
+		steps := []struct {
+			model tabler
+			query string
+			args  []interface{}
+		}{
+			{&mealLogItemsModels.MealLogItem{}, "meal_log_id IN (?)", []interface{}{mealLogIDs}},
+			{&mealLogModels.MealLog{}, "user_id = ?", []interface{}{userID}},
+			{&dailyNutritionModels.DailyNutritionTotal{}, "user_id = ?", []interface{}{userID}},
+			{&dailyNutritionModels.DailyNutritionStatus{}, "user_id = ?", []interface{}{userID}},
+			{&userBiometricsModels.UserBiometric{}, "user_id = ?", []interface{}{userID}},
+			{&recipeModels.RecipeIngredient{}, "recipe_id IN (?)", []interface{}{recipeIDs}},
+			{&recipeModels.Recipe{}, "user_id = ?", []interface{}{userID}},
+			{&mealTemplateModels.MealTemplateItem{}, "meal_template_id IN (?)", []interface{}{templateIDs}},
+			{&mealTemplateModels.MealTemplate{}, "user_id = ?", []interface{}{userID}},
+			{&targetsModels.NutrientTarget{}, "target_set_id IN (?)", []interface{}{targetSetIDs}},
+			{&targetsModels.TargetSet{}, "user_id = ?", []interface{}{userID}},
+			{&exerciseLogModels.ExerciseLog{}, "user_id = ?", []interface{}{userID}},
+			{&exerciseLogModels.WorkoutImport{}, "user_id = ?", []interface{}{userID}},
+		}
+		for _, step := range steps {
+			if err := remove(step.model, step.query, step.args...); err != nil {
+				return err
+			}
+		}
+
+		kept := tx.Model(&foodModels.Food{}).
+			Where("owner_id = ? AND ("+foodReferenced+")", userID).
+			Update("owner_id", gorm.Expr("NULL"))
+		if kept.Error != nil {
+			return kept.Error
+		}
+		result.Anonymized[foodModels.Food{}.TableName()] = kept.RowsAffected
+
+		ownedFoodIDs := tx.Model(&foodModels.Food{}).Select("id").Where("owner_id = ?", userID)
+		if err := remove(&foodNutrientsModels.FoodNutrient{}, "food_id IN (?)", ownedFoodIDs); err != nil {
+			return err
+		}
+		if err := remove(&foodPortionModels.FoodPortion{}, "food_id IN (?)", ownedFoodIDs); err != nil {
+			return err
+		}
+		if err := remove(&foodModels.Food{}, "owner_id = ?", userID); err != nil {
+			return err
+		}
+		return remove(&userModels.User{}, "id = ?", userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
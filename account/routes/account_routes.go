@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/account/controllers"
+	"github.com/momokapoolz/caloriesapp/account/repository"
+	"github.com/momokapoolz/caloriesapp/account/services"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"gorm.io/gorm"
+)
+
+// SetupAccountRoutes initializes account export and deletion routes
+func SetupAccountRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	accountController := controllers.NewAccountController(services.NewAccountService(
+		repository.NewAccountRepository(db),
+		services.DeletionGracePeriod(),
+	))
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	accountRoutes := router.Group("/account", authMiddleware.RequireAuth())
+	{
+		accountRoutes.DELETE("", accountController.DeleteAccount)
+		accountRoutes.GET("/export", accountController.ExportAccount)
+		accountRoutes.GET("/deletion", accountController.GetDeletion)
+		accountRoutes.POST("/deletion/cancel", accountController.CancelDeletion)
+	}
+}
@@ -0,0 +1,95 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/account/models"
+	"github.com/momokapoolz/caloriesapp/dto"
+)
+
+// archiveManifest describes an account export: when it was made and how many records each
+// file of the archive holds
+type archiveManifest struct {
+	UserID     uint           `json:"user_id"`
+	ExportedAt time.Time      `json:"exported_at"`
+	Files      map[string]int `json:"files"`
+}
+
+// archiveProfile is the profile of an account export. The password hash is never exported.
+type archiveProfile struct {
+	dto.UserResponseDTO
+	Deletion dto.AccountDeletionDTO `json:"deletion"`
+}
+
+// archiveFile is a JSON file of an account export
+type archiveFile struct {
+	name    string
+	records int
+	content interface{}
+}
+
+// WriteAccountArchive writes data as a zip archive with one JSON file per kind of record and a
+// manifest.json listing them
+func WriteAccountArchive(w io.Writer, data *models.AccountData, exportedAt time.Time) error {
+	user := data.User
+	profile := archiveProfile{
+		UserResponseDTO: dto.UserResponseDTO{
+			ID:            user.ID,
+			Name:          user.Name,
+			Email:         user.Email,
+			Age:           user.Age,
+			Gender:        user.Gender,
+			Weight:        user.Weight,
+			Height:        user.Height,
+			Goal:          user.Goal,
+			ActivityLevel: user.ActivityLevel,
+			Role:          user.Role,
+			Timezone:      user.Timezone,
+			CreatedAt:     user.CreatedAt,
+		},
+		Deletion: *deletionStatus(&user),
+	}
+
+	files := []archiveFile{
+		{"profile.json", 1, profile},
+		{"meal_logs.json", len(data.MealLogs), orEmpty(data.MealLogs)},
+		{"biometrics.json", len(data.Biometrics), orEmpty(data.Biometrics)},
+		{"daily_nutrition.json", len(data.DailyNutrition), orEmpty(data.DailyNutrition)},
+		{"recipes.json", len(data.Recipes), orEmpty(data.Recipes)},
+		{"meal_templates.json", len(data.MealTemplates), orEmpty(data.MealTemplates)},
+		{"targets.json", len(data.TargetSets), orEmpty(data.TargetSets)},
+		{"exercise_logs.json", len(data.ExerciseLogs), orEmpty(data.ExerciseLogs)},
+		{"workout_imports.json", len(data.WorkoutImports), orEmpty(data.WorkoutImports)},
+		{"foods.json", len(data.Foods), orEmpty(data.Foods)},
+	}
+	manifest := archiveManifest{UserID: user.ID, ExportedAt: exportedAt.UTC(), Files: make(map[string]int, len(files))}
+	for _, file := range files {
+		manifest.Files[file.name] = file.records
+	}
+
+	archive := zip.NewWriter(w)
+	for _, file := range append([]archiveFile{{"manifest.json", 1, manifest}}, files...) {
+		entry, err := archive.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: exportedAt})
+		if err != nil {
+			return fmt.Errorf("failed to add %s: %w", file.name, err)
+		}
+		encoder := json.NewEncoder(entry)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(file.content); err != nil {
+			return fmt.Errorf("failed to write %s: %w", file.name, err)
+		}
+	}
+	return archive.Close()
+}
+
+// orEmpty returns an empty slice for nil, so the file holds [] rather than null
+func orEmpty[T any](records []T) []T {
+	if records == nil {
+		return []T{}
+	}
+	return records
+}
@@ -0,0 +1,111 @@
+package services
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/account/models"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	userModels "github.com/momokapoolz/caloriesapp/user/models"
+	userBiometricsModels "github.com/momokapoolz/caloriesapp/user_biometrics/models"
+)
+
+// readArchive reads every file of a zip archive
+func readArchive(t *testing.T, archive []byte) map[string][]byte {
+	t.Helper()
+	reader, err := zip.NewReader(bytes.NewReader(archive), int64(len(archive)))
+	if err != nil {
+		t.Fatalf("zip.NewReader: %v", err)
+	}
+	files := make(map[string][]byte, len(reader.File))
+	for _, file := range reader.File {
+		entry, err := file.Open()
+		if err != nil {
+			t.Fatalf("open %s: %v", file.Name, err)
+		}
+		content, err := io.ReadAll(entry)
+		entry.Close()
+		if err != nil {
+			t.Fatalf("read %s: %v", file.Name, err)
+		}
+		files[file.Name] = content
+	}
+	return files
+}
+
+func TestWriteAccountArchive(t *testing.T) {
+	requestedAt := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	scheduledAt := requestedAt.Add(DefaultDeletionGracePeriod)
+	data := &models.AccountData{
+		User: userModels.User{
+			ID: 7, Name: "Sam", Email: "sam@example.com", PasswordHash: "$2a$10$secret",
+			DeletionRequestedAt: &requestedAt, DeletionScheduledAt: &scheduledAt,
+		},
+		MealLogs: []mealLogModels.MealLogWithItems{{
+			MealLog: mealLogModels.MealLog{ID: 3, UserID: 7, MealType: "breakfast"},
+			Items:   []mealLogItemsModels.MealLogItem{{ID: 9, MealLogID: 3, FoodID: 1, QuantityGrams: 40}},
+		}},
+		Biometrics: []userBiometricsModels.UserBiometric{{ID: 4, UserID: 7, Type: "weight", Value: 70, Unit: "kg"}},
+	}
+
+	var archive bytes.Buffer
+	if err := WriteAccountArchive(&archive, data, requestedAt.Add(time.Hour)); err != nil {
+		t.Fatalf("WriteAccountArchive: %v", err)
+	}
+	files := readArchive(t, archive.Bytes())
+
+	var manifest archiveManifest
+	if err := json.Unmarshal(files["manifest.json"], &manifest); err != nil {
+		t.Fatalf("manifest.json: %v", err)
+	}
+	if manifest.UserID != 7 || len(manifest.Files) != 10 || manifest.Files["meal_logs.json"] != 1 || manifest.Files["recipes.json"] != 0 {
+		t.Errorf("manifest = %+v", manifest)
+	}
+	for name := range manifest.Files {
+		if _, ok := files[name]; !ok {
+			t.Errorf("archive lacks %s", name)
+		}
+	}
+
+	profile := string(files["profile.json"])
+	if strings.Contains(profile, "secret") || !strings.Contains(profile, `"email": "sam@example.com"`) || !strings.Contains(profile, `"scheduled": true`) {
+		t.Errorf("profile.json = %s", profile)
+	}
+
+	var mealLogs []mealLogModels.MealLogWithItems
+	if err := json.Unmarshal(files["meal_logs.json"], &mealLogs); err != nil {
+		t.Fatalf("meal_logs.json: %v", err)
+	}
+	if len(mealLogs) != 1 || len(mealLogs[0].Items) != 1 || mealLogs[0].Items[0].QuantityGrams != 40 {
+		t.Errorf("meal logs = %+v", mealLogs)
+	}
+	// Kinds of records the user has none of are empty lists rather than null
+	if recipes := strings.TrimSpace(string(files["recipes.json"])); recipes != "[]" {
+		t.Errorf("recipes.json = %s, want []", recipes)
+	}
+}
+
+func TestDeletionGracePeriod(t *testing.T) {
+	cases := []struct {
+		value string
+		want  time.Duration
+	}{
+		{"", DefaultDeletionGracePeriod},
+		{"7", 7 * 24 * time.Hour},
+		{"0", 0},
+		{"-1", DefaultDeletionGracePeriod},
+		{"a week", DefaultDeletionGracePeriod},
+	}
+	for _, tc := range cases {
+		t.Setenv("ACCOUNT_DELETION_GRACE_DAYS", tc.value)
+		if got := DeletionGracePeriod(); got != tc.want {
+			t.Errorf("ACCOUNT_DELETION_GRACE_DAYS=%q: got %s, want %s", tc.value, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/account/repository"
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	userModels "github.com/momokapoolz/caloriesapp/user/models"
+	"gorm.io/gorm"
+)
+
+// DefaultDeletionGracePeriod is how long a requested account deletion can be cancelled
+const DefaultDeletionGracePeriod = 30 * 24 * time.Hour
+
+var (
+	// ErrUserNotFound is returned for accounts that do not exist
+	ErrUserNotFound = errors.New("user not found")
+	// ErrNoDeletionScheduled is returned when cancelling a deletion that was not requested
+	ErrNoDeletionScheduled = errors.New("no account deletion is scheduled")
+)
+
+// DeletionGracePeriod returns the grace period set in days by ACCOUNT_DELETION_GRACE_DAYS, or the
+// default when it is unset or invalid. Zero purges accounts on the next purge run.
+func DeletionGracePeriod() time.Duration {
+	value := os.Getenv("ACCOUNT_DELETION_GRACE_DAYS")
+	if value == "" {
+		return DefaultDeletionGracePeriod
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		log.Printf("Invalid ACCOUNT_DELETION_GRACE_DAYS %q, using %s", value, DefaultDeletionGracePeriod)
+		return DefaultDeletionGracePeriod
+	}
+	return time.Duration(days) * 24 * time.Hour
+}
+
+// AccountService exports a user's data and deletes accounts after a grace period
+type AccountService struct {
+	repo  *repository.AccountRepository
+	grace time.Duration
+}
+
+// NewAccountService creates a new account service instance
+func NewAccountService(repo *repository.AccountRepository, grace time.Duration) *AccountService {
+	return &AccountService{
+		repo:  repo,
+		grace: grace,
+	}
+}
+
+// ExportAccount writes a zip archive of everything stored about a user to w
+func (s *AccountService) ExportAccount(userID uint, w io.Writer, now time.Time) error {
+	data, err := s.repo.GetAccountData(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to load account data: %w", err)
+	}
+	return WriteAccountArchive(w, data, now)
+}
+
+// GetDeletion reports the pending deletion of a user's account, if any
+func (s *AccountService) GetDeletion(userID uint) (*dto.AccountDeletionDTO, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	return deletionStatus(user), nil
+}
+
+// RequestDeletion schedules the deletion of a user's account once the grace period has passed.
+// Asking again while a deletion is pending keeps the original schedule.
+func (s *AccountService) RequestDeletion(userID uint, now time.Time) (*dto.AccountDeletionDTO, error) {
+	user, err := s.getUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.DeletionScheduledAt != nil {
+		return deletionStatus(user), nil
+	}
+
+	requestedAt, scheduledAt := now, now.Add(s.grace)
+	if err := s.repo.ScheduleDeletion(userID, requestedAt, scheduledAt); err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to schedule deletion: %w", err)
+	}
+	user.DeletionRequestedAt, user.DeletionScheduledAt = &requestedAt, &scheduledAt
+	return deletionStatus(user), nil
+}
+
+// CancelDeletion keeps an account whose deletion is still pending
+func (s *AccountService) CancelDeletion(userID uint) (*dto.AccountDeletionDTO, error) {
+	if _, err := s.getUser(userID); err != nil {
+		return nil, err
+	}
+	cancelled, err := s.repo.CancelDeletion(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to cancel deletion: %w", err)
+	}
+	if !cancelled {
+		return nil, ErrNoDeletionScheduled
+	}
+	return &dto.AccountDeletionDTO{}, nil
+}
+
+// PurgeDueAccounts deletes every account whose grace period ended by now and returns how many were
+// deleted. Each account is deleted in its own transaction, so one failure does not keep the others.
+func (s *AccountService) PurgeDueAccounts(now time.Time) (int, error) {
+	userIDs, err := s.repo.GetUserIDsDueForDeletion(now)
+	if err != nil {
+		helpers.LogError(err)
+		return 0, fmt.Errorf("failed to list accounts due for deletion: %w", err)
+	}
+
+	purged := 0
+	var errs []error
+	for _, userID := range userIDs {
+		result, err := s.repo.PurgeUser(userID, now)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			// Cancelled or purged since the accounts were listed
+			continue
+		}
+		if err != nil {
+			helpers.LogError(err)
+			errs = append(errs, fmt.Errorf("user %d: %w", userID, err))
+			continue
+		}
+		purged++
+		log.Printf("Purged account %d: deleted %v, anonymized %v", userID, result.Deleted, result.Anonymized)
+	}
+	return purged, errors.Join(errs...)
+}
+
+// getUser loads a user, mapping a missing row to ErrUserNotFound
+func (s *AccountService) getUser(userID uint) (*userModels.User, error) {
+	user, err := s.repo.GetUser(userID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return user, nil
+}
+
+// deletionStatus reports the deletion schedule of a user
+func deletionStatus(user *userModels.User) *dto.AccountDeletionDTO {
+	return &dto.AccountDeletionDTO{
+		Scheduled:   user.DeletionScheduledAt != nil,
+		RequestedAt: user.DeletionRequestedAt,
+		ScheduledAt: user.DeletionScheduledAt,
+	}
+}
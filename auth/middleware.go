@@ -6,11 +6,15 @@ import (
 	"strings"
 
 	"github.com/gin-gonic/gin"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
 )
 
 const (
 	AccessTokenCookie  = "access_token"
 	RefreshTokenCookie = "refresh_token"
+
+	// RoleAdmin is the role of users who administer the food catalog
+	RoleAdmin = "admin"
 )
 
 // AuthMiddleware provides JWT authentication for routes
@@ -127,6 +131,15 @@ func GetCurrentUser(c *gin.Context) (Claims, bool) {
 	return userClaims, ok
 }
 
+// GetFoodViewer builds the viewer food visibility is checked against from the current user
+func GetFoodViewer(c *gin.Context) (foodModels.FoodViewer, bool) {
+	userClaims, ok := GetCurrentUser(c)
+	if !ok {
+		return foodModels.FoodViewer{}, false
+	}
+	return foodModels.FoodViewer{UserID: userClaims.UserID, IsAdmin: userClaims.Role == RoleAdmin}, true
+}
+
 // CORSMiddleware sets CORS headers. The allowed origin is read from the
 // CORS_ORIGIN env variable; falls back to http://localhost:3000 for development.
 func CORSMiddleware() gin.HandlerFunc {
@@ -0,0 +1,231 @@
+package main
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"time"
+
+	accountRepo "github.com/momokapoolz/caloriesapp/account/repository"
+	accountServices "github.com/momokapoolz/caloriesapp/account/services"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/database"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodImportRepo "github.com/momokapoolz/caloriesapp/food_import/repository"
+	foodImportServices "github.com/momokapoolz/caloriesapp/food_import/services"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	recipeRepo "github.com/momokapoolz/caloriesapp/recipe/repository"
+	recipeServices "github.com/momokapoolz/caloriesapp/recipe/services"
+	userBiometricsRepo "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+	userBiometricsServices "github.com/momokapoolz/caloriesapp/user_biometrics/services"
+	"gorm.io/gorm"
+)
+
+// runCommand dispatches a maintenance subcommand given on the command line
+func runCommand(name string, args []string) error {
+	switch name {
+	case "migrate":
+		return migrate(args)
+	case "rebuild-rollups":
+		return rebuildRollups()
+	case "import-usda":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: import-usda <file.json|file.zip|directory>")
+		}
+		return importUSDA(args[0])
+	case "import-off":
+		if len(args) != 1 {
+			return fmt.Errorf("usage: import-off <file.jsonl|file.csv>[.gz]")
+		}
+		return importOpenFoodFacts(args[0])
+	case "import-apple-health":
+		if len(args) != 2 {
+			return fmt.Errorf("usage: import-apple-health <user-id> <export.zip|export.xml>")
+		}
+		userID, err := strconv.ParseUint(args[0], 10, 32)
+		if err != nil {
+			return fmt.Errorf("invalid user ID %q", args[0])
+		}
+		return importAppleHealth(uint(userID), args[1])
+	case "purge-accounts":
+		return purgeAccounts()
+	default:
+		return fmt.Errorf("unknown command %q", name)
+	}
+}
+
+// migrate applies, reverts or lists the versioned schema migrations
+func migrate(args []string) error {
+	const usage = "usage: migrate up | down [steps] | status"
+	if len(args) == 0 {
+		return fmt.Errorf(usage)
+	}
+	db := database.ConnectDatabase()
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			log.Printf("Applied %06d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Migrations up to date: %d applied", len(applied))
+		return nil
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			log.Printf("Reverted %06d_%s", migration.Version, migration.Name)
+		}
+		if err != nil {
+			return err
+		}
+		log.Printf("Migrations reverted: %d", len(reverted))
+		return nil
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := migrator.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.AppliedAt != nil {
+				state = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%06d_%-36s %s\n", status.Version, status.Name, state)
+		}
+		return nil
+	default:
+		return fmt.Errorf(usage)
+	}
+}
+
+// newRollupService builds the daily nutrition rollup service with its dependencies
+func newRollupService(db *gorm.DB) *dailyNutritionServices.DailyNutritionService {
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepo.NewMealLogItemRepository(db),
+		foodRepo.NewFoodRepository(db),
+		foodNutrientsRepo.NewFoodNutrientRepository(db),
+		nutrientRepo.NewNutrientRepository(db),
+	)
+	return dailyNutritionServices.NewDailyNutritionService(
+		dailyNutritionRepo.NewDailyNutritionRepository(db),
+		mealLogRepo.NewMealLogRepository(db),
+		engine,
+	)
+}
+
+// newRecipeService builds the recipe service, used to keep recipes in step with imported foods
+func newRecipeService(db *gorm.DB, rollup *dailyNutritionServices.DailyNutritionService) *recipeServices.RecipeService {
+	return recipeServices.NewRecipeService(
+		recipeRepo.NewRecipeRepository(db),
+		foodRepo.NewFoodRepository(db),
+		foodNutrientsRepo.NewFoodNutrientRepository(db),
+		nutrientRepo.NewNutrientRepository(db),
+		rollup,
+	)
+}
+
+// rebuildRollups regenerates the daily nutrition rollup from meal_log and meal_log_items
+func rebuildRollups() error {
+	db := database.ConnectDatabase()
+	rollup := newRollupService(db)
+
+	log.Println("Rebuilding daily nutrition rollups...")
+	if err := rollup.Rebuild(); err != nil {
+		return err
+	}
+	log.Println("Daily nutrition rollups rebuilt")
+	return nil
+}
+
+// importUSDA imports a USDA FoodData Central download from disk
+func importUSDA(path string) error {
+	db := database.ConnectDatabase()
+	rollup := newRollupService(db)
+	importService := foodImportServices.NewUSDAImportService(
+		foodImportRepo.NewFoodImportRepository(db),
+		nutrientRepo.NewNutrientRepository(db),
+		rollup,
+		newRecipeService(db, rollup),
+	)
+
+	log.Printf("Importing USDA foods from %s...", path)
+	result, err := importService.ImportPath(path)
+	if err != nil {
+		return err
+	}
+	log.Printf("USDA import finished: %d foods created, %d updated, %d skipped, %d nutrients created",
+		result.FoodsCreated, result.FoodsUpdated, result.FoodsSkipped, result.NutrientsCreated)
+	for _, refreshErr := range result.RefreshErrors {
+		log.Printf("Warning: %s", refreshErr)
+	}
+	return nil
+}
+
+// importOpenFoodFacts imports an Open Food Facts dump from disk
+func importOpenFoodFacts(path string) error {
+	db := database.ConnectDatabase()
+	rollup := newRollupService(db)
+	importService := foodImportServices.NewOpenFoodFactsImportService(
+		foodImportRepo.NewFoodImportRepository(db),
+		nutrientRepo.NewNutrientRepository(db),
+		rollup,
+		newRecipeService(db, rollup),
+	)
+
+	log.Printf("Importing Open Food Facts products from %s...", path)
+	result, err := importService.ImportPath(path)
+	if err != nil {
+		return err
+	}
+	log.Printf("Open Food Facts import finished: %d foods created, %d updated, %d skipped, %d nutrients created",
+		result.FoodsCreated, result.FoodsUpdated, result.FoodsSkipped, result.NutrientsCreated)
+	for _, refreshErr := range result.RefreshErrors {
+		log.Printf("Warning: %s", refreshErr)
+	}
+	return nil
+}
+
+// importAppleHealth imports the biometrics of an Apple Health export from disk for a user
+func importAppleHealth(userID uint, path string) error {
+	db := database.ConnectDatabase()
+	importService := userBiometricsServices.NewAppleHealthImportService(database.NewUnitOfWork(db), userBiometricsRepo.NewUserBiometricRepository(db))
+
+	log.Printf("Importing Apple Health biometrics for user %d from %s...", userID, path)
+	result, err := importService.ImportPath(userID, path)
+	if err != nil {
+		return err
+	}
+	log.Printf("Apple Health import finished: %d biometrics imported, %d duplicates, %d skipped",
+		result.Imported, result.Duplicates, result.Skipped)
+	return nil
+}
+
+// purgeAccounts deletes the accounts whose deletion grace period has ended
+func purgeAccounts() error {
+	db := database.ConnectDatabase()
+	accountService := accountServices.NewAccountService(accountRepo.NewAccountRepository(db), accountServices.DeletionGracePeriod())
+
+	log.Println("Purging accounts due for deletion...")
+	purged, err := accountService.PurgeDueAccounts(time.Now())
+	log.Printf("Account purge finished: %d accounts deleted", purged)
+	return err
+}
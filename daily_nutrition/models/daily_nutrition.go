@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+)
+
+// DailyNutritionTotal represents the daily_nutrition_totals table in the database.
+// Each row holds the amount of one nutrient a user consumed on one diary date.
+type DailyNutritionTotal struct {
+	ID         uint      `gorm:"primaryKey;column:id" json:"id"`
+	UserID     uint      `gorm:"column:user_id;not null;uniqueIndex:idx_daily_nutrition_totals_user_date_nutrient,priority:1" json:"user_id"`
+	Date       time.Time `gorm:"column:date;type:date;not null;uniqueIndex:idx_daily_nutrition_totals_user_date_nutrient,priority:2" json:"date"`
+	NutrientID uint      `gorm:"column:nutrient_id;not null;uniqueIndex:idx_daily_nutrition_totals_user_date_nutrient,priority:3" json:"nutrient_id"`
+	Amount     float64   `gorm:"column:amount;not null" json:"amount"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+}
+
+// TableName specifies the table name for the DailyNutritionTotal model
+func (DailyNutritionTotal) TableName() string {
+	return "daily_nutrition_totals"
+}
+
+// DailyNutritionStatus tracks whether the rollup of a user's diary date is up to date.
+// A day is marked stale before its source rows change and cleared once it is recomputed.
+type DailyNutritionStatus struct {
+	UserID     uint      `gorm:"primaryKey;autoIncrement:false;column:user_id" json:"user_id"`
+	Date       time.Time `gorm:"primaryKey;column:date;type:date" json:"date"`
+	Stale      bool      `gorm:"column:stale;not null;default:true" json:"stale"`
+	ComputedAt time.Time `gorm:"column:computed_at" json:"computed_at"`
+}
+
+// TableName specifies the table name for the DailyNutritionStatus model
+func (DailyNutritionStatus) TableName() string {
+	return "daily_nutrition_status"
+}
+
+// UserDay identifies one diary date of one user
+type UserDay struct {
+	UserID uint   `gorm:"column:user_id"`
+	Date   string `gorm:"column:date"`
+}
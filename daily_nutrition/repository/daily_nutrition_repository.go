@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/daily_nutrition/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// diaryDate is the SQL expression of the date a meal log was consumed on in its user's timezone.
+// Queries using it join "User" on meal_log.user_id.
+const diaryDate = `DATE(meal_log.consumed_at AT TIME ZONE "User".timezone)`
+
+// DailyNutritionRepository handles all database operations for the daily nutrition rollup
+type DailyNutritionRepository struct {
+	db *gorm.DB
+}
+
+// NewDailyNutritionRepository creates a new daily nutrition repository instance
+func NewDailyNutritionRepository(db *gorm.DB) *DailyNutritionRepository {
+	return &DailyNutritionRepository{db: db}
+}
+
+// GetByUserIDAndDateRange retrieves the rollup rows of a user between two dates (inclusive)
+func (r *DailyNutritionRepository) GetByUserIDAndDateRange(userID uint, startDate, endDate string) ([]models.DailyNutritionTotal, error) {
+	var totals []models.DailyNutritionTotal
+	err := r.db.Where("user_id = ? AND date >= ? AND date <= ?", userID, startDate, endDate).
+		Order("date ASC").
+		Find(&totals).Error
+	return totals, err
+}
+
+// IsRangeFresh reports whether every diary date of a user between two dates has an up to date
+// rollup, in a single query
+func (r *DailyNutritionRepository) IsRangeFresh(userID uint, startDate, endDate string) (bool, error) {
+	var counts struct {
+		Stale   int64
+		Tracked int64
+		Logged  int64
+	}
+	err := r.db.Raw(`SELECT
+		(SELECT COUNT(*) FROM daily_nutrition_status
+			WHERE user_id = @user AND date >= @start AND date <= @end AND stale) AS stale,
+		(SELECT COUNT(*) FROM daily_nutrition_status
+			WHERE user_id = @user AND date >= @start AND date <= @end) AS tracked,
+		(SELECT COUNT(DISTINCT `+diaryDate+`) FROM meal_log JOIN "User" ON "User".id = meal_log.user_id
+			WHERE meal_log.user_id = @user AND `+diaryDate+` >= @start AND `+diaryDate+` <= @end) AS logged`,
+		map[string]interface{}{"user": userID, "start": startDate, "end": endDate}).
+		Scan(&counts).Error
+	if err != nil {
+		return false, err
+	}
+
+	return counts.Stale == 0 && counts.Tracked == counts.Logged, nil
+}
+
+// MarkStale flags the rollup of the given days as out of date
+func (r *DailyNutritionRepository) MarkStale(days []models.UserDay) error {
+	if len(days) == 0 {
+		return nil
+	}
+
+	statuses := make([]models.DailyNutritionStatus, 0, len(days))
+	for _, day := range days {
+		date, err := time.Parse("2006-01-02", day.Date)
+		if err != nil {
+			return err
+		}
+		statuses = append(statuses, models.DailyNutritionStatus{UserID: day.UserID, Date: date, Stale: true})
+	}
+
+	return r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+		DoUpdates: clause.Assignments(map[string]interface{}{"stale": true}),
+	}).Create(&statuses).Error
+}
+
+// MarkFoodsStale flags the rollup of every user diary date containing an item of any of the given
+// foods as out of date, in a single statement
+func (r *DailyNutritionRepository) MarkFoodsStale(foodIDs []uint) error {
+	if len(foodIDs) == 0 {
+		return nil
+	}
+	return r.db.Exec(`INSERT INTO daily_nutrition_status (user_id, date, stale)
+		SELECT DISTINCT meal_log.user_id, `+diaryDate+`, TRUE
+		FROM meal_log_items
+		JOIN meal_log ON meal_log.id = meal_log_items.meal_log_id
+		JOIN "User" ON "User".id = meal_log.user_id
+		WHERE meal_log_items.food_id IN ?
+		ON CONFLICT (user_id, date) DO UPDATE SET stale = TRUE`, foodIDs).Error
+}
+
+// ReplaceDay atomically swaps the rollup rows of one day for the given totals and marks it fresh.
+// A nil totals map removes the day from the rollup, e.g. after its last meal log was deleted.
+func (r *DailyNutritionRepository) ReplaceDay(userID uint, date string, totals map[uint]float64) error {
+	day, err := time.Parse("2006-01-02", date)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND date = ?", userID, date).Delete(&models.DailyNutritionTotal{}).Error; err != nil {
+			return err
+		}
+
+		if totals == nil {
+			return tx.Where("user_id = ? AND date = ?", userID, date).Delete(&models.DailyNutritionStatus{}).Error
+		}
+
+		now := time.Now()
+		rows := make([]models.DailyNutritionTotal, 0, len(totals))
+		for nutrientID, amount := range totals {
+			rows = append(rows, models.DailyNutritionTotal{
+				UserID:     userID,
+				Date:       day,
+				NutrientID: nutrientID,
+				Amount:     amount,
+				UpdatedAt:  now,
+			})
+		}
+		if len(rows) > 0 {
+			if err := tx.Create(&rows).Error; err != nil {
+				return err
+			}
+		}
+
+		status := models.DailyNutritionStatus{UserID: userID, Date: day, Stale: false, ComputedAt: now}
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "date"}},
+			DoUpdates: clause.AssignmentColumns([]string{"stale", "computed_at"}),
+		}).Create(&status).Error
+	})
+}
+
+// GetDaysByFoodIDs retrieves every user diary date containing an item of any of the given foods
+func (r *DailyNutritionRepository) GetDaysByFoodIDs(foodIDs []uint) ([]models.UserDay, error) {
+	var days []models.UserDay
+	err := r.db.Table("meal_log_items").
+		Select("DISTINCT meal_log.user_id AS user_id, TO_CHAR("+diaryDate+", 'YYYY-MM-DD') AS date").
+		Joins("JOIN meal_log ON meal_log.id = meal_log_items.meal_log_id").
+		Joins(`JOIN "User" ON "User".id = meal_log.user_id`).
+		Where("meal_log_items.food_id IN ?", foodIDs).
+		Scan(&days).Error
+	return days, err
+}
+
+// GetLoggedUserIDs retrieves the IDs of every user with at least one meal log
+func (r *DailyNutritionRepository) GetLoggedUserIDs() ([]uint, error) {
+	var userIDs []uint
+	err := r.db.Table("meal_log").Distinct("user_id").Order("user_id").Pluck("user_id", &userIDs).Error
+	return userIDs, err
+}
+
+// DeleteByUserID removes the whole rollup of one user
+func (r *DailyNutritionRepository) DeleteByUserID(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ?", userID).Delete(&models.DailyNutritionTotal{}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.DailyNutritionStatus{}).Error
+	})
+}
+
+// DeleteAll empties the rollup so it can be rebuilt from scratch
+func (r *DailyNutritionRepository) DeleteAll() error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.DailyNutritionTotal{}).Error; err != nil {
+			return err
+		}
+		return tx.Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&models.DailyNutritionStatus{}).Error
+	})
+}
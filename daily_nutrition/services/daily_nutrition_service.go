@@ -0,0 +1,272 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/daily_nutrition/models"
+	"github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+)
+
+// DailyNutritionService maintains the daily_nutrition_totals rollup. Services that
+// change diary or food composition rows call the Refresh methods afterwards so the
+// rollup stays in step with the raw data.
+type DailyNutritionService struct {
+	repo        *repository.DailyNutritionRepository
+	mealLogRepo *mealLogRepo.MealLogRepository
+	engine      *nutritionEngine.NutritionEngine
+}
+
+// NewDailyNutritionService creates a new daily nutrition service instance
+func NewDailyNutritionService(
+	repo *repository.DailyNutritionRepository,
+	mealLogRepo *mealLogRepo.MealLogRepository,
+	engine *nutritionEngine.NutritionEngine,
+) *DailyNutritionService {
+	return &DailyNutritionService{
+		repo:        repo,
+		mealLogRepo: mealLogRepo,
+		engine:      engine,
+	}
+}
+
+// MarkDaysStale flags the rollup of the user diary dates the given times fall on in the user's
+// timezone as out of date. Services call it before changing the meal logs or items of those days, so
+// readers fall back to the raw rows while they are being written, and call RefreshDay once they are saved.
+func (s *DailyNutritionService) MarkDaysStale(userID uint, times ...time.Time) error {
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get user timezone: %w", err)
+	}
+
+	days := make([]models.UserDay, 0, len(times))
+	seen := make(map[string]bool, len(times))
+	for _, t := range times {
+		// A day may only appear once in the upsert
+		if date := helpers.DateIn(t, loc); !seen[date] {
+			seen[date] = true
+			days = append(days, models.UserDay{UserID: userID, Date: date})
+		}
+	}
+	if err := s.repo.MarkStale(days); err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to mark rollup stale: %w", err)
+	}
+	return nil
+}
+
+// MarkMealLogStale flags the rollup of the day a meal log belongs to as out of date
+func (s *DailyNutritionService) MarkMealLogStale(mealLogID uint) error {
+	mealLog, err := s.mealLogRepo.GetByID(mealLogID)
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get meal log: %w", err)
+	}
+
+	return s.MarkDaysStale(mealLog.UserID, mealLog.ConsumedAt)
+}
+
+// RefreshDay recomputes the rollup of the user diary date consumedAt falls on in the user's
+// timezone from the raw meal log rows. The day was marked stale with MarkDaysStale before its rows
+// changed, so a failed recomputation leaves it stale and readers fall back to the raw rows.
+func (s *DailyNutritionService) RefreshDay(userID uint, consumedAt time.Time) error {
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get user timezone: %w", err)
+	}
+
+	return s.recomputeDay(userID, consumedAt.In(loc), loc)
+}
+
+// RefreshMealLog recomputes the rollup of the day a meal log belongs to
+func (s *DailyNutritionService) RefreshMealLog(mealLogID uint) error {
+	mealLog, err := s.mealLogRepo.GetByID(mealLogID)
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get meal log: %w", err)
+	}
+
+	return s.RefreshDay(mealLog.UserID, mealLog.ConsumedAt)
+}
+
+// MarkFoodsStale flags the rollup of every day on which any of the given foods was logged as out
+// of date, in a single statement. Services call it before changing the nutrient profile of the
+// foods and call RefreshFoods once it is saved; a failure must stop the change, or the old totals
+// would keep being served as fresh.
+func (s *DailyNutritionService) MarkFoodsStale(foodIDs ...uint) error {
+	if err := s.repo.MarkFoodsStale(foodIDs); err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to mark rollup stale: %w", err)
+	}
+	return nil
+}
+
+// RefreshFoods recomputes the rollup of every day on which any of the given foods was logged. The
+// days were marked stale with MarkFoodsStale before the foods changed, so a failed recomputation
+// leaves them stale and readers fall back to the raw rows. Callers changing many foods should pass
+// them in chunks rather than one by one.
+func (s *DailyNutritionService) RefreshFoods(foodIDs ...uint) error {
+	if len(foodIDs) == 0 {
+		return nil
+	}
+	days, err := s.repo.GetDaysByFoodIDs(foodIDs)
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get days for food: %w", err)
+	}
+
+	locations := make(map[uint]*time.Location)
+	for _, userDay := range days {
+		day, err := time.Parse("2006-01-02", userDay.Date)
+		if err != nil {
+			return err
+		}
+		loc, ok := locations[userDay.UserID]
+		if !ok {
+			if loc, err = s.mealLogRepo.GetUserLocation(userDay.UserID); err != nil {
+				helpers.LogError(err)
+				return fmt.Errorf("failed to get timezone of user %d: %w", userDay.UserID, err)
+			}
+			locations[userDay.UserID] = loc
+		}
+		if err := s.recomputeDay(userDay.UserID, day, loc); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rebuild discards the whole rollup and regenerates it from meal_log and meal_log_items
+func (s *DailyNutritionService) Rebuild() error {
+	if err := s.repo.DeleteAll(); err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to clear rollup: %w", err)
+	}
+
+	userIDs, err := s.repo.GetLoggedUserIDs()
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get users: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		if err := s.rebuildUser(userID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RebuildUser discards the rollup of one user and regenerates it from the raw rows, e.g. after
+// the user changed timezone and every diary day moved
+func (s *DailyNutritionService) RebuildUser(userID uint) error {
+	if err := s.repo.DeleteByUserID(userID); err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to clear rollup of user %d: %w", userID, err)
+	}
+
+	return s.rebuildUser(userID)
+}
+
+// GetFreshReport returns a report built from the rollup for a user between two dates.
+// The boolean is false when any day in the range is stale or missing, in which case
+// callers should compute from the raw rows instead.
+func (s *DailyNutritionService) GetFreshReport(userID uint, startDate, endDate time.Time) (*nutritionEngine.NutritionReport, bool, error) {
+	start := startDate.Format("2006-01-02")
+	end := endDate.Format("2006-01-02")
+
+	fresh, err := s.repo.IsRangeFresh(userID, start, end)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, false, fmt.Errorf("failed to check rollup freshness: %w", err)
+	}
+	if !fresh {
+		return nil, false, nil
+	}
+
+	rows, err := s.repo.GetByUserIDAndDateRange(userID, start, end)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, false, fmt.Errorf("failed to get rollup: %w", err)
+	}
+
+	days := []nutritionEngine.DayNutrition{}
+	for _, row := range rows {
+		date := row.Date.Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, nutritionEngine.DayNutrition{Date: date, Nutrients: nutritionEngine.NutrientTotals{}})
+		}
+		days[len(days)-1].Nutrients[row.NutrientID] += row.Amount
+	}
+
+	report, err := s.engine.ReportFromDays(days)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return report, true, nil
+}
+
+// rebuildUser calculates every diary day of a user from the raw rows and stores them in the rollup
+func (s *DailyNutritionService) rebuildUser(userID uint) error {
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get timezone of user %d: %w", userID, err)
+	}
+
+	mealLogs, err := s.mealLogRepo.GetByUserID(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get meal logs for user %d: %w", userID, err)
+	}
+
+	report, err := s.engine.Calculate(mealLogs, loc)
+	if err != nil {
+		return fmt.Errorf("failed to calculate nutrition for user %d: %w", userID, err)
+	}
+
+	for _, day := range report.Days {
+		if err := s.repo.ReplaceDay(userID, day.Date, day.Nutrients); err != nil {
+			helpers.LogError(err)
+			return fmt.Errorf("failed to store rollup for user %d on %s: %w", userID, day.Date, err)
+		}
+	}
+
+	log.Printf("[daily_nutrition] Rebuilt %d days for user %d", len(report.Days), userID)
+	return nil
+}
+
+// recomputeDay calculates the calendar date of day in loc from the raw rows and stores it in the rollup
+func (s *DailyNutritionService) recomputeDay(userID uint, day time.Time, loc *time.Location) error {
+	mealLogs, err := s.mealLogRepo.GetByUserIDAndDate(userID, day, loc)
+	if err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to get meal logs: %w", err)
+	}
+
+	report, err := s.engine.Calculate(mealLogs, loc)
+	if err != nil {
+		return err
+	}
+
+	totals := report.Totals
+	if len(mealLogs) == 0 {
+		totals = nil
+	}
+
+	if err := s.repo.ReplaceDay(userID, day.Format("2006-01-02"), totals); err != nil {
+		helpers.LogError(err)
+		return fmt.Errorf("failed to store rollup: %w", err)
+	}
+
+	return nil
+}
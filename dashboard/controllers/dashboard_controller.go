@@ -21,11 +21,11 @@ func NewDashboardController(service *services.DashboardService) *DashboardContro
 
 // GetUserDashboard godoc
 // @Summary      Get user dashboard
-// @Description  Get calorie summary and nutrition data for a specific date
+// @Description  Get calorie summary and nutrition data for a specific date, with the energy burned by logged exercise, the net calories, the user's daily calorie and macro targets and the remaining budget
 // @Tags         dashboard
 // @Accept       json
 // @Produce      json
-// @Param        date  query     string  false  "Date in YYYY-MM-DD format (default: today)"
+// @Param        date  query     string  false  "Date in YYYY-MM-DD format (default: today in the user's timezone)"
 // @Success      200   {object}  dto.DashboardResponseDTO  "Dashboard data retrieved successfully"
 // @Failure      400   {object}  map[string]string         "Invalid date format"
 // @Failure      401   {object}  map[string]string         "Unauthorized"
@@ -40,12 +40,14 @@ func (c *DashboardController) GetUserDashboard(ctx *gin.Context) {
 		return
 	}
 
-	//Get date from query parameter (if not have, default is today)
-	dateStr := ctx.DefaultQuery("date", time.Now().Format("2006-01-02"))
-	date, err := time.Parse("2006-01-02", dateStr)
-	if err != nil {
-		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
-		return
+	//Get date from query parameter (if not have, default is today in the user's timezone)
+	var date time.Time
+	if dateStr := ctx.Query("date"); dateStr != "" {
+		var err error
+		if date, err = time.Parse("2006-01-02", dateStr); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
 	}
 
 	//Get dashboard data from service
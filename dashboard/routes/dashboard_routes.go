@@ -3,13 +3,21 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepository "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
 	"github.com/momokapoolz/caloriesapp/dashboard/controllers"
 	"github.com/momokapoolz/caloriesapp/dashboard/services"
+	exerciseLogRepository "github.com/momokapoolz/caloriesapp/exercise_log/repository"
 	foodRepository "github.com/momokapoolz/caloriesapp/food/repository"
 	foodNutrientsRepository "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
 	mealLogRepository "github.com/momokapoolz/caloriesapp/meal_log/repository"
 	mealLogItemsRepository "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
 	nutrientRepository "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	targetsRepository "github.com/momokapoolz/caloriesapp/targets/repository"
+	targetsServices "github.com/momokapoolz/caloriesapp/targets/services"
+	userRepository "github.com/momokapoolz/caloriesapp/user/repository"
+	userBiometricsRepository "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
 	"gorm.io/gorm"
 )
 
@@ -22,13 +30,38 @@ func SetupDashboardRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	nutrientRepo := nutrientRepository.NewNutrientRepository(db)
 	foodNutrientsRepo := foodNutrientsRepository.NewFoodNutrientRepository(db)
 
-	// Initialize service
-	dashboardService := services.NewDashboardService(
-		mealLogRepo,
+	// Initialize the shared nutrition engine
+	engine := nutritionEngine.NewNutritionEngine(
 		mealLogItemsRepo,
 		foodRepo,
-		nutrientRepo,
 		foodNutrientsRepo,
+		nutrientRepo,
+	)
+
+	// Initialize the daily nutrition rollup
+	rollup := dailyNutritionServices.NewDailyNutritionService(
+		dailyNutritionRepository.NewDailyNutritionRepository(db),
+		mealLogRepo,
+		engine,
+	)
+
+	// Initialize the targets computed from the user's profile, biometrics and weight trend
+	biometricRepo := userBiometricsRepository.NewUserBiometricRepository(db)
+	targets := targetsServices.NewTargetsService(
+		userRepository.NewUserRepository(),
+		biometricRepo,
+		targetsRepository.NewTargetSetRepository(db),
+		nutrientRepo,
+		targetsServices.NewAdaptiveTDEEService(biometricRepo, mealLogRepo, engine, rollup),
+	)
+
+	// Initialize service
+	dashboardService := services.NewDashboardService(
+		mealLogRepo,
+		exerciseLogRepository.NewExerciseLogRepository(db),
+		engine,
+		rollup,
+		targets,
 	)
 
 	// Initialize controller
@@ -1,174 +1,173 @@
 package services
 
 import (
+	"errors"
 	"fmt"
 	"math"
 	"time"
 
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
 	"github.com/momokapoolz/caloriesapp/dto"
-	"github.com/momokapoolz/caloriesapp/food/repository"
-	foodNutrientsRepository "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	exerciseLogRepository "github.com/momokapoolz/caloriesapp/exercise_log/repository"
 	"github.com/momokapoolz/caloriesapp/helpers"
 	mealLogRepository "github.com/momokapoolz/caloriesapp/meal_log/repository"
-	mealLogItemsRepository "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
-	nutrientRepository "github.com/momokapoolz/caloriesapp/nutrient/repository"
-)
-
-// Nutrient IDs for macronutrients (these should match your database)
-const (
-	CaloriesNutrientID     = 1
-	ProteinNutrientID      = 2
-	CarbohydrateNutrientID = 3
-	FatNutrientID          = 4
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	targetsServices "github.com/momokapoolz/caloriesapp/targets/services"
 )
 
 // DashboardService handles business logic for dashboard operations (DI)
 type DashboardService struct {
-	mealLogRepo       *mealLogRepository.MealLogRepository
-	mealLogItemsRepo  *mealLogItemsRepository.MealLogItemRepository
-	foodRepo          *repository.FoodRepository
-	nutrientRepo      *nutrientRepository.NutrientRepository
-	foodNutrientsRepo *foodNutrientsRepository.FoodNutrientRepository
+	mealLogRepo  *mealLogRepository.MealLogRepository
+	exerciseRepo *exerciseLogRepository.ExerciseLogRepository
+	engine       *nutritionEngine.NutritionEngine
+	rollup       *dailyNutritionServices.DailyNutritionService
+	targets      *targetsServices.TargetsService
 }
 
 // NewDashboardService creates a new dashboard service instance (Constructor)
 func NewDashboardService(
 	mealLogRepo *mealLogRepository.MealLogRepository,
-	mealLogItemsRepo *mealLogItemsRepository.MealLogItemRepository,
-	foodRepo *repository.FoodRepository,
-	nutrientRepo *nutrientRepository.NutrientRepository,
-	foodNutrientsRepo *foodNutrientsRepository.FoodNutrientRepository,
+	exerciseRepo *exerciseLogRepository.ExerciseLogRepository,
+	engine *nutritionEngine.NutritionEngine,
+	rollup *dailyNutritionServices.DailyNutritionService,
+	targets *targetsServices.TargetsService,
 ) *DashboardService {
 	return &DashboardService{
-		mealLogRepo:       mealLogRepo,
-		mealLogItemsRepo:  mealLogItemsRepo,
-		foodRepo:          foodRepo,
-		nutrientRepo:      nutrientRepo,
-		foodNutrientsRepo: foodNutrientsRepo,
+		mealLogRepo:  mealLogRepo,
+		exerciseRepo: exerciseRepo,
+		engine:       engine,
+		rollup:       rollup,
+		targets:      targets,
 	}
 }
 
-// GetUserDashboard retrieves dashboard data for a user on a specific date
+// GetUserDashboard retrieves dashboard data for a user on a calendar date in the user's timezone.
+// A zero date stands for the user's today.
 func (s *DashboardService) GetUserDashboard(userID uint, date time.Time) (*dto.DashboardResponseDTO, error) {
-	// Get all meal logs for the user on the specified date
-	mealLogs, err := s.mealLogRepo.GetByUserIDAndDate(userID, date)
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	if date.IsZero() {
+		date = time.Now().In(loc)
+	}
+
+	// Get all meal logs the user consumed on the specified date
+	mealLogs, err := s.mealLogRepo.GetByUserIDAndDate(userID, date, loc)
 	if err != nil {
 		helpers.LogError(err)
 		return nil, fmt.Errorf("failed to get meal logs: %w", err)
 	}
 
+	// Day totals come from the rollup when it is fresh, so the meals are only needed for their
+	// calories. Otherwise the macros are calculated from the raw rows along with them.
+	totals, fresh, err := s.rollup.GetFreshReport(userID, date, date)
+	if err != nil {
+		helpers.LogError(err)
+	}
+	codes := []string{nutrientModels.CodeEnergy}
+	if !fresh {
+		codes = append(codes, nutrientModels.CodeProtein, nutrientModels.CodeCarbohydrate, nutrientModels.CodeFat)
+	}
+
+	report, err := s.engine.CalculateMeals(mealLogs, loc, codes...)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to calculate nutrition: %w", err)
+	}
+	if !fresh {
+		totals = report
+	}
+
 	// Create the response DTO
 	dashboard := &dto.DashboardResponseDTO{
-		Date:                date.Format("2006-01-02"),
-		NumberOfMeals:       len(mealLogs),
-		MealLogs:            make([]dto.MealLogSummaryDTO, 0, len(mealLogs)),
-		TotalMacronutrients: dto.MacronutrientsDTO{},
+		Date:          date.Format("2006-01-02"),
+		NumberOfMeals: len(mealLogs),
+		MealLogs:      make([]dto.MealLogSummaryDTO, 0, len(report.Meals)),
+		TotalCalories: roundTo2dp(totals.Amount(totals.Totals, nutrientModels.CodeEnergy)),
+		TotalMacronutrients: dto.MacronutrientsDTO{
+			Protein:      roundTo2dp(totals.Amount(totals.Totals, nutrientModels.CodeProtein)),
+			Carbohydrate: roundTo2dp(totals.Amount(totals.Totals, nutrientModels.CodeCarbohydrate)),
+			Fat:          roundTo2dp(totals.Amount(totals.Totals, nutrientModels.CodeFat)),
+		},
 	}
 
-	var totalCalories float64
-
-	// Process each meal log
-	for _, mealLog := range mealLogs {
+	for _, meal := range report.Meals {
 		mealLogSummary := dto.MealLogSummaryDTO{
-			ID:        mealLog.ID,
-			MealType:  mealLog.MealType,
-			CreatedAt: mealLog.CreatedAt,
-			FoodItems: []dto.FoodItemSummaryDTO{},
+			ID:            meal.MealLog.ID,
+			MealType:      meal.MealLog.MealType,
+			CreatedAt:     meal.MealLog.CreatedAt,
+			ConsumedAt:    meal.MealLog.ConsumedAt.In(loc),
+			TotalCalories: roundTo2dp(report.Amount(meal.Nutrients, nutrientModels.CodeEnergy)),
+			FoodItems:     make([]dto.FoodItemSummaryDTO, 0, len(meal.Items)),
 		}
 
-		// Get meal log items for this meal log
-		items, err := s.mealLogItemsRepo.GetByMealLogID(mealLog.ID)
-		if err != nil {
-			helpers.LogError(err)
-			return nil, fmt.Errorf("failed to get meal log items: %w", err)
-		}
-
-		var mealCalories float64
-
-		// Process each meal log item
-		for _, item := range items {
-			// Get food information
-			food, err := s.foodRepo.GetByID(item.FoodID)
-			if err != nil {
-				helpers.LogError(err)
-				return nil, fmt.Errorf("failed to get food: %w", err)
-			}
-
-			// Calculate calories for this food item
-			calories, err := s.calculateCalories(item.FoodID, item.QuantityGrams)
-			if err != nil {
-				helpers.LogError(err)
-				return nil, fmt.Errorf("failed to calculate calories: %w", err)
-			}
-
-			// Create food item summary
-			foodItem := dto.FoodItemSummaryDTO{
-				ID:            item.ID,
-				FoodID:        item.FoodID,
-				FoodName:      food.Name,
-				Quantity:      item.Quantity,
-				QuantityGrams: item.QuantityGrams,
-				Calories:      calories,
-			}
-
-			mealLogSummary.FoodItems = append(mealLogSummary.FoodItems, foodItem)
-			mealCalories += calories
+		for _, item := range meal.Items {
+			mealLogSummary.FoodItems = append(mealLogSummary.FoodItems, dto.FoodItemSummaryDTO{
+				ID:            item.Item.ID,
+				FoodID:        item.Item.FoodID,
+				FoodName:      item.FoodName,
+				Quantity:      item.Item.Quantity,
+				Unit:          item.Item.Unit,
+				Amount:        item.Item.Amount,
+				QuantityGrams: item.Item.QuantityGrams,
+				Calories:      roundTo2dp(report.Amount(item.Nutrients, nutrientModels.CodeEnergy)),
+			})
 		}
 
-		mealLogSummary.TotalCalories = mealCalories
 		dashboard.MealLogs = append(dashboard.MealLogs, mealLogSummary)
-		totalCalories += mealCalories
-
-		// Calculate macronutrients (optional)
-		protein, carbs, fat, err := s.calculateMacronutrients(mealLog.ID)
-		if err == nil { // Only update if calculation succeeds
-			dashboard.TotalMacronutrients.Protein += protein
-			dashboard.TotalMacronutrients.Carbohydrate += carbs
-			dashboard.TotalMacronutrients.Fat += fat
-		}
 	}
 
-	dashboard.TotalCalories = totalCalories
-
-	return dashboard, nil
-}
-
-// roundTo2dp rounds a float64 to 2 decimal places to avoid floating point display errors.
-func roundTo2dp(v float64) float64 {
-	return math.Round(v*100) / 100
-}
-
-// calculateCalories calculates the calories for a specific food item and quantity
-func (s *DashboardService) calculateCalories(foodID uint, grams float64) (float64, error) {
-	foodNutrient, err := s.foodNutrientsRepo.GetByFoodIDAndNutrientID(foodID, CaloriesNutrientID)
+	// Energy burned by the exercise logged on the date
+	exercises, err := s.exerciseRepo.GetByUserIDAndDate(userID, date, loc)
 	if err != nil {
-		// Fallback: 4 kcal per gram if no calorie record exists for the food
-		return roundTo2dp(grams * 4), nil
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get exercise logs: %w", err)
 	}
+	var burned float64
+	for _, exercise := range exercises {
+		burned += exercise.CaloriesBurned
+	}
+	dashboard.CaloriesBurned = roundTo2dp(burned)
+	dashboard.NetCalories = roundTo2dp(dashboard.TotalCalories - burned)
+
+	s.addTargets(dashboard, userID, date)
 
-	calories := (foodNutrient.AmountPer100g / 100) * grams
-	return roundTo2dp(calories), nil
+	return dashboard, nil
 }
 
-// calculateMacronutrients calculates protein, carbs, and fat for all items in a meal log
-func (s *DashboardService) calculateMacronutrients(mealLogID uint) (protein, carbs, fat float64, err error) {
-	items, err := s.mealLogItemsRepo.GetByMealLogID(mealLogID)
+// addTargets puts the user's targets for the date and the remaining budget next to the consumed totals.
+// Users without a complete profile or configured targets get none; the rest of the dashboard is still returned.
+func (s *DashboardService) addTargets(dashboard *dto.DashboardResponseDTO, userID uint, date time.Time) {
+	targets, err := s.targets.GetDailyTargets(userID, date)
 	if err != nil {
-		helpers.LogError(err)
-		return 0, 0, 0, fmt.Errorf("failed to get meal log items: %w", err)
+		if !errors.Is(err, targetsServices.ErrIncompleteProfile) {
+			helpers.LogError(err)
+		}
+		return
 	}
 
-	for _, item := range items {
-		if fn, e := s.foodNutrientsRepo.GetByFoodIDAndNutrientID(item.FoodID, ProteinNutrientID); e == nil {
-			protein += (fn.AmountPer100g / 100) * item.QuantityGrams
-		}
-		if fn, e := s.foodNutrientsRepo.GetByFoodIDAndNutrientID(item.FoodID, CarbohydrateNutrientID); e == nil {
-			carbs += (fn.AmountPer100g / 100) * item.QuantityGrams
-		}
-		if fn, e := s.foodNutrientsRepo.GetByFoodIDAndNutrientID(item.FoodID, FatNutrientID); e == nil {
-			fat += (fn.AmountPer100g / 100) * item.QuantityGrams
-		}
+	targets.BMR = roundTo2dp(targets.BMR)
+	targets.TDEE = roundTo2dp(targets.TDEE)
+	targets.TDEEConfidence = roundTo2dp(targets.TDEEConfidence)
+	targets.GoalAdjustment = roundTo2dp(targets.GoalAdjustment)
+	targets.Calories = roundTo2dp(targets.Calories)
+	targets.Protein = roundTo2dp(targets.Protein)
+	targets.Carbohydrate = roundTo2dp(targets.Carbohydrate)
+	targets.Fat = roundTo2dp(targets.Fat)
+
+	dashboard.Targets = targets
+	dashboard.Remaining = &dto.RemainingBudgetDTO{
+		Calories:     roundTo2dp(targets.Calories - dashboard.TotalCalories),
+		Protein:      roundTo2dp(targets.Protein - dashboard.TotalMacronutrients.Protein),
+		Carbohydrate: roundTo2dp(targets.Carbohydrate - dashboard.TotalMacronutrients.Carbohydrate),
+		Fat:          roundTo2dp(targets.Fat - dashboard.TotalMacronutrients.Fat),
 	}
+}
 
-	return roundTo2dp(protein), roundTo2dp(carbs), roundTo2dp(fat), nil
+// roundTo2dp rounds a float64 to 2 decimal places to avoid floating point display errors.
+func roundTo2dp(v float64) float64 {
+	return math.Round(v*100) / 100
 }
@@ -0,0 +1,79 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// PostgreSQL error codes of constraint violations
+const (
+	pgNotNullViolation    = "23502"
+	pgForeignKeyViolation = "23503"
+	pgUniqueViolation     = "23505"
+	pgCheckViolation      = "23514"
+)
+
+// Kinds of constraint violations, matched with errors.Is on a *ConstraintError
+var (
+	// ErrDuplicate is returned when a write repeats a value that must be unique
+	ErrDuplicate = errors.New("record already exists")
+	// ErrReferenceNotFound is returned when a write points at a record that does not exist
+	ErrReferenceNotFound = errors.New("referenced record does not exist")
+	// ErrStillReferenced is returned when deleting a record that other records still point at
+	ErrStillReferenced = errors.New("record is still in use")
+	// ErrInvalidValue is returned when a value breaks a check or not-null constraint
+	ErrInvalidValue = errors.New("invalid value")
+)
+
+// ConstraintError is a write the database rejected because it breaks a constraint of the schema
+type ConstraintError struct {
+	Kind       error
+	Table      string
+	Constraint string
+	Detail     string
+}
+
+// Error describes the violation with the detail PostgreSQL gave, such as the duplicated key
+func (e *ConstraintError) Error() string {
+	if e.Detail != "" {
+		return fmt.Sprintf("%s: %s", e.Kind, e.Detail)
+	}
+	return fmt.Sprintf("%s: violates %s", e.Kind, e.Constraint)
+}
+
+// Unwrap returns the kind of violation
+func (e *ConstraintError) Unwrap() error {
+	return e.Kind
+}
+
+// TranslateError turns a constraint violation reported by PostgreSQL into a *ConstraintError.
+// Other errors, nil included, are returned unchanged.
+func TranslateError(err error) error {
+	var pgErr *pgconn.PgError
+	if !errors.As(err, &pgErr) {
+		return err
+	}
+
+	constraintErr := &ConstraintError{Table: pgErr.TableName, Constraint: pgErr.ConstraintName, Detail: pgErr.Detail}
+	switch pgErr.Code {
+	case pgUniqueViolation:
+		constraintErr.Kind = ErrDuplicate
+	case pgForeignKeyViolation:
+		// The same code covers inserting a dangling reference and deleting a referenced row
+		constraintErr.Kind = ErrReferenceNotFound
+		if strings.Contains(pgErr.Detail, "is still referenced") {
+			constraintErr.Kind = ErrStillReferenced
+		}
+	case pgCheckViolation:
+		// The detail lists the whole failing row, which is not worth echoing back
+		constraintErr.Kind, constraintErr.Detail = ErrInvalidValue, ""
+	case pgNotNullViolation:
+		constraintErr.Kind, constraintErr.Detail = ErrInvalidValue, pgErr.ColumnName+" is required"
+	default:
+		return err
+	}
+	return constraintErr
+}
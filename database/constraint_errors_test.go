@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+func TestTranslateError(t *testing.T) {
+	cases := []struct {
+		name    string
+		pgErr   *pgconn.PgError
+		kind    error
+		message string
+	}{
+		{
+			name: "duplicate",
+			pgErr: &pgconn.PgError{Code: "23505", TableName: "food_nutrients", ConstraintName: "food_nutrients_food_nutrient_key",
+				Detail: "Key (food_id, nutrient_id)=(1, 2) already exists."},
+			kind:    ErrDuplicate,
+			message: "record already exists: Key (food_id, nutrient_id)=(1, 2) already exists.",
+		},
+		{
+			name: "missing reference",
+			pgErr: &pgconn.PgError{Code: "23503", TableName: "meal_log_items", ConstraintName: "meal_log_items_fk2",
+				Detail: `Key (food_id)=(99) is not present in table "food".`},
+			kind: ErrReferenceNotFound,
+		},
+		{
+			name: "still referenced",
+			pgErr: &pgconn.PgError{Code: "23503", TableName: "food_nutrients", ConstraintName: "food_nutrients_fk2",
+				Detail: `Key (id)=(3) is still referenced from table "food_nutrients".`},
+			kind: ErrStillReferenced,
+		},
+		{
+			name: "check",
+			pgErr: &pgconn.PgError{Code: "23514", TableName: "food_nutrients", ConstraintName: "food_nutrients_amount_per_100g_check",
+				Detail: "Failing row contains (1, 1, 2, -5)."},
+			kind:    ErrInvalidValue,
+			message: "invalid value: violates food_nutrients_amount_per_100g_check",
+		},
+		{
+			name:    "not null",
+			pgErr:   &pgconn.PgError{Code: "23502", TableName: "meal_log", ColumnName: "meal_type"},
+			kind:    ErrInvalidValue,
+			message: "invalid value: meal_type is required",
+		},
+	}
+	for _, tc := range cases {
+		err := TranslateError(fmt.Errorf("failed to save: %w", tc.pgErr))
+		var constraintErr *ConstraintError
+		if !errors.As(err, &constraintErr) || !errors.Is(err, tc.kind) {
+			t.Errorf("%s: got %v, want a %v constraint error", tc.name, err, tc.kind)
+			continue
+		}
+		if constraintErr.Constraint != tc.pgErr.ConstraintName || constraintErr.Table != tc.pgErr.TableName {
+			t.Errorf("%s: constraint %s on %s", tc.name, constraintErr.Constraint, constraintErr.Table)
+		}
+		if tc.message != "" && err.Error() != tc.message {
+			t.Errorf("%s: message %q, want %q", tc.name, err.Error(), tc.message)
+		}
+	}
+
+	other := &pgconn.PgError{Code: "40001"}
+	if err := TranslateError(other); err != other {
+		t.Errorf("serialization failure translated to %v", err)
+	}
+	if err := TranslateError(nil); err != nil {
+		t.Errorf("TranslateError(nil) = %v", err)
+	}
+}
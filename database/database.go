@@ -6,13 +6,6 @@ import (
 	"os"
 	"time"
 
-	"github.com/momokapoolz/caloriesapp/food/models"
-	food_nutrients_models "github.com/momokapoolz/caloriesapp/food_nutrients/models"
-	meal_log_models "github.com/momokapoolz/caloriesapp/meal_log/models"
-	meal_log_items_models "github.com/momokapoolz/caloriesapp/meal_log_items/models"
-	nutrient_models "github.com/momokapoolz/caloriesapp/nutrient/models"
-	user_models "github.com/momokapoolz/caloriesapp/user/models"
-	user_biometrics_models "github.com/momokapoolz/caloriesapp/user_biometrics/models"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
@@ -50,7 +43,8 @@ func GetDBConfig() DBConfig {
 	}
 }
 
-// ConnectDatabase initializes the database connection
+// ConnectDatabase initializes the database connection. It never changes the schema, which is
+// managed by the versioned migrations of the migrate command.
 func ConnectDatabase() *gorm.DB {
 	var err error
 
@@ -91,7 +85,6 @@ func ConnectDatabase() *gorm.DB {
 		NamingStrategy: schema.NamingStrategy{
 			SingularTable: true, // Use singular table names
 		},
-		DisableForeignKeyConstraintWhenMigrating: true, // Disable foreign key constraint when migrating
 	}
 
 	// Open database connection
@@ -116,65 +109,9 @@ func ConnectDatabase() *gorm.DB {
 	// SetConnMaxLifetime sets the maximum amount of time a connection may be reused
 	sqlDB.SetConnMaxLifetime(time.Hour)
 
-	// Handle existing tables if needed
-	handleExistingTables()
-
-	// Auto migrate all models in one place — single source of truth for schema
-	err = DB.AutoMigrate(
-		&user_models.User{},
-		&models.Food{},
-		&nutrient_models.Nutrient{},
-		&food_nutrients_models.FoodNutrient{},
-		&meal_log_models.MealLog{},
-		&meal_log_items_models.MealLogItem{},
-		&user_biometrics_models.UserBiometric{},
-	)
-	if err != nil {
-		log.Fatal("Failed to auto migrate database schema: ", err)
-	}
-
 	fmt.Println("==================================")
-	fmt.Println("Database connected and migrated successfully")
+	fmt.Println("Database connected successfully")
 	fmt.Println("==================================")
 
 	return DB
 }
-
-// handleExistingTables attempts to handle existing tables and their constraints
-func handleExistingTables() {
-	// List of tables to check
-	tables := []string{"food", "nutrient", "food_nutrient", "meal_log", "meal_log_item", "user_biometric", "User"}
-
-	for _, table := range tables {
-		// Check if table exists
-		var count int64
-		DB.Raw("SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?", table).Count(&count)
-
-		if count > 0 {
-			log.Printf("Table %s exists, dropping related constraints...", table)
-
-			// Get the list of constraints to drop
-			var constraints []struct {
-				ConstraintName string `gorm:"column:conname"`
-				TableName      string `gorm:"column:relname"`
-			}
-
-			DB.Raw(`
-				SELECT con.conname, cl.relname
-				FROM pg_constraint con
-				JOIN pg_class cl ON con.conrelid = cl.oid
-				WHERE cl.relname = ?
-			`, table).Scan(&constraints)
-
-			// Drop each constraint
-			for _, constraint := range constraints {
-				log.Printf("Dropping constraint %s on table %s\n", constraint.ConstraintName, constraint.TableName)
-				DB.Exec(fmt.Sprintf("ALTER TABLE %s DROP CONSTRAINT IF EXISTS %s CASCADE", constraint.TableName, constraint.ConstraintName))
-			}
-
-			// Try to drop indexes if they exist
-			log.Printf("Dropping indexes on %s table...", table)
-			DB.Exec(fmt.Sprintf("DROP INDEX IF EXISTS idx_%s_id", table))
-		}
-	}
-}
@@ -0,0 +1,70 @@
+package database
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"github.com/momokapoolz/caloriesapp/database/migrations"
+)
+
+func TestEmbeddedMigrations(t *testing.T) {
+	loaded, err := LoadMigrations(migrations.FS)
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(loaded) == 0 || loaded[0].Version != 0 || loaded[0].Name != "create_base_schema" {
+		t.Fatalf("first migration = %+v, want the base schema", loaded[0])
+	}
+	for i, migration := range loaded {
+		if migration.Version != int64(i) {
+			t.Errorf("migration %d has version %d, versions should have no gaps", i, migration.Version)
+		}
+		if strings.TrimSpace(migration.Up) == "" || strings.TrimSpace(migration.Down) == "" {
+			t.Errorf("migration %06d_%s has an empty up or down file", migration.Version, migration.Name)
+		}
+	}
+}
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(content string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(content)} }
+
+	loaded, err := LoadMigrations(fstest.MapFS{
+		"000002_second.up.sql":   file("CREATE TABLE b ();"),
+		"000002_second.down.sql": file("DROP TABLE b;"),
+		"000001_first.up.sql":    file("CREATE TABLE a ();"),
+		"000001_first.down.sql":  file("DROP TABLE a;"),
+	})
+	if err != nil {
+		t.Fatalf("LoadMigrations: %v", err)
+	}
+	if len(loaded) != 2 || loaded[0].Name != "first" || loaded[1].Version != 2 || loaded[1].Down != "DROP TABLE b;" {
+		t.Errorf("loaded = %+v", loaded)
+	}
+
+	cases := map[string]fstest.MapFS{
+		"missing down": {
+			"000001_first.up.sql": file("CREATE TABLE a ();"),
+		},
+		"bad name": {
+			"first.up.sql":   file("CREATE TABLE a ();"),
+			"first.down.sql": file("DROP TABLE a;"),
+		},
+		"version reused": {
+			"000001_first.up.sql":    file("CREATE TABLE a ();"),
+			"000001_first.down.sql":  file("DROP TABLE a;"),
+			"000001_second.up.sql":   file("CREATE TABLE b ();"),
+			"000001_second.down.sql": file("DROP TABLE b;"),
+		},
+		"duplicate file": {
+			"000001_first.up.sql":   file("CREATE TABLE a ();"),
+			"0001_first.up.sql":     file("CREATE TABLE a ();"),
+			"000001_first.down.sql": file("DROP TABLE a;"),
+		},
+	}
+	for name, fsys := range cases {
+		if _, err := LoadMigrations(fsys); err == nil {
+			t.Errorf("%s: LoadMigrations succeeded, want an error", name)
+		}
+	}
+}
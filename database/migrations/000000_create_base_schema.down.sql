@@ -0,0 +1,7 @@
+DROP TABLE IF EXISTS user_biometrics;
+DROP TABLE IF EXISTS meal_log_items;
+DROP TABLE IF EXISTS meal_log;
+DROP TABLE IF EXISTS food_nutrients;
+DROP TABLE IF EXISTS nutrient;
+DROP TABLE IF EXISTS food;
+DROP TABLE IF EXISTS "User";
@@ -1,3 +1,6 @@
+-- Tables that predate versioned migrations, as first laid out before the schema was versioned.
+-- Databases created before schema_migrations existed already have them, so nothing here is
+-- replaced. database/sql/schema.sql holds the schema after every migration.
 CREATE TABLE IF NOT EXISTS "User" (
 	"id" serial NOT NULL UNIQUE,
 	"name" varchar(255) NOT NULL,
@@ -63,15 +66,3 @@ CREATE TABLE IF NOT EXISTS "user_biometrics" (
 	"unit" varchar(255) NOT NULL,
 	PRIMARY KEY ("id")
 );
-
-
-
-
-ALTER TABLE "food_nutrients" ADD CONSTRAINT "food_nutrients_fk1" FOREIGN KEY ("food_id") REFERENCES "food"("id");
-
-ALTER TABLE "food_nutrients" ADD CONSTRAINT "food_nutrients_fk2" FOREIGN KEY ("nutrient_id") REFERENCES "nutrient"("id");
-ALTER TABLE "meal_log" ADD CONSTRAINT "meal_log_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id");
-ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_fk1" FOREIGN KEY ("meal_log_id") REFERENCES "meal_log"("id");
-
-ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_fk2" FOREIGN KEY ("food_id") REFERENCES "food"("id");
-ALTER TABLE "user_biometrics" ADD CONSTRAINT "user_biometrics_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id");
\ No newline at end of file
@@ -1 +1 @@
-ALTER TABLE nutrient DROP COLUMN unit;
+ALTER TABLE nutrient DROP COLUMN IF EXISTS unit;
@@ -1,5 +1,5 @@
 ALTER TABLE nutrient
-    ADD COLUMN unit VARCHAR(20) NOT NULL DEFAULT 'g';
+    ADD COLUMN IF NOT EXISTS unit VARCHAR(20) NOT NULL DEFAULT 'g';
 
 -- Set correct units for the 10 seeded nutrients
 UPDATE nutrient SET unit = 'kcal' WHERE id = 1;  -- Energy
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_nutrient_code;
+ALTER TABLE nutrient DROP COLUMN IF EXISTS code;
@@ -0,0 +1,17 @@
+ALTER TABLE nutrient
+    ADD COLUMN IF NOT EXISTS code VARCHAR(64) NOT NULL DEFAULT '';
+
+-- Stable codes for the 10 seeded nutrients. The name must match too: databases not built from the
+-- seed may hold other nutrients under these IDs, and those are left for 000019 to match by name.
+UPDATE nutrient SET code = 'energy'       WHERE id = 1   AND code = '' AND name = 'Energy';
+UPDATE nutrient SET code = 'protein'      WHERE id = 2   AND code = '' AND name = 'Protein';
+UPDATE nutrient SET code = 'fat'          WHERE id = 3   AND code = '' AND name = 'Total lipid (fat)';
+UPDATE nutrient SET code = 'carbohydrate' WHERE id = 4   AND code = '' AND name = 'Carbohydrate, by difference';
+UPDATE nutrient SET code = 'fiber'        WHERE id = 5   AND code = '' AND name = 'Fiber, total dietary';
+UPDATE nutrient SET code = 'cholesterol'  WHERE id = 6   AND code = '' AND name = 'Cholesterol';
+UPDATE nutrient SET code = 'vitamin_a'    WHERE id = 7   AND code = '' AND name = 'Vitamin A, RAE';
+UPDATE nutrient SET code = 'vitamin_b12'  WHERE id = 8   AND code = '' AND name = 'Vitamin B12';
+UPDATE nutrient SET code = 'calcium'      WHERE id = 9   AND code = '' AND name = 'Calcium, Ca';
+UPDATE nutrient SET code = 'iron'         WHERE id = 10  AND code = '' AND name = 'Iron, Fe';
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_nutrient_code ON nutrient (code) WHERE code <> '';
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS daily_nutrition_status;
+DROP TABLE IF EXISTS daily_nutrition_totals;
@@ -0,0 +1,21 @@
+-- Per user, date and nutrient rollup of consumed amounts
+CREATE TABLE IF NOT EXISTS daily_nutrition_totals (
+    id          BIGSERIAL PRIMARY KEY,
+    user_id     BIGINT           NOT NULL,
+    date        DATE             NOT NULL,
+    nutrient_id BIGINT           NOT NULL,
+    amount      DOUBLE PRECISION NOT NULL,
+    updated_at  TIMESTAMPTZ      NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_daily_nutrition_totals_user_date_nutrient
+    ON daily_nutrition_totals (user_id, date, nutrient_id);
+
+-- Freshness of each rolled up user diary date
+CREATE TABLE IF NOT EXISTS daily_nutrition_status (
+    user_id     BIGINT      NOT NULL,
+    date        DATE        NOT NULL,
+    stale       BOOLEAN     NOT NULL DEFAULT TRUE,
+    computed_at TIMESTAMPTZ,
+    PRIMARY KEY (user_id, date)
+);
@@ -0,0 +1,2 @@
+-- Imports match existing foods on their source, e.g. 'usda:<fdc_id>'
+CREATE INDEX IF NOT EXISTS idx_food_source ON food (source);
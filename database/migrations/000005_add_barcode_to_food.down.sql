@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_food_barcode;
+ALTER TABLE food DROP COLUMN IF EXISTS barcode;
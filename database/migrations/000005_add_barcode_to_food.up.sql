@@ -0,0 +1,5 @@
+-- GTIN of packaged foods, stored as EAN-8, EAN-13 or GTIN-14 (UPC-A is widened to EAN-13)
+ALTER TABLE food
+    ADD COLUMN IF NOT EXISTS barcode VARCHAR(14) NOT NULL DEFAULT '';
+
+CREATE INDEX IF NOT EXISTS idx_food_barcode ON food (barcode);
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_food_brand_lower;
+DROP INDEX IF EXISTS idx_food_name_trgm;
+DROP INDEX IF EXISTS idx_food_search_document;
+ALTER TABLE food DROP COLUMN IF EXISTS brand;
@@ -0,0 +1,13 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE food
+    ADD COLUMN IF NOT EXISTS brand VARCHAR(255) NOT NULL DEFAULT '';
+
+-- Full-text search over name and brand; the expression must match FoodRepository.Search
+CREATE INDEX IF NOT EXISTS idx_food_search_document
+    ON food USING GIN (to_tsvector('english', name || ' ' || brand));
+
+-- Trigram similarity on name, so misspelled queries still match
+CREATE INDEX IF NOT EXISTS idx_food_name_trgm ON food USING GIN (name gin_trgm_ops);
+
+CREATE INDEX IF NOT EXISTS idx_food_brand_lower ON food (LOWER(brand));
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS idx_food_owner;
+ALTER TABLE food DROP CONSTRAINT IF EXISTS food_visibility_check;
+ALTER TABLE food DROP COLUMN IF EXISTS visibility;
+ALTER TABLE food DROP COLUMN IF EXISTS owner_id;
@@ -0,0 +1,15 @@
+-- Custom foods belong to the user who created them; catalog foods have no owner
+ALTER TABLE food
+    ADD COLUMN IF NOT EXISTS owner_id BIGINT;
+
+-- private: owner only, shared: every user, verified: public and reviewed by an admin.
+-- Existing foods were created before ownership existed and stay in the public catalog.
+ALTER TABLE food
+    ADD COLUMN IF NOT EXISTS visibility VARCHAR(16) NOT NULL DEFAULT 'verified';
+
+ALTER TABLE food
+    DROP CONSTRAINT IF EXISTS food_visibility_check;
+ALTER TABLE food
+    ADD CONSTRAINT food_visibility_check CHECK (visibility IN ('private', 'shared', 'verified'));
+
+CREATE INDEX IF NOT EXISTS idx_food_owner ON food (owner_id);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS recipe_ingredients;
+DROP TABLE IF EXISTS recipe;
@@ -0,0 +1,24 @@
+-- Recipes are backed by a food row holding their name, visibility and derived nutrient profile
+CREATE TABLE IF NOT EXISTS recipe (
+    id                 BIGSERIAL PRIMARY KEY,
+    user_id            BIGINT           NOT NULL,
+    food_id            BIGINT           NOT NULL,
+    servings           DOUBLE PRECISION NOT NULL,
+    cooked_weight_gram DOUBLE PRECISION NOT NULL DEFAULT 0,
+    created_at         TIMESTAMPTZ      NOT NULL,
+    updated_at         TIMESTAMPTZ      NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_recipe_food ON recipe (food_id);
+CREATE INDEX IF NOT EXISTS idx_recipe_user ON recipe (user_id);
+
+-- Raw weight of each ingredient food used in a recipe
+CREATE TABLE IF NOT EXISTS recipe_ingredients (
+    id        BIGSERIAL PRIMARY KEY,
+    recipe_id BIGINT           NOT NULL,
+    food_id   BIGINT           NOT NULL,
+    grams     DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_recipe_ingredients_recipe ON recipe_ingredients (recipe_id);
+CREATE INDEX IF NOT EXISTS idx_recipe_ingredients_food ON recipe_ingredients (food_id);
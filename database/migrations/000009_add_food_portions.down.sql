@@ -0,0 +1,3 @@
+ALTER TABLE meal_log_items DROP COLUMN IF EXISTS amount;
+ALTER TABLE meal_log_items DROP COLUMN IF EXISTS unit;
+DROP TABLE IF EXISTS food_portion;
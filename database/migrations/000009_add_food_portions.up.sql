@@ -0,0 +1,18 @@
+-- Household measures of a food (cup, slice, tbsp, piece) and their weight in grams
+CREATE TABLE IF NOT EXISTS food_portion (
+    id          BIGSERIAL PRIMARY KEY,
+    food_id     BIGINT           NOT NULL,
+    unit        VARCHAR(32)      NOT NULL,
+    description TEXT             NOT NULL DEFAULT '',
+    gram_weight DOUBLE PRECISION NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_food_portion_food_unit ON food_portion (food_id, unit);
+
+-- Log items record the unit and fractional amount the user picked; quantity_grams is resolved from them
+ALTER TABLE meal_log_items ADD COLUMN IF NOT EXISTS unit VARCHAR(32) NOT NULL DEFAULT 'serving';
+ALTER TABLE meal_log_items ADD COLUMN IF NOT EXISTS amount DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+-- Existing items were logged in servings; items without a quantity only carry their grams
+UPDATE meal_log_items SET amount = quantity, unit = 'serving' WHERE quantity > 0 AND amount = 0;
+UPDATE meal_log_items SET amount = quantity_grams, unit = 'g' WHERE quantity = 0 AND amount = 0;
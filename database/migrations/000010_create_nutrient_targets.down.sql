@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS nutrient_target;
+DROP TABLE IF EXISTS nutrient_target_set;
@@ -0,0 +1,28 @@
+-- Targets chosen by a user, in effect from effective_from until the user's next set
+CREATE TABLE IF NOT EXISTS nutrient_target_set (
+    id                   BIGSERIAL PRIMARY KEY,
+    user_id              BIGINT           NOT NULL,
+    effective_from       DATE             NOT NULL,
+    calories             DOUBLE PRECISION,
+    preset               VARCHAR(32)      NOT NULL DEFAULT '',
+    protein_percent      DOUBLE PRECISION,
+    carbohydrate_percent DOUBLE PRECISION,
+    fat_percent          DOUBLE PRECISION,
+    protein_grams        DOUBLE PRECISION,
+    carbohydrate_grams   DOUBLE PRECISION,
+    fat_grams            DOUBLE PRECISION,
+    created_at           TIMESTAMPTZ      NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_nutrient_target_set_user_date ON nutrient_target_set (user_id, effective_from);
+
+-- Daily min/max ranges per nutrient, in the nutrient's unit
+CREATE TABLE IF NOT EXISTS nutrient_target (
+    id            BIGSERIAL PRIMARY KEY,
+    target_set_id BIGINT NOT NULL,
+    nutrient_id   BIGINT NOT NULL,
+    min_amount    DOUBLE PRECISION,
+    max_amount    DOUBLE PRECISION
+);
+
+CREATE INDEX IF NOT EXISTS idx_nutrient_target_set ON nutrient_target (target_set_id);
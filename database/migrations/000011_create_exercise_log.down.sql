@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS exercise_log;
+DROP TABLE IF EXISTS exercise_activity;
@@ -0,0 +1,53 @@
+-- Catalog of activities with their metabolic equivalents (MET) per intensity
+CREATE TABLE IF NOT EXISTS exercise_activity (
+    id           BIGSERIAL PRIMARY KEY,
+    code         VARCHAR(64)      NOT NULL,
+    name         TEXT             NOT NULL,
+    category     VARCHAR(64)      NOT NULL DEFAULT '',
+    met_light    DOUBLE PRECISION NOT NULL,
+    met_moderate DOUBLE PRECISION NOT NULL,
+    met_vigorous DOUBLE PRECISION NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exercise_activity_code ON exercise_activity (code);
+
+-- Logged exercise, with the MET and body weight the burn was computed from
+CREATE TABLE IF NOT EXISTS exercise_log (
+    id               BIGSERIAL PRIMARY KEY,
+    user_id          BIGINT           NOT NULL,
+    activity_id      BIGINT           NOT NULL,
+    performed_at     TIMESTAMPTZ      NOT NULL,
+    duration_minutes DOUBLE PRECISION NOT NULL,
+    intensity        VARCHAR(16)      NOT NULL,
+    met              DOUBLE PRECISION NOT NULL,
+    weight_kg        DOUBLE PRECISION NOT NULL,
+    calories_burned  DOUBLE PRECISION NOT NULL,
+    note             TEXT             NOT NULL DEFAULT '',
+    created_at       TIMESTAMPTZ      NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_exercise_log_user_performed ON exercise_log (user_id, performed_at);
+
+-- Compendium of Physical Activities values at light, moderate and vigorous intensity
+INSERT INTO exercise_activity (code, name, category, met_light, met_moderate, met_vigorous) VALUES
+    ('walking', 'Walking', 'Walking', 2.8, 3.5, 5.0),
+    ('hiking', 'Hiking', 'Walking', 5.3, 6.0, 7.8),
+    ('running', 'Running', 'Running', 7.0, 9.8, 11.8),
+    ('cycling', 'Cycling', 'Cycling', 4.0, 6.8, 10.0),
+    ('stationary_cycling', 'Stationary cycling', 'Cycling', 3.5, 6.8, 8.8),
+    ('swimming', 'Swimming laps', 'Water', 5.8, 8.3, 9.8),
+    ('rowing_machine', 'Rowing machine', 'Conditioning', 4.8, 7.0, 8.5),
+    ('elliptical', 'Elliptical trainer', 'Conditioning', 4.0, 5.0, 6.0),
+    ('stair_climbing', 'Stair climbing', 'Conditioning', 4.0, 6.0, 8.8),
+    ('circuit_training', 'Circuit training', 'Conditioning', 4.3, 8.0, 9.5),
+    ('jump_rope', 'Jumping rope', 'Conditioning', 8.8, 11.8, 12.3),
+    ('strength_training', 'Strength training', 'Strength', 3.5, 5.0, 6.0),
+    ('yoga', 'Yoga', 'Flexibility', 2.5, 3.0, 4.0),
+    ('pilates', 'Pilates', 'Flexibility', 2.8, 3.0, 3.8),
+    ('dancing', 'Dancing', 'Dance', 3.0, 5.0, 7.3),
+    ('tennis', 'Tennis', 'Sports', 5.0, 7.3, 8.0),
+    ('basketball', 'Basketball', 'Sports', 4.5, 6.5, 8.0),
+    ('soccer', 'Soccer', 'Sports', 5.0, 7.0, 10.0),
+    ('cross_country_skiing', 'Cross-country skiing', 'Winter', 6.8, 9.0, 12.5),
+    ('gardening', 'Gardening', 'Household', 2.3, 3.8, 4.5)
+ON CONFLICT (code) DO NOTHING;
@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_exercise_log_import;
+ALTER TABLE exercise_log DROP COLUMN IF EXISTS device_calories;
+ALTER TABLE exercise_log DROP COLUMN IF EXISTS max_heart_rate;
+ALTER TABLE exercise_log DROP COLUMN IF EXISTS avg_heart_rate;
+ALTER TABLE exercise_log DROP COLUMN IF EXISTS elevation_gain_meters;
+ALTER TABLE exercise_log DROP COLUMN IF EXISTS distance_meters;
+ALTER TABLE exercise_log DROP COLUMN IF EXISTS import_id;
+ALTER TABLE exercise_log DROP COLUMN IF EXISTS source;
+DROP TABLE IF EXISTS exercise_import;
@@ -0,0 +1,22 @@
+-- Uploaded workout files, recognized by their SHA-256 so a file is imported once per user
+CREATE TABLE IF NOT EXISTS exercise_import (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT      NOT NULL,
+    file_hash  VARCHAR(64) NOT NULL,
+    file_name  TEXT        NOT NULL DEFAULT '',
+    format     VARCHAR(8)  NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exercise_import_user_hash ON exercise_import (user_id, file_hash);
+
+-- Measures recorded by the device of an imported workout
+ALTER TABLE exercise_log ADD COLUMN IF NOT EXISTS source VARCHAR(16) NOT NULL DEFAULT 'manual';
+ALTER TABLE exercise_log ADD COLUMN IF NOT EXISTS import_id BIGINT;
+ALTER TABLE exercise_log ADD COLUMN IF NOT EXISTS distance_meters DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE exercise_log ADD COLUMN IF NOT EXISTS elevation_gain_meters DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE exercise_log ADD COLUMN IF NOT EXISTS avg_heart_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE exercise_log ADD COLUMN IF NOT EXISTS max_heart_rate DOUBLE PRECISION NOT NULL DEFAULT 0;
+ALTER TABLE exercise_log ADD COLUMN IF NOT EXISTS device_calories DOUBLE PRECISION NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_exercise_log_import ON exercise_log (import_id);
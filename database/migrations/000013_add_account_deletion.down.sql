@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_user_deletion_scheduled;
+ALTER TABLE "User" DROP COLUMN IF EXISTS deletion_scheduled_at;
+ALTER TABLE "User" DROP COLUMN IF EXISTS deletion_requested_at;
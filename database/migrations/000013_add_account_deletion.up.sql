@@ -0,0 +1,5 @@
+-- Requested account deletions wait out a grace period before the account is purged
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS deletion_requested_at TIMESTAMPTZ;
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS deletion_scheduled_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS idx_user_deletion_scheduled ON "User" (deletion_scheduled_at);
@@ -0,0 +1,7 @@
+-- Primary keys are kept: the schema never should have lost them
+DROP INDEX IF EXISTS idx_email;
+
+ALTER TABLE user_biometrics ALTER COLUMN created_at TYPE DATE;
+ALTER TABLE meal_log ALTER COLUMN created_at TYPE DATE;
+ALTER TABLE meal_log DROP COLUMN IF EXISTS note;
+ALTER TABLE food DROP COLUMN IF EXISTS image_url;
@@ -0,0 +1,22 @@
+-- Columns and indexes that AutoMigrate created from the models without a migration
+ALTER TABLE food ADD COLUMN IF NOT EXISTS image_url TEXT;
+ALTER TABLE meal_log ADD COLUMN IF NOT EXISTS note TEXT;
+ALTER TABLE meal_log ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+ALTER TABLE user_biometrics ALTER COLUMN created_at TYPE TIMESTAMPTZ;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_email ON "User" (email);
+
+-- Startup used to drop every constraint of these tables, primary keys included
+DO $$
+DECLARE
+    name TEXT;
+BEGIN
+    FOREACH name IN ARRAY ARRAY['User', 'food', 'nutrient', 'meal_log'] LOOP
+        IF NOT EXISTS (
+            SELECT 1 FROM pg_constraint
+            WHERE conrelid = format('%I', name)::regclass AND contype = 'p'
+        ) THEN
+            EXECUTE format('ALTER TABLE %I ADD PRIMARY KEY (id)', name);
+        END IF;
+    END LOOP;
+END $$;
@@ -0,0 +1,38 @@
+-- Orphaned rows removed and negative amounts reset by the up migration are not restored
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_calories_burned_check;
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_duration_minutes_check;
+ALTER TABLE nutrient_target DROP CONSTRAINT IF EXISTS nutrient_target_max_amount_check;
+ALTER TABLE nutrient_target DROP CONSTRAINT IF EXISTS nutrient_target_min_amount_check;
+ALTER TABLE daily_nutrition_totals DROP CONSTRAINT IF EXISTS daily_nutrition_totals_amount_check;
+ALTER TABLE recipe_ingredients DROP CONSTRAINT IF EXISTS recipe_ingredients_grams_check;
+ALTER TABLE recipe DROP CONSTRAINT IF EXISTS recipe_cooked_weight_gram_check;
+ALTER TABLE recipe DROP CONSTRAINT IF EXISTS recipe_servings_check;
+ALTER TABLE food_portion DROP CONSTRAINT IF EXISTS food_portion_gram_weight_check;
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_quantity_grams_check;
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_amount_check;
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_quantity_check;
+ALTER TABLE food_nutrients DROP CONSTRAINT IF EXISTS food_nutrients_amount_per_100g_check;
+ALTER TABLE food DROP CONSTRAINT IF EXISTS food_serving_size_gram_check;
+ALTER TABLE food_nutrients DROP CONSTRAINT IF EXISTS food_nutrients_food_nutrient_key;
+ALTER TABLE exercise_import DROP CONSTRAINT IF EXISTS exercise_import_fk1;
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_fk3;
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_fk2;
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_fk1;
+ALTER TABLE nutrient_target DROP CONSTRAINT IF EXISTS nutrient_target_fk2;
+ALTER TABLE nutrient_target DROP CONSTRAINT IF EXISTS nutrient_target_fk1;
+ALTER TABLE nutrient_target_set DROP CONSTRAINT IF EXISTS nutrient_target_set_fk1;
+ALTER TABLE food_portion DROP CONSTRAINT IF EXISTS food_portion_fk1;
+ALTER TABLE recipe_ingredients DROP CONSTRAINT IF EXISTS recipe_ingredients_fk2;
+ALTER TABLE recipe_ingredients DROP CONSTRAINT IF EXISTS recipe_ingredients_fk1;
+ALTER TABLE recipe DROP CONSTRAINT IF EXISTS recipe_fk2;
+ALTER TABLE recipe DROP CONSTRAINT IF EXISTS recipe_fk1;
+ALTER TABLE daily_nutrition_status DROP CONSTRAINT IF EXISTS daily_nutrition_status_fk1;
+ALTER TABLE daily_nutrition_totals DROP CONSTRAINT IF EXISTS daily_nutrition_totals_fk2;
+ALTER TABLE daily_nutrition_totals DROP CONSTRAINT IF EXISTS daily_nutrition_totals_fk1;
+ALTER TABLE user_biometrics DROP CONSTRAINT IF EXISTS user_biometrics_fk1;
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_fk2;
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_fk1;
+ALTER TABLE meal_log DROP CONSTRAINT IF EXISTS meal_log_fk1;
+ALTER TABLE food_nutrients DROP CONSTRAINT IF EXISTS food_nutrients_fk2;
+ALTER TABLE food_nutrients DROP CONSTRAINT IF EXISTS food_nutrients_fk1;
+ALTER TABLE food DROP CONSTRAINT IF EXISTS food_owner_fk;
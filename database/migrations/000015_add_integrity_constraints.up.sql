@@ -0,0 +1,124 @@
+-- Rows pointing at a parent that no longer exists were unreachable; remove them so the foreign
+-- keys below can be declared. Children go before their parents.
+DELETE FROM meal_log WHERE NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = meal_log.user_id);
+DELETE FROM meal_log_items WHERE NOT EXISTS (SELECT 1 FROM meal_log WHERE meal_log.id = meal_log_items.meal_log_id)
+    OR NOT EXISTS (SELECT 1 FROM food WHERE food.id = meal_log_items.food_id);
+DELETE FROM food_nutrients WHERE NOT EXISTS (SELECT 1 FROM food WHERE food.id = food_nutrients.food_id)
+    OR NOT EXISTS (SELECT 1 FROM nutrient WHERE nutrient.id = food_nutrients.nutrient_id);
+DELETE FROM food_portion WHERE NOT EXISTS (SELECT 1 FROM food WHERE food.id = food_portion.food_id);
+DELETE FROM user_biometrics WHERE NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = user_biometrics.user_id);
+DELETE FROM daily_nutrition_totals WHERE NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = daily_nutrition_totals.user_id)
+    OR NOT EXISTS (SELECT 1 FROM nutrient WHERE nutrient.id = daily_nutrition_totals.nutrient_id);
+DELETE FROM daily_nutrition_status WHERE NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = daily_nutrition_status.user_id);
+DELETE FROM recipe WHERE NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = recipe.user_id)
+    OR NOT EXISTS (SELECT 1 FROM food WHERE food.id = recipe.food_id);
+DELETE FROM recipe_ingredients WHERE NOT EXISTS (SELECT 1 FROM recipe WHERE recipe.id = recipe_ingredients.recipe_id)
+    OR NOT EXISTS (SELECT 1 FROM food WHERE food.id = recipe_ingredients.food_id);
+DELETE FROM nutrient_target_set WHERE NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = nutrient_target_set.user_id);
+DELETE FROM nutrient_target WHERE NOT EXISTS (SELECT 1 FROM nutrient_target_set WHERE nutrient_target_set.id = nutrient_target.target_set_id)
+    OR NOT EXISTS (SELECT 1 FROM nutrient WHERE nutrient.id = nutrient_target.nutrient_id);
+DELETE FROM exercise_import WHERE NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = exercise_import.user_id);
+DELETE FROM exercise_log WHERE NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = exercise_log.user_id)
+    OR NOT EXISTS (SELECT 1 FROM exercise_activity WHERE exercise_activity.id = exercise_log.activity_id);
+UPDATE exercise_log SET import_id = NULL
+    WHERE import_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM exercise_import WHERE exercise_import.id = exercise_log.import_id);
+UPDATE food SET owner_id = NULL
+    WHERE owner_id IS NOT NULL AND NOT EXISTS (SELECT 1 FROM "User" WHERE "User".id = food.owner_id);
+
+-- A user's rows go with the user, a meal's items with the meal and a food's nutrients and portions
+-- with the food. Foods, nutrients and activities still used elsewhere cannot be deleted.
+ALTER TABLE food DROP CONSTRAINT IF EXISTS food_owner_fk,
+    ADD CONSTRAINT food_owner_fk FOREIGN KEY (owner_id) REFERENCES "User" (id) ON DELETE SET NULL;
+ALTER TABLE food_nutrients DROP CONSTRAINT IF EXISTS food_nutrients_fk1,
+    ADD CONSTRAINT food_nutrients_fk1 FOREIGN KEY (food_id) REFERENCES food (id) ON DELETE CASCADE;
+ALTER TABLE food_nutrients DROP CONSTRAINT IF EXISTS food_nutrients_fk2,
+    ADD CONSTRAINT food_nutrients_fk2 FOREIGN KEY (nutrient_id) REFERENCES nutrient (id) ON DELETE RESTRICT;
+ALTER TABLE meal_log DROP CONSTRAINT IF EXISTS meal_log_fk1,
+    ADD CONSTRAINT meal_log_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_fk1,
+    ADD CONSTRAINT meal_log_items_fk1 FOREIGN KEY (meal_log_id) REFERENCES meal_log (id) ON DELETE CASCADE;
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_fk2,
+    ADD CONSTRAINT meal_log_items_fk2 FOREIGN KEY (food_id) REFERENCES food (id) ON DELETE RESTRICT;
+ALTER TABLE user_biometrics DROP CONSTRAINT IF EXISTS user_biometrics_fk1,
+    ADD CONSTRAINT user_biometrics_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+ALTER TABLE daily_nutrition_totals DROP CONSTRAINT IF EXISTS daily_nutrition_totals_fk1,
+    ADD CONSTRAINT daily_nutrition_totals_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+ALTER TABLE daily_nutrition_totals DROP CONSTRAINT IF EXISTS daily_nutrition_totals_fk2,
+    ADD CONSTRAINT daily_nutrition_totals_fk2 FOREIGN KEY (nutrient_id) REFERENCES nutrient (id) ON DELETE CASCADE;
+ALTER TABLE daily_nutrition_status DROP CONSTRAINT IF EXISTS daily_nutrition_status_fk1,
+    ADD CONSTRAINT daily_nutrition_status_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+ALTER TABLE recipe DROP CONSTRAINT IF EXISTS recipe_fk1,
+    ADD CONSTRAINT recipe_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+ALTER TABLE recipe DROP CONSTRAINT IF EXISTS recipe_fk2,
+    ADD CONSTRAINT recipe_fk2 FOREIGN KEY (food_id) REFERENCES food (id) ON DELETE RESTRICT;
+ALTER TABLE recipe_ingredients DROP CONSTRAINT IF EXISTS recipe_ingredients_fk1,
+    ADD CONSTRAINT recipe_ingredients_fk1 FOREIGN KEY (recipe_id) REFERENCES recipe (id) ON DELETE CASCADE;
+ALTER TABLE recipe_ingredients DROP CONSTRAINT IF EXISTS recipe_ingredients_fk2,
+    ADD CONSTRAINT recipe_ingredients_fk2 FOREIGN KEY (food_id) REFERENCES food (id) ON DELETE RESTRICT;
+ALTER TABLE food_portion DROP CONSTRAINT IF EXISTS food_portion_fk1,
+    ADD CONSTRAINT food_portion_fk1 FOREIGN KEY (food_id) REFERENCES food (id) ON DELETE CASCADE;
+ALTER TABLE nutrient_target_set DROP CONSTRAINT IF EXISTS nutrient_target_set_fk1,
+    ADD CONSTRAINT nutrient_target_set_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+ALTER TABLE nutrient_target DROP CONSTRAINT IF EXISTS nutrient_target_fk1,
+    ADD CONSTRAINT nutrient_target_fk1 FOREIGN KEY (target_set_id) REFERENCES nutrient_target_set (id) ON DELETE CASCADE;
+ALTER TABLE nutrient_target DROP CONSTRAINT IF EXISTS nutrient_target_fk2,
+    ADD CONSTRAINT nutrient_target_fk2 FOREIGN KEY (nutrient_id) REFERENCES nutrient (id) ON DELETE RESTRICT;
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_fk1,
+    ADD CONSTRAINT exercise_log_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_fk2,
+    ADD CONSTRAINT exercise_log_fk2 FOREIGN KEY (activity_id) REFERENCES exercise_activity (id) ON DELETE RESTRICT;
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_fk3,
+    ADD CONSTRAINT exercise_log_fk3 FOREIGN KEY (import_id) REFERENCES exercise_import (id) ON DELETE SET NULL;
+ALTER TABLE exercise_import DROP CONSTRAINT IF EXISTS exercise_import_fk1,
+    ADD CONSTRAINT exercise_import_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+
+-- A food has one amount per nutrient; keep the latest of any duplicates
+DELETE FROM food_nutrients duplicate USING food_nutrients latest
+    WHERE duplicate.food_id = latest.food_id AND duplicate.nutrient_id = latest.nutrient_id AND duplicate.id < latest.id;
+ALTER TABLE food_nutrients DROP CONSTRAINT IF EXISTS food_nutrients_food_nutrient_key,
+    ADD CONSTRAINT food_nutrients_food_nutrient_key UNIQUE (food_id, nutrient_id);
+
+-- Amounts, weights and quantities are never negative; negative values left by earlier bugs become 0
+UPDATE food SET serving_size_gram = 0 WHERE serving_size_gram < 0;
+UPDATE food_nutrients SET amount_per_100g = 0 WHERE amount_per_100g < 0;
+UPDATE meal_log_items SET quantity = 0 WHERE quantity < 0;
+UPDATE meal_log_items SET amount = 0 WHERE amount < 0;
+UPDATE meal_log_items SET quantity_grams = 0 WHERE quantity_grams < 0;
+UPDATE food_portion SET gram_weight = 0 WHERE gram_weight < 0;
+UPDATE recipe SET servings = 0 WHERE servings < 0;
+UPDATE recipe SET cooked_weight_gram = 0 WHERE cooked_weight_gram < 0;
+UPDATE recipe_ingredients SET grams = 0 WHERE grams < 0;
+UPDATE daily_nutrition_totals SET amount = 0 WHERE amount < 0;
+UPDATE nutrient_target SET min_amount = 0 WHERE min_amount < 0;
+UPDATE nutrient_target SET max_amount = 0 WHERE max_amount < 0;
+UPDATE exercise_log SET duration_minutes = 0 WHERE duration_minutes < 0;
+UPDATE exercise_log SET calories_burned = 0 WHERE calories_burned < 0;
+
+ALTER TABLE food DROP CONSTRAINT IF EXISTS food_serving_size_gram_check,
+    ADD CONSTRAINT food_serving_size_gram_check CHECK (serving_size_gram >= 0);
+ALTER TABLE food_nutrients DROP CONSTRAINT IF EXISTS food_nutrients_amount_per_100g_check,
+    ADD CONSTRAINT food_nutrients_amount_per_100g_check CHECK (amount_per_100g >= 0);
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_quantity_check,
+    ADD CONSTRAINT meal_log_items_quantity_check CHECK (quantity >= 0);
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_amount_check,
+    ADD CONSTRAINT meal_log_items_amount_check CHECK (amount >= 0);
+ALTER TABLE meal_log_items DROP CONSTRAINT IF EXISTS meal_log_items_quantity_grams_check,
+    ADD CONSTRAINT meal_log_items_quantity_grams_check CHECK (quantity_grams >= 0);
+ALTER TABLE food_portion DROP CONSTRAINT IF EXISTS food_portion_gram_weight_check,
+    ADD CONSTRAINT food_portion_gram_weight_check CHECK (gram_weight >= 0);
+ALTER TABLE recipe DROP CONSTRAINT IF EXISTS recipe_servings_check,
+    ADD CONSTRAINT recipe_servings_check CHECK (servings >= 0);
+ALTER TABLE recipe DROP CONSTRAINT IF EXISTS recipe_cooked_weight_gram_check,
+    ADD CONSTRAINT recipe_cooked_weight_gram_check CHECK (cooked_weight_gram >= 0);
+ALTER TABLE recipe_ingredients DROP CONSTRAINT IF EXISTS recipe_ingredients_grams_check,
+    ADD CONSTRAINT recipe_ingredients_grams_check CHECK (grams >= 0);
+ALTER TABLE daily_nutrition_totals DROP CONSTRAINT IF EXISTS daily_nutrition_totals_amount_check,
+    ADD CONSTRAINT daily_nutrition_totals_amount_check CHECK (amount >= 0);
+ALTER TABLE nutrient_target DROP CONSTRAINT IF EXISTS nutrient_target_min_amount_check,
+    ADD CONSTRAINT nutrient_target_min_amount_check CHECK (min_amount >= 0);
+ALTER TABLE nutrient_target DROP CONSTRAINT IF EXISTS nutrient_target_max_amount_check,
+    ADD CONSTRAINT nutrient_target_max_amount_check CHECK (max_amount >= 0);
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_duration_minutes_check,
+    ADD CONSTRAINT exercise_log_duration_minutes_check CHECK (duration_minutes >= 0);
+ALTER TABLE exercise_log DROP CONSTRAINT IF EXISTS exercise_log_calories_burned_check,
+    ADD CONSTRAINT exercise_log_calories_burned_check CHECK (calories_burned >= 0);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_meal_log_user_consumed;
+ALTER TABLE meal_log DROP COLUMN IF EXISTS consumed_at;
+ALTER TABLE "User" DROP COLUMN IF EXISTS timezone;
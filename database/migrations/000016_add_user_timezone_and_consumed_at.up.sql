@@ -0,0 +1,13 @@
+-- Diary days are resolved in the user's timezone; users who never picked one keep UTC
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS timezone VARCHAR(64) NOT NULL DEFAULT 'UTC';
+
+-- When a meal was eaten, as opposed to when it was logged. Existing meals were eaten when logged.
+ALTER TABLE meal_log ADD COLUMN IF NOT EXISTS consumed_at TIMESTAMPTZ;
+UPDATE meal_log SET consumed_at = created_at WHERE consumed_at IS NULL;
+ALTER TABLE meal_log ALTER COLUMN consumed_at SET NOT NULL;
+
+CREATE INDEX IF NOT EXISTS idx_meal_log_user_consumed ON meal_log (user_id, consumed_at);
+
+-- The rollup was keyed by dates in the server's timezone; readers use the raw rows until each day is
+-- refreshed or "rebuild-rollups" runs
+UPDATE daily_nutrition_status SET stale = true;
@@ -0,0 +1,37 @@
+-- Saved meals a user logs again in one request; shared templates are visible to every user
+CREATE TABLE IF NOT EXISTS meal_template (
+    id         BIGSERIAL PRIMARY KEY,
+    user_id    BIGINT       NOT NULL,
+    name       VARCHAR(255) NOT NULL,
+    meal_type  VARCHAR(255) NOT NULL DEFAULT '',
+    visibility VARCHAR(16)  NOT NULL DEFAULT 'private',
+    created_at TIMESTAMPTZ  NOT NULL,
+    updated_at TIMESTAMPTZ  NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_template_user ON meal_template (user_id);
+CREATE INDEX IF NOT EXISTS idx_meal_template_visibility ON meal_template (visibility);
+
+-- Amount of each food in a template, in the unit the user picked
+CREATE TABLE IF NOT EXISTS meal_template_items (
+    id               BIGSERIAL PRIMARY KEY,
+    meal_template_id BIGINT           NOT NULL,
+    food_id          BIGINT           NOT NULL,
+    unit             VARCHAR(32)      NOT NULL DEFAULT 'serving',
+    amount           DOUBLE PRECISION NOT NULL,
+    quantity_grams   DOUBLE PRECISION NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_meal_template_items_template ON meal_template_items (meal_template_id);
+CREATE INDEX IF NOT EXISTS idx_meal_template_items_food ON meal_template_items (food_id);
+
+ALTER TABLE meal_template DROP CONSTRAINT IF EXISTS meal_template_fk1,
+    ADD CONSTRAINT meal_template_fk1 FOREIGN KEY (user_id) REFERENCES "User" (id) ON DELETE CASCADE;
+ALTER TABLE meal_template_items DROP CONSTRAINT IF EXISTS meal_template_items_fk1,
+    ADD CONSTRAINT meal_template_items_fk1 FOREIGN KEY (meal_template_id) REFERENCES meal_template (id) ON DELETE CASCADE;
+ALTER TABLE meal_template_items DROP CONSTRAINT IF EXISTS meal_template_items_fk2,
+    ADD CONSTRAINT meal_template_items_fk2 FOREIGN KEY (food_id) REFERENCES food (id) ON DELETE RESTRICT;
+ALTER TABLE meal_template_items DROP CONSTRAINT IF EXISTS meal_template_items_amount_check,
+    ADD CONSTRAINT meal_template_items_amount_check CHECK (amount >= 0);
+ALTER TABLE meal_template_items DROP CONSTRAINT IF EXISTS meal_template_items_quantity_grams_check,
+    ADD CONSTRAINT meal_template_items_quantity_grams_check CHECK (quantity_grams >= 0);
@@ -0,0 +1 @@
+ALTER TABLE "User" DROP COLUMN IF EXISTS life_stage;
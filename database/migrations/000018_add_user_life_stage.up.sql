@@ -0,0 +1,3 @@
+-- Pregnancy and lactation select their own reference intakes; empty for everyone else
+ALTER TABLE "User" ADD COLUMN IF NOT EXISTS life_stage VARCHAR(16) NOT NULL DEFAULT ''
+    CHECK (life_stage IN ('', 'pregnancy', 'lactation'));
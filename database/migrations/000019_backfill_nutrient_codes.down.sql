@@ -0,0 +1,2 @@
+-- The backfilled codes are indistinguishable from seeded ones and stay in place
+SELECT 1;
@@ -0,0 +1,43 @@
+-- Databases built by the old startup auto-migration got the code column without the codes
+-- seeded by 000002, or hold the seeded nutrients under other IDs. Match the nutrients still
+-- without a code by name, preferring the USDA name; codes already in use are left alone.
+CREATE TEMP TABLE nutrient_code_aliases (code, name, priority) ON COMMIT DROP AS
+VALUES
+    ('energy',       'energy',                      1),
+    ('protein',      'protein',                     1),
+    ('fat',          'total lipid (fat)',           1),
+    ('fat',          'total fat',                   2),
+    ('fat',          'fat',                         3),
+    ('carbohydrate', 'carbohydrate, by difference', 1),
+    ('carbohydrate', 'carbohydrate',                2),
+    ('fiber',        'fiber, total dietary',        1),
+    ('fiber',        'fiber',                       2),
+    ('cholesterol',  'cholesterol',                 1),
+    ('vitamin_a',    'vitamin a, rae',              1),
+    ('vitamin_b12',  'vitamin b12',                 1),
+    ('calcium',      'calcium, ca',                 1),
+    ('calcium',      'calcium',                     2),
+    ('iron',         'iron, fe',                    1),
+    ('iron',         'iron',                        2);
+
+-- Earlier versions of 000002 stamped codes by ID alone, so a nutrient named after one code may
+-- hold another, e.g. 'fat' on the carbohydrate row. Clear those before matching.
+UPDATE nutrient
+SET code = ''
+FROM nutrient_code_aliases aliases
+WHERE LOWER(TRIM(nutrient.name)) = aliases.name
+  AND nutrient.code <> ''
+  AND nutrient.code <> aliases.code
+  AND nutrient.code IN (SELECT code FROM nutrient_code_aliases);
+
+WITH matches AS (
+    SELECT DISTINCT ON (aliases.code) aliases.code, nutrient.id
+    FROM nutrient_code_aliases aliases
+    JOIN nutrient ON LOWER(TRIM(nutrient.name)) = aliases.name AND nutrient.code = ''
+    WHERE NOT EXISTS (SELECT 1 FROM nutrient taken WHERE taken.code = aliases.code)
+    ORDER BY aliases.code, aliases.priority, nutrient.id
+)
+UPDATE nutrient
+SET code = matches.code
+FROM matches
+WHERE nutrient.id = matches.id;
@@ -0,0 +1,10 @@
+// Package migrations holds the versioned SQL migrations of the database schema. Each version is a
+// pair of files, NNNNNN_name.up.sql and NNNNNN_name.down.sql, applied in version order.
+package migrations
+
+import "embed"
+
+// FS holds the migration files, embedded so the binary migrates without the source tree
+//
+//go:embed *.sql
+var FS embed.FS
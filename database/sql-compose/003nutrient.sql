@@ -1,13 +1,13 @@
-INSERT INTO "nutrient" ("id", "name", "category") VALUES
-                                                      (1, 'Energy', 'Macronutrient'),
-                                                      (2, 'Protein', 'Macronutrient'),
-                                                      (3, 'Total lipid (fat)', 'Macronutrient'),
-                                                      (4, 'Carbohydrate, by difference', 'Macronutrient'),
-                                                      (5, 'Fiber, total dietary', 'Macronutrient'),
-                                                      (6, 'Cholesterol', 'Macronutrient'),
-                                                      (7, 'Vitamin A, RAE', 'Vitamin'),
-                                                      (8, 'Vitamin B12', 'Vitamin'),
-                                                      (9, 'Calcium, Ca', 'Mineral'),
-                                                      (10, 'Iron, Fe', 'Mineral');
+INSERT INTO "nutrient" ("id", "code", "name", "category") VALUES
+                                                      (1, 'energy', 'Energy', 'Macronutrient'),
+                                                      (2, 'protein', 'Protein', 'Macronutrient'),
+                                                      (3, 'fat', 'Total lipid (fat)', 'Macronutrient'),
+                                                      (4, 'carbohydrate', 'Carbohydrate, by difference', 'Macronutrient'),
+                                                      (5, 'fiber', 'Fiber, total dietary', 'Macronutrient'),
+                                                      (6, 'cholesterol', 'Cholesterol', 'Macronutrient'),
+                                                      (7, 'vitamin_a', 'Vitamin A, RAE', 'Vitamin'),
+                                                      (8, 'vitamin_b12', 'Vitamin B12', 'Vitamin'),
+                                                      (9, 'calcium', 'Calcium, Ca', 'Mineral'),
+                                                      (10, 'iron', 'Iron, Fe', 'Mineral');
 
 
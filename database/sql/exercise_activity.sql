@@ -0,0 +1,23 @@
+INSERT INTO "exercise_activity" ("id", "code", "name", "category", "met_light", "met_moderate", "met_vigorous") VALUES
+                                                      (1, 'walking', 'Walking', 'Walking', 2.8, 3.5, 5.0),
+                                                      (2, 'hiking', 'Hiking', 'Walking', 5.3, 6.0, 7.8),
+                                                      (3, 'running', 'Running', 'Running', 7.0, 9.8, 11.8),
+                                                      (4, 'cycling', 'Cycling', 'Cycling', 4.0, 6.8, 10.0),
+                                                      (5, 'stationary_cycling', 'Stationary cycling', 'Cycling', 3.5, 6.8, 8.8),
+                                                      (6, 'swimming', 'Swimming laps', 'Water', 5.8, 8.3, 9.8),
+                                                      (7, 'rowing_machine', 'Rowing machine', 'Conditioning', 4.8, 7.0, 8.5),
+                                                      (8, 'elliptical', 'Elliptical trainer', 'Conditioning', 4.0, 5.0, 6.0),
+                                                      (9, 'stair_climbing', 'Stair climbing', 'Conditioning', 4.0, 6.0, 8.8),
+                                                      (10, 'circuit_training', 'Circuit training', 'Conditioning', 4.3, 8.0, 9.5),
+                                                      (11, 'jump_rope', 'Jumping rope', 'Conditioning', 8.8, 11.8, 12.3),
+                                                      (12, 'strength_training', 'Strength training', 'Strength', 3.5, 5.0, 6.0),
+                                                      (13, 'yoga', 'Yoga', 'Flexibility', 2.5, 3.0, 4.0),
+                                                      (14, 'pilates', 'Pilates', 'Flexibility', 2.8, 3.0, 3.8),
+                                                      (15, 'dancing', 'Dancing', 'Dance', 3.0, 5.0, 7.3),
+                                                      (16, 'tennis', 'Tennis', 'Sports', 5.0, 7.3, 8.0),
+                                                      (17, 'basketball', 'Basketball', 'Sports', 4.5, 6.5, 8.0),
+                                                      (18, 'soccer', 'Soccer', 'Sports', 5.0, 7.0, 10.0),
+                                                      (19, 'cross_country_skiing', 'Cross-country skiing', 'Winter', 6.8, 9.0, 12.5),
+                                                      (20, 'gardening', 'Gardening', 'Household', 2.3, 3.8, 4.5);
+
+SELECT setval(pg_get_serial_sequence('"exercise_activity"', 'id'), (SELECT MAX("id") FROM "exercise_activity"));
@@ -1,3 +1,5 @@
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
 CREATE TABLE IF NOT EXISTS "User" (
                                       "id" serial NOT NULL UNIQUE,
                                       "name" varchar(255) NOT NULL,
@@ -11,20 +13,37 @@ CREATE TABLE IF NOT EXISTS "User" (
     "activity_level" varchar(255) NOT NULL,
     "created_at" timestamp with time zone NOT NULL,
                                "role" varchar(255) NOT NULL,
+    "deletion_requested_at" timestamp with time zone,
+    "deletion_scheduled_at" timestamp with time zone,
+    "timezone" varchar(64) NOT NULL DEFAULT 'UTC',
+    "life_stage" varchar(16) NOT NULL DEFAULT '' CHECK ("life_stage" IN ('', 'pregnancy', 'lactation')),
     PRIMARY KEY ("id")
     );
 
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_email" ON "User" ("email");
+CREATE INDEX IF NOT EXISTS "idx_user_deletion_scheduled" ON "User" ("deletion_scheduled_at");
+
 CREATE TABLE IF NOT EXISTS "food" (
                                       "id" serial NOT NULL UNIQUE,
                                       "name" varchar(255) NOT NULL,
+    "brand" varchar(255) NOT NULL DEFAULT '',
     "serving_size_gram" double precision NOT NULL,
     "source" varchar(255) NOT NULL,
     "image_url" varchar(255),
+    "barcode" varchar(14) NOT NULL DEFAULT '',
+    "owner_id" bigint,
+    "visibility" varchar(16) NOT NULL DEFAULT 'verified' CHECK ("visibility" IN ('private', 'shared', 'verified')),
     PRIMARY KEY ("id")
     );
 
+CREATE INDEX IF NOT EXISTS "idx_food_search_document" ON "food" USING GIN (to_tsvector('english', "name" || ' ' || "brand"));
+CREATE INDEX IF NOT EXISTS "idx_food_name_trgm" ON "food" USING GIN ("name" gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS "idx_food_brand_lower" ON "food" (LOWER("brand"));
+CREATE INDEX IF NOT EXISTS "idx_food_owner" ON "food" ("owner_id");
+
 CREATE TABLE IF NOT EXISTS "nutrient" (
                                           "id" serial NOT NULL UNIQUE,
+                                          "code" varchar(64) NOT NULL DEFAULT '',
                                           "name" varchar(255) NOT NULL,
     "category" varchar(255) NOT NULL,
     "unit" varchar(20) NOT NULL DEFAULT 'g',
@@ -42,16 +61,22 @@ CREATE TABLE IF NOT EXISTS "food_nutrients" (
 CREATE TABLE IF NOT EXISTS "meal_log" (
                                           "id" serial NOT NULL UNIQUE,
                                           "user_id" bigint NOT NULL,
-                                          "created_at" date NOT NULL,
+                                          "created_at" timestamp with time zone NOT NULL,
                                           "meal_type" varchar(255) NOT NULL,
+                                          "note" text,
+                                          "consumed_at" timestamp with time zone NOT NULL,
     PRIMARY KEY ("id")
     );
 
+CREATE INDEX IF NOT EXISTS "idx_meal_log_user_consumed" ON "meal_log" ("user_id", "consumed_at");
+
 CREATE TABLE IF NOT EXISTS "meal_log_items" (
                                                 "id" serial NOT NULL UNIQUE,
                                                 "meal_log_id" bigint NOT NULL,
                                                 "food_id" bigint NOT NULL,
                                                 "quantity" bigint NOT NULL,
+                                                "unit" varchar(32) NOT NULL DEFAULT 'serving',
+                                                "amount" double precision NOT NULL DEFAULT 0,
                                                 "quantity_grams" double precision NOT NULL,
                                                 PRIMARY KEY ("id")
     );
@@ -59,21 +84,219 @@ CREATE TABLE IF NOT EXISTS "meal_log_items" (
 CREATE TABLE IF NOT EXISTS "user_biometrics" (
                                                  "id" serial NOT NULL UNIQUE,
                                                  "user_id" bigint NOT NULL,
-                                                 "created_at" date NOT NULL,
+                                                 "created_at" timestamp with time zone NOT NULL,
                                                  "type" varchar(255) NOT NULL,
     "value" double precision NOT NULL,
     "unit" varchar(255) NOT NULL,
     PRIMARY KEY ("id")
     );
 
+CREATE TABLE IF NOT EXISTS "daily_nutrition_totals" (
+                                                        "id" bigserial NOT NULL UNIQUE,
+                                                        "user_id" bigint NOT NULL,
+                                                        "date" date NOT NULL,
+                                                        "nutrient_id" bigint NOT NULL,
+                                                        "amount" double precision NOT NULL,
+                                                        "updated_at" timestamp with time zone NOT NULL,
+                                                        PRIMARY KEY ("id")
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_daily_nutrition_totals_user_date_nutrient" ON "daily_nutrition_totals" ("user_id", "date", "nutrient_id");
+
+CREATE TABLE IF NOT EXISTS "daily_nutrition_status" (
+                                                        "user_id" bigint NOT NULL,
+                                                        "date" date NOT NULL,
+                                                        "stale" boolean NOT NULL DEFAULT true,
+                                                        "computed_at" timestamp with time zone,
+                                                        PRIMARY KEY ("user_id", "date")
+    );
+
+CREATE TABLE IF NOT EXISTS "recipe" (
+                                        "id" bigserial NOT NULL UNIQUE,
+                                        "user_id" bigint NOT NULL,
+                                        "food_id" bigint NOT NULL,
+                                        "servings" double precision NOT NULL,
+                                        "cooked_weight_gram" double precision NOT NULL DEFAULT 0,
+                                        "created_at" timestamp with time zone NOT NULL,
+                                        "updated_at" timestamp with time zone NOT NULL,
+                                        PRIMARY KEY ("id")
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_recipe_food" ON "recipe" ("food_id");
+CREATE INDEX IF NOT EXISTS "idx_recipe_user" ON "recipe" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "recipe_ingredients" (
+                                                    "id" bigserial NOT NULL UNIQUE,
+                                                    "recipe_id" bigint NOT NULL,
+                                                    "food_id" bigint NOT NULL,
+                                                    "grams" double precision NOT NULL,
+                                                    PRIMARY KEY ("id")
+    );
+
+CREATE INDEX IF NOT EXISTS "idx_recipe_ingredients_recipe" ON "recipe_ingredients" ("recipe_id");
+CREATE INDEX IF NOT EXISTS "idx_recipe_ingredients_food" ON "recipe_ingredients" ("food_id");
+
+CREATE TABLE IF NOT EXISTS "food_portion" (
+                                              "id" bigserial NOT NULL UNIQUE,
+                                              "food_id" bigint NOT NULL,
+                                              "unit" varchar(32) NOT NULL,
+                                              "description" text NOT NULL DEFAULT '',
+                                              "gram_weight" double precision NOT NULL,
+                                              PRIMARY KEY ("id")
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_food_portion_food_unit" ON "food_portion" ("food_id", "unit");
+
+CREATE TABLE IF NOT EXISTS "nutrient_target_set" (
+                                                     "id" bigserial NOT NULL UNIQUE,
+                                                     "user_id" bigint NOT NULL,
+                                                     "effective_from" date NOT NULL,
+                                                     "calories" double precision,
+                                                     "preset" varchar(32) NOT NULL DEFAULT '',
+                                                     "protein_percent" double precision,
+                                                     "carbohydrate_percent" double precision,
+                                                     "fat_percent" double precision,
+                                                     "protein_grams" double precision,
+                                                     "carbohydrate_grams" double precision,
+                                                     "fat_grams" double precision,
+                                                     "created_at" timestamp with time zone NOT NULL,
+                                                     PRIMARY KEY ("id")
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_nutrient_target_set_user_date" ON "nutrient_target_set" ("user_id", "effective_from");
+
+CREATE TABLE IF NOT EXISTS "nutrient_target" (
+                                                 "id" bigserial NOT NULL UNIQUE,
+                                                 "target_set_id" bigint NOT NULL,
+                                                 "nutrient_id" bigint NOT NULL,
+                                                 "min_amount" double precision,
+                                                 "max_amount" double precision,
+                                                 PRIMARY KEY ("id")
+    );
+
+CREATE INDEX IF NOT EXISTS "idx_nutrient_target_set" ON "nutrient_target" ("target_set_id");
+
+CREATE TABLE IF NOT EXISTS "exercise_activity" (
+                                                   "id" bigserial NOT NULL UNIQUE,
+                                                   "code" varchar(64) NOT NULL,
+                                                   "name" text NOT NULL,
+                                                   "category" varchar(64) NOT NULL DEFAULT '',
+                                                   "met_light" double precision NOT NULL,
+                                                   "met_moderate" double precision NOT NULL,
+                                                   "met_vigorous" double precision NOT NULL,
+                                                   PRIMARY KEY ("id")
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_exercise_activity_code" ON "exercise_activity" ("code");
+
+CREATE TABLE IF NOT EXISTS "exercise_log" (
+                                              "id" bigserial NOT NULL UNIQUE,
+                                              "user_id" bigint NOT NULL,
+                                              "activity_id" bigint NOT NULL,
+                                              "performed_at" timestamp with time zone NOT NULL,
+                                              "duration_minutes" double precision NOT NULL,
+                                              "intensity" varchar(16) NOT NULL,
+                                              "met" double precision NOT NULL,
+                                              "weight_kg" double precision NOT NULL,
+                                              "calories_burned" double precision NOT NULL,
+                                              "note" text NOT NULL DEFAULT '',
+                                              "source" varchar(16) NOT NULL DEFAULT 'manual',
+                                              "import_id" bigint,
+                                              "distance_meters" double precision NOT NULL DEFAULT 0,
+                                              "elevation_gain_meters" double precision NOT NULL DEFAULT 0,
+                                              "avg_heart_rate" double precision NOT NULL DEFAULT 0,
+                                              "max_heart_rate" double precision NOT NULL DEFAULT 0,
+                                              "device_calories" double precision NOT NULL DEFAULT 0,
+                                              "created_at" timestamp with time zone NOT NULL,
+                                              PRIMARY KEY ("id")
+    );
+
+CREATE INDEX IF NOT EXISTS "idx_exercise_log_user_performed" ON "exercise_log" ("user_id", "performed_at");
+
+CREATE INDEX IF NOT EXISTS "idx_exercise_log_import" ON "exercise_log" ("import_id");
+
+CREATE TABLE IF NOT EXISTS "exercise_import" (
+                                                 "id" bigserial NOT NULL UNIQUE,
+                                                 "user_id" bigint NOT NULL,
+                                                 "file_hash" varchar(64) NOT NULL,
+                                                 "file_name" text NOT NULL DEFAULT '',
+                                                 "format" varchar(8) NOT NULL,
+                                                 "created_at" timestamp with time zone NOT NULL,
+                                                 PRIMARY KEY ("id")
+    );
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_exercise_import_user_hash" ON "exercise_import" ("user_id", "file_hash");
+
+CREATE TABLE IF NOT EXISTS "meal_template" (
+                                               "id" bigserial NOT NULL UNIQUE,
+                                               "user_id" bigint NOT NULL,
+                                               "name" varchar(255) NOT NULL,
+                                               "meal_type" varchar(255) NOT NULL DEFAULT '',
+                                               "visibility" varchar(16) NOT NULL DEFAULT 'private',
+                                               "created_at" timestamp with time zone NOT NULL,
+                                               "updated_at" timestamp with time zone NOT NULL,
+                                               PRIMARY KEY ("id")
+    );
+
+CREATE INDEX IF NOT EXISTS "idx_meal_template_user" ON "meal_template" ("user_id");
+CREATE INDEX IF NOT EXISTS "idx_meal_template_visibility" ON "meal_template" ("visibility");
+
+CREATE TABLE IF NOT EXISTS "meal_template_items" (
+                                                     "id" bigserial NOT NULL UNIQUE,
+                                                     "meal_template_id" bigint NOT NULL,
+                                                     "food_id" bigint NOT NULL,
+                                                     "unit" varchar(32) NOT NULL DEFAULT 'serving',
+                                                     "amount" double precision NOT NULL,
+                                                     "quantity_grams" double precision NOT NULL,
+                                                     PRIMARY KEY ("id")
+    );
+
+CREATE INDEX IF NOT EXISTS "idx_meal_template_items_template" ON "meal_template_items" ("meal_template_id");
+CREATE INDEX IF NOT EXISTS "idx_meal_template_items_food" ON "meal_template_items" ("food_id");
 
 
 
-ALTER TABLE "food_nutrients" ADD CONSTRAINT "food_nutrients_fk1" FOREIGN KEY ("food_id") REFERENCES "food"("id");
 
-ALTER TABLE "food_nutrients" ADD CONSTRAINT "food_nutrients_fk2" FOREIGN KEY ("nutrient_id") REFERENCES "nutrient"("id");
-ALTER TABLE "meal_log" ADD CONSTRAINT "meal_log_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id");
-ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_fk1" FOREIGN KEY ("meal_log_id") REFERENCES "meal_log"("id");
+ALTER TABLE "food" ADD CONSTRAINT "food_owner_fk" FOREIGN KEY ("owner_id") REFERENCES "User"("id") ON DELETE SET NULL;
+ALTER TABLE "food_nutrients" ADD CONSTRAINT "food_nutrients_fk1" FOREIGN KEY ("food_id") REFERENCES "food"("id") ON DELETE CASCADE;
+ALTER TABLE "food_nutrients" ADD CONSTRAINT "food_nutrients_fk2" FOREIGN KEY ("nutrient_id") REFERENCES "nutrient"("id") ON DELETE RESTRICT;
+ALTER TABLE "meal_log" ADD CONSTRAINT "meal_log_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_fk1" FOREIGN KEY ("meal_log_id") REFERENCES "meal_log"("id") ON DELETE CASCADE;
+ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_fk2" FOREIGN KEY ("food_id") REFERENCES "food"("id") ON DELETE RESTRICT;
+ALTER TABLE "user_biometrics" ADD CONSTRAINT "user_biometrics_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "daily_nutrition_totals" ADD CONSTRAINT "daily_nutrition_totals_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "daily_nutrition_totals" ADD CONSTRAINT "daily_nutrition_totals_fk2" FOREIGN KEY ("nutrient_id") REFERENCES "nutrient"("id") ON DELETE CASCADE;
+ALTER TABLE "daily_nutrition_status" ADD CONSTRAINT "daily_nutrition_status_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "recipe" ADD CONSTRAINT "recipe_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "recipe" ADD CONSTRAINT "recipe_fk2" FOREIGN KEY ("food_id") REFERENCES "food"("id") ON DELETE RESTRICT;
+ALTER TABLE "recipe_ingredients" ADD CONSTRAINT "recipe_ingredients_fk1" FOREIGN KEY ("recipe_id") REFERENCES "recipe"("id") ON DELETE CASCADE;
+ALTER TABLE "recipe_ingredients" ADD CONSTRAINT "recipe_ingredients_fk2" FOREIGN KEY ("food_id") REFERENCES "food"("id") ON DELETE RESTRICT;
+ALTER TABLE "food_portion" ADD CONSTRAINT "food_portion_fk1" FOREIGN KEY ("food_id") REFERENCES "food"("id") ON DELETE CASCADE;
+ALTER TABLE "nutrient_target_set" ADD CONSTRAINT "nutrient_target_set_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "nutrient_target" ADD CONSTRAINT "nutrient_target_fk1" FOREIGN KEY ("target_set_id") REFERENCES "nutrient_target_set"("id") ON DELETE CASCADE;
+ALTER TABLE "nutrient_target" ADD CONSTRAINT "nutrient_target_fk2" FOREIGN KEY ("nutrient_id") REFERENCES "nutrient"("id") ON DELETE RESTRICT;
+ALTER TABLE "exercise_log" ADD CONSTRAINT "exercise_log_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "exercise_log" ADD CONSTRAINT "exercise_log_fk2" FOREIGN KEY ("activity_id") REFERENCES "exercise_activity"("id") ON DELETE RESTRICT;
+ALTER TABLE "exercise_log" ADD CONSTRAINT "exercise_log_fk3" FOREIGN KEY ("import_id") REFERENCES "exercise_import"("id") ON DELETE SET NULL;
+ALTER TABLE "exercise_import" ADD CONSTRAINT "exercise_import_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "meal_template" ADD CONSTRAINT "meal_template_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id") ON DELETE CASCADE;
+ALTER TABLE "meal_template_items" ADD CONSTRAINT "meal_template_items_fk1" FOREIGN KEY ("meal_template_id") REFERENCES "meal_template"("id") ON DELETE CASCADE;
+ALTER TABLE "meal_template_items" ADD CONSTRAINT "meal_template_items_fk2" FOREIGN KEY ("food_id") REFERENCES "food"("id") ON DELETE RESTRICT;
 
-ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_fk2" FOREIGN KEY ("food_id") REFERENCES "food"("id");
-ALTER TABLE "user_biometrics" ADD CONSTRAINT "user_biometrics_fk1" FOREIGN KEY ("user_id") REFERENCES "User"("id");
\ No newline at end of file
+ALTER TABLE "food_nutrients" ADD CONSTRAINT "food_nutrients_food_nutrient_key" UNIQUE ("food_id", "nutrient_id");
+ALTER TABLE "food" ADD CONSTRAINT "food_serving_size_gram_check" CHECK ("serving_size_gram" >= 0);
+ALTER TABLE "food_nutrients" ADD CONSTRAINT "food_nutrients_amount_per_100g_check" CHECK ("amount_per_100g" >= 0);
+ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_quantity_check" CHECK ("quantity" >= 0);
+ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_amount_check" CHECK ("amount" >= 0);
+ALTER TABLE "meal_log_items" ADD CONSTRAINT "meal_log_items_quantity_grams_check" CHECK ("quantity_grams" >= 0);
+ALTER TABLE "food_portion" ADD CONSTRAINT "food_portion_gram_weight_check" CHECK ("gram_weight" >= 0);
+ALTER TABLE "recipe" ADD CONSTRAINT "recipe_servings_check" CHECK ("servings" >= 0);
+ALTER TABLE "recipe" ADD CONSTRAINT "recipe_cooked_weight_gram_check" CHECK ("cooked_weight_gram" >= 0);
+ALTER TABLE "recipe_ingredients" ADD CONSTRAINT "recipe_ingredients_grams_check" CHECK ("grams" >= 0);
+ALTER TABLE "daily_nutrition_totals" ADD CONSTRAINT "daily_nutrition_totals_amount_check" CHECK ("amount" >= 0);
+ALTER TABLE "nutrient_target" ADD CONSTRAINT "nutrient_target_min_amount_check" CHECK ("min_amount" >= 0);
+ALTER TABLE "nutrient_target" ADD CONSTRAINT "nutrient_target_max_amount_check" CHECK ("max_amount" >= 0);
+ALTER TABLE "exercise_log" ADD CONSTRAINT "exercise_log_duration_minutes_check" CHECK ("duration_minutes" >= 0);
+ALTER TABLE "exercise_log" ADD CONSTRAINT "exercise_log_calories_burned_check" CHECK ("calories_burned" >= 0);
+ALTER TABLE "meal_template_items" ADD CONSTRAINT "meal_template_items_amount_check" CHECK ("amount" >= 0);
+ALTER TABLE "meal_template_items" ADD CONSTRAINT "meal_template_items_quantity_grams_check" CHECK ("quantity_grams" >= 0);
\ No newline at end of file
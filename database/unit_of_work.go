@@ -0,0 +1,21 @@
+package database
+
+import "gorm.io/gorm"
+
+// UnitOfWork runs a service operation spanning several repositories in a single transaction, so
+// it is either saved whole or not at all. Repositories join the transaction through their WithTx
+// method.
+type UnitOfWork struct {
+	db *gorm.DB
+}
+
+// NewUnitOfWork creates a unit of work over db
+func NewUnitOfWork(db *gorm.DB) *UnitOfWork {
+	return &UnitOfWork{db: db}
+}
+
+// Do runs fn in a transaction, committing when it returns nil and rolling back on an error or a
+// panic. Constraint violations are returned as a *ConstraintError.
+func (u *UnitOfWork) Do(fn func(tx *gorm.DB) error) error {
+	return TranslateError(u.db.Transaction(fn))
+}
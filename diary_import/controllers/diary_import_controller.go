@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/diary_import/models"
+	"github.com/momokapoolz/caloriesapp/diary_import/services"
+	"github.com/momokapoolz/caloriesapp/helpers"
+)
+
+// DiaryImportController handles HTTP requests for diary imports
+type DiaryImportController struct {
+	service *services.DiaryImportService
+}
+
+// NewDiaryImportController creates a new diary import controller instance
+func NewDiaryImportController(service *services.DiaryImportService) *DiaryImportController {
+	return &DiaryImportController{service: service}
+}
+
+// ImportDiary godoc
+// @Summary      Import diary history
+// @Description  Import a Cronometer servings or daily summary export, or a MyFitnessPal nutrition export, into the authenticated user's meal logs. Foods are matched by name against the catalog when logged in grams or a portion of the food; other entries get a private placeholder food carrying the exported nutrients. Meals already imported from the same application are skipped. With dry_run=true nothing is stored and the response reports what would be created.
+// @Tags         diary_import
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file     formData  file    true   "CSV export"
+// @Param        format   formData  string  false  "cronometer_servings, cronometer_daily_summary or myfitnesspal; detected from the columns by default"
+// @Param        dry_run  formData  bool    false  "Report the import without storing it"
+// @Success      200  {object}  dto.DiaryImportResultDTO  "Dry run report"
+// @Success      201  {object}  dto.DiaryImportResultDTO  "Diary imported"
+// @Failure      400  {object}  map[string]string         "Missing or unreadable export"
+// @Failure      401  {object}  map[string]string         "Unauthorized"
+// @Failure      500  {object}  map[string]string         "Internal server error"
+// @Security     BearerAuth
+// @Router       /diary-import/ [post]
+func (c *DiaryImportController) ImportDiary(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A file upload is required"})
+		return
+	}
+
+	format := ctx.PostForm("format")
+	switch format {
+	case "", models.FormatCronometerServings, models.FormatCronometerDailySummary, models.FormatMyFitnessPal:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be cronometer_servings, cronometer_daily_summary or myfitnesspal"})
+		return
+	}
+	dryRun := false
+	if value := ctx.PostForm("dry_run"); value != "" {
+		if dryRun, err = strconv.ParseBool(value); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "dry_run must be true or false"})
+			return
+		}
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+	defer file.Close()
+
+	result, err := c.service.ImportDiary(userClaims.UserID, format, file, dryRun)
+	if errors.Is(err, services.ErrInvalidDiaryFile) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import diary"})
+		return
+	}
+
+	status := http.StatusCreated
+	if dryRun {
+		status = http.StatusOK
+	}
+	ctx.JSON(status, result)
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// Diary export formats
+const (
+	FormatCronometerServings     = "cronometer_servings"
+	FormatCronometerDailySummary = "cronometer_daily_summary"
+	FormatMyFitnessPal           = "myfitnesspal"
+)
+
+// Meal types diary entries are filed under
+const (
+	MealBreakfast = "breakfast"
+	MealLunch     = "lunch"
+	MealDinner    = "dinner"
+	MealSnack     = "snack"
+	MealOther     = "other"
+)
+
+// DiaryEntry is one food, or one meal or day total, read from a diary export. Amounts of
+// nutrients are totals for the entry, keyed by nutrient, in Units of the export.
+type DiaryEntry struct {
+	Date      time.Time
+	MealType  string
+	FoodName  string
+	Amount    float64
+	Unit      string
+	Nutrients map[string]NutrientAmount
+}
+
+// NutrientAmount is an exported nutrient amount. Code is set when the column maps onto a
+// stable nutrient code; otherwise Name is matched against the nutrient table.
+type NutrientAmount struct {
+	Code   string
+	Name   string
+	Unit   string
+	Amount float64
+}
+
+// MealKey identifies a meal of a day in an import
+type MealKey struct {
+	Date     string
+	MealType string
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+)
+
+// PlaceholderFood is a private quick-entry food carrying the nutrients of an exported entry
+// that matched no catalog food. Its serving is the exported amount, stored as 100 g so the
+// nutrients per 100 g are the exported totals. Food.ID is zero until the food is stored.
+type PlaceholderFood struct {
+	Food      foodModels.Food
+	Nutrients []foodNutrientsModels.FoodNutrient
+}
+
+// PlannedItem is an item to log. Items of placeholder foods take the ID of their placeholder
+// once it is stored.
+type PlannedItem struct {
+	Item        mealLogItemsModels.MealLogItem
+	Placeholder *PlaceholderFood
+}
+
+// PlannedMeal is a meal log to create with its items
+type PlannedMeal struct {
+	MealLog mealLogModels.MealLog
+	Items   []PlannedItem
+}
@@ -0,0 +1,108 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/diary_import/models"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	"gorm.io/gorm"
+)
+
+// DiaryImportRepository handles the database operations of diary imports
+type DiaryImportRepository struct {
+	db *gorm.DB
+}
+
+// NewDiaryImportRepository creates a new diary import repository instance
+func NewDiaryImportRepository(db *gorm.DB) *DiaryImportRepository {
+	return &DiaryImportRepository{db: db}
+}
+
+// GetFoodsByNames retrieves the foods a user may see whose name matches one of names,
+// ignoring case. Verified foods come first.
+func (r *DiaryImportRepository) GetFoodsByNames(names []string, userID uint) ([]foodModels.Food, error) {
+	var foods []foodModels.Food
+	if len(names) == 0 {
+		return foods, nil
+	}
+	lowered := make([]string, 0, len(names))
+	for _, name := range names {
+		lowered = append(lowered, strings.ToLower(name))
+	}
+	err := r.db.Scopes(foodRepo.VisibleTo(foodModels.FoodViewer{UserID: userID})).
+		Where("LOWER(name) IN ?", lowered).
+		Order("visibility = '" + foodModels.VisibilityVerified + "' DESC, id").
+		Find(&foods).Error
+	return foods, err
+}
+
+// GetOwnedFoodsBySources retrieves the foods of a user with one of the sources
+func (r *DiaryImportRepository) GetOwnedFoodsBySources(sources []string, userID uint) ([]foodModels.Food, error) {
+	var foods []foodModels.Food
+	if len(sources) == 0 {
+		return foods, nil
+	}
+	err := r.db.Where("source IN ? AND owner_id = ?", sources, userID).Find(&foods).Error
+	return foods, err
+}
+
+// GetMealLogsByNote retrieves the meal logs of a user consumed within a range that carry a note
+func (r *DiaryImportRepository) GetMealLogsByNote(userID uint, note string, startDate, endDate time.Time) ([]mealLogModels.MealLog, error) {
+	var mealLogs []mealLogModels.MealLog
+	err := r.db.Where("user_id = ? AND note = ? AND consumed_at >= ? AND consumed_at <= ?", userID, note, startDate, endDate).
+		Find(&mealLogs).Error
+	return mealLogs, err
+}
+
+// CreateMeals stores the new placeholder foods with their nutrients, then the meal logs with
+// their items, in a single transaction
+func (r *DiaryImportRepository) CreateMeals(placeholders []*models.PlaceholderFood, meals []models.PlannedMeal) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		for _, placeholder := range placeholders {
+			if placeholder.Food.ID != 0 {
+				continue
+			}
+			if err := tx.Create(&placeholder.Food).Error; err != nil {
+				return err
+			}
+			for i := range placeholder.Nutrients {
+				placeholder.Nutrients[i].FoodID = placeholder.Food.ID
+			}
+			if len(placeholder.Nutrients) > 0 {
+				if err := tx.Create(&placeholder.Nutrients).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		for i := range meals {
+			meal := &meals[i]
+			if err := tx.Create(&meal.MealLog).Error; err != nil {
+				return err
+			}
+			items := make([]mealLogItemsModels.MealLogItem, 0, len(meal.Items))
+			for j := range meal.Items {
+				item := &meal.Items[j]
+				item.Item.MealLogID = meal.MealLog.ID
+				if item.Placeholder != nil {
+					item.Item.FoodID = item.Placeholder.Food.ID
+				}
+				items = append(items, item.Item)
+			}
+			if len(items) > 0 {
+				if err := tx.Create(&items).Error; err != nil {
+					return err
+				}
+			}
+			for j := range items {
+				meal.Items[j].Item.ID = items[j].ID
+			}
+		}
+		return nil
+	}))
+}
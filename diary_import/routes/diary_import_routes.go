@@ -0,0 +1,49 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/diary_import/controllers"
+	"github.com/momokapoolz/caloriesapp/diary_import/repository"
+	"github.com/momokapoolz/caloriesapp/diary_import/services"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	foodPortionRepo "github.com/momokapoolz/caloriesapp/food_portion/repository"
+	foodPortionServices "github.com/momokapoolz/caloriesapp/food_portion/services"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	"gorm.io/gorm"
+)
+
+// SetupDiaryImportRoutes initializes diary import routes
+func SetupDiaryImportRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	nutrientRepository := nutrientRepo.NewNutrientRepository(db)
+	foodRepository := foodRepo.NewFoodRepository(db)
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepo.NewMealLogItemRepository(db),
+		foodRepository,
+		foodNutrientsRepo.NewFoodNutrientRepository(db),
+		nutrientRepository,
+	)
+	mealLogRepository := mealLogRepo.NewMealLogRepository(db)
+	rollup := dailyNutritionServices.NewDailyNutritionService(dailyNutritionRepo.NewDailyNutritionRepository(db), mealLogRepository, engine)
+	portions := foodPortionServices.NewFoodPortionService(foodPortionRepo.NewFoodPortionRepository(db), foodRepository)
+	diaryImportController := controllers.NewDiaryImportController(services.NewDiaryImportService(
+		repository.NewDiaryImportRepository(db),
+		nutrientRepository,
+		portions,
+		rollup,
+		mealLogRepository,
+	))
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	diaryImportRoutes := router.Group("/diary-import", authMiddleware.RequireAuth())
+	{
+		diaryImportRoutes.POST("/", diaryImportController.ImportDiary)
+	}
+}
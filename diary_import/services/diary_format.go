@@ -0,0 +1,247 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/diary_import/models"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+)
+
+// ErrInvalidDiaryFile is returned for files that are not a supported diary export
+var ErrInvalidDiaryFile = errors.New("invalid diary export")
+
+// nutrientColumn maps an export column onto a nutrient code with the unit used when the
+// header does not name one
+type nutrientColumn struct {
+	Code string
+	Unit string
+}
+
+// cronometerColumns maps the Cronometer columns of the nutrients with a stable code. Other
+// nutrient columns are matched on the name of the nutrient.
+var cronometerColumns = map[string]nutrientColumn{
+	"energy":          {nutrientModels.CodeEnergy, "kcal"},
+	"protein":         {nutrientModels.CodeProtein, "g"},
+	"carbs":           {nutrientModels.CodeCarbohydrate, "g"},
+	"fat":             {nutrientModels.CodeFat, "g"},
+	"fiber":           {nutrientModels.CodeFiber, "g"},
+	"cholesterol":     {nutrientModels.CodeCholesterol, "mg"},
+	"vitamin a":       {nutrientModels.CodeVitaminA, "mcg"},
+	"b12 (cobalamin)": {nutrientModels.CodeVitaminB12, "mcg"},
+	"calcium":         {nutrientModels.CodeCalcium, "mg"},
+	"iron":            {nutrientModels.CodeIron, "mg"},
+}
+
+// myFitnessPalColumns maps the MyFitnessPal nutrition export columns. Its vitamin and mineral
+// columns are percents of the daily value and are not imported.
+var myFitnessPalColumns = map[string]nutrientColumn{
+	"calories":      {nutrientModels.CodeEnergy, "kcal"},
+	"fat":           {nutrientModels.CodeFat, "g"},
+	"carbohydrates": {nutrientModels.CodeCarbohydrate, "g"},
+	"protein":       {nutrientModels.CodeProtein, "g"},
+	"fiber":         {nutrientModels.CodeFiber, "g"},
+	"cholesterol":   {nutrientModels.CodeCholesterol, "mg"},
+}
+
+// layoutColumns are the export columns that describe an entry rather than a nutrient
+var layoutColumns = map[string]bool{
+	"day": true, "date": true, "time": true, "group": true, "food name": true,
+	"amount": true, "category": true, "completed": true, "meal": true, "note": true,
+}
+
+// headerUnit splits "Energy (kcal)" into the column name and its unit
+var headerUnit = regexp.MustCompile(`^(.*?)\s*\(([^()]*)\)$`)
+
+// diaryTable is an export read into rows addressed by column name
+type diaryTable struct {
+	columns map[string]int
+	header  []string
+	rows    [][]string
+}
+
+// readDiaryTable reads a CSV export with a header row
+func readDiaryTable(r io.Reader) (*diaryTable, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: failed to read header: %w", ErrInvalidDiaryFile, err)
+	}
+	table := &diaryTable{columns: make(map[string]int, len(header)), header: header}
+	for i, column := range header {
+		column = strings.ToLower(strings.TrimSpace(strings.TrimPrefix(column, "\ufeff")))
+		table.header[i] = column
+		table.columns[column] = i
+	}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return table, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: %w", ErrInvalidDiaryFile, line, err)
+		}
+		table.rows = append(table.rows, record)
+	}
+}
+
+// has reports whether the table has all the columns
+func (t *diaryTable) has(columns ...string) bool {
+	for _, column := range columns {
+		if _, ok := t.columns[column]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// field returns a trimmed cell of a row, or "" when the row is short or lacks the column
+func (t *diaryTable) field(row []string, column string) string {
+	i, ok := t.columns[column]
+	if !ok || i >= len(row) {
+		return ""
+	}
+	return strings.TrimSpace(row[i])
+}
+
+// format tells the format of an export from its columns
+func (t *diaryTable) format() (string, bool) {
+	switch {
+	case t.has("day", "food name", "amount"):
+		return models.FormatCronometerServings, true
+	case t.has("date", "meal", "calories"):
+		return models.FormatMyFitnessPal, true
+	case t.has("date", "energy (kcal)"):
+		return models.FormatCronometerDailySummary, true
+	default:
+		return "", false
+	}
+}
+
+// ParseDiary reads the entries of a Cronometer or MyFitnessPal CSV export. An empty format is
+// detected from the columns. It also returns the format and the number of rows skipped for
+// lacking a date or any nutrient.
+func ParseDiary(format string, r io.Reader) (string, []models.DiaryEntry, int, error) {
+	table, err := readDiaryTable(r)
+	if err != nil {
+		return "", nil, 0, err
+	}
+	detected, ok := table.format()
+	if !ok {
+		return "", nil, 0, fmt.Errorf("%w: columns do not match a Cronometer or MyFitnessPal export", ErrInvalidDiaryFile)
+	}
+	if format != "" && format != detected {
+		return "", nil, 0, fmt.Errorf("%w: the file looks like a %s export, not %s", ErrInvalidDiaryFile, detected, format)
+	}
+
+	var entries []models.DiaryEntry
+	skipped := 0
+	for _, row := range table.rows {
+		entry, ok := table.entry(detected, row)
+		if !ok {
+			skipped++
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return detected, entries, skipped, nil
+}
+
+// entry reads a row of an export in a format
+func (t *diaryTable) entry(format string, row []string) (models.DiaryEntry, bool) {
+	var entry models.DiaryEntry
+	var dateColumn string
+	columns := cronometerColumns
+	switch format {
+	case models.FormatCronometerServings:
+		dateColumn = "day"
+		entry.MealType = NormalizeMealType(t.field(row, "group"))
+		entry.FoodName = t.field(row, "food name")
+		entry.Amount, entry.Unit = parseAmount(t.field(row, "amount"))
+		if entry.FoodName == "" {
+			return entry, false
+		}
+	case models.FormatCronometerDailySummary:
+		dateColumn = "date"
+		entry.MealType = models.MealOther
+	case models.FormatMyFitnessPal:
+		dateColumn = "date"
+		entry.MealType = NormalizeMealType(t.field(row, "meal"))
+		columns = myFitnessPalColumns
+	}
+
+	date, err := time.Parse("2006-01-02", t.field(row, dateColumn))
+	if err != nil {
+		return entry, false
+	}
+	entry.Date = date
+
+	entry.Nutrients = make(map[string]models.NutrientAmount)
+	for i, header := range t.header {
+		if i >= len(row) || layoutColumns[header] {
+			continue
+		}
+		value := strings.TrimSpace(row[i])
+		if value == "" {
+			continue
+		}
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(value, ",", ""), 64)
+		if err != nil || amount < 0 {
+			continue
+		}
+
+		name, unit := header, ""
+		if match := headerUnit.FindStringSubmatch(header); match != nil {
+			name, unit = match[1], match[2]
+		}
+		nutrient := models.NutrientAmount{Name: name, Unit: unit, Amount: amount}
+		if column, ok := columns[name]; ok {
+			nutrient.Code = column.Code
+			if nutrient.Unit == "" {
+				nutrient.Unit = column.Unit
+			}
+		} else if format == models.FormatMyFitnessPal {
+			continue
+		}
+		entry.Nutrients[header] = nutrient
+	}
+	if len(entry.Nutrients) == 0 {
+		return entry, false
+	}
+	return entry, true
+}
+
+// parseAmount splits a Cronometer amount such as "1.50 cup" into its number and unit
+func parseAmount(value string) (float64, string) {
+	number, unit, _ := strings.Cut(strings.TrimSpace(value), " ")
+	amount, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, ""
+	}
+	return amount, strings.TrimSpace(unit)
+}
+
+// NormalizeMealType maps the meal or group names of the exports onto meal types
+func NormalizeMealType(name string) string {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "breakfast":
+		return models.MealBreakfast
+	case "lunch":
+		return models.MealLunch
+	case "dinner", "supper":
+		return models.MealDinner
+	case "snack", "snacks":
+		return models.MealSnack
+	default:
+		return models.MealOther
+	}
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/momokapoolz/caloriesapp/diary_import/models"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+)
+
+const cronometerServings = `Day,Time,Group,Food Name,Amount,Energy (kcal),Protein (g),Carbs (g),Fat (g),Fiber (g),B12 (Cobalamin) (µg),Sodium (mg),Category
+2026-03-01,08:10,Breakfast,"Oats, Rolled",40.00 g,150.4,5.3,27.1,2.6,4.0,,1.2,Cereals
+2026-03-01,08:10,Breakfast,Banana,1.00 medium,105.0,1.3,27.0,0.4,3.1,,1.2,Fruits
+2026-03-01,13:00,Lunch,Chicken Breast,150.00 g,247.5,46.5,0,5.4,0,0.5,111.0,Poultry
+2026-03-02,19:30,Dinner,Mystery Stew,1.00 serving,,,,,,,,
+,,Snacks,Apple,1.00 medium,95,0.5,25,0.3,4.4,,,Fruits
+`
+
+const cronometerDailySummary = `Date,Energy (kcal),Protein (g),Carbs (g),Fat (g),Fiber (g),Completed
+2026-03-01,1850.2,95.1,210.4,60.3,31.0,true
+2026-03-02,2010.0,101.0,230.0,70.0,28.5,false
+`
+
+const myFitnessPalExport = `Date,Meal,Calories,Fat (g),Saturated Fat,Cholesterol,Sodium (mg),Carbohydrates (g),Fiber,Sugar,Protein (g),Vitamin A,Calcium,Iron,Note
+2026-03-01,Breakfast,420,12.5,3,180,400,55,6,12,20,10,15,20,
+2026-03-01,Snacks,150,8,2,0,90,15,2,10,3,0,4,2,
+`
+
+func TestParseCronometerServings(t *testing.T) {
+	format, entries, skipped, err := ParseDiary("", strings.NewReader(cronometerServings))
+	if err != nil {
+		t.Fatalf("ParseDiary: %v", err)
+	}
+	if format != models.FormatCronometerServings {
+		t.Errorf("format = %q, want cronometer_servings", format)
+	}
+	// The stew has no nutrients and the apple no day
+	if len(entries) != 3 || skipped != 2 {
+		t.Fatalf("got %d entries and %d skipped, want 3 and 2", len(entries), skipped)
+	}
+
+	oats := entries[0]
+	if oats.FoodName != "Oats, Rolled" || oats.Amount != 40 || oats.Unit != "g" || oats.MealType != models.MealBreakfast {
+		t.Errorf("oats = %+v", oats)
+	}
+	if energy := oats.Nutrients["energy (kcal)"]; energy.Code != nutrientModels.CodeEnergy || energy.Amount != 150.4 || energy.Unit != "kcal" {
+		t.Errorf("oats energy = %+v", energy)
+	}
+	if entries[1].Unit != "medium" {
+		t.Errorf("banana unit = %q, want medium", entries[1].Unit)
+	}
+
+	chicken := entries[2]
+	if b12 := chicken.Nutrients["b12 (cobalamin) (µg)"]; b12.Code != nutrientModels.CodeVitaminB12 || b12.Unit != "µg" {
+		t.Errorf("chicken B12 = %+v", b12)
+	}
+	// Columns without a stable code keep their name to be matched against the nutrient table
+	if sodium := chicken.Nutrients["sodium (mg)"]; sodium.Code != "" || sodium.Name != "sodium" || sodium.Amount != 111 {
+		t.Errorf("chicken sodium = %+v", sodium)
+	}
+	if _, ok := chicken.Nutrients["category"]; ok {
+		t.Error("the category column is not a nutrient")
+	}
+}
+
+func TestParseCronometerDailySummaryAndMyFitnessPal(t *testing.T) {
+	format, entries, _, err := ParseDiary("", strings.NewReader(cronometerDailySummary))
+	if err != nil || format != models.FormatCronometerDailySummary || len(entries) != 2 {
+		t.Fatalf("daily summary: format %q, %d entries, error %v", format, len(entries), err)
+	}
+	if entries[0].MealType != models.MealOther || entries[0].Nutrients["energy (kcal)"].Amount != 1850.2 {
+		t.Errorf("daily summary entry = %+v", entries[0])
+	}
+
+	format, entries, _, err = ParseDiary(models.FormatMyFitnessPal, strings.NewReader(myFitnessPalExport))
+	if err != nil || format != models.FormatMyFitnessPal || len(entries) != 2 {
+		t.Fatalf("MyFitnessPal: format %q, %d entries, error %v", format, len(entries), err)
+	}
+	breakfast := entries[0]
+	if breakfast.MealType != models.MealBreakfast || breakfast.Nutrients["calories"].Unit != "kcal" {
+		t.Errorf("breakfast = %+v", breakfast)
+	}
+	// Vitamins and minerals are percents of the daily value in MyFitnessPal exports
+	if _, ok := breakfast.Nutrients["calcium"]; ok {
+		t.Error("MyFitnessPal calcium should not be imported")
+	}
+	if cholesterol := breakfast.Nutrients["cholesterol"]; cholesterol.Code != nutrientModels.CodeCholesterol || cholesterol.Unit != "mg" {
+		t.Errorf("cholesterol = %+v", cholesterol)
+	}
+	if entries[1].MealType != models.MealSnack {
+		t.Errorf("Snacks maps to %q, want snack", entries[1].MealType)
+	}
+}
+
+func TestParseDiaryRejectsOtherFiles(t *testing.T) {
+	if _, _, _, err := ParseDiary("", strings.NewReader("name,value\na,1\n")); !errors.Is(err, ErrInvalidDiaryFile) {
+		t.Errorf("unknown columns: error = %v, want ErrInvalidDiaryFile", err)
+	}
+	if _, _, _, err := ParseDiary(models.FormatMyFitnessPal, strings.NewReader(cronometerServings)); !errors.Is(err, ErrInvalidDiaryFile) {
+		t.Errorf("format mismatch: error = %v, want ErrInvalidDiaryFile", err)
+	}
+}
+
+func TestPlaceholderFood(t *testing.T) {
+	nutrients := newNutrientLookup([]nutrientModels.Nutrient{
+		{ID: 1, Code: nutrientModels.CodeEnergy, Name: "Energy", Unit: "kcal"},
+		{ID: 2, Code: nutrientModels.CodeVitaminB12, Name: "Vitamin B12", Unit: "mg"},
+		{ID: 3, Name: "Sodium", Unit: "mg"},
+	})
+	_, entries, _, err := ParseDiary("", strings.NewReader(cronometerServings))
+	if err != nil {
+		t.Fatalf("ParseDiary: %v", err)
+	}
+
+	chicken := placeholderFood(7, models.FormatCronometerServings, entries[2], nutrients)
+	if chicken.Food.Name != "Chicken Breast (150 g)" || *chicken.Food.OwnerID != 7 || chicken.Food.ServingSizeGram != 100 {
+		t.Errorf("placeholder food = %+v", chicken.Food)
+	}
+	amounts := make(map[uint]float64)
+	for _, nutrient := range chicken.Nutrients {
+		amounts[nutrient.NutrientID] = nutrient.AmountPer100g
+	}
+	// Amounts are converted into the unit of the nutrient; unknown columns are dropped
+	if len(amounts) != 3 || amounts[1] != 247.5 || math.Abs(amounts[2]-0.0005) > 1e-9 || amounts[3] != 111 {
+		t.Errorf("placeholder nutrients = %v", amounts)
+	}
+
+	again := placeholderFood(7, models.FormatCronometerServings, entries[2], nutrients)
+	if again.Food.Source != chicken.Food.Source {
+		t.Error("identical entries should share a placeholder source")
+	}
+	if other := placeholderFood(7, models.FormatCronometerServings, entries[0], nutrients); other.Food.Source == chicken.Food.Source {
+		t.Error("different entries should not share a placeholder source")
+	}
+}
+
+func TestGroupByMeal(t *testing.T) {
+	_, entries, _, err := ParseDiary("", strings.NewReader(myFitnessPalExport+"2026-02-28,Dinner,600,20,5,0,0,60,5,5,40,0,0,0,\n"))
+	if err != nil {
+		t.Fatalf("ParseDiary: %v", err)
+	}
+	keys, grouped := groupByMeal(entries)
+	want := []models.MealKey{
+		{Date: "2026-02-28", MealType: models.MealDinner},
+		{Date: "2026-03-01", MealType: models.MealBreakfast},
+		{Date: "2026-03-01", MealType: models.MealSnack},
+	}
+	if len(keys) != len(want) {
+		t.Fatalf("keys = %v, want %v", keys, want)
+	}
+	for i := range want {
+		if keys[i] != want[i] || len(grouped[keys[i]]) != 1 {
+			t.Errorf("key %d = %v, want %v", i, keys[i], want[i])
+		}
+	}
+	if kcal := entryCalories(grouped[want[1]][0]); kcal != 420 {
+		t.Errorf("entryCalories = %v, want 420", kcal)
+	}
+}
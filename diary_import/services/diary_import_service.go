@@ -0,0 +1,451 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/diary_import/models"
+	"github.com/momokapoolz/caloriesapp/diary_import/repository"
+	"github.com/momokapoolz/caloriesapp/dto"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	foodPortionServices "github.com/momokapoolz/caloriesapp/food_portion/services"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	referenceIntakeServices "github.com/momokapoolz/caloriesapp/reference_intake/services"
+)
+
+// placeholderServingGrams is the serving size of placeholder foods, so their nutrients per
+// 100 g are the exported totals of one serving
+const placeholderServingGrams = 100
+
+// mealHours is the hour of the day imported meals are logged at, exports having no time
+var mealHours = map[string]int{
+	models.MealBreakfast: 8,
+	models.MealLunch:     12,
+	models.MealSnack:     15,
+	models.MealDinner:    19,
+	models.MealOther:     12,
+}
+
+// formatSources names the application each export format comes from
+var formatSources = map[string]string{
+	models.FormatCronometerServings:     "Cronometer",
+	models.FormatCronometerDailySummary: "Cronometer",
+	models.FormatMyFitnessPal:           "MyFitnessPal",
+}
+
+// DiaryImportService imports the diary history of Cronometer and MyFitnessPal exports into
+// meal logs. Foods are matched on their name against the catalog; entries without a match,
+// or logged in a unit the food has no portion for, get a private placeholder food carrying
+// the exported nutrients.
+type DiaryImportService struct {
+	repo         *repository.DiaryImportRepository
+	nutrientRepo *nutrientRepo.NutrientRepository
+	portions     *foodPortionServices.FoodPortionService
+	rollup       *dailyNutritionServices.DailyNutritionService
+	mealLogRepo  *mealLogRepo.MealLogRepository
+}
+
+// NewDiaryImportService creates a new diary import service instance
+func NewDiaryImportService(
+	repo *repository.DiaryImportRepository,
+	nutrientRepo *nutrientRepo.NutrientRepository,
+	portions *foodPortionServices.FoodPortionService,
+	rollup *dailyNutritionServices.DailyNutritionService,
+	mealLogRepo *mealLogRepo.MealLogRepository,
+) *DiaryImportService {
+	return &DiaryImportService{
+		repo:         repo,
+		nutrientRepo: nutrientRepo,
+		portions:     portions,
+		rollup:       rollup,
+		mealLogRepo:  mealLogRepo,
+	}
+}
+
+// ImportDiary imports an export for a user. format may be empty to detect it. Meals imported
+// before from the same application are skipped, so an export can be uploaded again after more
+// days were logged. With dryRun, the import is planned and reported but nothing is stored.
+func (s *DiaryImportService) ImportDiary(userID uint, format string, r io.Reader, dryRun bool) (*dto.DiaryImportResultDTO, error) {
+	format, entries, skipped, err := ParseDiary(format, r)
+	if err != nil {
+		return nil, err
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("%w: no diary entries found", ErrInvalidDiaryFile)
+	}
+
+	result := &dto.DiaryImportResultDTO{Format: format, DryRun: dryRun, RowsSkipped: skipped, Meals: []dto.DiaryImportMealDTO{}}
+	note := "Imported from " + formatSources[format]
+
+	// Exported dates are the user's diary days, so meals are placed on them in the user's timezone
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+
+	keys, grouped := groupByMeal(entries)
+	imported, err := s.importedMeals(userID, note, keys, loc)
+	if err != nil {
+		return nil, err
+	}
+
+	nutrientRows, err := s.nutrientRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nutrients: %w", err)
+	}
+	nutrients := newNutrientLookup(nutrientRows)
+	matcher, err := s.newFoodMatcher(userID, entries)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	placeholders := make(map[string]*models.PlaceholderFood)
+	var meals []models.PlannedMeal
+	var reports []dto.DiaryImportMealDTO
+	for _, key := range keys {
+		if imported[key] {
+			result.MealsSkipped++
+			continue
+		}
+
+		date, _ := time.Parse("2006-01-02", key.Date)
+		meal := models.PlannedMeal{MealLog: mealLogModels.MealLog{
+			UserID:     userID,
+			CreatedAt:  now,
+			ConsumedAt: time.Date(date.Year(), date.Month(), date.Day(), mealHours[key.MealType], 0, 0, 0, loc),
+			MealType:   key.MealType,
+			Note:       note,
+		}}
+		report := dto.DiaryImportMealDTO{Date: key.Date, MealType: key.MealType}
+		for _, entry := range grouped[key] {
+			itemReport := dto.DiaryImportItemDTO{Calories: entryCalories(entry)}
+			if item, food, ok := matcher.match(entry); ok {
+				meal.Items = append(meal.Items, models.PlannedItem{Item: item})
+				itemReport.Name, itemReport.FoodID = food.Name, food.ID
+				itemReport.Amount, itemReport.Unit, itemReport.QuantityGrams = item.Amount, item.Unit, item.QuantityGrams
+				result.FoodsMatched++
+			} else {
+				placeholder := placeholderFood(userID, format, entry, nutrients)
+				if existing, ok := placeholders[placeholder.Food.Source]; ok {
+					placeholder = existing
+				} else {
+					placeholders[placeholder.Food.Source] = placeholder
+				}
+				meal.Items = append(meal.Items, models.PlannedItem{
+					Item: mealLogItemsModels.MealLogItem{
+						Quantity:      1,
+						Unit:          foodPortionServices.UnitServing,
+						Amount:        1,
+						QuantityGrams: placeholderServingGrams,
+					},
+					Placeholder: placeholder,
+				})
+				itemReport.Name, itemReport.Placeholder = placeholder.Food.Name, true
+				itemReport.Amount, itemReport.Unit, itemReport.QuantityGrams = 1, foodPortionServices.UnitServing, placeholderServingGrams
+			}
+			report.Items = append(report.Items, itemReport)
+		}
+		meals = append(meals, meal)
+		reports = append(reports, report)
+	}
+
+	pending, err := s.resolvePlaceholders(userID, placeholders, result)
+	if err != nil {
+		return nil, err
+	}
+
+	if !dryRun && len(meals) > 0 {
+		days := mealDays(meals)
+		if err := s.rollup.MarkDaysStale(userID, days...); err != nil {
+			helpers.LogError(err)
+		}
+		if err := s.repo.CreateMeals(pending, meals); err != nil {
+			return nil, fmt.Errorf("failed to save imported meals: %w", err)
+		}
+		for _, day := range days {
+			if err := s.rollup.RefreshDay(userID, day); err != nil {
+				helpers.LogError(err)
+			}
+		}
+	}
+
+	for i, meal := range meals {
+		reports[i].MealLogID = meal.MealLog.ID
+		for j, item := range meal.Items {
+			if item.Placeholder != nil {
+				reports[i].Items[j].FoodID = item.Placeholder.Food.ID
+			}
+		}
+		result.ItemsCreated += len(meal.Items)
+	}
+	result.MealsCreated = len(meals)
+	result.Meals = append(result.Meals, reports...)
+	return result, nil
+}
+
+// importedMeals returns the meals of the export a previous import already created
+func (s *DiaryImportService) importedMeals(userID uint, note string, keys []models.MealKey, loc *time.Location) (map[models.MealKey]bool, error) {
+	first, _ := time.Parse("2006-01-02", keys[0].Date)
+	last, _ := time.Parse("2006-01-02", keys[len(keys)-1].Date)
+	_, end := helpers.DayBounds(last, loc)
+	mealLogs, err := s.repo.GetMealLogsByNote(userID, note, helpers.DayStart(first, loc), end.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get imported meals: %w", err)
+	}
+	imported := make(map[models.MealKey]bool, len(mealLogs))
+	for _, mealLog := range mealLogs {
+		imported[models.MealKey{Date: helpers.DateIn(mealLog.ConsumedAt, loc), MealType: mealLog.MealType}] = true
+	}
+	return imported, nil
+}
+
+// resolvePlaceholders finds the placeholders stored by earlier imports and returns every
+// placeholder in a stable order, counting the ones created and reused
+func (s *DiaryImportService) resolvePlaceholders(userID uint, placeholders map[string]*models.PlaceholderFood, result *dto.DiaryImportResultDTO) ([]*models.PlaceholderFood, error) {
+	sources := make([]string, 0, len(placeholders))
+	for source := range placeholders {
+		sources = append(sources, source)
+	}
+	sort.Strings(sources)
+
+	existing, err := s.repo.GetOwnedFoodsBySources(sources, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get placeholder foods: %w", err)
+	}
+	for _, food := range existing {
+		if placeholder, ok := placeholders[food.Source]; ok {
+			placeholder.Food.ID = food.ID
+		}
+	}
+
+	pending := make([]*models.PlaceholderFood, 0, len(sources))
+	for _, source := range sources {
+		placeholder := placeholders[source]
+		if placeholder.Food.ID != 0 {
+			result.PlaceholderFoodsReused++
+		} else {
+			result.PlaceholderFoodsCreated++
+		}
+		pending = append(pending, placeholder)
+	}
+	return pending, nil
+}
+
+// foodMatcher logs exported entries against catalog foods with the same name
+type foodMatcher struct {
+	foods    map[string]foodModels.Food
+	resolver *foodPortionServices.GramResolver
+}
+
+// newFoodMatcher loads the catalog foods named like the entries and their portions
+func (s *DiaryImportService) newFoodMatcher(userID uint, entries []models.DiaryEntry) (*foodMatcher, error) {
+	seen := make(map[string]bool)
+	var names []string
+	for _, entry := range entries {
+		name := strings.ToLower(entry.FoodName)
+		if name != "" && !seen[name] {
+			seen[name] = true
+			names = append(names, entry.FoodName)
+		}
+	}
+
+	foods, err := s.repo.GetFoodsByNames(names, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to match foods: %w", err)
+	}
+	matcher := &foodMatcher{foods: make(map[string]foodModels.Food, len(foods))}
+	foodIDs := make([]uint, 0, len(foods))
+	for _, food := range foods {
+		name := strings.ToLower(food.Name)
+		if _, ok := matcher.foods[name]; !ok {
+			matcher.foods[name] = food
+			foodIDs = append(foodIDs, food.ID)
+		}
+	}
+	if matcher.resolver, err = s.portions.NewGramResolver(foodIDs, foodModels.FoodViewer{UserID: userID}); err != nil {
+		return nil, fmt.Errorf("failed to load food portions: %w", err)
+	}
+	return matcher, nil
+}
+
+// match returns the item logging an entry against its catalog food. Servings are not matched:
+// the serving of the export's food is not the serving of the catalog food.
+func (m *foodMatcher) match(entry models.DiaryEntry) (mealLogItemsModels.MealLogItem, foodModels.Food, bool) {
+	food, ok := m.foods[strings.ToLower(entry.FoodName)]
+	unit := foodPortionServices.NormalizeUnit(entry.Unit)
+	if !ok || unit == foodPortionServices.UnitServing {
+		return mealLogItemsModels.MealLogItem{}, food, false
+	}
+	grams, err := m.resolver.Grams(food.ID, entry.Amount, unit)
+	if err != nil {
+		return mealLogItemsModels.MealLogItem{}, food, false
+	}
+	return mealLogItemsModels.MealLogItem{
+		FoodID:        food.ID,
+		Unit:          unit,
+		Amount:        entry.Amount,
+		QuantityGrams: grams,
+	}, food, true
+}
+
+// placeholderFood builds the quick-entry food of an entry. Its source hashes the name and the
+// nutrients, so an identical entry reuses the food instead of creating another.
+func placeholderFood(userID uint, format string, entry models.DiaryEntry, nutrients nutrientLookup) *models.PlaceholderFood {
+	name := placeholderName(format, entry)
+	columns := make([]string, 0, len(entry.Nutrients))
+	for column := range entry.Nutrients {
+		columns = append(columns, column)
+	}
+	sort.Strings(columns)
+
+	placeholder := &models.PlaceholderFood{}
+	used := make(map[uint]bool)
+	hash := sha256.New()
+	hash.Write([]byte(name))
+	for _, column := range columns {
+		exported := entry.Nutrients[column]
+		nutrient, ok := nutrients.find(exported)
+		if !ok || used[nutrient.ID] {
+			continue
+		}
+		amount, ok := convertNutrientAmount(exported.Amount, exported.Unit, nutrient.Unit)
+		if !ok {
+			continue
+		}
+		used[nutrient.ID] = true
+		placeholder.Nutrients = append(placeholder.Nutrients, foodNutrientsModels.FoodNutrient{
+			NutrientID:    nutrient.ID,
+			AmountPer100g: amount,
+		})
+		fmt.Fprintf(hash, "|%d=%.4f", nutrient.ID, amount)
+	}
+
+	ownerID := userID
+	placeholder.Food = foodModels.Food{
+		Name:            name,
+		ServingSizeGram: placeholderServingGrams,
+		Source:          "diary_import:" + format + ":" + hex.EncodeToString(hash.Sum(nil))[:16],
+		OwnerID:         &ownerID,
+		Visibility:      foodModels.VisibilityPrivate,
+	}
+	return placeholder
+}
+
+// nutrientLookup finds the nutrient of an exported column by code, or else by name
+type nutrientLookup struct {
+	byCode map[string]nutrientModels.Nutrient
+	byName map[string]nutrientModels.Nutrient
+}
+
+// newNutrientLookup indexes the nutrient table
+func newNutrientLookup(nutrients []nutrientModels.Nutrient) nutrientLookup {
+	lookup := nutrientLookup{
+		byCode: make(map[string]nutrientModels.Nutrient, len(nutrients)),
+		byName: make(map[string]nutrientModels.Nutrient, len(nutrients)),
+	}
+	for _, nutrient := range nutrients {
+		if nutrient.Code != "" {
+			lookup.byCode[nutrient.Code] = nutrient
+		}
+		lookup.byName[strings.ToLower(nutrient.Name)] = nutrient
+	}
+	return lookup
+}
+
+// find returns the nutrient an exported amount is stored as
+func (l nutrientLookup) find(exported models.NutrientAmount) (nutrientModels.Nutrient, bool) {
+	if nutrient, ok := l.byCode[exported.Code]; ok && exported.Code != "" {
+		return nutrient, true
+	}
+	nutrient, ok := l.byName[strings.ToLower(exported.Name)]
+	return nutrient, ok
+}
+
+// placeholderName names the quick-entry food of an entry
+func placeholderName(format string, entry models.DiaryEntry) string {
+	switch {
+	case format == models.FormatCronometerDailySummary:
+		return "Cronometer daily total"
+	case format == models.FormatMyFitnessPal:
+		return "MyFitnessPal " + entry.MealType
+	case entry.Amount > 0 && entry.Unit != "":
+		return fmt.Sprintf("%s (%g %s)", entry.FoodName, entry.Amount, entry.Unit)
+	default:
+		return entry.FoodName
+	}
+}
+
+// convertNutrientAmount converts an exported amount into the unit of a nutrient: kJ and kcal
+// for energy, mass units otherwise
+func convertNutrientAmount(amount float64, from, to string) (float64, bool) {
+	from, to = strings.ToLower(strings.TrimSpace(from)), strings.ToLower(strings.TrimSpace(to))
+	switch {
+	case from == to:
+		return amount, true
+	case from == "kj" && to == "kcal":
+		return amount / 4.184, true
+	case from == "kcal" && to == "kj":
+		return amount * 4.184, true
+	default:
+		return referenceIntakeServices.ConvertAmount(amount, from, to)
+	}
+}
+
+// entryCalories returns the exported energy of an entry in kcal
+func entryCalories(entry models.DiaryEntry) float64 {
+	for _, nutrient := range entry.Nutrients {
+		if nutrient.Code == nutrientModels.CodeEnergy {
+			if kcal, ok := convertNutrientAmount(nutrient.Amount, nutrient.Unit, "kcal"); ok {
+				return kcal
+			}
+		}
+	}
+	return 0
+}
+
+// groupByMeal groups entries by day and meal, in date and meal order
+func groupByMeal(entries []models.DiaryEntry) ([]models.MealKey, map[models.MealKey][]models.DiaryEntry) {
+	grouped := make(map[models.MealKey][]models.DiaryEntry)
+	var keys []models.MealKey
+	for _, entry := range entries {
+		key := models.MealKey{Date: entry.Date.Format("2006-01-02"), MealType: entry.MealType}
+		if _, ok := grouped[key]; !ok {
+			keys = append(keys, key)
+		}
+		grouped[key] = append(grouped[key], entry)
+	}
+	sort.SliceStable(keys, func(i, j int) bool {
+		if keys[i].Date != keys[j].Date {
+			return keys[i].Date < keys[j].Date
+		}
+		return mealHours[keys[i].MealType] < mealHours[keys[j].MealType]
+	})
+	return keys, grouped
+}
+
+// mealDays returns the distinct days of the meals
+func mealDays(meals []models.PlannedMeal) []time.Time {
+	seen := make(map[string]bool)
+	var days []time.Time
+	for _, meal := range meals {
+		day := meal.MealLog.ConsumedAt.Format("2006-01-02")
+		if !seen[day] {
+			seen[day] = true
+			days = append(days, meal.MealLog.ConsumedAt)
+		}
+	}
+	return days
+}
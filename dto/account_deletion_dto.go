@@ -0,0 +1,11 @@
+package dto
+
+import "time"
+
+// AccountDeletionDTO reports whether a deletion of the user's account is pending. The account
+// and its data are purged at ScheduledAt unless the deletion is cancelled before.
+type AccountDeletionDTO struct {
+	Scheduled   bool       `json:"scheduled"`
+	RequestedAt *time.Time `json:"requested_at"`
+	ScheduledAt *time.Time `json:"scheduled_at"`
+}
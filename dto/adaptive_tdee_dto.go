@@ -0,0 +1,31 @@
+package dto
+
+// AdaptiveTDEEDTO represents the energy expenditure of a user estimated from the weight trend
+// and the logged intake. TDEE blends the adaptive estimate with the formula TDEE by Confidence,
+// so it follows the formula while data is sparse and the measured expenditure as it builds up.
+type AdaptiveTDEEDTO struct {
+	StartDate     string          `json:"start_date"`
+	EndDate       string          `json:"end_date"`
+	Source        string          `json:"source"`
+	TDEE          float64         `json:"tdee"`
+	AdaptiveTDEE  float64         `json:"adaptive_tdee"`
+	FormulaTDEE   float64         `json:"formula_tdee"`
+	Confidence    float64         `json:"confidence"`
+	TrendWeightKg float64         `json:"trend_weight_kg"`
+	LoggedDays    int             `json:"logged_days"`
+	WeighIns      int             `json:"weigh_ins"`
+	Windows       []TDEEWindowDTO `json:"windows"`
+}
+
+// TDEEWindowDTO represents the expenditure estimated over one rolling window: the average
+// intake of the logged days minus the energy stored or released by the trend weight change
+type TDEEWindowDTO struct {
+	StartDate     string  `json:"start_date"`
+	EndDate       string  `json:"end_date"`
+	LoggedDays    int     `json:"logged_days"`
+	WeighIns      int     `json:"weigh_ins"`
+	AverageIntake float64 `json:"average_intake"`
+	TrendChangeKg float64 `json:"trend_change_kg"`
+	TDEE          float64 `json:"tdee"`
+	Confidence    float64 `json:"confidence"`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// BiometricImportResultDTO summarizes an import of biometrics from a health app export
+type BiometricImportResultDTO struct {
+	Imported   int            `json:"imported"`
+	Duplicates int            `json:"duplicates"`
+	Skipped    int            `json:"skipped"`
+	ByType     map[string]int `json:"by_type"`
+}
@@ -0,0 +1,21 @@
+package dto
+
+// CopyMealLogRequestDTO represents a request to log a meal again on another date or as another meal
+type CopyMealLogRequestDTO struct {
+	// Date is the YYYY-MM-DD diary date to copy to, today in the user's timezone when empty
+	Date string `json:"date"`
+	// MealType replaces the meal type of the copy when not empty
+	MealType string `json:"meal_type"`
+}
+
+// CopyDayRequestDTO represents a request to log every meal of a diary date again on another date
+type CopyDayRequestDTO struct {
+	FromDate string `json:"from_date" binding:"required"`
+	ToDate   string `json:"to_date" binding:"required"`
+}
+
+// MoveMealLogItemsRequestDTO represents a request to move items of a meal log to another meal log
+type MoveMealLogItemsRequestDTO struct {
+	ItemIDs         []uint `json:"item_ids" binding:"required,min=1"`
+	TargetMealLogID uint   `json:"target_meal_log_id" binding:"required"`
+}
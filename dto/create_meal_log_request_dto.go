@@ -1,13 +1,19 @@
 package dto
 
+import "time"
+
 type CreateMealLogRequestDTO struct {
-	MealType string           `json:"meal_type"`
-	Note     string           `json:"note"`
-	Items    []MealLogItemDTO `json:"items"`
+	MealType string `json:"meal_type"`
+	Note     string `json:"note"`
+	// ConsumedAt is when the meal was eaten, e.g. "2024-05-01T12:30:00+02:00"; defaults to now
+	ConsumedAt *time.Time       `json:"consumed_at,omitempty"`
+	Items      []MealLogItemDTO `json:"items"`
 }
 
 type MealLogItemDTO struct {
 	FoodID        uint    `json:"food_id"`
 	Quantity      uint    `json:"quantity"`
+	Unit          string  `json:"unit"`
+	Amount        float64 `json:"amount"`
 	QuantityGrams float64 `json:"quantity_grams"`
 }
@@ -2,13 +2,18 @@ package dto
 
 import "time"
 
-// DashboardResponseDTO represents the data structure for the user dashboard
+// DashboardResponseDTO represents the data structure for the user dashboard. NetCalories is
+// the energy consumed minus the energy burned by logged exercise.
 type DashboardResponseDTO struct {
-	Date                string              `json:"date"`
-	TotalCalories       float64             `json:"total_calories"`
-	NumberOfMeals       int                 `json:"number_of_meals"`
-	MealLogs            []MealLogSummaryDTO `json:"meal_logs"`
-	TotalMacronutrients MacronutrientsDTO   `json:"total_macronutrients,omitempty"`
+	Date                string               `json:"date"`
+	TotalCalories       float64              `json:"total_calories"`
+	CaloriesBurned      float64              `json:"calories_burned"`
+	NetCalories         float64              `json:"net_calories"`
+	NumberOfMeals       int                  `json:"number_of_meals"`
+	MealLogs            []MealLogSummaryDTO  `json:"meal_logs"`
+	TotalMacronutrients MacronutrientsDTO    `json:"total_macronutrients,omitempty"`
+	Targets             *NutritionTargetsDTO `json:"targets,omitempty"`
+	Remaining           *RemainingBudgetDTO  `json:"remaining,omitempty"`
 }
 
 // MealLogSummaryDTO represents the summary of a meal log for the dashboard
@@ -16,6 +21,7 @@ type MealLogSummaryDTO struct {
 	ID            uint                 `json:"id"`
 	MealType      string               `json:"meal_type"`
 	CreatedAt     time.Time            `json:"created_at"`
+	ConsumedAt    time.Time            `json:"consumed_at"`
 	TotalCalories float64              `json:"total_calories"`
 	FoodItems     []FoodItemSummaryDTO `json:"food_items"`
 }
@@ -26,6 +32,8 @@ type FoodItemSummaryDTO struct {
 	FoodID        uint    `json:"food_id"`
 	FoodName      string  `json:"food_name"`
 	Quantity      uint    `json:"quantity"`
+	Unit          string  `json:"unit"`
+	Amount        float64 `json:"amount"`
 	QuantityGrams float64 `json:"quantity_grams"`
 	Calories      float64 `json:"calories"`
 }
@@ -36,3 +44,42 @@ type MacronutrientsDTO struct {
 	Carbohydrate float64 `json:"carbohydrate"`
 	Fat          float64 `json:"fat"`
 }
+
+// NutritionTargetsDTO represents the daily calorie and macro targets of a user. Source is
+// "computed" when they come from the profile alone and "user" when a target set applies,
+// in which case the set's values replace the computed ones. TDEESource is "adaptive" when the
+// TDEE blends in the expenditure measured from the weight trend, weighted by TDEEConfidence.
+type NutritionTargetsDTO struct {
+	Source         string             `json:"source"`
+	TargetSetID    *uint              `json:"target_set_id,omitempty"`
+	Preset         string             `json:"preset,omitempty"`
+	Formula        string             `json:"formula"`
+	BMR            float64            `json:"bmr"`
+	ActivityFactor float64            `json:"activity_factor"`
+	TDEE           float64            `json:"tdee"`
+	TDEESource     string             `json:"tdee_source"`
+	TDEEConfidence float64            `json:"tdee_confidence"`
+	Goal           string             `json:"goal"`
+	GoalAdjustment float64            `json:"goal_adjustment"`
+	WeightKg       float64            `json:"weight_kg"`
+	Calories       float64            `json:"calories"`
+	Protein        float64            `json:"protein"`
+	Carbohydrate   float64            `json:"carbohydrate"`
+	Fat            float64            `json:"fat"`
+	Nutrients      []NutrientRangeDTO `json:"nutrients"`
+}
+
+// NutrientRangeDTO represents a daily min/max range for a nutrient; either bound may be unset
+type NutrientRangeDTO struct {
+	NutrientID uint     `json:"nutrient_id"`
+	Min        *float64 `json:"min"`
+	Max        *float64 `json:"max"`
+}
+
+// RemainingBudgetDTO represents what is left of the daily targets; negative values mean the target was exceeded
+type RemainingBudgetDTO struct {
+	Calories     float64 `json:"calories"`
+	Protein      float64 `json:"protein"`
+	Carbohydrate float64 `json:"carbohydrate"`
+	Fat          float64 `json:"fat"`
+}
@@ -0,0 +1,43 @@
+package dto
+
+// DiaryExportDTO is a user's diary for a date range at item, meal and day level. Nutrient
+// amounts are keyed by the Cronometer column header of the nutrient, such as "Energy (kcal)";
+// Columns lists those headers in export order.
+type DiaryExportDTO struct {
+	UserID    uint                 `json:"user_id"`
+	StartDate string               `json:"start_date"`
+	EndDate   string               `json:"end_date"`
+	Columns   []string             `json:"columns"`
+	Items     []DiaryExportItemDTO `json:"items"`
+	Meals     []DiaryExportMealDTO `json:"meals"`
+	Days      []DiaryExportDayDTO  `json:"days"`
+}
+
+// DiaryExportItemDTO is a logged food, as in a Cronometer servings export
+type DiaryExportItemDTO struct {
+	Day       string             `json:"day"`
+	Time      string             `json:"time"`
+	Group     string             `json:"group"`
+	FoodName  string             `json:"food_name"`
+	Amount    string             `json:"amount"`
+	Category  string             `json:"category"`
+	MealLogID uint               `json:"meal_log_id"`
+	FoodID    uint               `json:"food_id"`
+	Nutrients map[string]float64 `json:"nutrients"`
+}
+
+// DiaryExportMealDTO is the total of a meal log
+type DiaryExportMealDTO struct {
+	Day       string             `json:"day"`
+	Time      string             `json:"time"`
+	Group     string             `json:"group"`
+	MealLogID uint               `json:"meal_log_id"`
+	FoodCount int                `json:"food_count"`
+	Nutrients map[string]float64 `json:"nutrients"`
+}
+
+// DiaryExportDayDTO is the total of a day, as in a Cronometer daily summary export
+type DiaryExportDayDTO struct {
+	Date      string             `json:"date"`
+	Nutrients map[string]float64 `json:"nutrients"`
+}
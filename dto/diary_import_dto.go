@@ -0,0 +1,36 @@
+package dto
+
+// DiaryImportResultDTO summarizes an import of a diary export. In a dry run nothing is
+// stored and the counts tell what the import would create.
+type DiaryImportResultDTO struct {
+	Format                  string               `json:"format"`
+	DryRun                  bool                 `json:"dry_run"`
+	MealsCreated            int                  `json:"meals_created"`
+	ItemsCreated            int                  `json:"items_created"`
+	FoodsMatched            int                  `json:"foods_matched"`
+	PlaceholderFoodsCreated int                  `json:"placeholder_foods_created"`
+	PlaceholderFoodsReused  int                  `json:"placeholder_foods_reused"`
+	MealsSkipped            int                  `json:"meals_skipped"`
+	RowsSkipped             int                  `json:"rows_skipped"`
+	Meals                   []DiaryImportMealDTO `json:"meals"`
+}
+
+// DiaryImportMealDTO is a meal created, or to be created, by a diary import
+type DiaryImportMealDTO struct {
+	Date      string               `json:"date"`
+	MealType  string               `json:"meal_type"`
+	MealLogID uint                 `json:"meal_log_id,omitempty"`
+	Items     []DiaryImportItemDTO `json:"items"`
+}
+
+// DiaryImportItemDTO is an item of an imported meal. FoodID is the matched catalog food or
+// the placeholder food; it is zero for placeholders a dry run would create.
+type DiaryImportItemDTO struct {
+	Name          string  `json:"name"`
+	FoodID        uint    `json:"food_id,omitempty"`
+	Placeholder   bool    `json:"placeholder"`
+	Amount        float64 `json:"amount"`
+	Unit          string  `json:"unit"`
+	QuantityGrams float64 `json:"quantity_grams"`
+	Calories      float64 `json:"calories"`
+}
@@ -0,0 +1,13 @@
+package dto
+
+import "time"
+
+// ExerciseLogRequestDTO represents a request to log or update an exercise. PerformedAt defaults
+// to now and Intensity (light, moderate, vigorous) to moderate.
+type ExerciseLogRequestDTO struct {
+	ActivityID      uint       `json:"activity_id" binding:"required"`
+	PerformedAt     *time.Time `json:"performed_at"`
+	DurationMinutes float64    `json:"duration_minutes" binding:"required"`
+	Intensity       string     `json:"intensity"`
+	Note            string     `json:"note"`
+}
@@ -0,0 +1,12 @@
+package dto
+
+// FoodImportResultDTO summarizes a bulk food import
+type FoodImportResultDTO struct {
+	FoodsCreated     int `json:"foods_created"`
+	FoodsUpdated     int `json:"foods_updated"`
+	FoodsSkipped     int `json:"foods_skipped"`
+	NutrientsCreated int `json:"nutrients_created"`
+	// RefreshErrors lists the diary days and recipes that could not be recomputed after their foods
+	// changed. The days stay stale and are served from the raw rows; the recipes keep their old profile.
+	RefreshErrors []string `json:"refresh_errors,omitempty"`
+}
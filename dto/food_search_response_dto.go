@@ -0,0 +1,21 @@
+package dto
+
+// FoodSearchResponseDTO represents one page of food search results
+type FoodSearchResponseDTO struct {
+	Foods      []FoodSearchItemDTO `json:"foods"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+// FoodSearchItemDTO represents a food matched by a search
+type FoodSearchItemDTO struct {
+	ID              uint    `json:"id"`
+	Name            string  `json:"name"`
+	Brand           string  `json:"brand"`
+	ServingSizeGram float64 `json:"serving_size_gram"`
+	Source          string  `json:"source"`
+	ImageURL        string  `json:"image_url"`
+	Barcode         string  `json:"barcode"`
+	OwnerID         *uint   `json:"owner_id"`
+	Visibility      string  `json:"visibility"`
+	Score           float64 `json:"score"`
+}
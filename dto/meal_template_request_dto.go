@@ -0,0 +1,36 @@
+package dto
+
+import "time"
+
+// MealTemplateRequestDTO represents the data needed to create or replace a meal template.
+// Visibility is private (the default) or shared.
+type MealTemplateRequestDTO struct {
+	Name       string                       `json:"name" binding:"required"`
+	MealType   string                       `json:"meal_type"`
+	Visibility string                       `json:"visibility"`
+	Items      []MealTemplateItemRequestDTO `json:"items" binding:"required,min=1,dive"`
+}
+
+// MealTemplateItemRequestDTO represents an amount of a food in a meal template, in a mass unit,
+// "serving" (the default) or one of the food's portions
+type MealTemplateItemRequestDTO struct {
+	FoodID uint    `json:"food_id" binding:"required"`
+	Unit   string  `json:"unit"`
+	Amount float64 `json:"amount" binding:"required,gt=0"`
+}
+
+// MealTemplateFromMealLogRequestDTO represents the data needed to save a logged meal as a template
+type MealTemplateFromMealLogRequestDTO struct {
+	MealLogID  uint   `json:"meal_log_id" binding:"required"`
+	Name       string `json:"name" binding:"required"`
+	Visibility string `json:"visibility"`
+}
+
+// LogMealTemplateRequestDTO represents the options of logging a meal template. Every amount is
+// multiplied by Scale, which defaults to 1; MealType defaults to the template's and ConsumedAt to now.
+type LogMealTemplateRequestDTO struct {
+	Scale      float64    `json:"scale" binding:"gte=0"`
+	MealType   string     `json:"meal_type"`
+	Note       string     `json:"note"`
+	ConsumedAt *time.Time `json:"consumed_at,omitempty"`
+}
@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// MealTemplateResponseDTO represents a meal template with its items and nutrition, both
+// multiplied by Scale
+type MealTemplateResponseDTO struct {
+	ID            uint                          `json:"id"`
+	OwnerID       uint                          `json:"owner_id"`
+	Name          string                        `json:"name"`
+	MealType      string                        `json:"meal_type"`
+	Visibility    string                        `json:"visibility"`
+	Scale         float64                       `json:"scale"`
+	TotalCalories float64                       `json:"total_calories"`
+	Items         []MealTemplateItemResponseDTO `json:"items"`
+	Nutrition     []MicronutrientDTO            `json:"nutrition"`
+	CreatedAt     time.Time                     `json:"created_at"`
+	UpdatedAt     time.Time                     `json:"updated_at"`
+}
+
+// MealTemplateItemResponseDTO represents an item of a meal template
+type MealTemplateItemResponseDTO struct {
+	ID            uint    `json:"id"`
+	FoodID        uint    `json:"food_id"`
+	FoodName      string  `json:"food_name"`
+	Unit          string  `json:"unit"`
+	Amount        float64 `json:"amount"`
+	QuantityGrams float64 `json:"quantity_grams"`
+}
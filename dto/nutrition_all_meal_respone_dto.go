@@ -7,6 +7,9 @@ type NutritionSummaryDTO struct {
 	MacroNutrientBreakDown []MacronutrientBreakdownDTO
 	MicroNutrientBreakDown []MicronutrientDTO
 	MealBreakdown          []MealNutritionDTO
+	DailyBreakdown         []DailyNutritionDTO       `json:"daily_breakdown"`
+	ReferenceIntakes       []NutrientAdequacyDTO     `json:"reference_intakes"`
+	Targets                []NutrientTargetStatusDTO `json:"targets"`
 }
 
 type MacronutrientBreakdownDTO struct {
@@ -40,6 +43,15 @@ type MealNutritionDTO struct {
 	FoodCount    int     `json:"food_count"`
 }
 
+// DailyNutritionDTO represents the headline nutrients consumed on a single date
+type DailyNutritionDTO struct {
+	Date         string  `json:"date"`
+	Calories     float64 `json:"calories"`
+	Protein      float64 `json:"protein"`
+	Carbohydrate float64 `json:"carbohydrate"`
+	Fat          float64 `json:"fat"`
+}
+
 // MealNutritionDetailDTO represents detailed nutrition information for a single meal
 type MealNutritionDetailDTO struct {
 	MealLogID              uint                        `json:"meal_log_id"`
@@ -50,4 +62,40 @@ type MealNutritionDetailDTO struct {
 	FoodCount              int                         `json:"food_count"`
 	MacroNutrientBreakDown []MacronutrientBreakdownDTO `json:"MacroNutrientBreakDown"`
 	MicroNutrientBreakDown []MicronutrientDTO          `json:"MicroNutrientBreakDown"`
+	ReferenceIntakes       []NutrientAdequacyDTO       `json:"reference_intakes"`
+}
+
+// NutrientAdequacyDTO compares the intake of a nutrient with the user's daily reference intakes.
+// The target is the RDA, or the AI where no RDA is established; 0 means the value is not established.
+type NutrientAdequacyDTO struct {
+	NutrientID         uint    `json:"nutrient_id"`
+	NutrientCode       string  `json:"nutrient_code"`
+	NutrientName       string  `json:"nutrient_name"`
+	Unit               string  `json:"unit"`
+	Amount             float64 `json:"amount"`
+	AverageDailyAmount float64 `json:"average_daily_amount"`
+	EAR                float64 `json:"ear"`
+	Target             float64 `json:"target"`
+	TargetType         string  `json:"target_type"`
+	UpperLimit         float64 `json:"upper_limit"`
+	PercentOfTarget    float64 `json:"percent_of_target"`
+	OverUpperLimit     bool    `json:"over_upper_limit"`
+	DaysOverUpperLimit int     `json:"days_over_upper_limit"`
+}
+
+// NutrientTargetStatusDTO scores the logged days of a summary against the user's target range
+// for a nutrient. Each day is scored against the targets in effect on that day; Min and Max are
+// the range of the latest scored day.
+type NutrientTargetStatusDTO struct {
+	NutrientID         uint     `json:"nutrient_id"`
+	NutrientCode       string   `json:"nutrient_code"`
+	NutrientName       string   `json:"nutrient_name"`
+	Unit               string   `json:"unit"`
+	Min                *float64 `json:"min"`
+	Max                *float64 `json:"max"`
+	AverageDailyAmount float64  `json:"average_daily_amount"`
+	DaysScored         int      `json:"days_scored"`
+	DaysBelow          int      `json:"days_below"`
+	DaysWithin         int      `json:"days_within"`
+	DaysAbove          int      `json:"days_above"`
 }
@@ -0,0 +1,18 @@
+package dto
+
+// RecipeRequestDTO represents the data needed to create or replace a recipe.
+// CookedWeightGram is the weight of the finished dish; when it is zero the raw
+// weight of the ingredients is used.
+type RecipeRequestDTO struct {
+	Name             string                       `json:"name" binding:"required"`
+	Servings         float64                      `json:"servings" binding:"required,gt=0"`
+	CookedWeightGram float64                      `json:"cooked_weight_gram" binding:"gte=0"`
+	Visibility       string                       `json:"visibility"`
+	Ingredients      []RecipeIngredientRequestDTO `json:"ingredients" binding:"required,min=1,dive"`
+}
+
+// RecipeIngredientRequestDTO represents a raw weight of a food used in a recipe
+type RecipeIngredientRequestDTO struct {
+	FoodID uint    `json:"food_id" binding:"required"`
+	Grams  float64 `json:"grams" binding:"required,gt=0"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+// RecipeResponseDTO represents a recipe with its ingredients and derived nutrition.
+// FoodID is the food to log in meal log items; one serving weighs ServingSizeGram.
+type RecipeResponseDTO struct {
+	ID                  uint                          `json:"id"`
+	FoodID              uint                          `json:"food_id"`
+	Name                string                        `json:"name"`
+	OwnerID             uint                          `json:"owner_id"`
+	Visibility          string                        `json:"visibility"`
+	Servings            float64                       `json:"servings"`
+	CookedWeightGram    float64                       `json:"cooked_weight_gram"`
+	ServingSizeGram     float64                       `json:"serving_size_gram"`
+	CaloriesPerServing  float64                       `json:"calories_per_serving"`
+	Ingredients         []RecipeIngredientResponseDTO `json:"ingredients"`
+	NutritionPerServing []MicronutrientDTO            `json:"nutrition_per_serving"`
+}
+
+// RecipeIngredientResponseDTO represents an ingredient line of a recipe
+type RecipeIngredientResponseDTO struct {
+	ID       uint    `json:"id"`
+	FoodID   uint    `json:"food_id"`
+	FoodName string  `json:"food_name"`
+	Grams    float64 `json:"grams"`
+}
@@ -11,4 +11,6 @@ type RegisterDTO struct {
 	Height        float64 `json:"height" binding:"required,gt=0"`
 	Goal          string  `json:"goal" binding:"required"`
 	ActivityLevel string  `json:"activity_level" binding:"required"`
+	// Timezone is an IANA name such as "Europe/Paris", UTC when omitted
+	Timezone string `json:"timezone"`
 }
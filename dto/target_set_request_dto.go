@@ -0,0 +1,24 @@
+package dto
+
+// TargetSetRequestDTO represents the targets a user sets from a date on. Calories and macros
+// left unset keep their computed values. Macros are given either as a preset (keto,
+// high_protein, zone), as custom percents of energy adding up to 100, or in grams.
+type TargetSetRequestDTO struct {
+	EffectiveFrom       string                     `json:"effective_from"`
+	Calories            *float64                   `json:"calories"`
+	Preset              string                     `json:"preset"`
+	ProteinPercent      *float64                   `json:"protein_percent"`
+	CarbohydratePercent *float64                   `json:"carbohydrate_percent"`
+	FatPercent          *float64                   `json:"fat_percent"`
+	ProteinGrams        *float64                   `json:"protein_grams"`
+	CarbohydrateGrams   *float64                   `json:"carbohydrate_grams"`
+	FatGrams            *float64                   `json:"fat_grams"`
+	Nutrients           []NutrientTargetRequestDTO `json:"nutrients"`
+}
+
+// NutrientTargetRequestDTO represents a daily min/max range for a nutrient, in the nutrient's unit
+type NutrientTargetRequestDTO struct {
+	NutrientID uint     `json:"nutrient_id" binding:"required"`
+	Min        *float64 `json:"min"`
+	Max        *float64 `json:"max"`
+}
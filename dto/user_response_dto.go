@@ -14,5 +14,7 @@ type UserResponseDTO struct {
 	Goal          string    `json:"goal"`
 	ActivityLevel string    `json:"activity_level"`
 	Role          string    `json:"role"`
+	Timezone      string    `json:"timezone"`
+	LifeStage     string    `json:"life_stage"`
 	CreatedAt     time.Time `json:"created_at"`
 } 
\ No newline at end of file
@@ -10,4 +10,8 @@ type UserUpdateProfileRequestDTO struct {
 	Height        *float64 `json:"height,omitempty"`
 	Goal          *string  `json:"goal,omitempty"`
 	ActivityLevel *string  `json:"activity_level,omitempty"`
+	// Timezone is an IANA name such as "Europe/Paris"; diary days are counted in it
+	Timezone *string `json:"timezone,omitempty"`
+	// LifeStage is "pregnancy", "lactation" or empty; it selects the reference intakes that apply
+	LifeStage *string `json:"life_stage,omitempty"`
 } 
\ No newline at end of file
@@ -0,0 +1,189 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+	"github.com/momokapoolz/caloriesapp/exercise_log/services"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	"gorm.io/gorm"
+)
+
+// ActivityController handles HTTP requests for the exercise activity catalog
+type ActivityController struct {
+	service *services.ActivityService
+}
+
+// NewActivityController creates a new activity controller instance
+func NewActivityController(service *services.ActivityService) *ActivityController {
+	return &ActivityController{service: service}
+}
+
+// writeActivityError maps activity service errors to responses; fallback is used for unexpected errors
+func writeActivityError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalidActivity):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrActivityInUse):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Activity not found"})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// GetActivities godoc
+// @Summary      List exercise activities
+// @Description  List the activity catalog with the MET of each activity at light, moderate and vigorous intensity
+// @Tags         exercise_activity
+// @Produce      json
+// @Param        q  query     string  false  "Filter by name or category"
+// @Success      200  {array}   models.Activity    "Activities retrieved successfully"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /exercise-activities/ [get]
+func (c *ActivityController) GetActivities(ctx *gin.Context) {
+	activities, err := c.service.GetActivities(ctx.Query("q"))
+	if err != nil {
+		writeActivityError(ctx, err, "Failed to retrieve activities")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, activities)
+}
+
+// GetActivity godoc
+// @Summary      Get exercise activity by ID
+// @Description  Retrieve an activity of the catalog by its ID
+// @Tags         exercise_activity
+// @Produce      json
+// @Param        id  path      int  true  "Activity ID"
+// @Success      200  {object}  models.Activity    "Activity retrieved successfully"
+// @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      404  {object}  map[string]string  "Activity not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /exercise-activities/{id} [get]
+func (c *ActivityController) GetActivity(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	activity, err := c.service.GetActivityByID(uint(id))
+	if err != nil {
+		writeActivityError(ctx, err, "Failed to retrieve activity")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, activity)
+}
+
+// CreateActivity godoc
+// @Summary      Create exercise activity (admin)
+// @Description  Add an activity to the catalog. The code is a stable lower-case identifier; METs must be positive and must not decrease from light to vigorous.
+// @Tags         exercise_activity
+// @Accept       json
+// @Produce      json
+// @Param        activity  body      models.Activity  true  "Activity data"
+// @Success      201  {object}  models.Activity    "Activity created successfully"
+// @Failure      400  {object}  map[string]string  "Invalid activity"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — admin only"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/exercise-activities/ [post]
+func (c *ActivityController) CreateActivity(ctx *gin.Context) {
+	var activity models.Activity
+	if err := ctx.ShouldBindJSON(&activity); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	activity.ID = 0
+	if err := c.service.CreateActivity(&activity); err != nil {
+		writeActivityError(ctx, err, "Failed to create activity")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, activity)
+}
+
+// UpdateActivity godoc
+// @Summary      Update exercise activity (admin)
+// @Description  Update an activity of the catalog. Logged exercises keep the MET they were logged with.
+// @Tags         exercise_activity
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int              true  "Activity ID"
+// @Param        activity  body      models.Activity  true  "Updated activity data"
+// @Success      200  {object}  models.Activity    "Activity updated successfully"
+// @Failure      400  {object}  map[string]string  "Invalid ID or activity"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — admin only"
+// @Failure      404  {object}  map[string]string  "Activity not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/exercise-activities/{id} [put]
+func (c *ActivityController) UpdateActivity(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var activity models.Activity
+	if err := ctx.ShouldBindJSON(&activity); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	activity.ID = uint(id)
+	if err := c.service.UpdateActivity(&activity); err != nil {
+		writeActivityError(ctx, err, "Failed to update activity")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, activity)
+}
+
+// DeleteActivity godoc
+// @Summary      Delete exercise activity (admin)
+// @Description  Delete an activity no exercise log refers to
+// @Tags         exercise_activity
+// @Produce      json
+// @Param        id  path      int  true  "Activity ID"
+// @Success      200  {object}  map[string]string  "Activity deleted successfully"
+// @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — admin only"
+// @Failure      404  {object}  map[string]string  "Activity not found"
+// @Failure      409  {object}  map[string]string  "Activity is used by exercise logs"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/exercise-activities/{id} [delete]
+func (c *ActivityController) DeleteActivity(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := c.service.DeleteActivity(uint(id)); err != nil {
+		writeActivityError(ctx, err, "Failed to delete activity")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Activity deleted successfully"})
+}
@@ -0,0 +1,247 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/exercise_log/services"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	"gorm.io/gorm"
+)
+
+// ExerciseLogController handles HTTP requests for exercise logs
+type ExerciseLogController struct {
+	service *services.ExerciseLogService
+}
+
+// NewExerciseLogController creates a new exercise log controller instance
+func NewExerciseLogController(service *services.ExerciseLogService) *ExerciseLogController {
+	return &ExerciseLogController{service: service}
+}
+
+// writeExerciseError maps exercise log service errors to responses; fallback is used for unexpected errors
+func writeExerciseError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalidExercise), errors.Is(err, services.ErrActivityNotFound):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrWeightRequired):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Record your weight to log exercise"})
+	case errors.Is(err, services.ErrExerciseNotAccessible):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to modify this exercise log"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Exercise log not found"})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CreateExerciseLog godoc
+// @Summary      Log exercise
+// @Description  Log an exercise. The energy burned is the activity's MET at the intensity (light, moderate or vigorous; default moderate) times the latest weight biometric, or the profile weight, times the duration in hours.
+// @Tags         exercise_log
+// @Accept       json
+// @Produce      json
+// @Param        exercise  body      dto.ExerciseLogRequestDTO  true  "Exercise data"
+// @Success      201  {object}  models.ExerciseLog  "Exercise logged successfully"
+// @Failure      400  {object}  map[string]string   "Invalid exercise or unknown activity"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      422  {object}  map[string]string   "No weight recorded"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /exercise-logs/ [post]
+func (c *ExerciseLogController) CreateExerciseLog(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.ExerciseLogRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := c.service.CreateExerciseLog(userClaims.UserID, req)
+	if err != nil {
+		writeExerciseError(ctx, err, "Failed to log exercise")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// GetExerciseLog godoc
+// @Summary      Get exercise log by ID
+// @Description  Retrieve an exercise log of the authenticated user with its activity
+// @Tags         exercise_log
+// @Produce      json
+// @Param        id  path      int  true  "Exercise log ID"
+// @Success      200  {object}  models.ExerciseLog  "Exercise log retrieved successfully"
+// @Failure      400  {object}  map[string]string   "Invalid ID format"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      403  {object}  map[string]string   "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string   "Exercise log not found"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /exercise-logs/{id} [get]
+func (c *ExerciseLogController) GetExerciseLog(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	entry, err := c.service.GetExerciseLog(uint(id), userClaims.UserID)
+	if err != nil {
+		writeExerciseError(ctx, err, "Failed to retrieve exercise log")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+// GetExerciseLogsByDateRange godoc
+// @Summary      Get exercise logs by date range
+// @Description  Retrieve the exercise logs of the authenticated user performed within a date range
+// @Tags         exercise_log
+// @Produce      json
+// @Param        startDate  query  string  true  "Start date in YYYY-MM-DD format"
+// @Param        endDate    query  string  true  "End date in YYYY-MM-DD format"
+// @Success      200  {array}   models.ExerciseLog  "Exercise logs in the date range"
+// @Failure      400  {object}  map[string]string   "Invalid date format or missing parameters"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /exercise-logs/date-range [get]
+func (c *ExerciseLogController) GetExerciseLogsByDateRange(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	startDateStr := ctx.Query("startDate")
+	endDateStr := ctx.Query("endDate")
+
+	if startDateStr == "" || endDateStr == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Start date and end date are required"})
+		return
+	}
+
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid start date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid end date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, endDate.Location())
+
+	entries, err := c.service.GetExerciseLogsByDateRange(userClaims.UserID, startDate, endDate)
+	if err != nil {
+		writeExerciseError(ctx, err, "Failed to retrieve exercise logs for the specified date range")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// UpdateExerciseLog godoc
+// @Summary      Update exercise log
+// @Description  Replace an exercise log of the authenticated user. The burn is recomputed at the weight recorded when the exercise was first logged.
+// @Tags         exercise_log
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                        true  "Exercise log ID"
+// @Param        exercise  body      dto.ExerciseLogRequestDTO  true  "Updated exercise data"
+// @Success      200  {object}  models.ExerciseLog  "Exercise log updated successfully"
+// @Failure      400  {object}  map[string]string   "Invalid ID, exercise or unknown activity"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      403  {object}  map[string]string   "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string   "Exercise log not found"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /exercise-logs/{id} [put]
+func (c *ExerciseLogController) UpdateExerciseLog(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req dto.ExerciseLogRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	entry, err := c.service.UpdateExerciseLog(uint(id), userClaims.UserID, req)
+	if err != nil {
+		writeExerciseError(ctx, err, "Failed to update exercise log")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+// DeleteExerciseLog godoc
+// @Summary      Delete exercise log
+// @Description  Delete an exercise log of the authenticated user
+// @Tags         exercise_log
+// @Produce      json
+// @Param        id  path      int  true  "Exercise log ID"
+// @Success      200  {object}  map[string]string  "Exercise log deleted successfully"
+// @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string  "Exercise log not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /exercise-logs/{id} [delete]
+func (c *ExerciseLogController) DeleteExerciseLog(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := c.service.DeleteExerciseLog(uint(id), userClaims.UserID); err != nil {
+		writeExerciseError(ctx, err, "Failed to delete exercise log")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Exercise log deleted successfully"})
+}
@@ -0,0 +1,96 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/exercise_log/services"
+)
+
+// maxWorkoutFileSize bounds the size of an uploaded workout file
+const maxWorkoutFileSize = 20 << 20
+
+// WorkoutImportController handles HTTP requests for workout file imports
+type WorkoutImportController struct {
+	service *services.WorkoutImportService
+}
+
+// NewWorkoutImportController creates a new workout import controller instance
+func NewWorkoutImportController(service *services.WorkoutImportService) *WorkoutImportController {
+	return &WorkoutImportController{service: service}
+}
+
+// ImportWorkouts godoc
+// @Summary      Import workout file
+// @Description  Log the workouts of a GPX, TCX or FIT file recorded by a watch, bike computer or app. Duration, distance, elevation gain and heart rate are read from the file; the calories reported by the device are kept, otherwise they are estimated from the MET at the latest weight. The activity is matched from the recorded sport unless activity_id is given, and the intensity follows the average speed. A file is imported once per user.
+// @Tags         exercise_log
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file         formData  file  true   "Workout file (.gpx, .tcx or .fit, up to 20 MB)"
+// @Param        activity_id  formData  int   false  "Activity to log the workouts as"
+// @Success      201  {object}  models.WorkoutImport  "Workouts imported successfully"
+// @Failure      400  {object}  map[string]string     "Missing, oversized or unreadable file, or unknown activity"
+// @Failure      401  {object}  map[string]string     "Unauthorized"
+// @Failure      409  {object}  map[string]string     "File already imported"
+// @Failure      422  {object}  map[string]string     "Sport without activity or no weight recorded"
+// @Failure      500  {object}  map[string]string     "Internal server error"
+// @Security     BearerAuth
+// @Router       /exercise-logs/import [post]
+func (c *WorkoutImportController) ImportWorkouts(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A file upload is required"})
+		return
+	}
+	if fileHeader.Size > maxWorkoutFileSize {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Workout files are limited to 20 MB"})
+		return
+	}
+
+	var activityID *uint
+	if value := ctx.PostForm("activity_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid activity_id"})
+			return
+		}
+		parsed := uint(id)
+		activityID = &parsed
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+	defer file.Close()
+	data, err := io.ReadAll(io.LimitReader(file, maxWorkoutFileSize))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read upload"})
+		return
+	}
+
+	workoutImport, err := c.service.ImportWorkouts(userClaims.UserID, fileHeader.Filename, data, activityID)
+	switch {
+	case err == nil:
+		ctx.JSON(http.StatusCreated, workoutImport)
+	case errors.Is(err, services.ErrInvalidWorkoutFile):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrDuplicateImport):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrUnmappedSport):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		writeExerciseError(ctx, err, "Failed to import workouts")
+	}
+}
@@ -0,0 +1,52 @@
+package models
+
+import "strings"
+
+// Exercise intensities
+const (
+	IntensityLight    = "light"
+	IntensityModerate = "moderate"
+	IntensityVigorous = "vigorous"
+)
+
+// Activity represents the exercise_activity table in the database: an entry of the activity
+// catalog with its metabolic equivalents (MET) at each intensity, after the Compendium of
+// Physical Activities. Code is a stable identifier importers map activities onto.
+type Activity struct {
+	ID          uint    `gorm:"primaryKey;column:id" json:"id"`
+	Code        string  `gorm:"column:code;type:varchar(64);not null;uniqueIndex:idx_exercise_activity_code" json:"code"`
+	Name        string  `gorm:"column:name;not null" json:"name"`
+	Category    string  `gorm:"column:category;type:varchar(64);not null;default:''" json:"category"`
+	METLight    float64 `gorm:"column:met_light;not null" json:"met_light"`
+	METModerate float64 `gorm:"column:met_moderate;not null" json:"met_moderate"`
+	METVigorous float64 `gorm:"column:met_vigorous;not null" json:"met_vigorous"`
+}
+
+// TableName specifies the table name for the Activity model
+func (Activity) TableName() string {
+	return "exercise_activity"
+}
+
+// MET returns the metabolic equivalent of the activity at an intensity. It reports false
+// for unknown intensities.
+func (a Activity) MET(intensity string) (float64, bool) {
+	switch NormalizeIntensity(intensity) {
+	case IntensityLight:
+		return a.METLight, true
+	case IntensityModerate:
+		return a.METModerate, true
+	case IntensityVigorous:
+		return a.METVigorous, true
+	default:
+		return 0, false
+	}
+}
+
+// NormalizeIntensity lower-cases an intensity; an empty intensity means moderate
+func NormalizeIntensity(intensity string) string {
+	intensity = strings.ToLower(strings.TrimSpace(intensity))
+	if intensity == "" {
+		return IntensityModerate
+	}
+	return intensity
+}
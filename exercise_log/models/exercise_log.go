@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+// Exercise log sources
+const (
+	SourceManual = "manual"
+	SourceGPX    = "gpx"
+	SourceTCX    = "tcx"
+	SourceFIT    = "fit"
+)
+
+// ExerciseLog represents the exercise_log table in the database. The MET and body weight
+// used for the burn are kept with the entry, so later catalog edits or weigh-ins do not
+// rewrite past days. Entries imported from a workout file carry the recorded distance,
+// elevation and heart rate, and the calories reported by the device replace the estimate.
+type ExerciseLog struct {
+	ID              uint      `gorm:"primaryKey;column:id" json:"id"`
+	UserID          uint      `gorm:"column:user_id;not null;index:idx_exercise_log_user_performed,priority:1" json:"user_id"`
+	ActivityID      uint      `gorm:"column:activity_id;not null" json:"activity_id"`
+	PerformedAt     time.Time `gorm:"column:performed_at;not null;index:idx_exercise_log_user_performed,priority:2" json:"performed_at"`
+	DurationMinutes float64   `gorm:"column:duration_minutes;not null" json:"duration_minutes"`
+	Intensity       string    `gorm:"column:intensity;type:varchar(16);not null" json:"intensity"`
+	MET             float64   `gorm:"column:met;not null" json:"met"`
+	WeightKg        float64   `gorm:"column:weight_kg;not null" json:"weight_kg"`
+	CaloriesBurned  float64   `gorm:"column:calories_burned;not null" json:"calories_burned"`
+	Note            string    `gorm:"column:note;not null;default:''" json:"note"`
+	Source          string    `gorm:"column:source;type:varchar(16);not null;default:'manual'" json:"source"`
+	ImportID        *uint     `gorm:"column:import_id;index:idx_exercise_log_import" json:"import_id,omitempty"`
+	DistanceMeters  float64   `gorm:"column:distance_meters;not null;default:0" json:"distance_meters"`
+	ElevationGain   float64   `gorm:"column:elevation_gain_meters;not null;default:0" json:"elevation_gain_meters"`
+	AvgHeartRate    float64   `gorm:"column:avg_heart_rate;not null;default:0" json:"avg_heart_rate"`
+	MaxHeartRate    float64   `gorm:"column:max_heart_rate;not null;default:0" json:"max_heart_rate"`
+	DeviceCalories  float64   `gorm:"column:device_calories;not null;default:0" json:"device_calories"`
+	CreatedAt       time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	Activity        *Activity `gorm:"foreignKey:ActivityID" json:"activity,omitempty"`
+}
+
+// TableName specifies the table name for the ExerciseLog model
+func (ExerciseLog) TableName() string {
+	return "exercise_log"
+}
@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// WorkoutImport represents the exercise_import table in the database: a workout file a user
+// uploaded. FileHash is the SHA-256 of the file, so uploading it again is detected.
+type WorkoutImport struct {
+	ID        uint          `gorm:"primaryKey;column:id" json:"id"`
+	UserID    uint          `gorm:"column:user_id;not null;uniqueIndex:idx_exercise_import_user_hash,priority:1" json:"user_id"`
+	FileHash  string        `gorm:"column:file_hash;type:varchar(64);not null;uniqueIndex:idx_exercise_import_user_hash,priority:2" json:"file_hash"`
+	FileName  string        `gorm:"column:file_name;not null;default:''" json:"file_name"`
+	Format    string        `gorm:"column:format;type:varchar(8);not null" json:"format"`
+	CreatedAt time.Time     `gorm:"column:created_at;not null" json:"created_at"`
+	Entries   []ExerciseLog `gorm:"foreignKey:ImportID" json:"exercise_logs"`
+}
+
+// TableName specifies the table name for the WorkoutImport model
+func (WorkoutImport) TableName() string {
+	return "exercise_import"
+}
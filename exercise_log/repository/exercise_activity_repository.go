@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+	"gorm.io/gorm"
+)
+
+// ActivityRepository handles all database operations for the Activity model
+type ActivityRepository struct {
+	db *gorm.DB
+}
+
+// NewActivityRepository creates a new activity repository instance
+func NewActivityRepository(db *gorm.DB) *ActivityRepository {
+	return &ActivityRepository{db: db}
+}
+
+// Create adds a new activity to the catalog
+func (r *ActivityRepository) Create(activity *models.Activity) error {
+	return database.TranslateError(r.db.Create(activity).Error)
+}
+
+// GetByID retrieves an activity by its ID
+func (r *ActivityRepository) GetByID(id uint) (*models.Activity, error) {
+	var activity models.Activity
+	err := r.db.Where("id = ?", id).First(&activity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// GetByCode retrieves an activity by its code
+func (r *ActivityRepository) GetByCode(code string) (*models.Activity, error) {
+	var activity models.Activity
+	err := r.db.Where("code = ?", code).First(&activity).Error
+	if err != nil {
+		return nil, err
+	}
+	return &activity, nil
+}
+
+// GetAll retrieves the catalog, optionally filtered by a case-insensitive name or category match
+func (r *ActivityRepository) GetAll(query string) ([]models.Activity, error) {
+	var activities []models.Activity
+	db := r.db
+	if query != "" {
+		pattern := "%" + query + "%"
+		db = db.Where("name ILIKE ? OR category ILIKE ?", pattern, pattern)
+	}
+	err := db.Order("category, name").Find(&activities).Error
+	return activities, err
+}
+
+// Update updates an activity
+func (r *ActivityRepository) Update(activity *models.Activity) error {
+	return database.TranslateError(r.db.Save(activity).Error)
+}
+
+// Delete removes an activity
+func (r *ActivityRepository) Delete(id uint) error {
+	return database.TranslateError(r.db.Delete(&models.Activity{}, id).Error)
+}
+
+// IsUsed reports whether any exercise log refers to an activity
+func (r *ActivityRepository) IsUsed(id uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.ExerciseLog{}).Where("activity_id = ?", id).Count(&count).Error
+	return count > 0, err
+}
@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	"gorm.io/gorm"
+)
+
+// ExerciseLogRepository handles all database operations for the ExerciseLog model
+type ExerciseLogRepository struct {
+	db *gorm.DB
+}
+
+// NewExerciseLogRepository creates a new exercise log repository instance
+func NewExerciseLogRepository(db *gorm.DB) *ExerciseLogRepository {
+	return &ExerciseLogRepository{db: db}
+}
+
+// Create adds a new exercise log record to the database
+func (r *ExerciseLogRepository) Create(entry *models.ExerciseLog) error {
+	return database.TranslateError(r.db.Omit("Activity").Create(entry).Error)
+}
+
+// GetByID retrieves an exercise log with its activity
+func (r *ExerciseLogRepository) GetByID(id uint) (*models.ExerciseLog, error) {
+	var entry models.ExerciseLog
+	err := r.db.Preload("Activity").Where("id = ?", id).First(&entry).Error
+	if err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// GetByUserIDAndDateRange retrieves the exercise logs of a user performed within a range, oldest first
+func (r *ExerciseLogRepository) GetByUserIDAndDateRange(userID uint, startDate, endDate time.Time) ([]models.ExerciseLog, error) {
+	var entries []models.ExerciseLog
+	err := r.db.Preload("Activity").
+		Where("user_id = ? AND performed_at >= ? AND performed_at <= ?", userID, startDate, endDate).
+		Order("performed_at").
+		Find(&entries).Error
+	return entries, err
+}
+
+// GetByUserIDAndDate retrieves the exercise logs of a user performed on the calendar date of date in loc
+func (r *ExerciseLogRepository) GetByUserIDAndDate(userID uint, date time.Time, loc *time.Location) ([]models.ExerciseLog, error) {
+	startDate, endDate := helpers.DayBounds(date, loc)
+	return r.GetByUserIDAndDateRange(userID, startDate, endDate.Add(-time.Nanosecond))
+}
+
+// Update updates an exercise log record
+func (r *ExerciseLogRepository) Update(entry *models.ExerciseLog) error {
+	return database.TranslateError(r.db.Omit("Activity").Save(entry).Error)
+}
+
+// Delete removes an exercise log record
+func (r *ExerciseLogRepository) Delete(id uint) error {
+	return database.TranslateError(r.db.Delete(&models.ExerciseLog{}, id).Error)
+}
@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+	"gorm.io/gorm"
+)
+
+// WorkoutImportRepository handles all database operations for the WorkoutImport model
+type WorkoutImportRepository struct {
+	db *gorm.DB
+}
+
+// NewWorkoutImportRepository creates a new workout import repository instance
+func NewWorkoutImportRepository(db *gorm.DB) *WorkoutImportRepository {
+	return &WorkoutImportRepository{db: db}
+}
+
+// Create adds an import with its exercise logs in a single transaction
+func (r *WorkoutImportRepository) Create(workoutImport *models.WorkoutImport) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Entries").Create(workoutImport).Error; err != nil {
+			return err
+		}
+		for i := range workoutImport.Entries {
+			workoutImport.Entries[i].ImportID = &workoutImport.ID
+			if err := tx.Omit("Activity").Create(&workoutImport.Entries[i]).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	}))
+}
+
+// GetByUserIDAndHash retrieves the import of a file by a user
+func (r *WorkoutImportRepository) GetByUserIDAndHash(userID uint, fileHash string) (*models.WorkoutImport, error) {
+	var workoutImport models.WorkoutImport
+	err := r.db.Where("user_id = ? AND file_hash = ?", userID, fileHash).First(&workoutImport).Error
+	if err != nil {
+		return nil, err
+	}
+	return &workoutImport, nil
+}
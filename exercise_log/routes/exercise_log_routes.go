@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/exercise_log/controllers"
+	"github.com/momokapoolz/caloriesapp/exercise_log/repository"
+	"github.com/momokapoolz/caloriesapp/exercise_log/services"
+	userRepository "github.com/momokapoolz/caloriesapp/user/repository"
+	userBiometricsRepository "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+	"gorm.io/gorm"
+)
+
+// SetupExerciseLogRoutes initializes exercise log and activity catalog routes
+func SetupExerciseLogRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	activityRepo := repository.NewActivityRepository(db)
+
+	exerciseLogService := services.NewExerciseLogService(
+		repository.NewExerciseLogRepository(db),
+		activityRepo,
+		userRepository.NewUserRepository(),
+		userBiometricsRepository.NewUserBiometricRepository(db),
+	)
+
+	activityController := controllers.NewActivityController(services.NewActivityService(activityRepo))
+	exerciseLogController := controllers.NewExerciseLogController(exerciseLogService)
+	workoutImportController := controllers.NewWorkoutImportController(services.NewWorkoutImportService(
+		repository.NewWorkoutImportRepository(db),
+		activityRepo,
+		exerciseLogService,
+	))
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	activityRoutes := router.Group("/exercise-activities", authMiddleware.RequireAuth())
+	{
+		activityRoutes.GET("/", activityController.GetActivities)
+		activityRoutes.GET("/:id", activityController.GetActivity)
+	}
+
+	adminRoutes := router.Group("/admin/exercise-activities", authMiddleware.RequireAuth(), authMiddleware.RequireRole("admin"))
+	{
+		adminRoutes.POST("/", activityController.CreateActivity)
+		adminRoutes.PUT("/:id", activityController.UpdateActivity)
+		adminRoutes.DELETE("/:id", activityController.DeleteActivity)
+	}
+
+	exerciseLogRoutes := router.Group("/exercise-logs", authMiddleware.RequireAuth())
+	{
+		exerciseLogRoutes.POST("/", exerciseLogController.CreateExerciseLog)
+		exerciseLogRoutes.POST("/import", workoutImportController.ImportWorkouts)
+		exerciseLogRoutes.GET("/date-range", exerciseLogController.GetExerciseLogsByDateRange)
+		exerciseLogRoutes.GET("/:id", exerciseLogController.GetExerciseLog)
+		exerciseLogRoutes.PUT("/:id", exerciseLogController.UpdateExerciseLog)
+		exerciseLogRoutes.DELETE("/:id", exerciseLogController.DeleteExerciseLog)
+	}
+}
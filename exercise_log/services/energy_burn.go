@@ -0,0 +1,7 @@
+package services
+
+// BurnedCalories returns the energy spent on an activity in kcal: its MET times the body
+// weight in kg times the duration in hours, one MET being 1 kcal per kg per hour
+func BurnedCalories(met, weightKg, durationMinutes float64) float64 {
+	return met * weightKg * durationMinutes / 60
+}
@@ -0,0 +1,86 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+	"github.com/momokapoolz/caloriesapp/exercise_log/repository"
+)
+
+// Error definitions
+var (
+	ErrInvalidActivity = errors.New("invalid activity")
+	ErrActivityInUse   = errors.New("activity is used by exercise logs")
+)
+
+// ActivityService handles business logic for the activity catalog
+type ActivityService struct {
+	repo *repository.ActivityRepository
+}
+
+// NewActivityService creates a new activity service instance
+func NewActivityService(repo *repository.ActivityRepository) *ActivityService {
+	return &ActivityService{repo: repo}
+}
+
+// GetActivities lists the catalog, optionally filtered by name or category
+func (s *ActivityService) GetActivities(query string) ([]models.Activity, error) {
+	return s.repo.GetAll(strings.TrimSpace(query))
+}
+
+// GetActivityByID retrieves an activity by its ID
+func (s *ActivityService) GetActivityByID(id uint) (*models.Activity, error) {
+	return s.repo.GetByID(id)
+}
+
+// CreateActivity adds an activity to the catalog
+func (s *ActivityService) CreateActivity(activity *models.Activity) error {
+	if err := validateActivity(activity); err != nil {
+		return err
+	}
+	return s.repo.Create(activity)
+}
+
+// UpdateActivity updates an activity. Logged exercises keep the MET they were logged with.
+func (s *ActivityService) UpdateActivity(activity *models.Activity) error {
+	if _, err := s.repo.GetByID(activity.ID); err != nil {
+		return err
+	}
+	if err := validateActivity(activity); err != nil {
+		return err
+	}
+	return s.repo.Update(activity)
+}
+
+// DeleteActivity removes an activity no exercise log refers to
+func (s *ActivityService) DeleteActivity(id uint) error {
+	if _, err := s.repo.GetByID(id); err != nil {
+		return err
+	}
+	used, err := s.repo.IsUsed(id)
+	if err != nil {
+		return fmt.Errorf("failed to check activity usage: %w", err)
+	}
+	if used {
+		return ErrActivityInUse
+	}
+	return s.repo.Delete(id)
+}
+
+// validateActivity normalizes the code of an activity and checks its METs rise with the intensity
+func validateActivity(activity *models.Activity) error {
+	activity.Code = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(activity.Code)), " ", "_")
+	activity.Name = strings.TrimSpace(activity.Name)
+	if activity.Code == "" || activity.Name == "" {
+		return fmt.Errorf("%w: code and name are required", ErrInvalidActivity)
+	}
+	if activity.METLight <= 0 || activity.METModerate <= 0 || activity.METVigorous <= 0 {
+		return fmt.Errorf("%w: METs must be positive", ErrInvalidActivity)
+	}
+	if activity.METLight > activity.METModerate || activity.METModerate > activity.METVigorous {
+		return fmt.Errorf("%w: METs cannot decrease with the intensity", ErrInvalidActivity)
+	}
+	return nil
+}
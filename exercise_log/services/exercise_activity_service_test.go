@@ -0,0 +1,59 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+)
+
+func TestBurnedCalories(t *testing.T) {
+	// Running at 9.8 MET for 30 minutes at 70 kg burns 343 kcal
+	if got := BurnedCalories(9.8, 70, 30); math.Abs(got-343) > 0.001 {
+		t.Errorf("BurnedCalories = %v, want 343", got)
+	}
+	if got := BurnedCalories(3.5, 80, 0); got != 0 {
+		t.Errorf("BurnedCalories without duration = %v, want 0", got)
+	}
+}
+
+func TestActivityMET(t *testing.T) {
+	activity := models.Activity{METLight: 2.8, METModerate: 3.5, METVigorous: 5}
+	cases := map[string]float64{
+		"light":    2.8,
+		"Moderate": 3.5,
+		"":         3.5,
+		"VIGOROUS": 5,
+	}
+	for intensity, want := range cases {
+		if got, ok := activity.MET(intensity); !ok || got != want {
+			t.Errorf("MET(%q) = %v, %v; want %v", intensity, got, ok, want)
+		}
+	}
+	if _, ok := activity.MET("extreme"); ok {
+		t.Error("unknown intensity should not have a MET")
+	}
+}
+
+func TestValidateActivity(t *testing.T) {
+	activity := models.Activity{Code: " Trail Running ", Name: "Trail running", METLight: 7, METModerate: 9, METVigorous: 12}
+	if err := validateActivity(&activity); err != nil {
+		t.Fatalf("valid activity: %v", err)
+	}
+	if activity.Code != "trail_running" {
+		t.Errorf("code = %q, want trail_running", activity.Code)
+	}
+
+	invalid := map[string]models.Activity{
+		"missing name":       {Code: "x", METLight: 1, METModerate: 2, METVigorous: 3},
+		"zero MET":           {Code: "x", Name: "X", METModerate: 2, METVigorous: 3},
+		"decreasing METs":    {Code: "x", Name: "X", METLight: 4, METModerate: 3, METVigorous: 5},
+		"vigorous below all": {Code: "x", Name: "X", METLight: 1, METModerate: 3, METVigorous: 2},
+	}
+	for name, activity := range invalid {
+		if err := validateActivity(&activity); !errors.Is(err, ErrInvalidActivity) {
+			t.Errorf("%s: err = %v, want ErrInvalidActivity", name, err)
+		}
+	}
+}
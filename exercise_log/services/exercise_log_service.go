@@ -0,0 +1,172 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+	"github.com/momokapoolz/caloriesapp/exercise_log/repository"
+	userRepository "github.com/momokapoolz/caloriesapp/user/repository"
+	userBiometricsModels "github.com/momokapoolz/caloriesapp/user_biometrics/models"
+	userBiometricsRepo "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+	"gorm.io/gorm"
+)
+
+// Error definitions
+var (
+	ErrInvalidExercise       = errors.New("invalid exercise")
+	ErrActivityNotFound      = errors.New("activity not found")
+	ErrWeightRequired        = errors.New("a weight is required to compute the energy burned")
+	ErrExerciseNotAccessible = errors.New("exercise log belongs to another user")
+)
+
+// ExerciseLogService handles business logic for exercise logs. The energy burned is computed
+// from the activity's MET at the logged intensity and the user's latest weight.
+type ExerciseLogService struct {
+	repo          *repository.ExerciseLogRepository
+	activityRepo  *repository.ActivityRepository
+	userRepo      *userRepository.UserRepository
+	biometricRepo *userBiometricsRepo.UserBiometricRepository
+}
+
+// NewExerciseLogService creates a new exercise log service instance
+func NewExerciseLogService(
+	repo *repository.ExerciseLogRepository,
+	activityRepo *repository.ActivityRepository,
+	userRepo *userRepository.UserRepository,
+	biometricRepo *userBiometricsRepo.UserBiometricRepository,
+) *ExerciseLogService {
+	return &ExerciseLogService{
+		repo:          repo,
+		activityRepo:  activityRepo,
+		userRepo:      userRepo,
+		biometricRepo: biometricRepo,
+	}
+}
+
+// CreateExerciseLog logs an exercise for a user, burning energy at the user's latest weight
+func (s *ExerciseLogService) CreateExerciseLog(userID uint, req dto.ExerciseLogRequestDTO) (*models.ExerciseLog, error) {
+	weightKg, err := s.LatestWeightKg(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &models.ExerciseLog{
+		UserID:    userID,
+		WeightKg:  weightKg,
+		Source:    models.SourceManual,
+		CreatedAt: time.Now(),
+	}
+	if err := s.applyRequest(entry, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Create(entry); err != nil {
+		return nil, fmt.Errorf("failed to create exercise log: %w", err)
+	}
+	return entry, nil
+}
+
+// GetExerciseLog retrieves an exercise log of a user
+func (s *ExerciseLogService) GetExerciseLog(id, userID uint) (*models.ExerciseLog, error) {
+	entry, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if entry.UserID != userID {
+		return nil, ErrExerciseNotAccessible
+	}
+	return entry, nil
+}
+
+// GetExerciseLogsByDateRange retrieves the exercise logs of a user within a range
+func (s *ExerciseLogService) GetExerciseLogsByDateRange(userID uint, startDate, endDate time.Time) ([]models.ExerciseLog, error) {
+	return s.repo.GetByUserIDAndDateRange(userID, startDate, endDate)
+}
+
+// UpdateExerciseLog replaces an exercise log of a user. The burn is recomputed at the weight
+// recorded when the exercise was first logged.
+func (s *ExerciseLogService) UpdateExerciseLog(id, userID uint, req dto.ExerciseLogRequestDTO) (*models.ExerciseLog, error) {
+	entry, err := s.GetExerciseLog(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.PerformedAt == nil {
+		req.PerformedAt = &entry.PerformedAt
+	}
+	if err := s.applyRequest(entry, req); err != nil {
+		return nil, err
+	}
+	if err := s.repo.Update(entry); err != nil {
+		return nil, fmt.Errorf("failed to update exercise log: %w", err)
+	}
+	return entry, nil
+}
+
+// DeleteExerciseLog removes an exercise log of a user
+func (s *ExerciseLogService) DeleteExerciseLog(id, userID uint) error {
+	if _, err := s.GetExerciseLog(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// LatestWeightKg returns the latest weight biometric of a user in kg, falling back to the profile weight
+func (s *ExerciseLogService) LatestWeightKg(userID uint) (float64, error) {
+	latest, err := s.biometricRepo.GetLatestByUserIDAndType(userID, userBiometricsModels.GetBiometricTypes().Weight)
+	switch {
+	case err == nil:
+		if kg, ok := latest.Kilograms(); ok && kg > 0 {
+			return kg, nil
+		}
+	case !errors.Is(err, gorm.ErrRecordNotFound):
+		return 0, fmt.Errorf("failed to get weight: %w", err)
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Weight <= 0 {
+		return 0, ErrWeightRequired
+	}
+	return user.Weight, nil
+}
+
+// applyRequest validates a request and sets it on an entry, computing the burn at the entry's weight.
+// Imported entries keep the calories their device reported.
+func (s *ExerciseLogService) applyRequest(entry *models.ExerciseLog, req dto.ExerciseLogRequestDTO) error {
+	if req.DurationMinutes <= 0 || req.DurationMinutes > 24*60 {
+		return fmt.Errorf("%w: duration must be between 0 and 1440 minutes", ErrInvalidExercise)
+	}
+
+	activity, err := s.activityRepo.GetByID(req.ActivityID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return ErrActivityNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get activity: %w", err)
+	}
+	intensity := models.NormalizeIntensity(req.Intensity)
+	met, ok := activity.MET(intensity)
+	if !ok {
+		return fmt.Errorf("%w: intensity must be light, moderate or vigorous", ErrInvalidExercise)
+	}
+
+	entry.ActivityID = activity.ID
+	entry.Activity = activity
+	entry.PerformedAt = time.Now()
+	if req.PerformedAt != nil {
+		entry.PerformedAt = *req.PerformedAt
+	}
+	entry.DurationMinutes = req.DurationMinutes
+	entry.Intensity = intensity
+	entry.MET = met
+	entry.CaloriesBurned = BurnedCalories(met, entry.WeightKg, req.DurationMinutes)
+	if entry.DeviceCalories > 0 {
+		entry.CaloriesBurned = entry.DeviceCalories
+	}
+	entry.Note = req.Note
+	return nil
+}
@@ -0,0 +1,324 @@
+package services
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// FIT global message numbers and the fields read from them, per the FIT SDK profile
+const (
+	fitMessageSession = 18
+	fitMessageRecord  = 20
+
+	fitFieldTimestamp = 253
+
+	fitSessionStartTime    = 2
+	fitSessionSport        = 5
+	fitSessionElapsedTime  = 7
+	fitSessionTimerTime    = 8
+	fitSessionDistance     = 9
+	fitSessionCalories     = 11
+	fitSessionAvgHeartRate = 16
+	fitSessionMaxHeartRate = 17
+	fitSessionTotalAscent  = 22
+
+	fitRecordLatitude         = 0
+	fitRecordLongitude        = 1
+	fitRecordAltitude         = 2
+	fitRecordHeartRate        = 3
+	fitRecordDistance         = 5
+	fitRecordEnhancedAltitude = 78
+)
+
+// fitEpoch is the origin of FIT timestamps, 1989-12-31T00:00:00Z
+var fitEpoch = time.Date(1989, 12, 31, 0, 0, 0, 0, time.UTC)
+
+// fitSports names the FIT sport enum values workouts are mapped from
+var fitSports = map[uint64]string{
+	0:  "generic",
+	1:  "running",
+	2:  "cycling",
+	4:  "fitness_equipment",
+	5:  "swimming",
+	6:  "basketball",
+	7:  "soccer",
+	8:  "tennis",
+	10: "training",
+	11: "walking",
+	12: "cross_country_skiing",
+	15: "rowing",
+	17: "hiking",
+}
+
+// fitField is a field of a FIT definition message
+type fitField struct {
+	Number   byte
+	Size     int
+	BaseType byte
+}
+
+// fitDefinition describes the layout of the data messages of a local message type
+type fitDefinition struct {
+	Global         uint16
+	BigEndian      bool
+	Fields         []fitField
+	DeveloperBytes int
+}
+
+// fitMessage holds the valid integer fields of a data message by field number
+type fitMessage map[byte]uint64
+
+// parseFIT reads a Garmin FIT activity file: one workout per session message, or a single
+// workout from the record messages when the file has no sessions
+func parseFIT(data []byte) ([]Workout, error) {
+	messages, err := decodeFIT(data, fitMessageSession, fitMessageRecord)
+	if err != nil {
+		return nil, err
+	}
+
+	var points []trackPoint
+	for _, record := range messages[fitMessageRecord] {
+		points = append(points, fitTrackPoint(record))
+	}
+
+	sessions := messages[fitMessageSession]
+	if len(sessions) == 0 {
+		return []Workout{summarizeTrack("", points)}, nil
+	}
+
+	workouts := make([]Workout, 0, len(sessions))
+	for _, session := range sessions {
+		start := fitTime(session[fitSessionStartTime])
+		seconds := float64(session[fitSessionTimerTime]) / 1000
+		if seconds == 0 {
+			seconds = float64(session[fitSessionElapsedTime]) / 1000
+		}
+
+		// Measures the session does not summarize come from its records
+		var sessionPoints []trackPoint
+		end := start.Add(time.Duration(float64(session[fitSessionElapsedTime]) / 1000 * float64(time.Second)))
+		for _, point := range points {
+			if !point.Time.Before(start) && !point.Time.After(end) {
+				sessionPoints = append(sessionPoints, point)
+			}
+		}
+		workout := summarizeTrack(fitSports[session[fitSessionSport]], sessionPoints)
+		workout.StartTime = start
+		workout.DurationSeconds = seconds
+		if distance, ok := session[fitSessionDistance]; ok {
+			workout.DistanceMeters = float64(distance) / 100
+		}
+		if ascent, ok := session[fitSessionTotalAscent]; ok {
+			workout.ElevationGainMeters = float64(ascent)
+		}
+		if heartRate, ok := session[fitSessionAvgHeartRate]; ok {
+			workout.AvgHeartRate = float64(heartRate)
+		}
+		if heartRate, ok := session[fitSessionMaxHeartRate]; ok {
+			workout.MaxHeartRate = float64(heartRate)
+		}
+		workout.DeviceCalories = float64(session[fitSessionCalories])
+		workouts = append(workouts, workout)
+	}
+	return workouts, nil
+}
+
+// fitTrackPoint converts a record message into a track point
+func fitTrackPoint(record fitMessage) trackPoint {
+	point := trackPoint{Time: fitTime(record[fitFieldTimestamp])}
+	if heartRate, ok := record[fitRecordHeartRate]; ok {
+		point.HeartRate = float64(heartRate)
+	}
+	if distance, ok := record[fitRecordDistance]; ok {
+		point.Distance, point.HasDistance = float64(distance)/100, true
+	}
+	if altitude, ok := record[fitRecordEnhancedAltitude]; ok {
+		point.Altitude, point.HasAltitude = float64(altitude)/5-500, true
+	} else if altitude, ok := record[fitRecordAltitude]; ok {
+		point.Altitude, point.HasAltitude = float64(altitude)/5-500, true
+	}
+	latitude, hasLatitude := record[fitRecordLatitude]
+	longitude, hasLongitude := record[fitRecordLongitude]
+	if hasLatitude && hasLongitude {
+		// Positions are signed semicircles
+		toDegrees := 180 / float64(uint64(1)<<31)
+		point.Latitude = float64(int32(uint32(latitude))) * toDegrees
+		point.Longitude = float64(int32(uint32(longitude))) * toDegrees
+		point.HasPosition = true
+	}
+	return point
+}
+
+// fitTime converts a FIT timestamp; zero stays the zero time
+func fitTime(seconds uint64) time.Time {
+	if seconds == 0 {
+		return time.Time{}
+	}
+	return fitEpoch.Add(time.Duration(seconds) * time.Second)
+}
+
+// decodeFIT walks the records of a FIT file and returns the data messages of the wanted global
+// message numbers. Compressed timestamp headers and developer fields are supported; the CRCs
+// are not checked.
+func decodeFIT(data []byte, wanted ...uint16) (map[uint16][]fitMessage, error) {
+	if len(data) < 12 || string(data[8:12]) != ".FIT" {
+		return nil, errors.New("missing FIT signature")
+	}
+	headerSize := int(data[0])
+	dataSize := int(binary.LittleEndian.Uint32(data[4:8]))
+	if headerSize < 12 || headerSize+dataSize > len(data) {
+		return nil, errors.New("truncated FIT file")
+	}
+
+	keep := make(map[uint16]bool, len(wanted))
+	for _, global := range wanted {
+		keep[global] = true
+	}
+	messages := make(map[uint16][]fitMessage)
+	definitions := make(map[byte]*fitDefinition)
+	var lastTimestamp uint64
+
+	body := data[headerSize : headerSize+dataSize]
+	for pos := 0; pos < len(body); {
+		header := body[pos]
+		pos++
+
+		var local byte
+		var compressedTimestamp *uint64
+		switch {
+		case header&0x80 != 0:
+			// Compressed timestamp header: a 5-bit offset from the last full timestamp
+			local = (header >> 5) & 0x03
+			offset := uint64(header & 0x1F)
+			timestamp := lastTimestamp&^0x1F + offset
+			if offset < lastTimestamp&0x1F {
+				timestamp += 0x20
+			}
+			lastTimestamp = timestamp
+			compressedTimestamp = &timestamp
+		case header&0x40 != 0:
+			definition, size, err := readFITDefinition(body[pos:], header&0x20 != 0)
+			if err != nil {
+				return nil, err
+			}
+			definitions[header&0x0F] = definition
+			pos += size
+			continue
+		default:
+			local = header & 0x0F
+		}
+
+		definition, ok := definitions[local]
+		if !ok {
+			return nil, fmt.Errorf("data message for undefined local type %d", local)
+		}
+		message := fitMessage{}
+		for _, field := range definition.Fields {
+			if pos+field.Size > len(body) {
+				return nil, errors.New("truncated FIT record")
+			}
+			if value, ok := fitValue(body[pos:pos+field.Size], field.BaseType, definition.BigEndian); ok {
+				message[field.Number] = value
+			}
+			pos += field.Size
+		}
+		pos += definition.DeveloperBytes
+		if pos > len(body) {
+			return nil, errors.New("truncated FIT record")
+		}
+
+		if timestamp, ok := message[fitFieldTimestamp]; ok {
+			lastTimestamp = timestamp
+		} else if compressedTimestamp != nil {
+			message[fitFieldTimestamp] = *compressedTimestamp
+		}
+		if keep[definition.Global] {
+			messages[definition.Global] = append(messages[definition.Global], message)
+		}
+	}
+	return messages, nil
+}
+
+// readFITDefinition reads a definition message and returns it with its size in bytes
+func readFITDefinition(data []byte, hasDeveloperFields bool) (*fitDefinition, int, error) {
+	if len(data) < 5 {
+		return nil, 0, errors.New("truncated FIT definition")
+	}
+	definition := &fitDefinition{BigEndian: data[1] == 1}
+	if definition.BigEndian {
+		definition.Global = binary.BigEndian.Uint16(data[2:4])
+	} else {
+		definition.Global = binary.LittleEndian.Uint16(data[2:4])
+	}
+
+	fieldCount := int(data[4])
+	pos := 5
+	if len(data) < pos+fieldCount*3 {
+		return nil, 0, errors.New("truncated FIT definition")
+	}
+	for i := 0; i < fieldCount; i++ {
+		definition.Fields = append(definition.Fields, fitField{
+			Number:   data[pos],
+			Size:     int(data[pos+1]),
+			BaseType: data[pos+2],
+		})
+		pos += 3
+	}
+
+	if hasDeveloperFields {
+		if len(data) < pos+1 {
+			return nil, 0, errors.New("truncated FIT definition")
+		}
+		developerCount := int(data[pos])
+		pos++
+		if len(data) < pos+developerCount*3 {
+			return nil, 0, errors.New("truncated FIT definition")
+		}
+		for i := 0; i < developerCount; i++ {
+			definition.DeveloperBytes += int(data[pos+1])
+			pos += 3
+		}
+	}
+	return definition, pos, nil
+}
+
+// fitValue decodes an unsigned or signed integer field of 1, 2 or 4 bytes. It reports false
+// for other types, arrays and the invalid value of the base type.
+func fitValue(raw []byte, baseType byte, bigEndian bool) (uint64, bool) {
+	var order binary.ByteOrder = binary.LittleEndian
+	if bigEndian {
+		order = binary.BigEndian
+	}
+
+	var value, invalid uint64
+	switch baseType & 0x1F {
+	case 0x00, 0x02: // enum, uint8
+		value, invalid = uint64(raw[0]), 0xFF
+	case 0x01: // sint8
+		value, invalid = uint64(raw[0]), 0x7F
+	case 0x0A: // uint8z
+		value, invalid = uint64(raw[0]), 0
+	case 0x03: // sint16
+		value, invalid = uint64(order.Uint16(raw)), 0x7FFF
+	case 0x04: // uint16
+		value, invalid = uint64(order.Uint16(raw)), 0xFFFF
+	case 0x0B: // uint16z
+		value, invalid = uint64(order.Uint16(raw)), 0
+	case 0x05: // sint32
+		value, invalid = uint64(order.Uint32(raw)), 0x7FFFFFFF
+	case 0x06: // uint32
+		value, invalid = uint64(order.Uint32(raw)), 0xFFFFFFFF
+	case 0x0C: // uint32z
+		value, invalid = uint64(order.Uint32(raw)), 0
+	default:
+		return 0, false
+	}
+
+	sizes := map[byte]int{0x00: 1, 0x01: 1, 0x02: 1, 0x0A: 1, 0x03: 2, 0x04: 2, 0x0B: 2, 0x05: 4, 0x06: 4, 0x0C: 4}
+	if len(raw) != sizes[baseType&0x1F] || value == invalid {
+		return 0, false
+	}
+	return value, true
+}
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"encoding/xml"
+	"strings"
+	"time"
+)
+
+// gpxFile mirrors the parts of a GPX 1.1 document a workout is read from. Heart rate comes
+// from the Garmin TrackPointExtension, matched by local name whatever its namespace prefix.
+type gpxFile struct {
+	Tracks []struct {
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Latitude  float64  `xml:"lat,attr"`
+				Longitude float64  `xml:"lon,attr"`
+				Elevation *float64 `xml:"ele"`
+				Time      string   `xml:"time"`
+				HeartRate float64  `xml:"extensions>TrackPointExtension>hr"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+// parseGPX reads one workout per track of a GPX file. GPX has no device calories.
+func parseGPX(data []byte) ([]Workout, error) {
+	var file gpxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	workouts := make([]Workout, 0, len(file.Tracks))
+	for _, track := range file.Tracks {
+		var points []trackPoint
+		for _, segment := range track.Segments {
+			for _, point := range segment.Points {
+				sample := trackPoint{
+					Latitude:    point.Latitude,
+					Longitude:   point.Longitude,
+					HasPosition: true,
+					HeartRate:   point.HeartRate,
+				}
+				if point.Elevation != nil {
+					sample.Altitude, sample.HasAltitude = *point.Elevation, true
+				}
+				if parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(point.Time)); err == nil {
+					sample.Time = parsed
+				}
+				points = append(points, sample)
+			}
+		}
+		workouts = append(workouts, summarizeTrack(track.Type, points))
+	}
+	return workouts, nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"encoding/xml"
+	"math"
+	"strings"
+	"time"
+)
+
+// tcxFile mirrors the parts of a Garmin Training Center document a workout is read from
+type tcxFile struct {
+	Activities []struct {
+		Sport string `xml:"Sport,attr"`
+		Laps  []struct {
+			StartTime        string  `xml:"StartTime,attr"`
+			TotalTimeSeconds float64 `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64 `xml:"DistanceMeters"`
+			Calories         float64 `xml:"Calories"`
+			MaxHeartRate     float64 `xml:"MaximumHeartRateBpm>Value"`
+			Trackpoints      []struct {
+				Time           string   `xml:"Time"`
+				Latitude       *float64 `xml:"Position>LatitudeDegrees"`
+				Longitude      *float64 `xml:"Position>LongitudeDegrees"`
+				AltitudeMeters *float64 `xml:"AltitudeMeters"`
+				DistanceMeters *float64 `xml:"DistanceMeters"`
+				HeartRate      float64  `xml:"HeartRateBpm>Value"`
+			} `xml:"Track>Trackpoint"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+// parseTCX reads one workout per activity of a TCX file. The lap totals recorded by the
+// device take precedence over what the track points add up to.
+func parseTCX(data []byte) ([]Workout, error) {
+	var file tcxFile
+	if err := xml.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	workouts := make([]Workout, 0, len(file.Activities))
+	for _, activity := range file.Activities {
+		var points []trackPoint
+		var lapSeconds, lapDistance, lapCalories, lapMaxHeartRate float64
+		for _, lap := range activity.Laps {
+			lapSeconds += lap.TotalTimeSeconds
+			lapDistance += lap.DistanceMeters
+			lapCalories += lap.Calories
+			lapMaxHeartRate = math.Max(lapMaxHeartRate, lap.MaxHeartRate)
+
+			for _, point := range lap.Trackpoints {
+				sample := trackPoint{HeartRate: point.HeartRate}
+				if point.Latitude != nil && point.Longitude != nil {
+					sample.Latitude, sample.Longitude, sample.HasPosition = *point.Latitude, *point.Longitude, true
+				}
+				if point.AltitudeMeters != nil {
+					sample.Altitude, sample.HasAltitude = *point.AltitudeMeters, true
+				}
+				if point.DistanceMeters != nil {
+					sample.Distance, sample.HasDistance = *point.DistanceMeters, true
+				}
+				if parsed, err := time.Parse(time.RFC3339, strings.TrimSpace(point.Time)); err == nil {
+					sample.Time = parsed
+				}
+				points = append(points, sample)
+			}
+		}
+
+		workout := summarizeTrack(activity.Sport, points)
+		if workout.StartTime.IsZero() && len(activity.Laps) > 0 {
+			workout.StartTime, _ = time.Parse(time.RFC3339, strings.TrimSpace(activity.Laps[0].StartTime))
+		}
+		if lapSeconds > 0 {
+			workout.DurationSeconds = lapSeconds
+		}
+		if lapDistance > 0 {
+			workout.DistanceMeters = lapDistance
+		}
+		workout.MaxHeartRate = math.Max(workout.MaxHeartRate, lapMaxHeartRate)
+		workout.DeviceCalories = lapCalories
+		workouts = append(workouts, workout)
+	}
+	return workouts, nil
+}
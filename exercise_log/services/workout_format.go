@@ -0,0 +1,166 @@
+package services
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+)
+
+// ErrInvalidWorkoutFile is returned for files that are not readable GPX, TCX or FIT workouts
+var ErrInvalidWorkoutFile = errors.New("invalid workout file")
+
+// Workout is one activity read from a workout file, independent of the file format.
+// Zero values mean the file did not record the measure.
+type Workout struct {
+	Sport               string
+	StartTime           time.Time
+	DurationSeconds     float64
+	DistanceMeters      float64
+	ElevationGainMeters float64
+	AvgHeartRate        float64
+	MaxHeartRate        float64
+	DeviceCalories      float64
+}
+
+// trackPoint is a sample of a recorded track. Fields a file does not record are left unset.
+type trackPoint struct {
+	Time        time.Time
+	Latitude    float64
+	Longitude   float64
+	HasPosition bool
+	Altitude    float64
+	HasAltitude bool
+	Distance    float64
+	HasDistance bool
+	HeartRate   float64
+}
+
+// DetectWorkoutFormat tells the format of a workout file from its content, falling back to
+// its extension. It reports false for anything else.
+func DetectWorkoutFormat(fileName string, data []byte) (string, bool) {
+	if len(data) >= 12 && string(data[8:12]) == ".FIT" {
+		return models.SourceFIT, true
+	}
+	head := data
+	if len(head) > 4096 {
+		head = head[:4096]
+	}
+	switch {
+	case bytes.Contains(head, []byte("<gpx")):
+		return models.SourceGPX, true
+	case bytes.Contains(head, []byte("<TrainingCenterDatabase")):
+		return models.SourceTCX, true
+	}
+
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".gpx":
+		return models.SourceGPX, true
+	case ".tcx":
+		return models.SourceTCX, true
+	case ".fit":
+		return models.SourceFIT, true
+	default:
+		return "", false
+	}
+}
+
+// ParseWorkouts reads the workouts of a file in one of the supported formats
+func ParseWorkouts(format string, data []byte) ([]Workout, error) {
+	var workouts []Workout
+	var err error
+	switch format {
+	case models.SourceGPX:
+		workouts, err = parseGPX(data)
+	case models.SourceTCX:
+		workouts, err = parseTCX(data)
+	case models.SourceFIT:
+		workouts, err = parseFIT(data)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidWorkoutFile, format)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidWorkoutFile, err)
+	}
+
+	recorded := workouts[:0]
+	for _, workout := range workouts {
+		if workout.DurationSeconds > 0 {
+			recorded = append(recorded, workout)
+		}
+	}
+	if len(recorded) == 0 {
+		return nil, fmt.Errorf("%w: no timed activity found", ErrInvalidWorkoutFile)
+	}
+	return recorded, nil
+}
+
+// summarizeTrack derives a workout from its track points: the duration between the first and
+// last timed point, the distance recorded by the device or else measured between positions,
+// the climbed elevation and the average and maximum heart rate
+func summarizeTrack(sport string, points []trackPoint) Workout {
+	workout := Workout{Sport: sport}
+
+	var first, last time.Time
+	var heartRateSum float64
+	var heartRateCount int
+	var previous *trackPoint
+	var measured, recorded float64
+	for i := range points {
+		point := &points[i]
+		if !point.Time.IsZero() {
+			if first.IsZero() || point.Time.Before(first) {
+				first = point.Time
+			}
+			if point.Time.After(last) {
+				last = point.Time
+			}
+		}
+		if point.HeartRate > 0 {
+			heartRateSum += point.HeartRate
+			heartRateCount++
+			workout.MaxHeartRate = math.Max(workout.MaxHeartRate, point.HeartRate)
+		}
+		if point.HasDistance {
+			recorded = math.Max(recorded, point.Distance)
+		}
+		if previous != nil {
+			if previous.HasPosition && point.HasPosition {
+				measured += haversineMeters(previous.Latitude, previous.Longitude, point.Latitude, point.Longitude)
+			}
+			if previous.HasAltitude && point.HasAltitude && point.Altitude > previous.Altitude {
+				workout.ElevationGainMeters += point.Altitude - previous.Altitude
+			}
+		}
+		previous = point
+	}
+
+	workout.StartTime = first
+	if !first.IsZero() {
+		workout.DurationSeconds = last.Sub(first).Seconds()
+	}
+	workout.DistanceMeters = measured
+	if recorded > 0 {
+		workout.DistanceMeters = recorded
+	}
+	if heartRateCount > 0 {
+		workout.AvgHeartRate = heartRateSum / float64(heartRateCount)
+	}
+	return workout
+}
+
+// haversineMeters returns the great-circle distance between two positions in degrees
+func haversineMeters(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000
+	toRadians := math.Pi / 180
+	dLat := (lat2 - lat1) * toRadians
+	dLon := (lon2 - lon1) * toRadians
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(lat1*toRadians)*math.Cos(lat2*toRadians)*math.Sin(dLon/2)*math.Sin(dLon/2)
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
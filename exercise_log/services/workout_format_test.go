@@ -0,0 +1,250 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+)
+
+const sampleGPX = `<?xml version="1.0" encoding="UTF-8"?>
+<gpx version="1.1" creator="test" xmlns="http://www.topografix.com/GPX/1/1"
+     xmlns:gpxtpx="http://www.garmin.com/xmlschemas/TrackPointExtension/v1">
+  <trk>
+    <type>running</type>
+    <trkseg>
+      <trkpt lat="48.8566" lon="2.3522"><ele>35</ele><time>2026-05-02T07:00:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>120</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+      <trkpt lat="48.8656" lon="2.3522"><ele>45</ele><time>2026-05-02T07:05:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>150</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+      <trkpt lat="48.8746" lon="2.3522"><ele>40</ele><time>2026-05-02T07:10:00Z</time>
+        <extensions><gpxtpx:TrackPointExtension><gpxtpx:hr>160</gpxtpx:hr></gpxtpx:TrackPointExtension></extensions>
+      </trkpt>
+    </trkseg>
+  </trk>
+</gpx>`
+
+const sampleTCX = `<?xml version="1.0" encoding="UTF-8"?>
+<TrainingCenterDatabase xmlns="http://www.garmin.com/xmlschemas/TrainingCenterDatabase/v2">
+  <Activities>
+    <Activity Sport="Biking">
+      <Id>2026-05-03T17:00:00Z</Id>
+      <Lap StartTime="2026-05-03T17:00:00Z">
+        <TotalTimeSeconds>3600</TotalTimeSeconds>
+        <DistanceMeters>25000</DistanceMeters>
+        <Calories>650</Calories>
+        <MaximumHeartRateBpm><Value>171</Value></MaximumHeartRateBpm>
+        <Track>
+          <Trackpoint><Time>2026-05-03T17:00:00Z</Time><AltitudeMeters>100</AltitudeMeters><DistanceMeters>0</DistanceMeters><HeartRateBpm><Value>130</Value></HeartRateBpm></Trackpoint>
+          <Trackpoint><Time>2026-05-03T17:59:00Z</Time><AltitudeMeters>130</AltitudeMeters><DistanceMeters>24800</DistanceMeters><HeartRateBpm><Value>150</Value></HeartRateBpm></Trackpoint>
+        </Track>
+      </Lap>
+    </Activity>
+  </Activities>
+</TrainingCenterDatabase>`
+
+func TestParseGPX(t *testing.T) {
+	format, ok := DetectWorkoutFormat("morning.xml", []byte(sampleGPX))
+	if !ok || format != models.SourceGPX {
+		t.Fatalf("DetectWorkoutFormat = %q, %v; want gpx", format, ok)
+	}
+	workouts, err := ParseWorkouts(format, []byte(sampleGPX))
+	if err != nil {
+		t.Fatalf("ParseWorkouts: %v", err)
+	}
+	if len(workouts) != 1 {
+		t.Fatalf("got %d workouts, want 1", len(workouts))
+	}
+
+	workout := workouts[0]
+	if workout.Sport != "running" || workout.DurationSeconds != 600 {
+		t.Errorf("sport %q and duration %v, want running and 600 s", workout.Sport, workout.DurationSeconds)
+	}
+	// 0.018 degrees of latitude is about 2 km
+	if math.Abs(workout.DistanceMeters-2001) > 5 {
+		t.Errorf("distance = %v, want about 2001 m", workout.DistanceMeters)
+	}
+	if workout.ElevationGainMeters != 10 || workout.MaxHeartRate != 160 || math.Abs(workout.AvgHeartRate-143.33) > 0.01 {
+		t.Errorf("elevation %v, heart rate %v/%v", workout.ElevationGainMeters, workout.AvgHeartRate, workout.MaxHeartRate)
+	}
+	if workout.DeviceCalories != 0 {
+		t.Errorf("GPX has no device calories, got %v", workout.DeviceCalories)
+	}
+}
+
+func TestParseTCX(t *testing.T) {
+	format, ok := DetectWorkoutFormat("ride.tcx", []byte(sampleTCX))
+	if !ok || format != models.SourceTCX {
+		t.Fatalf("DetectWorkoutFormat = %q, %v; want tcx", format, ok)
+	}
+	workouts, err := ParseWorkouts(format, []byte(sampleTCX))
+	if err != nil {
+		t.Fatalf("ParseWorkouts: %v", err)
+	}
+
+	// Lap totals take precedence over the track
+	workout := workouts[0]
+	if workout.Sport != "Biking" || workout.DurationSeconds != 3600 || workout.DistanceMeters != 25000 {
+		t.Errorf("sport %q, duration %v, distance %v", workout.Sport, workout.DurationSeconds, workout.DistanceMeters)
+	}
+	if workout.DeviceCalories != 650 || workout.MaxHeartRate != 171 || workout.ElevationGainMeters != 30 {
+		t.Errorf("calories %v, max heart rate %v, elevation %v", workout.DeviceCalories, workout.MaxHeartRate, workout.ElevationGainMeters)
+	}
+}
+
+// fitFile assembles a little-endian FIT file from definition and data messages
+type fitFile struct {
+	body bytes.Buffer
+}
+
+func (f *fitFile) define(local byte, global uint16, fields ...fitField) {
+	f.body.WriteByte(0x40 | local)
+	f.body.Write([]byte{0, 0})
+	binary.Write(&f.body, binary.LittleEndian, global)
+	f.body.WriteByte(byte(len(fields)))
+	for _, field := range fields {
+		f.body.Write([]byte{field.Number, byte(field.Size), field.BaseType})
+	}
+}
+
+func (f *fitFile) data(header byte, values ...any) {
+	f.body.WriteByte(header)
+	for _, value := range values {
+		binary.Write(&f.body, binary.LittleEndian, value)
+	}
+}
+
+func (f *fitFile) bytes() []byte {
+	header := make([]byte, 14)
+	header[0] = 14
+	header[1] = 0x20
+	binary.LittleEndian.PutUint32(header[4:8], uint32(f.body.Len()))
+	copy(header[8:12], ".FIT")
+	return append(append(header, f.body.Bytes()...), 0, 0)
+}
+
+func TestParseFIT(t *testing.T) {
+	start := time.Date(2026, 5, 4, 6, 30, 0, 0, time.UTC)
+	startTimestamp := uint32(start.Sub(fitEpoch).Seconds())
+
+	var file fitFile
+	file.define(0, fitMessageRecord,
+		fitField{Number: fitFieldTimestamp, Size: 4, BaseType: 0x86},
+		fitField{Number: fitRecordHeartRate, Size: 1, BaseType: 0x02},
+		fitField{Number: fitRecordAltitude, Size: 2, BaseType: 0x84},
+	)
+	file.data(0x00, startTimestamp, uint8(140), uint16((50+500)*5))
+	// Compressed timestamp header: local type 0, 10 seconds later; the heart rate is invalid
+	file.data(0x80|byte((startTimestamp+10)&0x1F), startTimestamp, uint8(0xFF), uint16((60+500)*5))
+	file.define(1, fitMessageSession,
+		fitField{Number: fitSessionStartTime, Size: 4, BaseType: 0x86},
+		fitField{Number: fitSessionSport, Size: 1, BaseType: 0x00},
+		fitField{Number: fitSessionElapsedTime, Size: 4, BaseType: 0x86},
+		fitField{Number: fitSessionTimerTime, Size: 4, BaseType: 0x86},
+		fitField{Number: fitSessionDistance, Size: 4, BaseType: 0x86},
+		fitField{Number: fitSessionCalories, Size: 2, BaseType: 0x84},
+		fitField{Number: fitSessionTotalAscent, Size: 2, BaseType: 0x84},
+		fitField{Number: fitSessionAvgHeartRate, Size: 1, BaseType: 0x02},
+		fitField{Number: fitSessionMaxHeartRate, Size: 1, BaseType: 0x02},
+	)
+	file.data(0x01, startTimestamp, uint8(11), uint32(1900000), uint32(1800000), uint32(250000), uint16(210), uint16(0xFFFF), uint8(110), uint8(135))
+
+	data := file.bytes()
+	format, ok := DetectWorkoutFormat("upload.bin", data)
+	if !ok || format != models.SourceFIT {
+		t.Fatalf("DetectWorkoutFormat = %q, %v; want fit", format, ok)
+	}
+	workouts, err := ParseWorkouts(format, data)
+	if err != nil {
+		t.Fatalf("ParseWorkouts: %v", err)
+	}
+	if len(workouts) != 1 {
+		t.Fatalf("got %d workouts, want 1", len(workouts))
+	}
+
+	workout := workouts[0]
+	if workout.Sport != "walking" || !workout.StartTime.Equal(start) {
+		t.Errorf("sport %q starting %v", workout.Sport, workout.StartTime)
+	}
+	if workout.DurationSeconds != 1800 || workout.DistanceMeters != 2500 || workout.DeviceCalories != 210 {
+		t.Errorf("duration %v, distance %v, calories %v", workout.DurationSeconds, workout.DistanceMeters, workout.DeviceCalories)
+	}
+	// The invalid total ascent falls back to the climb between the records
+	if workout.ElevationGainMeters != 10 || workout.AvgHeartRate != 110 || workout.MaxHeartRate != 135 {
+		t.Errorf("elevation %v, heart rate %v/%v", workout.ElevationGainMeters, workout.AvgHeartRate, workout.MaxHeartRate)
+	}
+}
+
+func TestParseWorkoutsRejectsInvalidFiles(t *testing.T) {
+	for format, data := range map[string]string{
+		models.SourceGPX: "<gpx><trk>",
+		models.SourceTCX: `<TrainingCenterDatabase><Activities></Activities></TrainingCenterDatabase>`,
+		models.SourceFIT: "\x0e\x20\x00\x00\xff\x00\x00\x00.FIT\x00\x00",
+	} {
+		if _, err := ParseWorkouts(format, []byte(data)); !errors.Is(err, ErrInvalidWorkoutFile) {
+			t.Errorf("%s: error = %v, want ErrInvalidWorkoutFile", format, err)
+		}
+	}
+	if _, ok := DetectWorkoutFormat("notes.txt", []byte("hello")); ok {
+		t.Error("a text file should not be detected as a workout")
+	}
+}
+
+func TestActivityCodeForSport(t *testing.T) {
+	cases := map[string]string{
+		"running":         "running",
+		"Trail Run":       "running",
+		"Biking":          "cycling",
+		"indoor_cycling":  "stationary_cycling",
+		"hiking":          "hiking",
+		"walking":         "walking",
+		"open_water_swim": "swimming",
+	}
+	for sport, want := range cases {
+		if got, ok := ActivityCodeForSport(sport); !ok || got != want {
+			t.Errorf("ActivityCodeForSport(%q) = %q, %v; want %q", sport, got, ok, want)
+		}
+	}
+	for _, sport := range []string{"", "Other", "training"} {
+		if code, ok := ActivityCodeForSport(sport); ok {
+			t.Errorf("ActivityCodeForSport(%q) = %q, want no match", sport, code)
+		}
+	}
+}
+
+func TestWorkoutEntry(t *testing.T) {
+	running := &models.Activity{ID: 3, Code: "running", METLight: 7, METModerate: 9.8, METVigorous: 11.8}
+
+	// 10 km in 50 minutes is 12 km/h: vigorous
+	workout := Workout{StartTime: time.Date(2026, 5, 2, 7, 0, 0, 0, time.UTC), DurationSeconds: 3000, DistanceMeters: 10000}
+	entry, err := workoutEntry(1, models.SourceGPX, workout, running, 70)
+	if err != nil {
+		t.Fatalf("workoutEntry: %v", err)
+	}
+	if entry.Intensity != models.IntensityVigorous || entry.MET != 11.8 || entry.Source != models.SourceGPX {
+		t.Errorf("intensity %q, MET %v, source %q", entry.Intensity, entry.MET, entry.Source)
+	}
+	if math.Abs(entry.CaloriesBurned-BurnedCalories(11.8, 70, 50)) > 0.001 {
+		t.Errorf("CaloriesBurned = %v, want the MET estimate", entry.CaloriesBurned)
+	}
+
+	// Device calories replace the estimate
+	workout.DeviceCalories = 720
+	entry, _ = workoutEntry(1, models.SourceFIT, workout, running, 70)
+	if entry.CaloriesBurned != 720 {
+		t.Errorf("CaloriesBurned = %v, want the device's 720", entry.CaloriesBurned)
+	}
+
+	if got := IntensityForSpeed("running", 7); got != models.IntensityLight {
+		t.Errorf("running at 7 km/h is %q, want light", got)
+	}
+	if got := IntensityForSpeed("yoga", 0); got != models.IntensityModerate {
+		t.Errorf("activity without thresholds is %q, want moderate", got)
+	}
+}
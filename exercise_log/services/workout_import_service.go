@@ -0,0 +1,244 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/exercise_log/models"
+	"github.com/momokapoolz/caloriesapp/exercise_log/repository"
+	"gorm.io/gorm"
+)
+
+// Error definitions
+var (
+	ErrDuplicateImport = errors.New("this workout file was already imported")
+	ErrUnmappedSport   = errors.New("sport does not match a catalog activity")
+)
+
+// importHashIndex is the unique index that keeps a user from importing the same file twice
+const importHashIndex = "idx_exercise_import_user_hash"
+
+// sportActivities maps fragments of the sport names written by devices onto activity codes.
+// The first matching fragment wins, so the more specific ones come first.
+var sportActivities = []struct {
+	Fragment string
+	Code     string
+}{
+	{"indoor_cycl", "stationary_cycling"},
+	{"stationary", "stationary_cycling"},
+	{"run", "running"},
+	{"cycl", "cycling"},
+	{"bik", "cycling"},
+	{"ride", "cycling"},
+	{"walk", "walking"},
+	{"hik", "hiking"},
+	{"swim", "swimming"},
+	{"row", "rowing_machine"},
+	{"ski", "cross_country_skiing"},
+	{"tennis", "tennis"},
+	{"basketball", "basketball"},
+	{"soccer", "soccer"},
+	{"elliptical", "elliptical"},
+	{"yoga", "yoga"},
+}
+
+// speedThresholds are the speeds in km/h below which a workout of an activity is light and
+// moderate respectively; faster is vigorous
+var speedThresholds = map[string][2]float64{
+	"running": {8, 11},
+	"cycling": {16, 22},
+	"walking": {4.5, 6},
+	"hiking":  {3, 5},
+}
+
+// WorkoutImportService turns uploaded GPX, TCX and FIT files into exercise logs. Files are
+// parsed locally and recognized by their SHA-256, so a file is imported once per user.
+type WorkoutImportService struct {
+	repo         *repository.WorkoutImportRepository
+	activityRepo *repository.ActivityRepository
+	exerciseLogs *ExerciseLogService
+}
+
+// NewWorkoutImportService creates a new workout import service instance
+func NewWorkoutImportService(
+	repo *repository.WorkoutImportRepository,
+	activityRepo *repository.ActivityRepository,
+	exerciseLogs *ExerciseLogService,
+) *WorkoutImportService {
+	return &WorkoutImportService{
+		repo:         repo,
+		activityRepo: activityRepo,
+		exerciseLogs: exerciseLogs,
+	}
+}
+
+// ImportWorkouts logs every workout of a file for a user. activityID, when set, overrides the
+// activity derived from the sport recorded in the file. The calories reported by the device
+// are kept; the others are estimated from the MET at the user's latest weight.
+func (s *WorkoutImportService) ImportWorkouts(userID uint, fileName string, data []byte, activityID *uint) (*models.WorkoutImport, error) {
+	format, ok := DetectWorkoutFormat(fileName, data)
+	if !ok {
+		return nil, fmt.Errorf("%w: upload a .gpx, .tcx or .fit file", ErrInvalidWorkoutFile)
+	}
+
+	sum := sha256.Sum256(data)
+	fileHash := hex.EncodeToString(sum[:])
+	_, err := s.repo.GetByUserIDAndHash(userID, fileHash)
+	if err == nil {
+		return nil, ErrDuplicateImport
+	}
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, fmt.Errorf("failed to check previous imports: %w", err)
+	}
+
+	workouts, err := ParseWorkouts(format, data)
+	if err != nil {
+		return nil, err
+	}
+
+	// The weight is kept with every entry but only needed for workouts without device calories
+	weightKg, err := s.exerciseLogs.LatestWeightKg(userID)
+	if err != nil && (!errors.Is(err, ErrWeightRequired) || needsWeight(workouts)) {
+		return nil, err
+	}
+
+	now := time.Now()
+	workoutImport := &models.WorkoutImport{
+		UserID:    userID,
+		FileHash:  fileHash,
+		FileName:  fileName,
+		Format:    format,
+		CreatedAt: now,
+	}
+	for _, workout := range workouts {
+		activity, err := s.resolveActivity(workout.Sport, activityID)
+		if err != nil {
+			return nil, err
+		}
+		entry, err := workoutEntry(userID, format, workout, activity, weightKg)
+		if err != nil {
+			return nil, err
+		}
+		entry.CreatedAt = now
+		workoutImport.Entries = append(workoutImport.Entries, *entry)
+	}
+
+	if err := s.repo.Create(workoutImport); err != nil {
+		// A concurrent upload of the same file got past the check above first
+		var constraintErr *database.ConstraintError
+		if errors.As(err, &constraintErr) && constraintErr.Constraint == importHashIndex {
+			return nil, ErrDuplicateImport
+		}
+		return nil, fmt.Errorf("failed to save workout import: %w", err)
+	}
+	return workoutImport, nil
+}
+
+// needsWeight reports whether some workout has no device calories to log
+func needsWeight(workouts []Workout) bool {
+	for _, workout := range workouts {
+		if workout.DeviceCalories <= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// resolveActivity returns the activity chosen by the user, or the one matching a sport
+func (s *WorkoutImportService) resolveActivity(sport string, activityID *uint) (*models.Activity, error) {
+	var activity *models.Activity
+	var err error
+	if activityID != nil {
+		activity, err = s.activityRepo.GetByID(*activityID)
+	} else {
+		code, ok := ActivityCodeForSport(sport)
+		if !ok {
+			return nil, fmt.Errorf("%w: %q, choose an activity_id", ErrUnmappedSport, sport)
+		}
+		activity, err = s.activityRepo.GetByCode(code)
+	}
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrActivityNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+	return activity, nil
+}
+
+// ActivityCodeForSport maps the sport name recorded by a device onto an activity code.
+// It reports false for sports the catalog has no activity for.
+func ActivityCodeForSport(sport string) (string, bool) {
+	sport = strings.ToLower(strings.TrimSpace(sport))
+	if sport == "" {
+		return "", false
+	}
+	for _, mapping := range sportActivities {
+		if strings.Contains(sport, mapping.Fragment) {
+			return mapping.Code, true
+		}
+	}
+	return "", false
+}
+
+// IntensityForSpeed grades a workout of an activity by its average speed in km/h.
+// Activities without speed thresholds, or workouts without distance, are moderate.
+func IntensityForSpeed(activityCode string, kmPerHour float64) string {
+	thresholds, ok := speedThresholds[activityCode]
+	switch {
+	case !ok || kmPerHour <= 0:
+		return models.IntensityModerate
+	case kmPerHour < thresholds[0]:
+		return models.IntensityLight
+	case kmPerHour < thresholds[1]:
+		return models.IntensityModerate
+	default:
+		return models.IntensityVigorous
+	}
+}
+
+// workoutEntry builds the exercise log of an imported workout
+func workoutEntry(userID uint, format string, workout Workout, activity *models.Activity, weightKg float64) (*models.ExerciseLog, error) {
+	minutes := workout.DurationSeconds / 60
+	if minutes > 24*60 {
+		return nil, fmt.Errorf("%w: a workout lasts more than 24 hours", ErrInvalidWorkoutFile)
+	}
+
+	var kmPerHour float64
+	if workout.DistanceMeters > 0 {
+		kmPerHour = workout.DistanceMeters / 1000 / (workout.DurationSeconds / 3600)
+	}
+	intensity := IntensityForSpeed(activity.Code, kmPerHour)
+	met, _ := activity.MET(intensity)
+
+	performedAt := workout.StartTime
+	if performedAt.IsZero() {
+		performedAt = time.Now()
+	}
+	entry := &models.ExerciseLog{
+		UserID:          userID,
+		ActivityID:      activity.ID,
+		PerformedAt:     performedAt,
+		DurationMinutes: minutes,
+		Intensity:       intensity,
+		MET:             met,
+		WeightKg:        weightKg,
+		CaloriesBurned:  BurnedCalories(met, weightKg, minutes),
+		Source:          format,
+		DistanceMeters:  workout.DistanceMeters,
+		ElevationGain:   workout.ElevationGainMeters,
+		AvgHeartRate:    workout.AvgHeartRate,
+		MaxHeartRate:    workout.MaxHeartRate,
+		DeviceCalories:  workout.DeviceCalories,
+		Activity:        activity,
+	}
+	if workout.DeviceCalories > 0 {
+		entry.CaloriesBurned = workout.DeviceCalories
+	}
+	return entry, nil
+}
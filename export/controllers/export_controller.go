@@ -0,0 +1,122 @@
+package controllers
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/export/models"
+	"github.com/momokapoolz/caloriesapp/export/services"
+	"github.com/momokapoolz/caloriesapp/helpers"
+)
+
+// ExportController handles HTTP requests for diary exports
+type ExportController struct {
+	service *services.ExportService
+}
+
+// NewExportController creates a new export controller instance
+func NewExportController(service *services.ExportService) *ExportController {
+	return &ExportController{service: service}
+}
+
+// Export godoc
+// @Summary      Export diary
+// @Description  Export the authenticated user's diary for a date range. CSV and JSON carry per-item, per-meal and per-day nutrient rows with the columns of a Cronometer export; a CSV holds the rows of one level. PDF is a printable report with daily totals, target adherence and a weight chart.
+// @Tags         export
+// @Produce      json
+// @Produce      text/csv
+// @Produce      application/pdf
+// @Param        format     query  string  false  "csv, json or pdf; json by default"
+// @Param        level      query  string  false  "Rows of a CSV export: items, meals or days; items by default"
+// @Param        startDate  query  string  true   "Start date in YYYY-MM-DD format"
+// @Param        endDate    query  string  true   "End date in YYYY-MM-DD format"
+// @Success      200  {object}  dto.DiaryExportDTO  "Diary export"
+// @Failure      400  {object}  map[string]string   "Invalid parameters"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /export [get]
+func (c *ExportController) Export(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	format := ctx.DefaultQuery("format", models.FormatJSON)
+	switch format {
+	case models.FormatCSV, models.FormatJSON, models.FormatPDF:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "format must be csv, json or pdf"})
+		return
+	}
+	level := ctx.DefaultQuery("level", models.LevelItems)
+	switch level {
+	case models.LevelItems, models.LevelMeals, models.LevelDays:
+	default:
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "level must be items, meals or days"})
+		return
+	}
+
+	startDateStr := ctx.Query("startDate")
+	endDateStr := ctx.Query("endDate")
+	if startDateStr == "" || endDateStr == "" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "startDate and endDate are required"})
+		return
+	}
+	startDate, err := time.Parse("2006-01-02", startDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid startDate format. Use YYYY-MM-DD"})
+		return
+	}
+	endDate, err := time.Parse("2006-01-02", endDateStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid endDate format. Use YYYY-MM-DD"})
+		return
+	}
+	if endDate.Before(startDate) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "endDate must not be before startDate"})
+		return
+	}
+
+	// Set end date to end of day
+	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, endDate.Location())
+	fileName := fmt.Sprintf("diary_%s_%s", startDateStr, endDateStr)
+
+	if format == models.FormatPDF {
+		report, err := c.service.RenderReport(userClaims.UserID, startDate, endDate)
+		if err != nil {
+			helpers.LogError(err)
+			ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to render report"})
+			return
+		}
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fileName+".pdf"))
+		ctx.Data(http.StatusOK, "application/pdf", report)
+		return
+	}
+
+	export, err := c.service.ExportDiary(userClaims.UserID, startDate, endDate)
+	if err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export diary"})
+		return
+	}
+
+	if format == models.FormatJSON {
+		ctx.JSON(http.StatusOK, export)
+		return
+	}
+
+	var body bytes.Buffer
+	if err := services.WriteCSV(&body, export, level); err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export diary"})
+		return
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("%s_%s.csv", fileName, level)))
+	ctx.Data(http.StatusOK, "text/csv; charset=utf-8", body.Bytes())
+}
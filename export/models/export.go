@@ -0,0 +1,30 @@
+package models
+
+// Export formats
+const (
+	FormatCSV  = "csv"
+	FormatJSON = "json"
+	FormatPDF  = "pdf"
+)
+
+// Row levels of a CSV export
+const (
+	LevelItems = "items"
+	LevelMeals = "meals"
+	LevelDays  = "days"
+)
+
+// NutrientColumn is a nutrient column of an export. Header is the Cronometer column header,
+// such as "Energy (kcal)", and amounts are in Unit.
+type NutrientColumn struct {
+	NutrientID uint
+	Code       string
+	Header     string
+	Unit       string
+}
+
+// WeightPoint is a weight measurement plotted in the PDF report
+type WeightPoint struct {
+	Date      string
+	Kilograms float64
+}
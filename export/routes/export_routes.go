@@ -0,0 +1,57 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/export/controllers"
+	"github.com/momokapoolz/caloriesapp/export/services"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutrientServices "github.com/momokapoolz/caloriesapp/nutrient/services"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	referenceIntakeServices "github.com/momokapoolz/caloriesapp/reference_intake/services"
+	targetsRepository "github.com/momokapoolz/caloriesapp/targets/repository"
+	targetsServices "github.com/momokapoolz/caloriesapp/targets/services"
+	userRepository "github.com/momokapoolz/caloriesapp/user/repository"
+	userBiometricsRepository "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+	"gorm.io/gorm"
+)
+
+// SetupExportRoutes initializes diary export routes
+func SetupExportRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	nutrientRepository := nutrientRepo.NewNutrientRepository(db)
+	mealLogRepository := mealLogRepo.NewMealLogRepository(db)
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepo.NewMealLogItemRepository(db),
+		foodRepo.NewFoodRepository(db),
+		foodNutrientsRepo.NewFoodNutrientRepository(db),
+		nutrientRepository,
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(dailyNutritionRepo.NewDailyNutritionRepository(db), mealLogRepository, engine)
+	biometricRepo := userBiometricsRepository.NewUserBiometricRepository(db)
+
+	nutrientService := nutrientServices.NewNutrientService(
+		nutrientRepository,
+		mealLogRepository,
+		engine,
+		rollup,
+		referenceIntakeServices.NewReferenceIntakeService(referenceIntakeServices.DefaultTable(), userRepository.NewUserRepository()),
+		targetsServices.NewTargetsService(
+			userRepository.NewUserRepository(),
+			biometricRepo,
+			targetsRepository.NewTargetSetRepository(db),
+			nutrientRepository,
+			targetsServices.NewAdaptiveTDEEService(biometricRepo, mealLogRepository, engine, rollup),
+		),
+	)
+	exportController := controllers.NewExportController(services.NewExportService(nutrientService, biometricRepo))
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	router.GET("/export", authMiddleware.RequireAuth(), exportController.Export)
+}
@@ -0,0 +1,222 @@
+package services
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/export/models"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+)
+
+// cronometerNutrients lists the nutrients with a stable code in export order, with the name
+// Cronometer gives their column. Other nutrients follow under their own name.
+var cronometerNutrients = []struct {
+	Code string
+	Name string
+}{
+	{nutrientModels.CodeEnergy, "Energy"},
+	{nutrientModels.CodeProtein, "Protein"},
+	{nutrientModels.CodeCarbohydrate, "Carbs"},
+	{nutrientModels.CodeFat, "Fat"},
+	{nutrientModels.CodeFiber, "Fiber"},
+	{nutrientModels.CodeCholesterol, "Cholesterol"},
+	{nutrientModels.CodeVitaminA, "Vitamin A"},
+	{nutrientModels.CodeVitaminB12, "B12 (Cobalamin)"},
+	{nutrientModels.CodeCalcium, "Calcium"},
+	{nutrientModels.CodeIron, "Iron"},
+}
+
+// cronometerGroups are the Cronometer diary groups of the meal types
+var cronometerGroups = map[string]string{
+	"breakfast": "Breakfast",
+	"lunch":     "Lunch",
+	"dinner":    "Dinner",
+	"snack":     "Snacks",
+}
+
+// NutrientColumns returns the nutrient columns of an export of report: the nutrients with a
+// stable code in Cronometer order, then every other nutrient consumed in the report by ID
+func NutrientColumns(report *nutritionEngine.NutritionReport) []models.NutrientColumn {
+	columns := []models.NutrientColumn{}
+	coded := make(map[uint]bool, len(cronometerNutrients))
+	for _, named := range cronometerNutrients {
+		id, ok := report.NutrientIDByCode(named.Code)
+		if !ok {
+			continue
+		}
+		nutrient, _ := report.Nutrient(id)
+		coded[id] = true
+		columns = append(columns, models.NutrientColumn{
+			NutrientID: id,
+			Code:       named.Code,
+			Header:     fmt.Sprintf("%s (%s)", named.Name, nutrient.Unit),
+			Unit:       nutrient.Unit,
+		})
+	}
+
+	others := []nutrientModels.Nutrient{}
+	for id := range report.Totals {
+		if nutrient, ok := report.Nutrient(id); ok && !coded[id] {
+			others = append(others, nutrient)
+		}
+	}
+	sort.Slice(others, func(i, j int) bool {
+		return others[i].ID < others[j].ID
+	})
+	for _, nutrient := range others {
+		columns = append(columns, models.NutrientColumn{
+			NutrientID: nutrient.ID,
+			Code:       nutrient.Code,
+			Header:     fmt.Sprintf("%s (%s)", nutrient.Name, nutrient.Unit),
+			Unit:       nutrient.Unit,
+		})
+	}
+	return columns
+}
+
+// BuildDiaryExport lays report out as export rows. Rows are ordered by the time of the meal;
+// Category is left blank because foods carry no category.
+func BuildDiaryExport(userID uint, startDate, endDate time.Time, report *nutritionEngine.NutritionReport, columns []models.NutrientColumn) *dto.DiaryExportDTO {
+	export := &dto.DiaryExportDTO{
+		UserID:    userID,
+		StartDate: startDate.Format("2006-01-02"),
+		EndDate:   endDate.Format("2006-01-02"),
+		Columns:   make([]string, 0, len(columns)),
+		Items:     []dto.DiaryExportItemDTO{},
+		Meals:     make([]dto.DiaryExportMealDTO, 0, len(report.Meals)),
+		Days:      make([]dto.DiaryExportDayDTO, 0, len(report.Days)),
+	}
+	for _, column := range columns {
+		export.Columns = append(export.Columns, column.Header)
+	}
+
+	meals := append([]nutritionEngine.MealNutrition(nil), report.Meals...)
+	sort.SliceStable(meals, func(i, j int) bool {
+		return meals[i].MealLog.ConsumedAt.Before(meals[j].MealLog.ConsumedAt)
+	})
+	for _, meal := range meals {
+		// Meals are listed on the day and at the time the user ate them, in the user's timezone
+		consumedAt := meal.MealLog.ConsumedAt.In(report.Location)
+		day := consumedAt.Format("2006-01-02")
+		clock := consumedAt.Format("15:04")
+		group := cronometerGroup(meal.MealLog)
+		for _, item := range meal.Items {
+			export.Items = append(export.Items, dto.DiaryExportItemDTO{
+				Day:       day,
+				Time:      clock,
+				Group:     group,
+				FoodName:  item.FoodName,
+				Amount:    formatAmount(item),
+				MealLogID: meal.MealLog.ID,
+				FoodID:    item.Item.FoodID,
+				Nutrients: nutrientValues(columns, item.Nutrients),
+			})
+		}
+		export.Meals = append(export.Meals, dto.DiaryExportMealDTO{
+			Day:       day,
+			Time:      clock,
+			Group:     group,
+			MealLogID: meal.MealLog.ID,
+			FoodCount: len(meal.Items),
+			Nutrients: nutrientValues(columns, meal.Nutrients),
+		})
+	}
+
+	for _, day := range report.Days {
+		export.Days = append(export.Days, dto.DiaryExportDayDTO{
+			Date:      day.Date,
+			Nutrients: nutrientValues(columns, day.Nutrients),
+		})
+	}
+	sort.SliceStable(export.Days, func(i, j int) bool {
+		return export.Days[i].Date < export.Days[j].Date
+	})
+
+	return export
+}
+
+// WriteCSV writes one level of an export as CSV. Items use the columns of a Cronometer servings
+// export and days those of a Cronometer daily summary, so both can be imported back.
+func WriteCSV(w io.Writer, export *dto.DiaryExportDTO, level string) error {
+	var header []string
+	var rows [][]string
+	switch level {
+	case models.LevelItems:
+		header = append([]string{"Day", "Time", "Group", "Food Name", "Amount"}, export.Columns...)
+		header = append(header, "Category")
+		for _, item := range export.Items {
+			row := append([]string{item.Day, item.Time, item.Group, item.FoodName, item.Amount}, csvValues(export.Columns, item.Nutrients)...)
+			rows = append(rows, append(row, item.Category))
+		}
+	case models.LevelMeals:
+		header = append([]string{"Day", "Time", "Group", "Foods"}, export.Columns...)
+		for _, meal := range export.Meals {
+			row := []string{meal.Day, meal.Time, meal.Group, strconv.Itoa(meal.FoodCount)}
+			rows = append(rows, append(row, csvValues(export.Columns, meal.Nutrients)...))
+		}
+	case models.LevelDays:
+		header = append([]string{"Date"}, export.Columns...)
+		for _, day := range export.Days {
+			rows = append(rows, append([]string{day.Date}, csvValues(export.Columns, day.Nutrients)...))
+		}
+	default:
+		return fmt.Errorf("unknown export level %q", level)
+	}
+
+	writer := csv.NewWriter(w)
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	if err := writer.WriteAll(rows); err != nil {
+		return err
+	}
+	return writer.Error()
+}
+
+// cronometerGroup returns the diary group a meal log is exported under
+func cronometerGroup(mealLog mealLogModels.MealLog) string {
+	mealType := strings.ToLower(strings.TrimSpace(mealLog.MealType))
+	if group, ok := cronometerGroups[mealType]; ok {
+		return group
+	}
+	if mealType == "" {
+		return "Uncategorized"
+	}
+	return strings.ToUpper(mealType[:1]) + mealType[1:]
+}
+
+// formatAmount formats the logged amount of an item the way Cronometer does, such as "1.50 cup".
+// Items logged before units were recorded are exported in grams.
+func formatAmount(item nutritionEngine.ItemNutrition) string {
+	if item.Item.Amount > 0 && item.Item.Unit != "" {
+		return fmt.Sprintf("%.2f %s", item.Item.Amount, item.Item.Unit)
+	}
+	return fmt.Sprintf("%.2f g", item.Item.QuantityGrams)
+}
+
+// nutrientValues keys the amounts of totals by column header, rounded to two decimals
+func nutrientValues(columns []models.NutrientColumn, totals nutritionEngine.NutrientTotals) map[string]float64 {
+	values := make(map[string]float64, len(columns))
+	for _, column := range columns {
+		values[column.Header] = math.Round(totals[column.NutrientID]*100) / 100
+	}
+	return values
+}
+
+// csvValues formats the nutrient amounts of a row in column order
+func csvValues(columns []string, values map[string]float64) []string {
+	fields := make([]string, 0, len(columns))
+	for _, column := range columns {
+		fields = append(fields, strconv.FormatFloat(values[column], 'f', 2, 64))
+	}
+	return fields
+}
@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	diaryImportModels "github.com/momokapoolz/caloriesapp/diary_import/models"
+	diaryImportServices "github.com/momokapoolz/caloriesapp/diary_import/services"
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/export/models"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+)
+
+// exportDiary builds a two day diary: oats and milk for breakfast on the first day, logged after
+// a dinner of rice that is stored first, and a snack of oats on the second day
+func exportDiary() (*nutritionEngine.NutritionReport, time.Time, time.Time) {
+	nutrients := []nutrientModels.Nutrient{
+		{ID: 1, Code: nutrientModels.CodeEnergy, Name: "Energy", Unit: "kcal"},
+		{ID: 2, Code: nutrientModels.CodeProtein, Name: "Protein", Unit: "g"},
+		{ID: 3, Code: nutrientModels.CodeVitaminB12, Name: "Vitamin B12", Unit: "µg"},
+		{ID: 4, Name: "Sodium", Unit: "mg"},
+		{ID: 5, Name: "Caffeine", Unit: "mg"},
+	}
+	foods := map[uint]foodModels.Food{
+		1: {ID: 1, Name: "Oats, Rolled"},
+		2: {ID: 2, Name: "Milk"},
+		3: {ID: 3, Name: "Rice"},
+	}
+	profiles := map[uint][]foodNutrientsModels.FoodNutrient{
+		1: {{FoodID: 1, NutrientID: 1, AmountPer100g: 375}, {FoodID: 1, NutrientID: 2, AmountPer100g: 13}},
+		2: {{FoodID: 2, NutrientID: 1, AmountPer100g: 60}, {FoodID: 2, NutrientID: 3, AmountPer100g: 0.45}, {FoodID: 2, NutrientID: 4, AmountPer100g: 44}},
+		3: {{FoodID: 3, NutrientID: 1, AmountPer100g: 130}, {FoodID: 3, NutrientID: 2, AmountPer100g: 2.7}},
+	}
+	day := time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC)
+	mealLogs := []mealLogModels.MealLog{
+		{ID: 10, UserID: 7, MealType: "dinner", ConsumedAt: day.Add(19 * time.Hour)},
+		{ID: 11, UserID: 7, MealType: "breakfast", ConsumedAt: day.Add(8*time.Hour + 15*time.Minute)},
+		{ID: 12, UserID: 7, MealType: "snack", ConsumedAt: day.Add(24*time.Hour + 16*time.Hour)},
+	}
+	items := map[uint][]mealLogItemsModels.MealLogItem{
+		10: {{MealLogID: 10, FoodID: 3, QuantityGrams: 200}},
+		11: {
+			{MealLogID: 11, FoodID: 1, Unit: "g", Amount: 40, QuantityGrams: 40},
+			{MealLogID: 11, FoodID: 2, Unit: "cup", Amount: 1, QuantityGrams: 244},
+		},
+		12: {{MealLogID: 12, FoodID: 1, Unit: "g", Amount: 30, QuantityGrams: 30}},
+	}
+	report := nutritionEngine.BuildReport(mealLogs, time.UTC, items, foods, profiles, nutrients)
+	return report, day, day.Add(48*time.Hour - time.Nanosecond)
+}
+
+func TestNutrientColumns(t *testing.T) {
+	report, _, _ := exportDiary()
+	var headers []string
+	for _, column := range NutrientColumns(report) {
+		headers = append(headers, column.Header)
+	}
+	// Coded nutrients come first in Cronometer order; caffeine is never consumed
+	want := []string{"Energy (kcal)", "Protein (g)", "B12 (Cobalamin) (µg)", "Sodium (mg)"}
+	if strings.Join(headers, "|") != strings.Join(want, "|") {
+		t.Errorf("headers = %v, want %v", headers, want)
+	}
+}
+
+func TestBuildDiaryExport(t *testing.T) {
+	report, start, end := exportDiary()
+	export := BuildDiaryExport(7, start, end, report, NutrientColumns(report))
+
+	if export.StartDate != "2026-03-01" || export.EndDate != "2026-03-02" {
+		t.Errorf("range = %s to %s", export.StartDate, export.EndDate)
+	}
+	if len(export.Items) != 4 || len(export.Meals) != 3 || len(export.Days) != 2 {
+		t.Fatalf("got %d items, %d meals and %d days", len(export.Items), len(export.Meals), len(export.Days))
+	}
+
+	oats := export.Items[0]
+	if oats.FoodName != "Oats, Rolled" || oats.Group != "Breakfast" || oats.Time != "08:15" || oats.Amount != "40.00 g" {
+		t.Errorf("first item = %+v, want the breakfast oats", oats)
+	}
+	if export.Items[1].Amount != "1.00 cup" || export.Items[1].Nutrients["B12 (Cobalamin) (µg)"] != 1.1 {
+		t.Errorf("milk = %+v", export.Items[1])
+	}
+	// Items logged without a unit are exported in grams
+	if rice := export.Items[2]; rice.Amount != "200.00 g" || rice.Group != "Dinner" {
+		t.Errorf("rice = %+v", rice)
+	}
+	if snack := export.Meals[2]; snack.Group != "Snacks" || snack.Day != "2026-03-02" || snack.Nutrients["Energy (kcal)"] != 112.5 {
+		t.Errorf("snack = %+v", snack)
+	}
+	if first := export.Days[0]; first.Date != "2026-03-01" || first.Nutrients["Energy (kcal)"] != 150+146.4+260 {
+		t.Errorf("first day = %+v", first)
+	}
+}
+
+func TestWriteCSVImportsBack(t *testing.T) {
+	report, start, end := exportDiary()
+	export := BuildDiaryExport(7, start, end, report, NutrientColumns(report))
+
+	var servings bytes.Buffer
+	if err := WriteCSV(&servings, export, models.LevelItems); err != nil {
+		t.Fatalf("WriteCSV items: %v", err)
+	}
+	if header := strings.SplitN(servings.String(), "\n", 2)[0]; header != "Day,Time,Group,Food Name,Amount,Energy (kcal),Protein (g),B12 (Cobalamin) (µg),Sodium (mg),Category" {
+		t.Errorf("items header = %q", header)
+	}
+	format, entries, skipped, err := diaryImportServices.ParseDiary("", &servings)
+	if err != nil || format != diaryImportModels.FormatCronometerServings || skipped != 0 || len(entries) != 4 {
+		t.Fatalf("ParseDiary items = %q, %d entries, %d skipped, %v", format, len(entries), skipped, err)
+	}
+	if milk := entries[1]; milk.FoodName != "Milk" || milk.Amount != 1 || milk.Unit != "cup" || milk.MealType != diaryImportModels.MealBreakfast {
+		t.Errorf("imported milk = %+v", milk)
+	}
+
+	var summary bytes.Buffer
+	if err := WriteCSV(&summary, export, models.LevelDays); err != nil {
+		t.Fatalf("WriteCSV days: %v", err)
+	}
+	format, entries, _, err = diaryImportServices.ParseDiary("", &summary)
+	if err != nil || format != diaryImportModels.FormatCronometerDailySummary || len(entries) != 2 {
+		t.Fatalf("ParseDiary days = %q, %d entries, %v", format, len(entries), err)
+	}
+	if energy := entries[1].Nutrients["energy (kcal)"]; energy.Code != nutrientModels.CodeEnergy || energy.Amount != 112.5 {
+		t.Errorf("imported second day energy = %+v", energy)
+	}
+
+	var meals bytes.Buffer
+	if err := WriteCSV(&meals, export, models.LevelMeals); err != nil {
+		t.Fatalf("WriteCSV meals: %v", err)
+	}
+	if lines := strings.Split(strings.TrimSpace(meals.String()), "\n"); len(lines) != 4 || lines[1] != "2026-03-01,08:15,Breakfast,2,296.40,5.20,1.10,107.36" {
+		t.Errorf("meals = %q", lines)
+	}
+
+	if err := WriteCSV(&meals, export, "weeks"); err == nil {
+		t.Error("WriteCSV accepted an unknown level")
+	}
+}
+
+func TestRenderReportPDF(t *testing.T) {
+	report, start, end := exportDiary()
+	columns := NutrientColumns(report)
+	export := BuildDiaryExport(7, start, end, report, columns)
+	lower, upper := 1800.0, 2200.0
+	targets := []dto.NutrientTargetStatusDTO{{NutrientName: "Energy", Unit: "kcal", Min: &lower, Max: &upper, DaysScored: 2, DaysBelow: 2}}
+	weights := []models.WeightPoint{{Date: "2026-03-01", Kilograms: 70.2}, {Date: "2026-03-02", Kilograms: 69.8}}
+
+	pdf := RenderReportPDF(export, columns, targets, weights)
+	if !bytes.HasPrefix(pdf, []byte("%PDF-1.4\n")) || !bytes.HasSuffix(pdf, []byte("%%EOF\n")) {
+		t.Fatalf("not a PDF: %q...", pdf[:min(len(pdf), 16)])
+	}
+	for _, text := range []string{"(Daily totals)", "(Energy \\(kcal\\))", "(Average of 2 days)", "(1800-2200)", "(Weight \\(kg\\))"} {
+		if !bytes.Contains(pdf, []byte(text)) {
+			t.Errorf("report lacks %s", text)
+		}
+	}
+
+	// Every cross-reference entry must point at its object
+	startxref := regexp.MustCompile(`startxref\n(\d+)\n`).FindSubmatch(pdf)
+	if startxref == nil {
+		t.Fatal("missing startxref")
+	}
+	xref, _ := strconv.Atoi(string(startxref[1]))
+	if !bytes.HasPrefix(pdf[xref:], []byte("xref\n")) {
+		t.Fatalf("startxref %d does not point at the xref table", xref)
+	}
+	entries := regexp.MustCompile(`(\d{10}) 00000 n `).FindAllSubmatch(pdf[xref:], -1)
+	if len(entries) < 6 {
+		t.Fatalf("got %d objects, want at least 6", len(entries))
+	}
+	for i, entry := range entries {
+		offset, _ := strconv.Atoi(string(entry[1]))
+		if want := fmt.Sprintf("%d 0 obj\n", i+1); !bytes.HasPrefix(pdf[offset:], []byte(want)) {
+			t.Errorf("xref entry %d points at %q", i+1, pdf[offset:offset+10])
+		}
+	}
+}
+
+func TestRenderReportPDFPaginates(t *testing.T) {
+	export := &dto.DiaryExportDTO{StartDate: "2026-01-01", EndDate: "2026-04-30"}
+	columns := []models.NutrientColumn{{NutrientID: 1, Code: nutrientModels.CodeEnergy, Header: "Energy (kcal)", Unit: "kcal"}}
+	for day := 0; day < 120; day++ {
+		date := time.Date(2026, 1, 1+day, 0, 0, 0, 0, time.UTC).Format("2006-01-02")
+		export.Days = append(export.Days, dto.DiaryExportDayDTO{Date: date, Nutrients: map[string]float64{"Energy (kcal)": 2000}})
+	}
+
+	pdf := RenderReportPDF(export, columns, nil, nil)
+	if pages := bytes.Count(pdf, []byte("/Type /Page ")); pages < 3 {
+		t.Errorf("got %d pages for 120 days, want at least 3", pages)
+	}
+	if !bytes.Contains(pdf, []byte("(No weight logged in this period)")) {
+		t.Error("report lacks the empty weight chart note")
+	}
+}
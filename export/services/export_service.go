@@ -0,0 +1,63 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/export/models"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	nutrientServices "github.com/momokapoolz/caloriesapp/nutrient/services"
+	userBiometricsModels "github.com/momokapoolz/caloriesapp/user_biometrics/models"
+	userBiometricsRepo "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+)
+
+// ExportService exports a user's diary. Nutrient amounts come from the same calculation as the
+// nutrition summaries, so an export always agrees with what the app shows.
+type ExportService struct {
+	nutrients     *nutrientServices.NutrientService
+	biometricRepo *userBiometricsRepo.UserBiometricRepository
+}
+
+// NewExportService creates a new export service instance
+func NewExportService(nutrients *nutrientServices.NutrientService, biometricRepo *userBiometricsRepo.UserBiometricRepository) *ExportService {
+	return &ExportService{
+		nutrients:     nutrients,
+		biometricRepo: biometricRepo,
+	}
+}
+
+// ExportDiary returns the item, meal and day rows of a user's diary within a date range
+func (s *ExportService) ExportDiary(userID uint, startDate, endDate time.Time) (*dto.DiaryExportDTO, error) {
+	_, report, err := s.nutrients.CalculateUserNutritionReport(userID, startDate, endDate)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, err
+	}
+	return BuildDiaryExport(userID, startDate, endDate, report, NutrientColumns(report)), nil
+}
+
+// RenderReport renders the printable PDF report of a user's diary within a date range
+func (s *ExportService) RenderReport(userID uint, startDate, endDate time.Time) ([]byte, error) {
+	summary, report, err := s.nutrients.CalculateUserNutritionReport(userID, startDate, endDate)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, err
+	}
+	columns := NutrientColumns(report)
+
+	biometrics, err := s.biometricRepo.GetByUserIDAndTypeAndDateRange(userID, userBiometricsModels.GetBiometricTypes().Weight, startDate, endDate)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get weights: %w", err)
+	}
+	weights := make([]models.WeightPoint, 0, len(biometrics))
+	for _, biometric := range biometrics {
+		if kilograms, ok := biometric.Kilograms(); ok {
+			weights = append(weights, models.WeightPoint{Date: biometric.CreatedAt.Format("2006-01-02"), Kilograms: kilograms})
+		}
+	}
+
+	export := BuildDiaryExport(userID, startDate, endDate, report, columns)
+	return RenderReportPDF(export, columns, summary.Targets, weights), nil
+}
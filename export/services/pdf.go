@@ -0,0 +1,135 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+)
+
+// PDF page size, A4 in points
+const (
+	pdfPageWidth  = 595.0
+	pdfPageHeight = 842.0
+)
+
+// pdfDocument writes a minimal PDF made of text in the standard Helvetica fonts and stroked lines,
+// which is all the report needs and keeps the export free of a PDF dependency
+type pdfDocument struct {
+	pages []*bytes.Buffer
+}
+
+// newPage starts a new page; drawing goes to the last page
+func (d *pdfDocument) newPage() {
+	d.pages = append(d.pages, &bytes.Buffer{})
+}
+
+// page returns the content stream of the last page, starting one when there is none
+func (d *pdfDocument) page() *bytes.Buffer {
+	if len(d.pages) == 0 {
+		d.newPage()
+	}
+	return d.pages[len(d.pages)-1]
+}
+
+// text draws s with its baseline starting at x, y
+func (d *pdfDocument) text(x, y, size float64, bold bool, s string) {
+	font := "F1"
+	if bold {
+		font = "F2"
+	}
+	fmt.Fprintf(d.page(), "BT /%s %.1f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x, y, pdfString(s))
+}
+
+// textRight draws s so that it ends at x. Widths are estimated from the average Helvetica
+// glyph width, which is close enough to right-align numbers.
+func (d *pdfDocument) textRight(x, y, size float64, bold bool, s string) {
+	d.text(x-float64(len(s))*size*0.52, y, size, bold, s)
+}
+
+// line strokes a line from x1, y1 to x2, y2
+func (d *pdfDocument) line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page(), "%.2f w %.2f %.2f m %.2f %.2f l S\n", width, x1, y1, x2, y2)
+}
+
+// polyline strokes a line through points given as x, y pairs
+func (d *pdfDocument) polyline(points [][2]float64, width float64) {
+	if len(points) < 2 {
+		return
+	}
+	page := d.page()
+	fmt.Fprintf(page, "%.2f w %.2f %.2f m", width, points[0][0], points[0][1])
+	for _, point := range points[1:] {
+		fmt.Fprintf(page, " %.2f %.2f l", point[0], point[1])
+	}
+	page.WriteString(" S\n")
+}
+
+// square fills a square of side size centered on x, y
+func (d *pdfDocument) square(x, y, size float64) {
+	fmt.Fprintf(d.page(), "%.2f %.2f %.2f %.2f re f\n", x-size/2, y-size/2, size, size)
+}
+
+// gray sets the stroke and fill gray level, from 0 for black to 1 for white
+func (d *pdfDocument) gray(level float64) {
+	fmt.Fprintf(d.page(), "%.2f G %.2f g\n", level, level)
+}
+
+// bytes serializes the document
+func (d *pdfDocument) bytes() []byte {
+	if len(d.pages) == 0 {
+		d.newPage()
+	}
+
+	// Objects 1 and 2 are the catalog and page tree, 3 and 4 the fonts, and every page adds a
+	// page object followed by its content stream
+	objects := []string{
+		"<< /Type /Catalog /Pages 2 0 R >>",
+		"",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>",
+		"<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>",
+	}
+	kids := make([]string, 0, len(d.pages))
+	for _, content := range d.pages {
+		pageID := len(objects) + 1
+		kids = append(kids, fmt.Sprintf("%d 0 R", pageID))
+		objects = append(objects,
+			fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.0f %.0f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+				pdfPageWidth, pdfPageHeight, pageID+1),
+			fmt.Sprintf("<< /Length %d >>\nstream\n%sendstream", content.Len(), content.String()),
+		)
+	}
+	objects[1] = fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages))
+
+	var out bytes.Buffer
+	out.WriteString("%PDF-1.4\n")
+	offsets := make([]int, len(objects))
+	for i, object := range objects {
+		offsets[i] = out.Len()
+		fmt.Fprintf(&out, "%d 0 obj\n%s\nendobj\n", i+1, object)
+	}
+	xref := out.Len()
+	fmt.Fprintf(&out, "xref\n0 %d\n0000000000 65535 f \n", len(objects)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&out, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&out, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(objects)+1, xref)
+	return out.Bytes()
+}
+
+// pdfString encodes s as the body of a PDF literal string in WinAnsiEncoding. Characters outside
+// Latin-1 are replaced with a question mark.
+func pdfString(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r == '\\' || r == '(' || r == ')':
+			b.WriteByte('\\')
+			b.WriteByte(byte(r))
+		case r >= 0x20 && r < 0x7f, r >= 0xa0 && r <= 0xff:
+			b.WriteByte(byte(r))
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return b.String()
+}
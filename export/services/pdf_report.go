@@ -0,0 +1,216 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/export/models"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+)
+
+// Layout of the PDF report in points
+const (
+	reportMargin      = 50.0
+	reportLineHeight  = 14.0
+	reportChartWidth  = pdfPageWidth - 2*reportMargin
+	reportChartHeight = 180.0
+)
+
+// reportDailyCodes are the nutrients in the daily totals table of the PDF report
+var reportDailyCodes = []string{
+	nutrientModels.CodeEnergy,
+	nutrientModels.CodeProtein,
+	nutrientModels.CodeCarbohydrate,
+	nutrientModels.CodeFat,
+	nutrientModels.CodeFiber,
+}
+
+// reportLayout places report content top to bottom, starting a new page when content would
+// run past the bottom margin
+type reportLayout struct {
+	doc *pdfDocument
+	y   float64
+}
+
+// reserve moves down by height, starting a new page first when the content does not fit
+func (l *reportLayout) reserve(height float64) float64 {
+	if l.doc.pages == nil || l.y-height < reportMargin {
+		l.doc.newPage()
+		l.y = pdfPageHeight - reportMargin
+	}
+	top := l.y
+	l.y -= height
+	return top
+}
+
+// heading writes a section heading
+func (l *reportLayout) heading(title string) {
+	top := l.reserve(2.5 * reportLineHeight)
+	l.doc.text(reportMargin, top-1.8*reportLineHeight, 13, true, title)
+}
+
+// row writes a table row with the first cell left aligned at the margin and the other cells
+// right aligned on stops
+func (l *reportLayout) row(stops []float64, bold bool, cells ...string) {
+	top := l.reserve(reportLineHeight)
+	baseline := top - reportLineHeight + 3
+	for i, cell := range cells {
+		if i == 0 {
+			l.doc.text(reportMargin, baseline, 9, bold, cell)
+			continue
+		}
+		l.doc.textRight(stops[i-1], baseline, 9, bold, cell)
+	}
+	if bold {
+		l.doc.line(reportMargin, baseline-3, pdfPageWidth-reportMargin, baseline-3, 0.5)
+	}
+}
+
+// RenderReportPDF renders the printable report of an export: the daily totals of the headline
+// nutrients, the adherence to the user's targets and a chart of the weights logged in the range
+func RenderReportPDF(export *dto.DiaryExportDTO, columns []models.NutrientColumn, targets []dto.NutrientTargetStatusDTO, weights []models.WeightPoint) []byte {
+	layout := &reportLayout{doc: &pdfDocument{}}
+
+	top := layout.reserve(3 * reportLineHeight)
+	layout.doc.text(reportMargin, top-18, 18, true, "Nutrition report")
+	layout.doc.text(reportMargin, top-18-reportLineHeight, 10, false, fmt.Sprintf("%s to %s", export.StartDate, export.EndDate))
+
+	writeDailyTotals(layout, export, columns)
+	writeTargetAdherence(layout, targets)
+	writeWeightChart(layout, export, weights)
+
+	return layout.doc.bytes()
+}
+
+// writeDailyTotals writes a row per logged day and the average over the logged days
+func writeDailyTotals(layout *reportLayout, export *dto.DiaryExportDTO, columns []models.NutrientColumn) {
+	layout.heading("Daily totals")
+	if len(export.Days) == 0 {
+		layout.row(nil, false, "No meals logged in this period")
+		return
+	}
+
+	headers := []string{"Date"}
+	stops := []float64{}
+	for _, code := range reportDailyCodes {
+		for _, column := range columns {
+			if column.Code == code {
+				headers = append(headers, column.Header)
+				stops = append(stops, reportMargin+170+float64(len(stops))*65)
+			}
+		}
+	}
+	layout.row(stops, true, headers...)
+
+	averages := make([]float64, len(headers)-1)
+	for _, day := range export.Days {
+		cells := []string{day.Date}
+		for i, header := range headers[1:] {
+			cells = append(cells, strconv.FormatFloat(day.Nutrients[header], 'f', 1, 64))
+			averages[i] += day.Nutrients[header] / float64(len(export.Days))
+		}
+		layout.row(stops, false, cells...)
+	}
+	cells := []string{fmt.Sprintf("Average of %d days", len(export.Days))}
+	for _, average := range averages {
+		cells = append(cells, strconv.FormatFloat(average, 'f', 1, 64))
+	}
+	layout.row(stops, true, cells...)
+}
+
+// writeTargetAdherence writes how many logged days each targeted nutrient fell below, within or
+// above the user's target range
+func writeTargetAdherence(layout *reportLayout, targets []dto.NutrientTargetStatusDTO) {
+	layout.heading("Target adherence")
+	if len(targets) == 0 {
+		layout.row(nil, false, "No targets apply to the logged days")
+		return
+	}
+
+	stops := []float64{reportMargin + 260, reportMargin + 350, reportMargin + 395, reportMargin + 445, reportMargin + 495}
+	layout.row(stops, true, "Nutrient", "Target", "Average/day", "Below", "Within", "Above")
+	for _, target := range targets {
+		layout.row(stops, false,
+			fmt.Sprintf("%s (%s)", target.NutrientName, target.Unit),
+			formatTargetRange(target.Min, target.Max),
+			strconv.FormatFloat(target.AverageDailyAmount, 'f', 1, 64),
+			strconv.Itoa(target.DaysBelow),
+			strconv.Itoa(target.DaysWithin),
+			strconv.Itoa(target.DaysAbove),
+		)
+	}
+}
+
+// formatTargetRange formats a target range with either bound open
+func formatTargetRange(lower, upper *float64) string {
+	switch {
+	case lower != nil && upper != nil:
+		return fmt.Sprintf("%.0f-%.0f", *lower, *upper)
+	case lower != nil:
+		return fmt.Sprintf(">= %.0f", *lower)
+	case upper != nil:
+		return fmt.Sprintf("<= %.0f", *upper)
+	default:
+		return "-"
+	}
+}
+
+// writeWeightChart plots the weights against the dates of the export range
+func writeWeightChart(layout *reportLayout, export *dto.DiaryExportDTO, weights []models.WeightPoint) {
+	layout.heading("Weight (kg)")
+	if len(weights) == 0 {
+		layout.row(nil, false, "No weight logged in this period")
+		return
+	}
+
+	start, errStart := time.Parse("2006-01-02", export.StartDate)
+	end, errEnd := time.Parse("2006-01-02", export.EndDate)
+	if errStart != nil || errEnd != nil || !end.After(start) {
+		start, end = weightDateSpan(weights)
+	}
+	span := end.Sub(start).Hours()
+
+	low, high := weights[0].Kilograms, weights[0].Kilograms
+	for _, weight := range weights {
+		low, high = min(low, weight.Kilograms), max(high, weight.Kilograms)
+	}
+	// Pad the axis so a flat trend sits mid-chart instead of on an edge
+	low, high = low-1, high+1
+
+	top := layout.reserve(reportChartHeight + 2*reportLineHeight)
+	left, bottom := reportMargin+40, top-reportChartHeight
+	width := reportChartWidth - 40
+	doc := layout.doc
+
+	doc.line(left, bottom, left, top, 0.5)
+	doc.line(left, bottom, left+width, bottom, 0.5)
+	doc.textRight(left-4, top-9, 8, false, strconv.FormatFloat(high, 'f', 1, 64))
+	doc.textRight(left-4, bottom, 8, false, strconv.FormatFloat(low, 'f', 1, 64))
+	doc.text(left, bottom-reportLineHeight, 8, false, start.Format("2006-01-02"))
+	doc.textRight(left+width, bottom-reportLineHeight, 8, false, end.Format("2006-01-02"))
+
+	points := make([][2]float64, 0, len(weights))
+	for _, weight := range weights {
+		x := left
+		if date, err := time.Parse("2006-01-02", weight.Date); err == nil && span > 0 {
+			x = left + width*date.Sub(start).Hours()/span
+		}
+		y := bottom + reportChartHeight*(weight.Kilograms-low)/(high-low)
+		points = append(points, [2]float64{x, y})
+	}
+	doc.gray(0.3)
+	doc.polyline(points, 1)
+	for _, point := range points {
+		doc.square(point[0], point[1], 3)
+	}
+	doc.gray(0)
+}
+
+// weightDateSpan returns the dates of the first and last weights
+func weightDateSpan(weights []models.WeightPoint) (time.Time, time.Time) {
+	first, _ := time.Parse("2006-01-02", weights[0].Date)
+	last, _ := time.Parse("2006-01-02", weights[len(weights)-1].Date)
+	return first, last
+}
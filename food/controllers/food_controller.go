@@ -1,13 +1,17 @@
 package controllers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
 	"github.com/momokapoolz/caloriesapp/food/models"
 	"github.com/momokapoolz/caloriesapp/food/services"
 	"github.com/momokapoolz/caloriesapp/helpers"
+	"gorm.io/gorm"
 )
 
 type FoodController struct {
@@ -19,28 +23,57 @@ func NewFoodController(service *services.FoodService) *FoodController {
 	return &FoodController{service: service}
 }
 
+// writeFoodError maps food service errors to responses; fallback is used for unexpected errors
+func writeFoodError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalidBarcode):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid barcode"})
+	case errors.Is(err, services.ErrInvalidVisibility):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Visibility must be private, shared or verified"})
+	case errors.Is(err, services.ErrVerificationNotAllowed):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only admins can verify foods"})
+	case errors.Is(err, services.ErrFoodNotAccessible):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to modify this food"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Food not found"})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
 // CreateFood godoc
 // @Summary      Create new food
-// @Description  Create a new food record
+// @Description  Create a custom food owned by the authenticated user. Visibility is private (default), shared with every user, or verified (admins only).
 // @Tags         food
 // @Accept       json
 // @Produce      json
 // @Param        food  body      models.Food       true  "Food data"
 // @Success      201   {object}  models.Food       "Food created successfully"
 // @Failure      400   {object}  map[string]string "Invalid request body"
+// @Failure      401   {object}  map[string]string "Unauthorized"
+// @Failure      403   {object}  map[string]string "Only admins can verify foods"
 // @Failure      500   {object}  map[string]string "Internal server error"
 // @Security     BearerAuth
 // @Router       /foods/ [post]
 func (c *FoodController) CreateFood(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	var food models.Food
 	if err := ctx.ShouldBindJSON(&food); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := c.service.CreateFood(&food); err != nil {
-		helpers.LogError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create food"})
+	if err := c.service.CreateFood(&food, viewer); err != nil {
+		writeFoodError(ctx, err, "Failed to create food")
 		return
 	}
 
@@ -49,7 +82,7 @@ func (c *FoodController) CreateFood(ctx *gin.Context) {
 
 // GetFood godoc
 // @Summary      Get a specific food
-// @Description  Retrieve a food record by ID
+// @Description  Retrieve a food record by ID. Private foods of other users are not found.
 // @Tags         food
 // @Accept       json
 // @Produce      json
@@ -61,6 +94,12 @@ func (c *FoodController) CreateFood(ctx *gin.Context) {
 // @Security     BearerAuth
 // @Router       /foods/{id} [get]
 func (c *FoodController) GetFood(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -68,9 +107,37 @@ func (c *FoodController) GetFood(ctx *gin.Context) {
 		return
 	}
 
-	food, err := c.service.GetFoodByID(uint(id))
+	food, err := c.service.GetFoodByID(uint(id), viewer)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Food not found"})
+		writeFoodError(ctx, err, "Failed to retrieve food")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, food)
+}
+
+// GetFoodByBarcode godoc
+// @Summary      Get a food by barcode
+// @Description  Retrieve a packaged food by its GTIN barcode (EAN-8, UPC-A, EAN-13 or GTIN-14). The check digit is validated and UPC-A codes are looked up as EAN-13. Verified foods are preferred over user-entered ones.
+// @Tags         food
+// @Produce      json
+// @Param        code  path      string            true  "Barcode digits"
+// @Success      200   {object}  models.Food       "Food retrieved successfully"
+// @Failure      400   {object}  map[string]string "Invalid barcode"
+// @Failure      404   {object}  map[string]string "Food not found"
+// @Failure      500   {object}  map[string]string "Internal server error"
+// @Security     BearerAuth
+// @Router       /foods/barcode/{code} [get]
+func (c *FoodController) GetFoodByBarcode(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	food, err := c.service.GetFoodByBarcode(ctx.Param("code"), viewer)
+	if err != nil {
+		writeFoodError(ctx, err, "Failed to retrieve food")
 		return
 	}
 
@@ -78,17 +145,61 @@ func (c *FoodController) GetFood(ctx *gin.Context) {
 }
 
 // GetAllFoods godoc
-// @Summary      Get all foods
-// @Description  Retrieve all food records
+// @Summary      Search foods
+// @Description  Search foods by name and brand using full-text search and trigram similarity, so misspelled queries still match. Results are ranked by relevance and paginated with an opaque cursor. Without a query, foods are listed in ID order. Only the user's own foods and shared or verified foods are searched.
 // @Tags         food
 // @Produce      json
-// @Success      200  {array}   models.Food       "List of foods"
-// @Failure      500  {object}  map[string]string "Internal server error"
+// @Param        q       query     string  false  "Search text, e.g. chicken breast"
+// @Param        source  query     string  false  "Source, e.g. usda or off (matches usda:<id>)"
+// @Param        brand   query     string  false  "Brand (case-insensitive)"
+// @Param        min     query     object  false  "Minimum amount per 100 g by nutrient code, e.g. min[protein]=20"
+// @Param        max     query     object  false  "Maximum amount per 100 g by nutrient code, e.g. max[fat]=5"
+// @Param        limit   query     int     false  "Page size (default 20, max 100)"
+// @Param        cursor  query     string  false  "next_cursor of the previous page"
+// @Success      200     {object}  dto.FoodSearchResponseDTO  "Page of foods"
+// @Failure      400     {object}  map[string]string          "Invalid parameters"
+// @Failure      500     {object}  map[string]string          "Internal server error"
 // @Security     BearerAuth
 // @Router       /foods/ [get]
 func (c *FoodController) GetAllFoods(ctx *gin.Context) {
-	foods, err := c.service.GetAllFoods()
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	filter := models.FoodSearchFilter{
+		Query:  ctx.Query("q"),
+		Source: ctx.Query("source"),
+		Brand:  ctx.Query("brand"),
+		Viewer: viewer,
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid limit"})
+			return
+		}
+		filter.Limit = limit
+	}
+
+	var err error
+	if filter.MinNutrients, err = parseNutrientThresholds(ctx.QueryMap("min")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid min threshold: " + err.Error()})
+		return
+	}
+	if filter.MaxNutrients, err = parseNutrientThresholds(ctx.QueryMap("max")); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid max threshold: " + err.Error()})
+		return
+	}
+
+	foods, err := c.service.SearchFoods(filter, ctx.Query("cursor"))
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidCursor) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid cursor"})
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve foods"})
 		return
@@ -97,9 +208,22 @@ func (c *FoodController) GetAllFoods(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, foods)
 }
 
+// parseNutrientThresholds converts query values such as min[protein]=20 into amounts by nutrient code
+func parseNutrientThresholds(values map[string]string) (map[string]float64, error) {
+	thresholds := make(map[string]float64, len(values))
+	for code, value := range values {
+		amount, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%s must be a number", code)
+		}
+		thresholds[code] = amount
+	}
+	return thresholds, nil
+}
+
 // UpdateFood godoc
 // @Summary      Update food
-// @Description  Update an existing food record by ID
+// @Description  Update a food by ID. Owners may update their foods until they are verified; admins may update any visible food. Only admins may set the verified visibility.
 // @Tags         food
 // @Accept       json
 // @Produce      json
@@ -107,10 +231,19 @@ func (c *FoodController) GetAllFoods(ctx *gin.Context) {
 // @Param        food  body      models.Food       true  "Updated food data"
 // @Success      200   {object}  models.Food       "Food updated successfully"
 // @Failure      400   {object}  map[string]string "Invalid ID or request body"
+// @Failure      401   {object}  map[string]string "Unauthorized"
+// @Failure      403   {object}  map[string]string "Forbidden — not the owner"
+// @Failure      404   {object}  map[string]string "Food not found"
 // @Failure      500   {object}  map[string]string "Internal server error"
 // @Security     BearerAuth
 // @Router       /foods/{id} [put]
 func (c *FoodController) UpdateFood(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -125,9 +258,8 @@ func (c *FoodController) UpdateFood(ctx *gin.Context) {
 	}
 
 	food.ID = uint(id)
-	if err := c.service.UpdateFood(&food); err != nil {
-		helpers.LogError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update food"})
+	if err := c.service.UpdateFood(&food, viewer); err != nil {
+		writeFoodError(ctx, err, "Failed to update food")
 		return
 	}
 
@@ -136,16 +268,26 @@ func (c *FoodController) UpdateFood(ctx *gin.Context) {
 
 // DeleteFood godoc
 // @Summary      Delete food
-// @Description  Delete a food record by ID
+// @Description  Delete a food by ID. Owners may delete their foods until they are verified; admins may delete any visible food.
 // @Tags         food
 // @Produce      json
 // @Param        id   path      int               true  "Food ID"
 // @Success      200  {object}  map[string]string "Food deleted successfully"
 // @Failure      400  {object}  map[string]string "Invalid ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized"
+// @Failure      403  {object}  map[string]string "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string "Food not found"
+// @Failure      409  {object}  map[string]string "Food is still used by meal logs or recipes"
 // @Failure      500  {object}  map[string]string "Internal server error"
 // @Security     BearerAuth
 // @Router       /foods/{id} [delete]
 func (c *FoodController) DeleteFood(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -153,11 +295,69 @@ func (c *FoodController) DeleteFood(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.service.DeleteFood(uint(id)); err != nil {
-		helpers.LogError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete food"})
+	if err := c.service.DeleteFood(uint(id), viewer); err != nil {
+		writeFoodError(ctx, err, "Failed to delete food")
 		return
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Food deleted successfully"})
 }
+
+// VerifyFood godoc
+// @Summary      Verify food
+// @Description  Mark a shared food as verified, making it part of the public catalog (admin only)
+// @Tags         food
+// @Produce      json
+// @Param        id   path      int               true  "Food ID"
+// @Success      200  {object}  models.Food       "Food verified successfully"
+// @Failure      400  {object}  map[string]string "Invalid ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized"
+// @Failure      403  {object}  map[string]string "Forbidden"
+// @Failure      404  {object}  map[string]string "Food not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/foods/{id}/verify [put]
+func (c *FoodController) VerifyFood(ctx *gin.Context) {
+	c.setFoodVerified(ctx, true)
+}
+
+// UnverifyFood godoc
+// @Summary      Unverify food
+// @Description  Return a verified food to shared visibility (admin only)
+// @Tags         food
+// @Produce      json
+// @Param        id   path      int               true  "Food ID"
+// @Success      200  {object}  models.Food       "Food verification removed successfully"
+// @Failure      400  {object}  map[string]string "Invalid ID format"
+// @Failure      401  {object}  map[string]string "Unauthorized"
+// @Failure      403  {object}  map[string]string "Forbidden"
+// @Failure      404  {object}  map[string]string "Food not found"
+// @Failure      500  {object}  map[string]string "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/foods/{id}/verify [delete]
+func (c *FoodController) UnverifyFood(ctx *gin.Context) {
+	c.setFoodVerified(ctx, false)
+}
+
+// setFoodVerified handles both verification endpoints
+func (c *FoodController) setFoodVerified(ctx *gin.Context, verified bool) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	food, err := c.service.SetFoodVerified(uint(id), verified, viewer)
+	if err != nil {
+		writeFoodError(ctx, err, "Failed to update food verification")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, food)
+}
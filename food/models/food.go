@@ -1,15 +1,50 @@
 package models
 
+// Food visibility levels
+const (
+	// VisibilityPrivate foods are only visible to their owner
+	VisibilityPrivate = "private"
+	// VisibilityShared foods are visible to every user but not reviewed
+	VisibilityShared = "shared"
+	// VisibilityVerified foods are public and were reviewed by an admin; imported foods are verified
+	VisibilityVerified = "verified"
+)
+
 // Food represents the food table in the database
 type Food struct {
 	ID              uint    `gorm:"primaryKey;column:id" json:"id"`
 	Name            string  `gorm:"column:name;not null" json:"name"`
+	Brand           string  `gorm:"column:brand;not null;default:''" json:"brand"`
 	ServingSizeGram float64 `gorm:"column:serving_size_gram;not null" json:"serving_size_gram"`
-	Source          string  `gorm:"column:source;not null" json:"source"`
+	Source          string  `gorm:"column:source;not null;index:idx_food_source" json:"source"`
 	ImageURL        string  `gorm:"column:image_url" json:"image_url"`
+	Barcode         string  `gorm:"column:barcode;type:varchar(14);not null;default:'';index:idx_food_barcode" json:"barcode"`
+	OwnerID         *uint   `gorm:"column:owner_id;index:idx_food_owner" json:"owner_id"`
+	Visibility      string  `gorm:"column:visibility;type:varchar(16);not null;default:'verified'" json:"visibility"`
 }
 
 // TableName specifies the table name for the Food model
 func (Food) TableName() string {
 	return "food"
 }
+
+// FoodViewer identifies the user a food query runs for, so results can be
+// limited to the foods that user may see
+type FoodViewer struct {
+	UserID  uint
+	IsAdmin bool
+}
+
+// Owns reports whether the viewer created the food
+func (v FoodViewer) Owns(food *Food) bool {
+	return food.OwnerID != nil && *food.OwnerID == v.UserID
+}
+
+// CanModify reports whether the viewer may edit or delete the food. Owners may
+// change their foods until they are verified; admins may change any food they can see.
+func (v FoodViewer) CanModify(food *Food) bool {
+	if v.IsAdmin {
+		return true
+	}
+	return v.Owns(food) && food.Visibility != VisibilityVerified
+}
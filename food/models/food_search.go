@@ -0,0 +1,26 @@
+package models
+
+// FoodSearchFilter holds the criteria of a food search. Nutrient thresholds are keyed
+// by nutrient code and expressed per 100 g in the unit of the nutrient.
+type FoodSearchFilter struct {
+	Query        string
+	Source       string
+	Brand        string
+	MinNutrients map[string]float64
+	MaxNutrients map[string]float64
+	Limit        int
+	After        *FoodSearchCursor
+	Viewer       FoodViewer
+}
+
+// FoodSearchCursor marks the last result of a page; the next page starts after it
+type FoodSearchCursor struct {
+	Score float64 `json:"s"`
+	ID    uint    `json:"id"`
+}
+
+// FoodSearchResult is a food matched by a search together with its relevance score
+type FoodSearchResult struct {
+	Food  `gorm:"embedded"`
+	Score float64 `gorm:"column:score" json:"score"`
+}
@@ -1,8 +1,12 @@
 package repository
 
 import (
+	"sort"
+
+	"github.com/momokapoolz/caloriesapp/database"
 	"github.com/momokapoolz/caloriesapp/food/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // FoodRepository handles all database operations for the Food model
@@ -15,9 +19,14 @@ func NewFoodRepository(db *gorm.DB) *FoodRepository {
 	return &FoodRepository{db: db}
 }
 
+// WithTx returns a repository running its queries in the transaction tx
+func (r *FoodRepository) WithTx(tx *gorm.DB) *FoodRepository {
+	return &FoodRepository{db: tx}
+}
+
 // Create adds a new food record to the database
 func (r *FoodRepository) Create(food *models.Food) error {
-	return r.db.Create(food).Error
+	return database.TranslateError(r.db.Create(food).Error)
 }
 
 // GetByID retrieves a food by its ID
@@ -30,19 +39,139 @@ func (r *FoodRepository) GetByID(id uint) (*models.Food, error) {
 	return &food, nil
 }
 
-// GetAll retrieves all foods
-func (r *FoodRepository) GetAll() ([]models.Food, error) {
+// GetVisibleByID retrieves a food by its ID if the viewer may see it
+func (r *FoodRepository) GetVisibleByID(id uint, viewer models.FoodViewer) (*models.Food, error) {
+	var food models.Food
+	err := r.db.Scopes(VisibleTo(viewer)).Where("id = ?", id).First(&food).Error
+	if err != nil {
+		return nil, err
+	}
+	return &food, nil
+}
+
+// GetByIDs retrieves several foods in a single query
+func (r *FoodRepository) GetByIDs(ids []uint) ([]models.Food, error) {
 	var foods []models.Food
-	err := r.db.Find(&foods).Error
+	if len(ids) == 0 {
+		return foods, nil
+	}
+	err := r.db.Where("id IN ?", ids).Find(&foods).Error
 	return foods, err
 }
 
+// LockVisibleByIDs retrieves the foods among ids that the viewer may see and locks them against
+// deletion until the transaction ends
+func (r *FoodRepository) LockVisibleByIDs(ids []uint, viewer models.FoodViewer) ([]models.Food, error) {
+	var foods []models.Food
+	if len(ids) == 0 {
+		return foods, nil
+	}
+	err := r.db.Clauses(clause.Locking{Strength: "SHARE"}).Scopes(VisibleTo(viewer)).Where("id IN ?", ids).Find(&foods).Error
+	return foods, err
+}
+
+// GetVisibleByIDs retrieves the foods among ids that the viewer may see
+func (r *FoodRepository) GetVisibleByIDs(ids []uint, viewer models.FoodViewer) ([]models.Food, error) {
+	var foods []models.Food
+	if len(ids) == 0 {
+		return foods, nil
+	}
+	err := r.db.Scopes(VisibleTo(viewer)).Where("id IN ?", ids).Find(&foods).Error
+	return foods, err
+}
+
+// GetByBarcode retrieves the food carrying the given normalized barcode among those
+// the viewer may see, preferring verified foods over user-entered ones
+func (r *FoodRepository) GetByBarcode(barcode string, viewer models.FoodViewer) (*models.Food, error) {
+	var food models.Food
+	err := r.db.Scopes(VisibleTo(viewer)).
+		Where("barcode = ?", barcode).
+		Order("visibility = '" + models.VisibilityVerified + "' DESC, id").
+		First(&food).Error
+	if err != nil {
+		return nil, err
+	}
+	return &food, nil
+}
+
+// foodSearchDocument is the text searched by the full-text index on food
+const foodSearchDocument = "to_tsvector('english', food.name || ' ' || food.brand)"
+
+// Search retrieves a page of foods matching the filter, best matches first. With a query,
+// foods match on full-text search or trigram similarity of their name, so misspelled
+// queries still find results; without one, foods are returned in ID order.
+func (r *FoodRepository) Search(filter models.FoodSearchFilter) ([]models.FoodSearchResult, error) {
+	query := r.db.Model(&models.Food{}).Scopes(VisibleTo(filter.Viewer))
+
+	if filter.Query != "" {
+		query = query.
+			Select("food.*, (ts_rank("+foodSearchDocument+", plainto_tsquery('english', ?)) + similarity(food.name, ?))::float8 AS score", filter.Query, filter.Query).
+			Where(foodSearchDocument+" @@ plainto_tsquery('english', ?) OR food.name % ? OR ? <% food.name", filter.Query, filter.Query, filter.Query)
+	} else {
+		query = query.Select("food.*, 0::float8 AS score")
+	}
+
+	if filter.Source != "" {
+		query = query.Where("food.source = ? OR food.source LIKE ?", filter.Source, filter.Source+":%")
+	}
+	if filter.Brand != "" {
+		query = query.Where("LOWER(food.brand) = LOWER(?)", filter.Brand)
+	}
+	for _, code := range sortedCodes(filter.MinNutrients) {
+		query = query.Where(nutrientThreshold(">="), code, filter.MinNutrients[code])
+	}
+	for _, code := range sortedCodes(filter.MaxNutrients) {
+		query = query.Where(nutrientThreshold("<="), code, filter.MaxNutrients[code])
+	}
+
+	page := r.db.Table("(?) AS ranked", query)
+	if filter.After != nil {
+		page = page.Where("score < ? OR (score = ? AND id > ?)", filter.After.Score, filter.After.Score, filter.After.ID)
+	}
+
+	var results []models.FoodSearchResult
+	err := page.Order("score DESC, id ASC").Limit(filter.Limit).Find(&results).Error
+	return results, err
+}
+
+// nutrientThreshold builds a condition comparing the per 100 g amount of a nutrient, given by code, with a value
+func nutrientThreshold(operator string) string {
+	return "EXISTS (SELECT 1 FROM food_nutrients fn JOIN nutrient n ON n.id = fn.nutrient_id" +
+		" WHERE fn.food_id = food.id AND n.code = ? AND fn.amount_per_100g " + operator + " ?)"
+}
+
+// sortedCodes returns the nutrient codes of thresholds in a stable order, so equal filters produce equal SQL
+func sortedCodes(thresholds map[string]float64) []string {
+	codes := make([]string, 0, len(thresholds))
+	for code := range thresholds {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// GetAll retrieves all foods the viewer may see
+func (r *FoodRepository) GetAll(viewer models.FoodViewer) ([]models.Food, error) {
+	var foods []models.Food
+	err := r.db.Scopes(VisibleTo(viewer)).Find(&foods).Error
+	return foods, err
+}
+
+// VisibleTo limits a food query to the foods the viewer may see: their own foods
+// plus every shared or verified food. Private foods stay private from admins too.
+func VisibleTo(viewer models.FoodViewer) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("food.visibility IN ? OR food.owner_id = ?",
+			[]string{models.VisibilityShared, models.VisibilityVerified}, viewer.UserID)
+	}
+}
+
 // Update updates a food record
 func (r *FoodRepository) Update(food *models.Food) error {
-	return r.db.Save(food).Error
+	return database.TranslateError(r.db.Save(food).Error)
 }
 
 // Delete removes a food record
 func (r *FoodRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Food{}, id).Error
+	return database.TranslateError(r.db.Delete(&models.Food{}, id).Error)
 } 
\ No newline at end of file
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
 	"github.com/momokapoolz/caloriesapp/food/controllers"
 	"github.com/momokapoolz/caloriesapp/food/repository"
 	"github.com/momokapoolz/caloriesapp/food/services"
@@ -14,12 +15,21 @@ func SetupFoodRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	foodService := services.NewFoodService(foodRepo)
 	foodController := controllers.NewFoodController(foodService)
 
-	foodRoutes := router.Group("/foods")
+	authMiddleware := auth.NewAuthMiddleware()
+
+	foodRoutes := router.Group("/foods", authMiddleware.RequireAuth())
 	{
 		foodRoutes.POST("/", foodController.CreateFood)
 		foodRoutes.GET("/", foodController.GetAllFoods)
+		foodRoutes.GET("/barcode/:code", foodController.GetFoodByBarcode)
 		foodRoutes.GET("/:id", foodController.GetFood)
 		foodRoutes.PUT("/:id", foodController.UpdateFood)
 		foodRoutes.DELETE("/:id", foodController.DeleteFood)
 	}
-} 
\ No newline at end of file
+
+	adminRoutes := router.Group("/admin/foods", authMiddleware.RequireAuth(), authMiddleware.RequireRole("admin"))
+	{
+		adminRoutes.PUT("/:id/verify", foodController.VerifyFood)
+		adminRoutes.DELETE("/:id/verify", foodController.UnverifyFood)
+	}
+}
\ No newline at end of file
@@ -0,0 +1,60 @@
+package services
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrInvalidBarcode is returned for barcodes that are not a valid GTIN
+var ErrInvalidBarcode = errors.New("invalid barcode")
+
+// NormalizeBarcode validates a GTIN barcode (EAN-8, UPC-A, EAN-13 or GTIN-14) and
+// returns it in the form stored on foods. UPC-A codes are widened to EAN-13 by
+// prefixing a zero and GTIN-14 codes with a leading zero are narrowed to EAN-13,
+// so the same product is found however it was scanned.
+func NormalizeBarcode(code string) (string, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return "", ErrInvalidBarcode
+	}
+	for _, r := range code {
+		if r < '0' || r > '9' {
+			return "", ErrInvalidBarcode
+		}
+	}
+
+	switch len(code) {
+	case 8, 13:
+	case 12:
+		code = "0" + code
+	case 14:
+		if code[0] == '0' {
+			code = code[1:]
+		}
+	default:
+		return "", ErrInvalidBarcode
+	}
+
+	if err := validateCheckDigit(code); err != nil {
+		return "", err
+	}
+	return code, nil
+}
+
+// validateCheckDigit verifies the GS1 mod 10 check digit of a numeric code
+func validateCheckDigit(code string) error {
+	sum := 0
+	// Weights alternate 3, 1, ... starting from the digit left of the check digit
+	for i := len(code) - 2; i >= 0; i-- {
+		digit := int(code[i] - '0')
+		if (len(code)-2-i)%2 == 0 {
+			digit *= 3
+		}
+		sum += digit
+	}
+
+	if (10-sum%10)%10 != int(code[len(code)-1]-'0') {
+		return ErrInvalidBarcode
+	}
+	return nil
+}
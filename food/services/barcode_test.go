@@ -0,0 +1,29 @@
+package services
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizeBarcode(t *testing.T) {
+	cases := []struct {
+		code string
+		want string
+		err  error
+	}{
+		{"4006381333931", "4006381333931", nil},  // EAN-13
+		{"036000291452", "0036000291452", nil},   // UPC-A widened to EAN-13
+		{"00036000291452", "0036000291452", nil}, // GTIN-14 with leading zero
+		{"96385074", "96385074", nil},            // EAN-8
+		{"4006381333932", "", ErrInvalidBarcode}, // wrong check digit
+		{"03600029145", "", ErrInvalidBarcode},   // wrong length
+		{"03600029145X", "", ErrInvalidBarcode},  // not numeric
+	}
+
+	for _, c := range cases {
+		got, err := NormalizeBarcode(c.code)
+		if got != c.want || !errors.Is(err, c.err) {
+			t.Errorf("NormalizeBarcode(%q) = %q, %v; want %q, %v", c.code, got, err, c.want, c.err)
+		}
+	}
+}
@@ -1,10 +1,35 @@
 package services
 
 import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/momokapoolz/caloriesapp/dto"
 	"github.com/momokapoolz/caloriesapp/food/models"
 	"github.com/momokapoolz/caloriesapp/food/repository"
 )
 
+const (
+	// DefaultSearchLimit is the page size of a food search when none is requested
+	DefaultSearchLimit = 20
+	// MaxSearchLimit is the largest page size a food search returns
+	MaxSearchLimit = 100
+)
+
+// Error definitions
+var (
+	// ErrInvalidCursor is returned for search cursors that were not produced by SearchFoods
+	ErrInvalidCursor = errors.New("invalid cursor")
+	// ErrInvalidVisibility is returned for visibilities other than private, shared and verified
+	ErrInvalidVisibility = errors.New("invalid visibility")
+	// ErrFoodNotAccessible is returned when the user may see a food but not change it
+	ErrFoodNotAccessible = errors.New("not allowed to modify this food")
+	// ErrVerificationNotAllowed is returned when a non-admin tries to verify a food
+	ErrVerificationNotAllowed = errors.New("only admins can verify foods")
+)
+
 // FoodService handles business logic for food operations
 type FoodService struct {
 	repo *repository.FoodRepository
@@ -15,27 +40,200 @@ func NewFoodService(repo *repository.FoodRepository) *FoodService {
 	return &FoodService{repo: repo}
 }
 
-// CreateFood creates a new food record
-func (s *FoodService) CreateFood(food *models.Food) error {
+// CreateFood creates a custom food owned by the viewer. Foods are private unless
+// another visibility is requested; only admins may create verified foods.
+func (s *FoodService) CreateFood(food *models.Food, viewer models.FoodViewer) error {
+	if food.Visibility == "" {
+		food.Visibility = models.VisibilityPrivate
+	}
+	if err := ValidateVisibility(food.Visibility, viewer); err != nil {
+		return err
+	}
+	if err := normalizeFoodBarcode(food); err != nil {
+		return err
+	}
+	food.ID = 0
+	food.OwnerID = &viewer.UserID
 	return s.repo.Create(food)
 }
 
-// GetFoodByID retrieves a food record by ID
-func (s *FoodService) GetFoodByID(id uint) (*models.Food, error) {
-	return s.repo.GetByID(id)
+// GetFoodByID retrieves a food record by ID if the viewer may see it
+func (s *FoodService) GetFoodByID(id uint, viewer models.FoodViewer) (*models.Food, error) {
+	return s.repo.GetVisibleByID(id, viewer)
+}
+
+// GetAllFoods retrieves all food records the viewer may see
+func (s *FoodService) GetAllFoods(viewer models.FoodViewer) ([]models.Food, error) {
+	return s.repo.GetAll(viewer)
 }
 
-// GetAllFoods retrieves all food records
-func (s *FoodService) GetAllFoods() ([]models.Food, error) {
-	return s.repo.GetAll()
+// SearchFoods returns one page of foods matching the filter, best matches first.
+// cursor is the NextCursor of the previous page, or empty for the first page.
+// Only foods visible to filter.Viewer are returned.
+func (s *FoodService) SearchFoods(filter models.FoodSearchFilter, cursor string) (*dto.FoodSearchResponseDTO, error) {
+	filter.Query = strings.TrimSpace(filter.Query)
+	if filter.Limit <= 0 {
+		filter.Limit = DefaultSearchLimit
+	}
+	if filter.Limit > MaxSearchLimit {
+		filter.Limit = MaxSearchLimit
+	}
+
+	if cursor != "" {
+		after, err := decodeSearchCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = after
+	}
+
+	// Fetch one extra row to know whether another page follows
+	limit := filter.Limit
+	filter.Limit++
+	results, err := s.repo.Search(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &dto.FoodSearchResponseDTO{Foods: make([]dto.FoodSearchItemDTO, 0, limit)}
+	for i, result := range results {
+		if i == limit {
+			last := results[limit-1]
+			response.NextCursor = encodeSearchCursor(models.FoodSearchCursor{Score: last.Score, ID: last.ID})
+			break
+		}
+		response.Foods = append(response.Foods, dto.FoodSearchItemDTO{
+			ID:              result.ID,
+			Name:            result.Name,
+			Brand:           result.Brand,
+			ServingSizeGram: result.ServingSizeGram,
+			Source:          result.Source,
+			ImageURL:        result.ImageURL,
+			Barcode:         result.Barcode,
+			OwnerID:         result.OwnerID,
+			Visibility:      result.Visibility,
+			Score:           result.Score,
+		})
+	}
+
+	return response, nil
 }
 
-// UpdateFood updates a food record
-func (s *FoodService) UpdateFood(food *models.Food) error {
+// GetFoodByBarcode retrieves a food by its GTIN barcode after validating and normalizing it
+func (s *FoodService) GetFoodByBarcode(code string, viewer models.FoodViewer) (*models.Food, error) {
+	barcode, err := NormalizeBarcode(code)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByBarcode(barcode, viewer)
+}
+
+// UpdateFood updates a food the viewer may modify. The owner never changes and the
+// visibility is kept when none is given.
+func (s *FoodService) UpdateFood(food *models.Food, viewer models.FoodViewer) error {
+	existing, err := s.modifiableFood(food.ID, viewer)
+	if err != nil {
+		return err
+	}
+	if food.Visibility == "" {
+		food.Visibility = existing.Visibility
+	}
+	if err := ValidateVisibility(food.Visibility, viewer); err != nil {
+		return err
+	}
+	if err := normalizeFoodBarcode(food); err != nil {
+		return err
+	}
+	food.OwnerID = existing.OwnerID
 	return s.repo.Update(food)
 }
 
-// DeleteFood removes a food record
-func (s *FoodService) DeleteFood(id uint) error {
+// DeleteFood removes a food the viewer may modify
+func (s *FoodService) DeleteFood(id uint, viewer models.FoodViewer) error {
+	if _, err := s.modifiableFood(id, viewer); err != nil {
+		return err
+	}
 	return s.repo.Delete(id)
-} 
\ No newline at end of file
+}
+
+// SetFoodVerified marks a food verified, or returns a verified food to shared.
+// Only admins may verify foods, and only among the foods they can see, so other
+// users' private foods must be shared by their owner first.
+func (s *FoodService) SetFoodVerified(id uint, verified bool, viewer models.FoodViewer) (*models.Food, error) {
+	if !viewer.IsAdmin {
+		return nil, ErrVerificationNotAllowed
+	}
+	food, err := s.repo.GetVisibleByID(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	if verified {
+		food.Visibility = models.VisibilityVerified
+	} else if food.Visibility == models.VisibilityVerified {
+		food.Visibility = models.VisibilityShared
+	}
+	if err := s.repo.Update(food); err != nil {
+		return nil, err
+	}
+	return food, nil
+}
+
+// modifiableFood loads a food and checks that the viewer may change it
+func (s *FoodService) modifiableFood(id uint, viewer models.FoodViewer) (*models.Food, error) {
+	food, err := s.repo.GetVisibleByID(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if !viewer.CanModify(food) {
+		return nil, ErrFoodNotAccessible
+	}
+	return food, nil
+}
+
+// ValidateVisibility validates a requested visibility; only admins may verify foods
+func ValidateVisibility(visibility string, viewer models.FoodViewer) error {
+	switch visibility {
+	case models.VisibilityPrivate, models.VisibilityShared:
+		return nil
+	case models.VisibilityVerified:
+		if !viewer.IsAdmin {
+			return ErrVerificationNotAllowed
+		}
+		return nil
+	default:
+		return ErrInvalidVisibility
+	}
+}
+
+// normalizeFoodBarcode stores an optional barcode in its normalized form
+func normalizeFoodBarcode(food *models.Food) error {
+	if food.Barcode == "" {
+		return nil
+	}
+	barcode, err := NormalizeBarcode(food.Barcode)
+	if err != nil {
+		return err
+	}
+	food.Barcode = barcode
+	return nil
+}
+
+// encodeSearchCursor turns the position of the last result of a page into an opaque cursor
+func encodeSearchCursor(cursor models.FoodSearchCursor) string {
+	data, _ := json.Marshal(cursor)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeSearchCursor parses a cursor produced by encodeSearchCursor
+func decodeSearchCursor(cursor string) (*models.FoodSearchCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var after models.FoodSearchCursor
+	if err := json.Unmarshal(data, &after); err != nil || after.ID == 0 {
+		return nil, ErrInvalidCursor
+	}
+	return &after, nil
+}
@@ -0,0 +1,64 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/momokapoolz/caloriesapp/food/models"
+)
+
+func TestSearchCursorRoundTrip(t *testing.T) {
+	// Scores come back from Postgres as float8 and must survive the round trip exactly,
+	// otherwise the keyset condition skips or repeats rows
+	want := models.FoodSearchCursor{Score: 0.1 + 0.2, ID: 42}
+
+	got, err := decodeSearchCursor(encodeSearchCursor(want))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *got != want {
+		t.Fatalf("cursor changed in round trip: got %+v, want %+v", *got, want)
+	}
+
+	if _, err := decodeSearchCursor("not a cursor"); !errors.Is(err, ErrInvalidCursor) {
+		t.Fatalf("expected ErrInvalidCursor, got %v", err)
+	}
+}
+
+func TestValidateVisibility(t *testing.T) {
+	user := models.FoodViewer{UserID: 1}
+	admin := models.FoodViewer{UserID: 2, IsAdmin: true}
+
+	if err := ValidateVisibility(models.VisibilityShared, user); err != nil {
+		t.Fatalf("users may share their foods, got %v", err)
+	}
+	if err := ValidateVisibility(models.VisibilityVerified, user); !errors.Is(err, ErrVerificationNotAllowed) {
+		t.Fatalf("expected ErrVerificationNotAllowed, got %v", err)
+	}
+	if err := ValidateVisibility(models.VisibilityVerified, admin); err != nil {
+		t.Fatalf("admins may verify foods, got %v", err)
+	}
+	if err := ValidateVisibility("public", admin); !errors.Is(err, ErrInvalidVisibility) {
+		t.Fatalf("expected ErrInvalidVisibility, got %v", err)
+	}
+}
+
+func TestFoodViewerCanModify(t *testing.T) {
+	owner := uint(1)
+	food := &models.Food{OwnerID: &owner, Visibility: models.VisibilityShared}
+
+	if !(models.FoodViewer{UserID: 1}).CanModify(food) {
+		t.Fatal("owners may modify their unverified foods")
+	}
+	if (models.FoodViewer{UserID: 3}).CanModify(food) {
+		t.Fatal("other users must not modify a shared food")
+	}
+
+	food.Visibility = models.VisibilityVerified
+	if (models.FoodViewer{UserID: 1}).CanModify(food) {
+		t.Fatal("owners must not modify a verified food")
+	}
+	if !(models.FoodViewer{UserID: 3, IsAdmin: true}).CanModify(food) {
+		t.Fatal("admins may modify verified foods")
+	}
+}
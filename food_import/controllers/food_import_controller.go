@@ -0,0 +1,134 @@
+package controllers
+
+import (
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/food_import/services"
+	"github.com/momokapoolz/caloriesapp/helpers"
+)
+
+type FoodImportController struct {
+	usdaService *services.USDAImportService
+	offService  *services.OpenFoodFactsImportService
+}
+
+// NewFoodImportController creates a new food import controller instance
+func NewFoodImportController(usdaService *services.USDAImportService, offService *services.OpenFoodFactsImportService) *FoodImportController {
+	return &FoodImportController{usdaService: usdaService, offService: offService}
+}
+
+// ImportUSDA godoc
+// @Summary      Import USDA FoodData Central foods
+// @Description  Upsert foods and nutrient profiles from an uploaded FoodData Central download (JSON file or zipped CSV). Admin only.
+// @Tags         food_import
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file                     true  "FDC download (.json or .zip)"
+// @Success      200   {object}  dto.FoodImportResultDTO  "Import summary"
+// @Failure      400   {object}  map[string]string        "Missing or unsupported file"
+// @Failure      401   {object}  map[string]string        "Unauthorized"
+// @Failure      403   {object}  map[string]string        "Forbidden"
+// @Failure      500   {object}  map[string]string        "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/foods/import/usda [post]
+func (c *FoodImportController) ImportUSDA(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A file upload is required"})
+		return
+	}
+
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	if ext != ".json" && ext != ".zip" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported file type. Upload a .json or .zip FDC download"})
+		return
+	}
+
+	// The importer reads zip archives randomly, so the upload is stored on disk first
+	path, err := saveUpload(ctx, fileHeader, ext)
+	if err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload"})
+		return
+	}
+	defer os.Remove(path)
+
+	result, err := c.usdaService.ImportPath(path)
+	if err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import foods: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// ImportOpenFoodFacts godoc
+// @Summary      Import Open Food Facts products
+// @Description  Upsert packaged foods, their barcodes and per-100g nutrients from an uploaded Open Food Facts dump (.jsonl or tab separated .csv, optionally gzipped). Admin only.
+// @Tags         food_import
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file                     true  "OFF dump (.jsonl, .csv, .jsonl.gz or .csv.gz)"
+// @Success      200   {object}  dto.FoodImportResultDTO  "Import summary"
+// @Failure      400   {object}  map[string]string        "Missing or unsupported file"
+// @Failure      401   {object}  map[string]string        "Unauthorized"
+// @Failure      403   {object}  map[string]string        "Forbidden"
+// @Failure      500   {object}  map[string]string        "Internal server error"
+// @Security     BearerAuth
+// @Router       /admin/foods/import/off [post]
+func (c *FoodImportController) ImportOpenFoodFacts(ctx *gin.Context) {
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A file upload is required"})
+		return
+	}
+
+	name := strings.ToLower(fileHeader.Filename)
+	suffix := filepath.Ext(strings.TrimSuffix(name, ".gz"))
+	if suffix != ".jsonl" && suffix != ".csv" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported file type. Upload a .jsonl or .csv OFF dump, optionally gzipped"})
+		return
+	}
+	if strings.HasSuffix(name, ".gz") {
+		suffix += ".gz"
+	}
+
+	// The importer picks the format from the file name, so the upload keeps its extension on disk
+	path, err := saveUpload(ctx, fileHeader, suffix)
+	if err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload"})
+		return
+	}
+	defer os.Remove(path)
+
+	result, err := c.offService.ImportPath(path)
+	if err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import foods: " + err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// saveUpload stores an uploaded file in a temporary file with the given suffix and returns its path
+func saveUpload(ctx *gin.Context, fileHeader *multipart.FileHeader, suffix string) (string, error) {
+	tmp, err := os.CreateTemp("", "food-import-*"+suffix)
+	if err != nil {
+		return "", err
+	}
+	tmp.Close()
+
+	if err := ctx.SaveUploadedFile(fileHeader, tmp.Name()); err != nil {
+		os.Remove(tmp.Name())
+		return "", err
+	}
+	return tmp.Name(), nil
+}
@@ -0,0 +1,22 @@
+package models
+
+import (
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+)
+
+// ImportedFood is a food read from an external database together with its
+// nutrient profile. Food.Source identifies the record in that database and is
+// used to find the row again when the import is re-run.
+type ImportedFood struct {
+	Food      foodModels.Food
+	Nutrients []foodNutrientsModels.FoodNutrient
+}
+
+// ImportBatchResult reports what happened to one batch of imported foods.
+// ChangedFoodIDs lists the updated foods whose nutrient profile changed.
+type ImportBatchResult struct {
+	Created        int
+	Updated        int
+	ChangedFoodIDs []uint
+}
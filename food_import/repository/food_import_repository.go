@@ -0,0 +1,129 @@
+package repository
+
+import (
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	"github.com/momokapoolz/caloriesapp/food_import/models"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	"gorm.io/gorm"
+)
+
+// FoodImportRepository handles the database operations of bulk food imports
+type FoodImportRepository struct {
+	db *gorm.DB
+}
+
+// NewFoodImportRepository creates a new food import repository instance
+func NewFoodImportRepository(db *gorm.DB) *FoodImportRepository {
+	return &FoodImportRepository{db: db}
+}
+
+// UpsertFoods inserts or updates a batch of foods, matched on their source, and
+// replaces their nutrient profiles in a single transaction. The daily nutrition
+// rollup of the days logging a food whose profile changed is marked stale in the
+// same transaction, so it is never served from the old profile.
+func (r *FoodImportRepository) UpsertFoods(foods []models.ImportedFood) (*models.ImportBatchResult, error) {
+	result := &models.ImportBatchResult{}
+	if len(foods) == 0 {
+		return result, nil
+	}
+
+	sources := make([]string, 0, len(foods))
+	for _, imported := range foods {
+		sources = append(sources, imported.Food.Source)
+	}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []foodModels.Food
+		// User-owned foods may reuse a source string; imports only ever update catalog foods
+		if err := tx.Where("source IN ? AND owner_id IS NULL", sources).Find(&existing).Error; err != nil {
+			return err
+		}
+		existingBySource := make(map[string]foodModels.Food, len(existing))
+		existingIDs := make([]uint, 0, len(existing))
+		for _, food := range existing {
+			existingBySource[food.Source] = food
+			existingIDs = append(existingIDs, food.ID)
+		}
+
+		var currentNutrients []foodNutrientsModels.FoodNutrient
+		if len(existingIDs) > 0 {
+			if err := tx.Where("food_id IN ?", existingIDs).Find(&currentNutrients).Error; err != nil {
+				return err
+			}
+		}
+		currentProfiles := make(map[uint][]foodNutrientsModels.FoodNutrient, len(existingIDs))
+		for _, nutrient := range currentNutrients {
+			currentProfiles[nutrient.FoodID] = append(currentProfiles[nutrient.FoodID], nutrient)
+		}
+
+		for _, imported := range foods {
+			food := imported.Food
+			if current, ok := existingBySource[food.Source]; ok {
+				food.ID = current.ID
+				if food.ImageURL == "" {
+					food.ImageURL = current.ImageURL
+				}
+				if food.Barcode == "" {
+					food.Barcode = current.Barcode
+				}
+				if err := tx.Save(&food).Error; err != nil {
+					return err
+				}
+				result.Updated++
+				// An unchanged profile is kept as is, so nothing derived from it needs recomputing
+				if sameProfile(currentProfiles[food.ID], imported.Nutrients) {
+					continue
+				}
+				if err := tx.Where("food_id = ?", food.ID).Delete(&foodNutrientsModels.FoodNutrient{}).Error; err != nil {
+					return err
+				}
+				result.ChangedFoodIDs = append(result.ChangedFoodIDs, food.ID)
+			} else {
+				if err := tx.Create(&food).Error; err != nil {
+					return err
+				}
+				existingBySource[food.Source] = food
+				result.Created++
+			}
+
+			if len(imported.Nutrients) == 0 {
+				continue
+			}
+			nutrients := make([]foodNutrientsModels.FoodNutrient, len(imported.Nutrients))
+			for i, nutrient := range imported.Nutrients {
+				nutrient.ID = 0
+				nutrient.FoodID = food.ID
+				nutrients[i] = nutrient
+			}
+			if err := tx.Create(&nutrients).Error; err != nil {
+				return err
+			}
+		}
+
+		return dailyNutritionRepo.NewDailyNutritionRepository(tx).MarkFoodsStale(result.ChangedFoodIDs)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// sameProfile reports whether two nutrient profiles hold the same amount of every nutrient
+func sameProfile(current, imported []foodNutrientsModels.FoodNutrient) bool {
+	if len(current) != len(imported) {
+		return false
+	}
+	amounts := make(map[uint]float64, len(current))
+	for _, nutrient := range current {
+		amounts[nutrient.NutrientID] = nutrient.AmountPer100g
+	}
+	for _, nutrient := range imported {
+		amount, ok := amounts[nutrient.NutrientID]
+		if !ok || amount != nutrient.AmountPer100g {
+			return false
+		}
+	}
+	return true
+}
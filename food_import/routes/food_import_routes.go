@@ -0,0 +1,47 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	"github.com/momokapoolz/caloriesapp/food_import/controllers"
+	"github.com/momokapoolz/caloriesapp/food_import/repository"
+	"github.com/momokapoolz/caloriesapp/food_import/services"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	recipeRepo "github.com/momokapoolz/caloriesapp/recipe/repository"
+	recipeServices "github.com/momokapoolz/caloriesapp/recipe/services"
+	"gorm.io/gorm"
+)
+
+// SetupFoodImportRoutes initializes the admin-only food import routes
+func SetupFoodImportRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	nutrientRepository := nutrientRepo.NewNutrientRepository(db)
+	foodRepository := foodRepo.NewFoodRepository(db)
+	foodNutrientRepository := foodNutrientsRepo.NewFoodNutrientRepository(db)
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepo.NewMealLogItemRepository(db),
+		foodRepository,
+		foodNutrientRepository,
+		nutrientRepository,
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(dailyNutritionRepo.NewDailyNutritionRepository(db), mealLogRepo.NewMealLogRepository(db), engine)
+	recipes := recipeServices.NewRecipeService(recipeRepo.NewRecipeRepository(db), foodRepository, foodNutrientRepository, nutrientRepository, rollup)
+	foodImportRepository := repository.NewFoodImportRepository(db)
+	usdaImportService := services.NewUSDAImportService(foodImportRepository, nutrientRepository, rollup, recipes)
+	offImportService := services.NewOpenFoodFactsImportService(foodImportRepository, nutrientRepository, rollup, recipes)
+	foodImportController := controllers.NewFoodImportController(usdaImportService, offImportService)
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	adminRoutes := router.Group("/admin/foods/import", authMiddleware.RequireAuth(), authMiddleware.RequireRole("admin"))
+	{
+		adminRoutes.POST("/usda", foodImportController.ImportUSDA)
+		adminRoutes.POST("/off", foodImportController.ImportOpenFoodFacts)
+	}
+}
@@ -0,0 +1,233 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/dto"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	"github.com/momokapoolz/caloriesapp/food_import/models"
+	"github.com/momokapoolz/caloriesapp/food_import/repository"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	recipeServices "github.com/momokapoolz/caloriesapp/recipe/services"
+)
+
+// importBatchSize is the number of foods upserted per transaction
+const importBatchSize = 500
+
+// nutrientMapping describes how a nutrient of an external food database maps onto
+// the nutrient table. When several source nutrients share a code, the one with the
+// lowest Priority present on a food wins.
+type nutrientMapping struct {
+	Code     string
+	Name     string
+	Category string
+	Unit     string
+	Priority int
+}
+
+// sourceAmount is the amount of one nutrient per 100 g of a food, in the unit of the source
+type sourceAmount struct {
+	Mapping nutrientMapping
+	Unit    string
+	Amount  float64
+}
+
+// sourceFood is a food read from an external database, ready to be stored
+type sourceFood struct {
+	Source          string
+	Name            string
+	Brand           string
+	Barcode         string
+	ServingSizeGram float64
+	Amounts         []sourceAmount
+}
+
+// foodImporter stores foods read from an external database. It is shared by the
+// importers of each database, which only differ in how they read their files.
+type foodImporter struct {
+	repo         *repository.FoodImportRepository
+	nutrientRepo *nutrientRepo.NutrientRepository
+	rollup       *dailyNutritionServices.DailyNutritionService
+	recipes      *recipeServices.RecipeService
+}
+
+// run upserts the foods produced by read in batches and returns a summary of the import
+func (i *foodImporter) run(read func(func(sourceFood) error) error) (*dto.FoodImportResultDTO, error) {
+	result := &dto.FoodImportResultDTO{}
+
+	nutrients, err := i.nutrientRepo.GetAll()
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get nutrients: %w", err)
+	}
+	byCode := make(map[string]nutrientModels.Nutrient, len(nutrients))
+	for _, nutrient := range nutrients {
+		if nutrient.Code != "" {
+			byCode[nutrient.Code] = nutrient
+		}
+	}
+
+	batch := make([]models.ImportedFood, 0, importBatchSize)
+	flush := func() error {
+		batchResult, err := i.repo.UpsertFoods(batch)
+		if err != nil {
+			helpers.LogError(err)
+			return fmt.Errorf("failed to store foods: %w", err)
+		}
+		result.FoodsCreated += batchResult.Created
+		result.FoodsUpdated += batchResult.Updated
+		batch = batch[:0]
+		log.Printf("[food_import] %d foods created, %d updated", result.FoodsCreated, result.FoodsUpdated)
+
+		// The batch marked the days of its changed foods stale when it committed; recompute them and
+		// the recipes using the foods now, so a later failure leaves nothing behind
+		if err := i.rollup.RefreshFoods(batchResult.ChangedFoodIDs...); err != nil {
+			result.RefreshErrors = append(result.RefreshErrors, fmt.Sprintf("failed to refresh diary days: %v", err))
+		}
+		if err := i.recipes.RefreshFoods(batchResult.ChangedFoodIDs...); err != nil {
+			helpers.LogError(err)
+			result.RefreshErrors = append(result.RefreshErrors, fmt.Sprintf("failed to refresh recipes: %v", err))
+		}
+		return nil
+	}
+
+	err = read(func(food sourceFood) error {
+		name := strings.TrimSpace(food.Name)
+		if food.Source == "" || name == "" {
+			result.FoodsSkipped++
+			return nil
+		}
+
+		profile, err := i.mapNutrients(food.Amounts, byCode, result)
+		if err != nil {
+			return err
+		}
+
+		batch = append(batch, models.ImportedFood{
+			Food: foodModels.Food{
+				Name:            name,
+				Brand:           strings.TrimSpace(food.Brand),
+				ServingSizeGram: food.ServingSizeGram,
+				Source:          food.Source,
+				Barcode:         food.Barcode,
+				Visibility:      foodModels.VisibilityVerified,
+			},
+			Nutrients: profile,
+		})
+		if len(batch) == importBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// mapNutrients converts source amounts into food nutrient rows, creating nutrients that
+// are not in the nutrient table yet and converting amounts to the stored unit
+func (i *foodImporter) mapNutrients(
+	amounts []sourceAmount,
+	byCode map[string]nutrientModels.Nutrient,
+	result *dto.FoodImportResultDTO,
+) ([]foodNutrientsModels.FoodNutrient, error) {
+	type choice struct {
+		amount   float64
+		priority int
+	}
+	chosen := make(map[string]choice)
+
+	for _, amount := range amounts {
+		mapping := amount.Mapping
+
+		nutrient, ok := byCode[mapping.Code]
+		if !ok {
+			nutrient = nutrientModels.Nutrient{
+				Code:     mapping.Code,
+				Name:     mapping.Name,
+				Category: mapping.Category,
+				Unit:     mapping.Unit,
+			}
+			if err := i.nutrientRepo.Create(&nutrient); err != nil {
+				helpers.LogError(err)
+				return nil, fmt.Errorf("failed to create nutrient %s: %w", mapping.Code, err)
+			}
+			byCode[mapping.Code] = nutrient
+			result.NutrientsCreated++
+		}
+
+		value, ok := convertAmount(amount.Amount, amount.Unit, nutrient.Unit)
+		if !ok {
+			continue
+		}
+		if current, ok := chosen[mapping.Code]; ok && current.priority <= mapping.Priority {
+			continue
+		}
+		chosen[mapping.Code] = choice{amount: value, priority: mapping.Priority}
+	}
+
+	profile := make([]foodNutrientsModels.FoodNutrient, 0, len(chosen))
+	for code, choice := range chosen {
+		profile = append(profile, foodNutrientsModels.FoodNutrient{
+			NutrientID:    byCode[code].ID,
+			AmountPer100g: choice.amount,
+		})
+	}
+	return profile, nil
+}
+
+// normalizeUnit maps the unit spellings used by food databases onto the ones stored in the nutrient table
+func normalizeUnit(unit string) string {
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "g", "grm":
+		return "g"
+	case "mg":
+		return "mg"
+	case "ug", "µg", "μg", "mcg":
+		return "mcg"
+	case "kcal":
+		return "kcal"
+	case "kj":
+		return "kj"
+	case "iu":
+		return "iu"
+	default:
+		return strings.ToLower(strings.TrimSpace(unit))
+	}
+}
+
+// convertAmount converts an amount between nutrient units. It reports false when
+// the units cannot be converted, e.g. IU to micrograms.
+func convertAmount(amount float64, from, to string) (float64, bool) {
+	from, to = normalizeUnit(from), normalizeUnit(to)
+	if from == to {
+		return amount, true
+	}
+
+	grams := map[string]float64{"g": 1, "mg": 1e-3, "mcg": 1e-6}
+	if fromFactor, ok := grams[from]; ok {
+		if toFactor, ok := grams[to]; ok {
+			return amount * fromFactor / toFactor, true
+		}
+	}
+
+	switch {
+	case from == "kj" && to == "kcal":
+		return amount / 4.184, true
+	case from == "kcal" && to == "kj":
+		return amount * 4.184, true
+	}
+
+	return 0, false
+}
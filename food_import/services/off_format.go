@@ -0,0 +1,167 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// offNutriment maps an Open Food Facts nutriment onto the nutrient table. OFF stores
+// every per-100g value in grams except energy, given in kcal and kJ.
+type offNutriment struct {
+	Mapping nutrientMapping
+	Unit    string
+}
+
+// offNutriments maps OFF nutriment names (the part before "_100g") onto nutrient codes.
+// The mappings are shared with the USDA importer so both fill the same nutrients.
+var offNutriments = map[string]offNutriment{
+	"energy-kcal":         {Mapping: fdcNutrients["208"], Unit: "kcal"},
+	"energy":              {Mapping: withPriority(fdcNutrients["208"], 1), Unit: "kj"},
+	"proteins":            {Mapping: fdcNutrients["203"], Unit: "g"},
+	"fat":                 {Mapping: fdcNutrients["204"], Unit: "g"},
+	"carbohydrates":       {Mapping: fdcNutrients["205"], Unit: "g"},
+	"fiber":               {Mapping: fdcNutrients["291"], Unit: "g"},
+	"cholesterol":         {Mapping: fdcNutrients["601"], Unit: "g"},
+	"vitamin-a":           {Mapping: fdcNutrients["320"], Unit: "g"},
+	"vitamin-b12":         {Mapping: fdcNutrients["418"], Unit: "g"},
+	"calcium":             {Mapping: fdcNutrients["301"], Unit: "g"},
+	"iron":                {Mapping: fdcNutrients["303"], Unit: "g"},
+	"sugars":              {Mapping: fdcNutrients["269"], Unit: "g"},
+	"added-sugars":        {Mapping: fdcNutrients["539"], Unit: "g"},
+	"saturated-fat":       {Mapping: fdcNutrients["606"], Unit: "g"},
+	"monounsaturated-fat": {Mapping: fdcNutrients["645"], Unit: "g"},
+	"polyunsaturated-fat": {Mapping: fdcNutrients["646"], Unit: "g"},
+	"trans-fat":           {Mapping: fdcNutrients["605"], Unit: "g"},
+	"vitamin-c":           {Mapping: fdcNutrients["401"], Unit: "g"},
+	"vitamin-d":           {Mapping: fdcNutrients["328"], Unit: "g"},
+	"vitamin-e":           {Mapping: fdcNutrients["323"], Unit: "g"},
+	"vitamin-k":           {Mapping: fdcNutrients["430"], Unit: "g"},
+	"vitamin-b1":          {Mapping: fdcNutrients["404"], Unit: "g"},
+	"vitamin-b2":          {Mapping: fdcNutrients["405"], Unit: "g"},
+	"vitamin-pp":          {Mapping: fdcNutrients["406"], Unit: "g"},
+	"vitamin-b6":          {Mapping: fdcNutrients["415"], Unit: "g"},
+	"vitamin-b9":          {Mapping: fdcNutrients["435"], Unit: "g"},
+	"magnesium":           {Mapping: fdcNutrients["304"], Unit: "g"},
+	"phosphorus":          {Mapping: fdcNutrients["305"], Unit: "g"},
+	"potassium":           {Mapping: fdcNutrients["306"], Unit: "g"},
+	"sodium":              {Mapping: fdcNutrients["307"], Unit: "g"},
+	"zinc":                {Mapping: fdcNutrients["309"], Unit: "g"},
+	"caffeine":            {Mapping: fdcNutrients["262"], Unit: "g"},
+}
+
+// withPriority returns a copy of mapping with another priority
+func withPriority(mapping nutrientMapping, priority int) nutrientMapping {
+	mapping.Priority = priority
+	return mapping
+}
+
+// offProduct is a product read from an Open Food Facts dump, independent of the file format
+type offProduct struct {
+	Code            string
+	Name            string
+	Brand           string
+	ServingQuantity float64
+	Nutriments      map[string]float64
+}
+
+// offJSONProduct mirrors the product objects of the OFF JSONL dump. Numbers are
+// not consistently typed in the dump, so they are decoded loosely.
+type offJSONProduct struct {
+	Code            interface{}            `json:"code"`
+	ProductName     string                 `json:"product_name"`
+	ProductNameEN   string                 `json:"product_name_en"`
+	Brands          string                 `json:"brands"`
+	ServingQuantity interface{}            `json:"serving_quantity"`
+	Nutriments      map[string]interface{} `json:"nutriments"`
+}
+
+// firstBrand returns the main brand of the comma separated OFF brands list
+func firstBrand(brands string) string {
+	brand, _, _ := strings.Cut(brands, ",")
+	return strings.TrimSpace(brand)
+}
+
+// offNumber reads a number that the OFF dump may encode as a JSON number or string
+func offNumber(value interface{}) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		number, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return number, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// readOFFJSONL streams the products of an OFF JSONL dump, one JSON object per line
+func readOFFJSONL(r io.Reader, fn func(offProduct) error) error {
+	dec := json.NewDecoder(r)
+	for {
+		var raw offJSONProduct
+		if err := dec.Decode(&raw); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return fmt.Errorf("failed to decode OFF product: %w", err)
+		}
+
+		product := offProduct{
+			Name:       raw.ProductName,
+			Brand:      firstBrand(raw.Brands),
+			Nutriments: make(map[string]float64),
+		}
+		if product.Name == "" {
+			product.Name = raw.ProductNameEN
+		}
+		switch code := raw.Code.(type) {
+		case string:
+			product.Code = code
+		case float64:
+			product.Code = strconv.FormatFloat(code, 'f', -1, 64)
+		}
+		if quantity, ok := offNumber(raw.ServingQuantity); ok {
+			product.ServingQuantity = quantity
+		}
+		for key, value := range raw.Nutriments {
+			name, ok := strings.CutSuffix(key, "_100g")
+			if !ok {
+				continue
+			}
+			if amount, ok := offNumber(value); ok {
+				product.Nutriments[name] = amount
+			}
+		}
+
+		if err := fn(product); err != nil {
+			return err
+		}
+	}
+}
+
+// readOFFCSV reads the products of the tab separated OFF CSV export
+func readOFFCSV(r io.Reader, fn func(offProduct) error) error {
+	return readCSV(r, '\t', func(row map[string]string) error {
+		product := offProduct{
+			Code:       row["code"],
+			Name:       row["product_name"],
+			Brand:      firstBrand(row["brands"]),
+			Nutriments: make(map[string]float64),
+		}
+		if quantity, err := strconv.ParseFloat(row["serving_quantity"], 64); err == nil {
+			product.ServingQuantity = quantity
+		}
+		for column, value := range row {
+			name, ok := strings.CutSuffix(column, "_100g")
+			if !ok || value == "" {
+				continue
+			}
+			if amount, err := strconv.ParseFloat(value, 64); err == nil {
+				product.Nutriments[name] = amount
+			}
+		}
+		return fn(product)
+	})
+}
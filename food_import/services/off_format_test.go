@@ -0,0 +1,45 @@
+package services
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestReadOFFJSONL(t *testing.T) {
+	input := `{"code": "3017620422003", "product_name": "Nutella", "serving_quantity": "15", "nutriments": {"energy-kcal_100g": 539, "sodium_100g": "0.0428", "sugars_serving": 8.4}}
+{"code": 737628064502, "product_name_en": "Thai peanut noodles", "nutriments": {}}
+`
+
+	var products []offProduct
+	err := readOFFJSONL(strings.NewReader(input), func(product offProduct) error {
+		products = append(products, product)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(products) != 2 {
+		t.Fatalf("expected 2 products, got %d", len(products))
+	}
+	if products[0].ServingQuantity != 15 || products[0].Nutriments["sodium"] != 0.0428 {
+		t.Fatalf("unexpected first product: %+v", products[0])
+	}
+	if _, ok := products[0].Nutriments["sugars"]; ok {
+		t.Fatalf("per serving nutriments must be ignored")
+	}
+	if products[1].Code != "737628064502" || products[1].Name != "Thai peanut noodles" {
+		t.Fatalf("unexpected second product: %+v", products[1])
+	}
+
+	var foods []sourceFood
+	if err := offToSource(func(food sourceFood) error {
+		foods = append(foods, food)
+		return nil
+	})(products[1]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if foods[0].Source != "off:0737628064502" || foods[0].Barcode != "0737628064502" {
+		t.Fatalf("UPC-A code must be stored as EAN-13, got %+v", foods[0])
+	}
+}
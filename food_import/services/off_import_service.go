@@ -0,0 +1,103 @@
+package services
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/dto"
+	foodServices "github.com/momokapoolz/caloriesapp/food/services"
+	"github.com/momokapoolz/caloriesapp/food_import/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	recipeServices "github.com/momokapoolz/caloriesapp/recipe/services"
+)
+
+// OpenFoodFactsImportService imports packaged products from the Open Food Facts
+// JSONL or CSV dumps. Products are matched on their source "off:<barcode>", so an
+// import can be re-run to refresh the data without duplicates.
+type OpenFoodFactsImportService struct {
+	importer *foodImporter
+}
+
+// NewOpenFoodFactsImportService creates a new Open Food Facts import service instance
+func NewOpenFoodFactsImportService(
+	repo *repository.FoodImportRepository,
+	nutrientRepo *nutrientRepo.NutrientRepository,
+	rollup *dailyNutritionServices.DailyNutritionService,
+	recipes *recipeServices.RecipeService,
+) *OpenFoodFactsImportService {
+	return &OpenFoodFactsImportService{
+		importer: &foodImporter{repo: repo, nutrientRepo: nutrientRepo, rollup: rollup, recipes: recipes},
+	}
+}
+
+// ImportPath imports an OFF dump from disk. The format is taken from the file
+// extension (.jsonl or .csv), optionally compressed with gzip (.gz).
+func (s *OpenFoodFactsImportService) ImportPath(filePath string) (*dto.FoodImportResultDTO, error) {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+	defer file.Close()
+
+	var reader io.Reader = file
+	name := strings.ToLower(filepath.Base(filePath))
+	if strings.HasSuffix(name, ".gz") {
+		gz, err := gzip.NewReader(file)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open gzip stream: %w", err)
+		}
+		defer gz.Close()
+		reader = gz
+		name = strings.TrimSuffix(name, ".gz")
+	}
+
+	switch filepath.Ext(name) {
+	case ".jsonl", ".json":
+		return s.ImportJSONL(reader)
+	case ".csv":
+		return s.ImportCSV(reader)
+	default:
+		return nil, fmt.Errorf("unsupported import file %q: expected .jsonl or .csv, optionally gzipped", filepath.Base(filePath))
+	}
+}
+
+// ImportJSONL imports the OFF JSONL dump
+func (s *OpenFoodFactsImportService) ImportJSONL(r io.Reader) (*dto.FoodImportResultDTO, error) {
+	return s.importer.run(func(fn func(sourceFood) error) error {
+		return readOFFJSONL(r, offToSource(fn))
+	})
+}
+
+// ImportCSV imports the tab separated OFF CSV export
+func (s *OpenFoodFactsImportService) ImportCSV(r io.Reader) (*dto.FoodImportResultDTO, error) {
+	return s.importer.run(func(fn func(sourceFood) error) error {
+		return readOFFCSV(r, offToSource(fn))
+	})
+}
+
+// offToSource adapts fn to receive OFF products. Products without a valid barcode
+// are passed on without a source, so the importer counts them as skipped.
+func offToSource(fn func(sourceFood) error) func(offProduct) error {
+	return func(product offProduct) error {
+		converted := sourceFood{
+			Name:            product.Name,
+			Brand:           product.Brand,
+			ServingSizeGram: servingSizeGrams(product.ServingQuantity, "g"),
+		}
+		if barcode, err := foodServices.NormalizeBarcode(product.Code); err == nil {
+			converted.Source = "off:" + barcode
+			converted.Barcode = barcode
+		}
+		for name, amount := range product.Nutriments {
+			if nutriment, ok := offNutriments[name]; ok {
+				converted.Amounts = append(converted.Amounts, sourceAmount{Mapping: nutriment.Mapping, Unit: nutriment.Unit, Amount: amount})
+			}
+		}
+		return fn(converted)
+	}
+}
@@ -0,0 +1,335 @@
+package services
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"strconv"
+	"strings"
+
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+)
+
+// fdcFood is a food read from a FoodData Central download, independent of the file format
+type fdcFood struct {
+	FDCID           int
+	Description     string
+	Brand           string
+	GTIN            string
+	ServingSizeGram float64
+	Nutrients       []fdcAmount
+}
+
+// fdcAmount is the amount of one nutrient per 100 g of a food, in the unit given by FDC
+type fdcAmount struct {
+	Number string
+	Unit   string
+	Amount float64
+}
+
+// fdcNutrients maps FDC nutrient numbers onto nutrient codes. Energy is reported as
+// 208 for SR Legacy and Branded foods, Foundation foods often only carry the Atwater
+// factors (958, 957).
+var fdcNutrients = map[string]nutrientMapping{
+	"208": {Code: nutrientModels.CodeEnergy, Name: "Energy", Category: "Macronutrient", Unit: "kcal"},
+	"958": {Code: nutrientModels.CodeEnergy, Name: "Energy", Category: "Macronutrient", Unit: "kcal", Priority: 1},
+	"957": {Code: nutrientModels.CodeEnergy, Name: "Energy", Category: "Macronutrient", Unit: "kcal", Priority: 2},
+	"203": {Code: nutrientModels.CodeProtein, Name: "Protein", Category: "Macronutrient", Unit: "g"},
+	"204": {Code: nutrientModels.CodeFat, Name: "Total lipid (fat)", Category: "Macronutrient", Unit: "g"},
+	"205": {Code: nutrientModels.CodeCarbohydrate, Name: "Carbohydrate, by difference", Category: "Macronutrient", Unit: "g"},
+	"291": {Code: nutrientModels.CodeFiber, Name: "Fiber, total dietary", Category: "Macronutrient", Unit: "g"},
+	"601": {Code: nutrientModels.CodeCholesterol, Name: "Cholesterol", Category: "Macronutrient", Unit: "mg"},
+	"320": {Code: nutrientModels.CodeVitaminA, Name: "Vitamin A, RAE", Category: "Vitamin", Unit: "mcg"},
+	"418": {Code: nutrientModels.CodeVitaminB12, Name: "Vitamin B12", Category: "Vitamin", Unit: "mcg"},
+	"301": {Code: nutrientModels.CodeCalcium, Name: "Calcium, Ca", Category: "Mineral", Unit: "mg"},
+	"303": {Code: nutrientModels.CodeIron, Name: "Iron, Fe", Category: "Mineral", Unit: "mg"},
+	"255": {Code: "water", Name: "Water", Category: "Macronutrient", Unit: "g"},
+	"269": {Code: "sugars", Name: "Sugars, total", Category: "Macronutrient", Unit: "g"},
+	"539": {Code: "added_sugars", Name: "Sugars, added", Category: "Macronutrient", Unit: "g"},
+	"606": {Code: "saturated_fat", Name: "Fatty acids, total saturated", Category: "Macronutrient", Unit: "g"},
+	"645": {Code: "monounsaturated_fat", Name: "Fatty acids, total monounsaturated", Category: "Macronutrient", Unit: "g"},
+	"646": {Code: "polyunsaturated_fat", Name: "Fatty acids, total polyunsaturated", Category: "Macronutrient", Unit: "g"},
+	"605": {Code: "trans_fat", Name: "Fatty acids, total trans", Category: "Macronutrient", Unit: "g"},
+	"401": {Code: "vitamin_c", Name: "Vitamin C, total ascorbic acid", Category: "Vitamin", Unit: "mg"},
+	"328": {Code: "vitamin_d", Name: "Vitamin D (D2 + D3)", Category: "Vitamin", Unit: "mcg"},
+	"323": {Code: "vitamin_e", Name: "Vitamin E (alpha-tocopherol)", Category: "Vitamin", Unit: "mg"},
+	"430": {Code: "vitamin_k", Name: "Vitamin K (phylloquinone)", Category: "Vitamin", Unit: "mcg"},
+	"404": {Code: "thiamin", Name: "Thiamin", Category: "Vitamin", Unit: "mg"},
+	"405": {Code: "riboflavin", Name: "Riboflavin", Category: "Vitamin", Unit: "mg"},
+	"406": {Code: "niacin", Name: "Niacin", Category: "Vitamin", Unit: "mg"},
+	"415": {Code: "vitamin_b6", Name: "Vitamin B-6", Category: "Vitamin", Unit: "mg"},
+	"435": {Code: "folate", Name: "Folate, DFE", Category: "Vitamin", Unit: "mcg"},
+	"304": {Code: "magnesium", Name: "Magnesium, Mg", Category: "Mineral", Unit: "mg"},
+	"305": {Code: "phosphorus", Name: "Phosphorus, P", Category: "Mineral", Unit: "mg"},
+	"306": {Code: "potassium", Name: "Potassium, K", Category: "Mineral", Unit: "mg"},
+	"307": {Code: "sodium", Name: "Sodium, Na", Category: "Mineral", Unit: "mg"},
+	"309": {Code: "zinc", Name: "Zinc, Zn", Category: "Mineral", Unit: "mg"},
+	"262": {Code: "caffeine", Name: "Caffeine", Category: "Other", Unit: "mg"},
+}
+
+// servingSizeGrams converts an FDC serving size to grams, falling back to 100 g
+// when the serving is missing or given in a unit without a fixed weight
+func servingSizeGrams(size float64, unit string) float64 {
+	if size <= 0 {
+		return 100
+	}
+	switch strings.ToLower(strings.TrimSpace(unit)) {
+	case "g", "grm", "ml", "mlt":
+		return size
+	default:
+		return 100
+	}
+}
+
+// firstNonEmpty returns the first of values that is not blank
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value = strings.TrimSpace(value); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+// normalizeNutrientNumber strips the decimal suffix some FDC CSV releases add to nutrient numbers
+func normalizeNutrientNumber(number string) string {
+	number = strings.TrimSpace(number)
+	if value, err := strconv.ParseFloat(number, 64); err == nil && value == float64(int(value)) {
+		return strconv.Itoa(int(value))
+	}
+	return number
+}
+
+// fdcJSONFood mirrors the food objects of the FDC JSON downloads
+type fdcJSONFood struct {
+	FdcID           int     `json:"fdcId"`
+	Description     string  `json:"description"`
+	BrandOwner      string  `json:"brandOwner"`
+	BrandName       string  `json:"brandName"`
+	GtinUpc         string  `json:"gtinUpc"`
+	ServingSize     float64 `json:"servingSize"`
+	ServingSizeUnit string  `json:"servingSizeUnit"`
+	FoodNutrients   []struct {
+		Nutrient struct {
+			Number   string `json:"number"`
+			UnitName string `json:"unitName"`
+		} `json:"nutrient"`
+		Amount *float64 `json:"amount"`
+	} `json:"foodNutrients"`
+	FoodPortions []struct {
+		GramWeight float64 `json:"gramWeight"`
+	} `json:"foodPortions"`
+}
+
+// readFDCJSON streams the foods of an FDC JSON download such as
+// {"FoundationFoods": [...]}, {"SRLegacyFoods": [...]} or {"BrandedFoods": [...]},
+// calling fn for each one without loading the whole file into memory
+func readFDCJSON(r io.Reader, fn func(fdcFood) error) error {
+	dec := json.NewDecoder(r)
+
+	if tok, err := dec.Token(); err != nil {
+		return fmt.Errorf("failed to read FDC JSON: %w", err)
+	} else if delim, ok := tok.(json.Delim); !ok || delim != '{' {
+		return errors.New("FDC JSON must be an object holding a list of foods")
+	}
+
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("failed to read FDC JSON: %w", err)
+		}
+
+		if tok, err := dec.Token(); err != nil {
+			return fmt.Errorf("failed to read FDC JSON: %w", err)
+		} else if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+			return fmt.Errorf("FDC JSON key %v does not hold a list of foods", key)
+		}
+
+		for dec.More() {
+			var raw fdcJSONFood
+			if err := dec.Decode(&raw); err != nil {
+				return fmt.Errorf("failed to decode FDC food: %w", err)
+			}
+
+			food := fdcFood{
+				FDCID:           raw.FdcID,
+				Description:     raw.Description,
+				Brand:           firstNonEmpty(raw.BrandName, raw.BrandOwner),
+				GTIN:            raw.GtinUpc,
+				ServingSizeGram: servingSizeGrams(raw.ServingSize, raw.ServingSizeUnit),
+			}
+			if raw.ServingSize == 0 && len(raw.FoodPortions) > 0 && raw.FoodPortions[0].GramWeight > 0 {
+				food.ServingSizeGram = raw.FoodPortions[0].GramWeight
+			}
+			for _, nutrient := range raw.FoodNutrients {
+				if nutrient.Amount == nil || nutrient.Nutrient.Number == "" {
+					continue
+				}
+				food.Nutrients = append(food.Nutrients, fdcAmount{
+					Number: normalizeNutrientNumber(nutrient.Nutrient.Number),
+					Unit:   nutrient.Nutrient.UnitName,
+					Amount: *nutrient.Amount,
+				})
+			}
+
+			if err := fn(food); err != nil {
+				return err
+			}
+		}
+
+		// closing ']' of the food list
+		if _, err := dec.Token(); err != nil {
+			return fmt.Errorf("failed to read FDC JSON: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// fdcCSVDataTypes lists the data_type values of food.csv that are imported
+var fdcCSVDataTypes = map[string]bool{
+	"foundation_food": true,
+	"sr_legacy_food":  true,
+	"branded_food":    true,
+}
+
+// readFDCCSV reads the foods of an FDC CSV download (food.csv, nutrient.csv,
+// food_nutrient.csv and, for Branded foods, branded_food.csv) and calls fn for each one
+func readFDCCSV(fsys fs.FS, fn func(fdcFood) error) error {
+	// nutrient.csv: FDC nutrient id -> number and unit
+	type csvNutrient struct{ number, unit string }
+	nutrients := make(map[string]csvNutrient)
+	err := readCSVFile(fsys, "nutrient.csv", true, func(row map[string]string) error {
+		nutrients[row["id"]] = csvNutrient{number: normalizeNutrientNumber(row["nutrient_nbr"]), unit: row["unit_name"]}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// food.csv: foods of the imported data types, in file order
+	var foods []fdcFood
+	index := make(map[int]int)
+	err = readCSVFile(fsys, "food.csv", true, func(row map[string]string) error {
+		if !fdcCSVDataTypes[row["data_type"]] {
+			return nil
+		}
+		fdcID, err := strconv.Atoi(row["fdc_id"])
+		if err != nil {
+			return fmt.Errorf("invalid fdc_id %q in food.csv", row["fdc_id"])
+		}
+		index[fdcID] = len(foods)
+		foods = append(foods, fdcFood{FDCID: fdcID, Description: row["description"], ServingSizeGram: 100})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// branded_food.csv: brands, barcodes and serving sizes of Branded foods
+	err = readCSVFile(fsys, "branded_food.csv", false, func(row map[string]string) error {
+		fdcID, _ := strconv.Atoi(row["fdc_id"])
+		idx, ok := index[fdcID]
+		if !ok {
+			return nil
+		}
+		size, _ := strconv.ParseFloat(row["serving_size"], 64)
+		foods[idx].Brand = firstNonEmpty(row["brand_name"], row["brand_owner"])
+		foods[idx].GTIN = row["gtin_upc"]
+		foods[idx].ServingSizeGram = servingSizeGrams(size, row["serving_size_unit"])
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	// food_nutrient.csv: amounts per 100 g
+	err = readCSVFile(fsys, "food_nutrient.csv", true, func(row map[string]string) error {
+		fdcID, _ := strconv.Atoi(row["fdc_id"])
+		idx, ok := index[fdcID]
+		if !ok {
+			return nil
+		}
+		nutrient, ok := nutrients[row["nutrient_id"]]
+		if !ok || row["amount"] == "" {
+			return nil
+		}
+		amount, err := strconv.ParseFloat(row["amount"], 64)
+		if err != nil {
+			return fmt.Errorf("invalid amount %q in food_nutrient.csv", row["amount"])
+		}
+		foods[idx].Nutrients = append(foods[idx].Nutrients, fdcAmount{Number: nutrient.number, Unit: nutrient.unit, Amount: amount})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, food := range foods {
+		if err := fn(food); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// readCSVFile calls fn for every row of a comma separated file, keyed by the header names.
+// A missing file is an error only when required is true.
+func readCSVFile(fsys fs.FS, name string, required bool, fn func(map[string]string) error) error {
+	file, err := fsys.Open(name)
+	if err != nil {
+		if !required && errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to open %s: %w", name, err)
+	}
+	defer file.Close()
+
+	if err := readCSV(file, ',', fn); err != nil {
+		return fmt.Errorf("failed to read %s: %w", name, err)
+	}
+	return nil
+}
+
+// readCSV calls fn for every row of a delimited file, keyed by the header names.
+// The map passed to fn is reused between rows.
+func readCSV(r io.Reader, comma rune, fn func(map[string]string) error) error {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.ReuseRecord = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make([]string, len(header))
+	for i, column := range header {
+		columns[i] = strings.TrimPrefix(strings.TrimSpace(column), "\ufeff")
+	}
+
+	row := make(map[string]string, len(columns))
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		for i, column := range columns {
+			if i < len(record) {
+				row[column] = record[i]
+			} else {
+				row[column] = ""
+			}
+		}
+		if err := fn(row); err != nil {
+			return err
+		}
+	}
+}
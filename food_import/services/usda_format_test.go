@@ -0,0 +1,93 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestReadFDCJSON(t *testing.T) {
+	input := `{"BrandedFoods": [
+		{"fdcId": 1105904, "description": "GRANOLA", "servingSize": 55, "servingSizeUnit": "g",
+		 "foodNutrients": [
+			{"nutrient": {"number": "208", "unitName": "kcal"}, "amount": 436},
+			{"nutrient": {"number": "303", "unitName": "mg"}},
+			{"nutrient": {"number": "418", "unitName": "µg"}, "amount": 0.5}
+		 ]},
+		{"fdcId": 2, "description": "OATS", "servingSize": 1, "servingSizeUnit": "cup"}
+	]}`
+
+	var foods []fdcFood
+	err := readFDCJSON(strings.NewReader(input), func(food fdcFood) error {
+		foods = append(foods, food)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(foods) != 2 {
+		t.Fatalf("expected 2 foods, got %d", len(foods))
+	}
+	if foods[0].FDCID != 1105904 || foods[0].ServingSizeGram != 55 {
+		t.Fatalf("unexpected first food: %+v", foods[0])
+	}
+	if len(foods[0].Nutrients) != 2 {
+		t.Fatalf("nutrients without an amount must be skipped, got %+v", foods[0].Nutrients)
+	}
+	if foods[1].ServingSizeGram != 100 {
+		t.Fatalf("serving without a weight must fall back to 100 g, got %v", foods[1].ServingSizeGram)
+	}
+}
+
+func TestReadFDCCSV(t *testing.T) {
+	fsys := fstest.MapFS{
+		"nutrient.csv": {Data: []byte("\"id\",\"name\",\"unit_name\",\"nutrient_nbr\"\n" +
+			"\"1008\",\"Energy\",\"KCAL\",\"208.0\"\n" +
+			"\"1003\",\"Protein\",\"G\",\"203.0\"\n")},
+		"food.csv": {Data: []byte("\"fdc_id\",\"data_type\",\"description\"\n" +
+			"\"170567\",\"sr_legacy_food\",\"Almonds\"\n" +
+			"\"999\",\"sub_sample_food\",\"Sample\"\n")},
+		"food_nutrient.csv": {Data: []byte("\"id\",\"fdc_id\",\"nutrient_id\",\"amount\"\n" +
+			"\"1\",\"170567\",\"1008\",\"579\"\n" +
+			"\"2\",\"170567\",\"1003\",\"21.15\"\n" +
+			"\"3\",\"999\",\"1003\",\"1\"\n")},
+	}
+
+	var foods []fdcFood
+	err := readFDCCSV(fsys, func(food fdcFood) error {
+		foods = append(foods, food)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(foods) != 1 || foods[0].FDCID != 170567 {
+		t.Fatalf("expected only the SR Legacy food, got %+v", foods)
+	}
+	if len(foods[0].Nutrients) != 2 || foods[0].Nutrients[0].Number != "208" {
+		t.Fatalf("unexpected nutrients: %+v", foods[0].Nutrients)
+	}
+}
+
+func TestConvertAmount(t *testing.T) {
+	cases := []struct {
+		amount   float64
+		from, to string
+		want     float64
+		ok       bool
+	}{
+		{1, "MG", "mcg", 1000, true},
+		{500, "UG", "mg", 0.5, true},
+		{418.4, "kJ", "kcal", 100, true},
+		{10, "IU", "mcg", 0, false},
+	}
+
+	for _, c := range cases {
+		got, ok := convertAmount(c.amount, c.from, c.to)
+		if ok != c.ok || (ok && (got-c.want > 1e-9 || c.want-got > 1e-9)) {
+			t.Errorf("convertAmount(%v, %s, %s) = %v, %v; want %v, %v", c.amount, c.from, c.to, got, ok, c.want, c.ok)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"archive/zip"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/dto"
+	foodServices "github.com/momokapoolz/caloriesapp/food/services"
+	"github.com/momokapoolz/caloriesapp/food_import/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	recipeServices "github.com/momokapoolz/caloriesapp/recipe/services"
+)
+
+// USDAImportService imports foods and nutrient profiles from USDA FoodData Central
+// downloads (Foundation, SR Legacy and Branded). Foods are matched on their source
+// "usda:<fdc_id>", so an import can be re-run to refresh the data without duplicates.
+type USDAImportService struct {
+	importer *foodImporter
+}
+
+// NewUSDAImportService creates a new USDA import service instance
+func NewUSDAImportService(
+	repo *repository.FoodImportRepository,
+	nutrientRepo *nutrientRepo.NutrientRepository,
+	rollup *dailyNutritionServices.DailyNutritionService,
+	recipes *recipeServices.RecipeService,
+) *USDAImportService {
+	return &USDAImportService{
+		importer: &foodImporter{repo: repo, nutrientRepo: nutrientRepo, rollup: rollup, recipes: recipes},
+	}
+}
+
+// ImportPath imports an FDC download from disk. JSON files are streamed; CSV
+// downloads are read from their zip archive or from the directory they were extracted to.
+func (s *USDAImportService) ImportPath(filePath string) (*dto.FoodImportResultDTO, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open import file: %w", err)
+	}
+
+	if info.IsDir() {
+		return s.ImportCSV(os.DirFS(filePath))
+	}
+
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".json":
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open import file: %w", err)
+		}
+		defer file.Close()
+		return s.ImportJSON(file)
+	case ".zip":
+		archive, err := zip.OpenReader(filePath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open zip archive: %w", err)
+		}
+		defer archive.Close()
+		return s.ImportCSV(csvRoot(archive))
+	default:
+		return nil, fmt.Errorf("unsupported import file %q: expected .json, .zip or a directory", filepath.Base(filePath))
+	}
+}
+
+// ImportJSON imports an FDC JSON download
+func (s *USDAImportService) ImportJSON(r io.Reader) (*dto.FoodImportResultDTO, error) {
+	return s.importer.run(func(fn func(sourceFood) error) error {
+		return readFDCJSON(r, fdcToSource(fn))
+	})
+}
+
+// ImportCSV imports an FDC CSV download holding food.csv, nutrient.csv and food_nutrient.csv
+func (s *USDAImportService) ImportCSV(fsys fs.FS) (*dto.FoodImportResultDTO, error) {
+	return s.importer.run(func(fn func(sourceFood) error) error {
+		return readFDCCSV(fsys, fdcToSource(fn))
+	})
+}
+
+// fdcToSource adapts fn to receive FDC foods, mapping their nutrient numbers
+func fdcToSource(fn func(sourceFood) error) func(fdcFood) error {
+	return func(food fdcFood) error {
+		converted := sourceFood{
+			Name:            food.Description,
+			Brand:           food.Brand,
+			ServingSizeGram: food.ServingSizeGram,
+		}
+		if food.FDCID != 0 {
+			converted.Source = fmt.Sprintf("usda:%d", food.FDCID)
+		}
+		// Branded foods carry the GTIN printed on the package; malformed ones are left out
+		if barcode, err := foodServices.NormalizeBarcode(food.GTIN); err == nil {
+			converted.Barcode = barcode
+		}
+		for _, amount := range food.Nutrients {
+			if mapping, ok := fdcNutrients[amount.Number]; ok {
+				converted.Amounts = append(converted.Amounts, sourceAmount{Mapping: mapping, Unit: amount.Unit, Amount: amount.Amount})
+			}
+		}
+		return fn(converted)
+	}
+}
+
+// csvRoot returns the directory of a zip archive holding food.csv, as FDC
+// archives wrap their CSV files in a dated folder
+func csvRoot(archive *zip.ReadCloser) fs.FS {
+	for _, file := range archive.File {
+		if path.Base(file.Name) == "food.csv" {
+			if dir := path.Dir(file.Name); dir != "." {
+				if sub, err := fs.Sub(archive, dir); err == nil {
+					return sub
+				}
+			}
+			break
+		}
+	}
+	return archive
+}
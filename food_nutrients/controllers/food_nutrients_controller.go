@@ -1,13 +1,16 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
 	"github.com/momokapoolz/caloriesapp/food_nutrients/models"
 	"github.com/momokapoolz/caloriesapp/food_nutrients/services"
 	"github.com/momokapoolz/caloriesapp/helpers"
+	"gorm.io/gorm"
 )
 
 type FoodNutrientController struct {
@@ -19,6 +22,22 @@ func NewFoodNutrientController(service *services.FoodNutrientService) *FoodNutri
 	return &FoodNutrientController{service: service}
 }
 
+// writeFoodNutrientError maps food nutrient service errors to responses; fallback is used for unexpected errors
+func writeFoodNutrientError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrFoodNutrientNotAccessible):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to modify the nutrients of this food"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Food nutrient not found"})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
 // CreateFoodNutrient godoc
 // @Summary      Create food nutrient
 // @Description  Create a new food nutrient record
@@ -28,19 +47,30 @@ func NewFoodNutrientController(service *services.FoodNutrientService) *FoodNutri
 // @Param        food_nutrient  body      models.FoodNutrient  true  "Food nutrient data"
 // @Success      201  {object}  models.FoodNutrient       "Food nutrient created successfully"
 // @Failure      400  {object}  map[string]string         "Invalid request body"
+// @Failure      401  {object}  map[string]string         "Unauthorized"
+// @Failure      403  {object}  map[string]string         "Forbidden — not allowed to modify the food"
+// @Failure      404  {object}  map[string]string         "Food not found"
+// @Failure      409  {object}  map[string]string         "The food already has this nutrient"
+// @Failure      422  {object}  map[string]string         "Food or nutrient not found, or negative amount"
 // @Failure      500  {object}  map[string]string         "Internal server error"
 // @Security     BearerAuth
 // @Router       /food-nutrients/ [post]
 func (c *FoodNutrientController) CreateFoodNutrient(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	var foodNutrient models.FoodNutrient
 	if err := ctx.ShouldBindJSON(&foodNutrient); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := c.service.CreateFoodNutrient(&foodNutrient); err != nil {
-		helpers.LogError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create food nutrient"})
+	foodNutrient.ID = 0
+	if err := c.service.CreateFoodNutrient(&foodNutrient, viewer); err != nil {
+		writeFoodNutrientError(ctx, err, "Failed to create food nutrient")
 		return
 	}
 
@@ -55,11 +85,18 @@ func (c *FoodNutrientController) CreateFoodNutrient(ctx *gin.Context) {
 // @Param        id  path      int  true  "Food nutrient ID"
 // @Success      200  {object}  models.FoodNutrient       "Food nutrient retrieved successfully"
 // @Failure      400  {object}  map[string]string         "Invalid ID format"
+// @Failure      401  {object}  map[string]string         "Unauthorized"
 // @Failure      404  {object}  map[string]string         "Food nutrient not found"
 // @Failure      500  {object}  map[string]string         "Internal server error"
 // @Security     BearerAuth
 // @Router       /food-nutrients/{id} [get]
 func (c *FoodNutrientController) GetFoodNutrient(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -67,9 +104,9 @@ func (c *FoodNutrientController) GetFoodNutrient(ctx *gin.Context) {
 		return
 	}
 
-	foodNutrient, err := c.service.GetFoodNutrientByID(uint(id))
+	foodNutrient, err := c.service.GetFoodNutrientByID(uint(id), viewer)
 	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{"error": "Food nutrient not found"})
+		writeFoodNutrientError(ctx, err, "Failed to retrieve food nutrient")
 		return
 	}
 
@@ -78,15 +115,22 @@ func (c *FoodNutrientController) GetFoodNutrient(ctx *gin.Context) {
 
 // GetAllFoodNutrients godoc
 // @Summary      Get all food nutrients
-// @Description  Retrieve all food nutrient records
+// @Description  Retrieve the nutrient records of every food the user can see
 // @Tags         food_nutrient
 // @Produce      json
 // @Success      200  {array}   models.FoodNutrient       "List of food nutrients"
+// @Failure      401  {object}  map[string]string         "Unauthorized"
 // @Failure      500  {object}  map[string]string         "Internal server error"
 // @Security     BearerAuth
 // @Router       /food-nutrients/ [get]
 func (c *FoodNutrientController) GetAllFoodNutrients(ctx *gin.Context) {
-	foodNutrients, err := c.service.GetAllFoodNutrients()
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	foodNutrients, err := c.service.GetAllFoodNutrients(viewer)
 	if err != nil {
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve food nutrients"})
@@ -104,10 +148,18 @@ func (c *FoodNutrientController) GetAllFoodNutrients(ctx *gin.Context) {
 // @Param        foodId  path      int  true  "Food ID"
 // @Success      200  {array}   models.FoodNutrient       "List of food nutrients for the food"
 // @Failure      400  {object}  map[string]string         "Invalid food ID format"
+// @Failure      401  {object}  map[string]string         "Unauthorized"
+// @Failure      404  {object}  map[string]string         "Food not found"
 // @Failure      500  {object}  map[string]string         "Internal server error"
 // @Security     BearerAuth
 // @Router       /food-nutrients/food/{foodId} [get]
 func (c *FoodNutrientController) GetFoodNutrientsByFoodID(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("foodId")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -115,7 +167,11 @@ func (c *FoodNutrientController) GetFoodNutrientsByFoodID(ctx *gin.Context) {
 		return
 	}
 
-	foodNutrients, err := c.service.GetFoodNutrientsByFoodID(uint(id))
+	foodNutrients, err := c.service.GetFoodNutrientsByFoodID(uint(id), viewer)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Food not found"})
+		return
+	}
 	if err != nil {
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve food nutrients by food ID"})
@@ -127,16 +183,23 @@ func (c *FoodNutrientController) GetFoodNutrientsByFoodID(ctx *gin.Context) {
 
 // GetFoodNutrientsByNutrientID godoc
 // @Summary      Get food nutrients by nutrient ID
-// @Description  Retrieve all food nutrient records associated with a specific nutrient
+// @Description  Retrieve the food nutrient records of a specific nutrient among the foods the user can see
 // @Tags         food_nutrient
 // @Produce      json
 // @Param        nutrientId  path      int  true  "Nutrient ID"
 // @Success      200  {array}   models.FoodNutrient       "List of food nutrients for the nutrient"
 // @Failure      400  {object}  map[string]string         "Invalid nutrient ID format"
+// @Failure      401  {object}  map[string]string         "Unauthorized"
 // @Failure      500  {object}  map[string]string         "Internal server error"
 // @Security     BearerAuth
 // @Router       /food-nutrients/nutrient/{nutrientId} [get]
 func (c *FoodNutrientController) GetFoodNutrientsByNutrientID(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("nutrientId")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -144,7 +207,7 @@ func (c *FoodNutrientController) GetFoodNutrientsByNutrientID(ctx *gin.Context)
 		return
 	}
 
-	foodNutrients, err := c.service.GetFoodNutrientsByNutrientID(uint(id))
+	foodNutrients, err := c.service.GetFoodNutrientsByNutrientID(uint(id), viewer)
 	if err != nil {
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve food nutrients by nutrient ID"})
@@ -164,10 +227,21 @@ func (c *FoodNutrientController) GetFoodNutrientsByNutrientID(ctx *gin.Context)
 // @Param        food_nutrient  body      models.FoodNutrient  true  "Updated food nutrient data"
 // @Success      200  {object}  models.FoodNutrient       "Food nutrient updated successfully"
 // @Failure      400  {object}  map[string]string         "Invalid ID or request body"
+// @Failure      401  {object}  map[string]string         "Unauthorized"
+// @Failure      403  {object}  map[string]string         "Forbidden — not allowed to modify the food"
+// @Failure      404  {object}  map[string]string         "Food nutrient not found"
+// @Failure      409  {object}  map[string]string         "The food already has this nutrient"
+// @Failure      422  {object}  map[string]string         "Food or nutrient not found, or negative amount"
 // @Failure      500  {object}  map[string]string         "Internal server error"
 // @Security     BearerAuth
 // @Router       /food-nutrients/{id} [put]
 func (c *FoodNutrientController) UpdateFoodNutrient(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -182,9 +256,8 @@ func (c *FoodNutrientController) UpdateFoodNutrient(ctx *gin.Context) {
 	}
 
 	foodNutrient.ID = uint(id)
-	if err := c.service.UpdateFoodNutrient(&foodNutrient); err != nil {
-		helpers.LogError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update food nutrient"})
+	if err := c.service.UpdateFoodNutrient(&foodNutrient, viewer); err != nil {
+		writeFoodNutrientError(ctx, err, "Failed to update food nutrient")
 		return
 	}
 
@@ -199,10 +272,19 @@ func (c *FoodNutrientController) UpdateFoodNutrient(ctx *gin.Context) {
 // @Param        id  path      int  true  "Food nutrient ID"
 // @Success      200  {object}  map[string]string  "Food nutrient deleted successfully"
 // @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — not allowed to modify the food"
+// @Failure      404  {object}  map[string]string  "Food nutrient not found"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Security     BearerAuth
 // @Router       /food-nutrients/{id} [delete]
 func (c *FoodNutrientController) DeleteFoodNutrient(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -210,9 +292,8 @@ func (c *FoodNutrientController) DeleteFoodNutrient(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.service.DeleteFoodNutrient(uint(id)); err != nil {
-		helpers.LogError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete food nutrient"})
+	if err := c.service.DeleteFoodNutrient(uint(id), viewer); err != nil {
+		writeFoodNutrientError(ctx, err, "Failed to delete food nutrient")
 		return
 	}
 
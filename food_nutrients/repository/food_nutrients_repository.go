@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"github.com/momokapoolz/caloriesapp/database"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
 	"github.com/momokapoolz/caloriesapp/food_nutrients/models"
 	"gorm.io/gorm"
 )
@@ -17,7 +20,7 @@ func NewFoodNutrientRepository(db *gorm.DB) *FoodNutrientRepository {
 
 // Create adds a new food nutrient record to the database
 func (r *FoodNutrientRepository) Create(foodNutrient *models.FoodNutrient) error {
-	return r.db.Create(foodNutrient).Error
+	return database.TranslateError(r.db.Create(foodNutrient).Error)
 }
 
 // GetByID retrieves a food nutrient by its ID
@@ -30,10 +33,10 @@ func (r *FoodNutrientRepository) GetByID(id uint) (*models.FoodNutrient, error)
 	return &foodNutrient, nil
 }
 
-// GetAll retrieves all food nutrients
-func (r *FoodNutrientRepository) GetAll() ([]models.FoodNutrient, error) {
+// GetVisible retrieves the food nutrients of every food the viewer may see
+func (r *FoodNutrientRepository) GetVisible(viewer foodModels.FoodViewer) ([]models.FoodNutrient, error) {
 	var foodNutrients []models.FoodNutrient
-	err := r.db.Find(&foodNutrients).Error
+	err := r.db.Scopes(r.ofVisibleFoods(viewer)).Find(&foodNutrients).Error
 	return foodNutrients, err
 }
 
@@ -44,10 +47,30 @@ func (r *FoodNutrientRepository) GetByFoodID(foodID uint) ([]models.FoodNutrient
 	return foodNutrients, err
 }
 
-// GetByNutrientID retrieves food nutrients by nutrient ID
-func (r *FoodNutrientRepository) GetByNutrientID(nutrientID uint) ([]models.FoodNutrient, error) {
+// GetByFoodIDs retrieves food nutrients for several foods in a single query
+func (r *FoodNutrientRepository) GetByFoodIDs(foodIDs []uint) ([]models.FoodNutrient, error) {
 	var foodNutrients []models.FoodNutrient
-	err := r.db.Where("nutrient_id = ?", nutrientID).Find(&foodNutrients).Error
+	if len(foodIDs) == 0 {
+		return foodNutrients, nil
+	}
+	err := r.db.Where("food_id IN ?", foodIDs).Find(&foodNutrients).Error
+	return foodNutrients, err
+}
+
+// GetByFoodIDsAndNutrientIDs retrieves the rows of only some nutrients for several foods in a single query
+func (r *FoodNutrientRepository) GetByFoodIDsAndNutrientIDs(foodIDs, nutrientIDs []uint) ([]models.FoodNutrient, error) {
+	var foodNutrients []models.FoodNutrient
+	if len(foodIDs) == 0 || len(nutrientIDs) == 0 {
+		return foodNutrients, nil
+	}
+	err := r.db.Where("food_id IN ? AND nutrient_id IN ?", foodIDs, nutrientIDs).Find(&foodNutrients).Error
+	return foodNutrients, err
+}
+
+// GetVisibleByNutrientID retrieves the food nutrients of a nutrient among the foods the viewer may see
+func (r *FoodNutrientRepository) GetVisibleByNutrientID(nutrientID uint, viewer foodModels.FoodViewer) ([]models.FoodNutrient, error) {
+	var foodNutrients []models.FoodNutrient
+	err := r.db.Scopes(r.ofVisibleFoods(viewer)).Where("nutrient_id = ?", nutrientID).Find(&foodNutrients).Error
 	return foodNutrients, err
 }
 
@@ -63,10 +86,18 @@ func (r *FoodNutrientRepository) GetByFoodIDAndNutrientID(foodID, nutrientID uin
 
 // Update updates a food nutrient record
 func (r *FoodNutrientRepository) Update(foodNutrient *models.FoodNutrient) error {
-	return r.db.Save(foodNutrient).Error
+	return database.TranslateError(r.db.Save(foodNutrient).Error)
 }
 
 // Delete removes a food nutrient record
 func (r *FoodNutrientRepository) Delete(id uint) error {
-	return r.db.Delete(&models.FoodNutrient{}, id).Error
+	return database.TranslateError(r.db.Delete(&models.FoodNutrient{}, id).Error)
+}
+
+// ofVisibleFoods limits a food nutrient query to the rows of foods the viewer may see
+func (r *FoodNutrientRepository) ofVisibleFoods(viewer foodModels.FoodViewer) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		visible := r.db.Model(&foodModels.Food{}).Select("food.id").Scopes(foodRepo.VisibleTo(viewer))
+		return db.Where("food_id IN (?)", visible)
+	}
 }
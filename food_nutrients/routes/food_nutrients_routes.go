@@ -2,19 +2,41 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
 	"github.com/momokapoolz/caloriesapp/food_nutrients/controllers"
 	"github.com/momokapoolz/caloriesapp/food_nutrients/repository"
 	"github.com/momokapoolz/caloriesapp/food_nutrients/services"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	recipeRepo "github.com/momokapoolz/caloriesapp/recipe/repository"
+	recipeServices "github.com/momokapoolz/caloriesapp/recipe/services"
 	"gorm.io/gorm"
 )
 
 // SetupFoodNutrientRoutes initializes food nutrient routes
 func SetupFoodNutrientRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	foodNutrientRepo := repository.NewFoodNutrientRepository(db)
-	foodNutrientService := services.NewFoodNutrientService(foodNutrientRepo)
+	foodRepository := foodRepo.NewFoodRepository(db)
+	nutrientRepository := nutrientRepo.NewNutrientRepository(db)
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepo.NewMealLogItemRepository(db),
+		foodRepository,
+		foodNutrientRepo,
+		nutrientRepository,
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(dailyNutritionRepo.NewDailyNutritionRepository(db), mealLogRepo.NewMealLogRepository(db), engine)
+	recipes := recipeServices.NewRecipeService(recipeRepo.NewRecipeRepository(db), foodRepository, foodNutrientRepo, nutrientRepository, rollup)
+	foodNutrientService := services.NewFoodNutrientService(foodNutrientRepo, foodRepository, rollup, recipes)
 	foodNutrientController := controllers.NewFoodNutrientController(foodNutrientService)
 
-	foodNutrientRoutes := router.Group("/food-nutrients")
+	authMiddleware := auth.NewAuthMiddleware()
+
+	foodNutrientRoutes := router.Group("/food-nutrients", authMiddleware.RequireAuth())
 	{
 		foodNutrientRoutes.POST("/", foodNutrientController.CreateFoodNutrient)
 		foodNutrientRoutes.GET("/", foodNutrientController.GetAllFoodNutrients)
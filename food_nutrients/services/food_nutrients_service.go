@@ -1,51 +1,156 @@
 package services
 
 import (
+	"errors"
+
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
 	"github.com/momokapoolz/caloriesapp/food_nutrients/models"
 	"github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	recipeServices "github.com/momokapoolz/caloriesapp/recipe/services"
 )
 
+// ErrFoodNutrientNotAccessible is returned when the viewer may see a food but not change its nutrients
+var ErrFoodNutrientNotAccessible = errors.New("not allowed to modify the nutrients of this food")
+
 // FoodNutrientService handles business logic for food nutrient operations
 type FoodNutrientService struct {
-	repo *repository.FoodNutrientRepository
+	repo     *repository.FoodNutrientRepository
+	foodRepo *foodRepo.FoodRepository
+	rollup   *dailyNutritionServices.DailyNutritionService
+	recipes  *recipeServices.RecipeService
 }
 
 // NewFoodNutrientService creates a new food nutrient service instance
-func NewFoodNutrientService(repo *repository.FoodNutrientRepository) *FoodNutrientService {
-	return &FoodNutrientService{repo: repo}
+func NewFoodNutrientService(
+	repo *repository.FoodNutrientRepository,
+	foodRepo *foodRepo.FoodRepository,
+	rollup *dailyNutritionServices.DailyNutritionService,
+	recipes *recipeServices.RecipeService,
+) *FoodNutrientService {
+	return &FoodNutrientService{repo: repo, foodRepo: foodRepo, rollup: rollup, recipes: recipes}
 }
 
-// CreateFoodNutrient creates a new food nutrient record
-func (s *FoodNutrientService) CreateFoodNutrient(foodNutrient *models.FoodNutrient) error {
-	return s.repo.Create(foodNutrient)
+// CreateFoodNutrient adds a nutrient to a food the viewer may modify
+func (s *FoodNutrientService) CreateFoodNutrient(foodNutrient *models.FoodNutrient, viewer foodModels.FoodViewer) error {
+	if err := s.checkFood(foodNutrient.FoodID, viewer); err != nil {
+		return err
+	}
+	if err := s.rollup.MarkFoodsStale(foodNutrient.FoodID); err != nil {
+		return err
+	}
+	if err := s.repo.Create(foodNutrient); err != nil {
+		return err
+	}
+
+	s.refreshRollup(foodNutrient.FoodID)
+	return nil
 }
 
-// GetFoodNutrientByID retrieves a food nutrient record by ID
-func (s *FoodNutrientService) GetFoodNutrientByID(id uint) (*models.FoodNutrient, error) {
-	return s.repo.GetByID(id)
+// GetFoodNutrientByID retrieves a food nutrient of a food the viewer may see
+func (s *FoodNutrientService) GetFoodNutrientByID(id uint, viewer foodModels.FoodViewer) (*models.FoodNutrient, error) {
+	foodNutrient, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.foodRepo.GetVisibleByID(foodNutrient.FoodID, viewer); err != nil {
+		return nil, err
+	}
+	return foodNutrient, nil
 }
 
-// GetAllFoodNutrients retrieves all food nutrient records
-func (s *FoodNutrientService) GetAllFoodNutrients() ([]models.FoodNutrient, error) {
-	return s.repo.GetAll()
+// GetAllFoodNutrients retrieves the food nutrients of every food the viewer may see
+func (s *FoodNutrientService) GetAllFoodNutrients(viewer foodModels.FoodViewer) ([]models.FoodNutrient, error) {
+	return s.repo.GetVisible(viewer)
 }
 
-// GetFoodNutrientsByFoodID retrieves food nutrients by food ID
-func (s *FoodNutrientService) GetFoodNutrientsByFoodID(foodID uint) ([]models.FoodNutrient, error) {
+// GetFoodNutrientsByFoodID retrieves the nutrients of a food the viewer may see
+func (s *FoodNutrientService) GetFoodNutrientsByFoodID(foodID uint, viewer foodModels.FoodViewer) ([]models.FoodNutrient, error) {
+	if _, err := s.foodRepo.GetVisibleByID(foodID, viewer); err != nil {
+		return nil, err
+	}
 	return s.repo.GetByFoodID(foodID)
 }
 
-// GetFoodNutrientsByNutrientID retrieves food nutrients by nutrient ID
-func (s *FoodNutrientService) GetFoodNutrientsByNutrientID(nutrientID uint) ([]models.FoodNutrient, error) {
-	return s.repo.GetByNutrientID(nutrientID)
+// GetFoodNutrientsByNutrientID retrieves the food nutrients of a nutrient among the foods the viewer may see
+func (s *FoodNutrientService) GetFoodNutrientsByNutrientID(nutrientID uint, viewer foodModels.FoodViewer) ([]models.FoodNutrient, error) {
+	return s.repo.GetVisibleByNutrientID(nutrientID, viewer)
+}
+
+// UpdateFoodNutrient updates a nutrient of a food the viewer may modify. Moving it to another
+// food requires being allowed to modify that food too.
+func (s *FoodNutrientService) UpdateFoodNutrient(foodNutrient *models.FoodNutrient, viewer foodModels.FoodViewer) error {
+	existing, err := s.repo.GetByID(foodNutrient.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkFood(existing.FoodID, viewer); err != nil {
+		return err
+	}
+	if existing.FoodID != foodNutrient.FoodID {
+		if err := s.checkFood(foodNutrient.FoodID, viewer); err != nil {
+			return err
+		}
+	}
+	if err := s.rollup.MarkFoodsStale(existing.FoodID, foodNutrient.FoodID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Update(foodNutrient); err != nil {
+		return err
+	}
+
+	s.refreshRollup(foodNutrient.FoodID)
+	if existing.FoodID != foodNutrient.FoodID {
+		s.refreshRollup(existing.FoodID)
+	}
+	return nil
+}
+
+// DeleteFoodNutrient removes a nutrient of a food the viewer may modify
+func (s *FoodNutrientService) DeleteFoodNutrient(id uint, viewer foodModels.FoodViewer) error {
+	foodNutrient, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkFood(foodNutrient.FoodID, viewer); err != nil {
+		return err
+	}
+	if err := s.rollup.MarkFoodsStale(foodNutrient.FoodID); err != nil {
+		return err
+	}
+
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.refreshRollup(foodNutrient.FoodID)
+	return nil
 }
 
-// UpdateFoodNutrient updates a food nutrient record
-func (s *FoodNutrientService) UpdateFoodNutrient(foodNutrient *models.FoodNutrient) error {
-	return s.repo.Update(foodNutrient)
+// checkFood checks that the viewer may change the nutrients of a food, under the same
+// rules as the food itself
+func (s *FoodNutrientService) checkFood(foodID uint, viewer foodModels.FoodViewer) error {
+	food, err := s.foodRepo.GetVisibleByID(foodID, viewer)
+	if err != nil {
+		return err
+	}
+	if !viewer.CanModify(food) {
+		return ErrFoodNutrientNotAccessible
+	}
+	return nil
 }
 
-// DeleteFoodNutrient removes a food nutrient record
-func (s *FoodNutrientService) DeleteFoodNutrient(id uint) error {
-	return s.repo.Delete(id)
-} 
\ No newline at end of file
+// refreshRollup recomputes the daily nutrition rollup of every day the food was logged on
+// and the recipes using the food as an ingredient. The days were marked stale before the
+// write, so failures are only logged: readers fall back to the raw rows.
+func (s *FoodNutrientService) refreshRollup(foodID uint) {
+	if err := s.rollup.RefreshFoods(foodID); err != nil {
+		helpers.LogError(err)
+	}
+	if err := s.recipes.RefreshFoods(foodID); err != nil {
+		helpers.LogError(err)
+	}
+}
@@ -0,0 +1,228 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/food_portion/models"
+	"github.com/momokapoolz/caloriesapp/food_portion/services"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	"gorm.io/gorm"
+)
+
+// FoodPortionController handles HTTP requests for food portion operations
+type FoodPortionController struct {
+	service *services.FoodPortionService
+}
+
+// NewFoodPortionController creates a new food portion controller instance
+func NewFoodPortionController(service *services.FoodPortionService) *FoodPortionController {
+	return &FoodPortionController{service: service}
+}
+
+// writePortionError maps food portion service errors to responses; fallback is used for unexpected errors
+func writePortionError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalidPortionRequest), errors.Is(err, services.ErrReservedUnit):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrPortionNotAccessible):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to modify the portions of this food"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Food portion not found"})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// CreateFoodPortion godoc
+// @Summary      Create food portion
+// @Description  Add a household measure (cup, slice, tbsp, piece...) with its gram weight to a food. Units are stored in canonical form, so "tablespoons" becomes "tbsp". Mass units and "serving" are available for every food and cannot be redefined.
+// @Tags         food_portion
+// @Accept       json
+// @Produce      json
+// @Param        food_portion  body      models.FoodPortion  true  "Food portion data"
+// @Success      201  {object}  models.FoodPortion  "Food portion created successfully"
+// @Failure      400  {object}  map[string]string   "Invalid request body"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      403  {object}  map[string]string   "Forbidden — not the owner of the food"
+// @Failure      404  {object}  map[string]string   "Food not found"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /food-portions/ [post]
+func (c *FoodPortionController) CreateFoodPortion(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var portion models.FoodPortion
+	if err := ctx.ShouldBindJSON(&portion); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	portion.ID = 0
+	if err := c.service.CreateFoodPortion(&portion, viewer); err != nil {
+		writePortionError(ctx, err, "Failed to create food portion")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, portion)
+}
+
+// GetFoodPortion godoc
+// @Summary      Get food portion by ID
+// @Description  Retrieve a food portion record by its ID
+// @Tags         food_portion
+// @Produce      json
+// @Param        id  path      int  true  "Food portion ID"
+// @Success      200  {object}  models.FoodPortion  "Food portion retrieved successfully"
+// @Failure      400  {object}  map[string]string   "Invalid ID format"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      404  {object}  map[string]string   "Food portion not found"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /food-portions/{id} [get]
+func (c *FoodPortionController) GetFoodPortion(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	portion, err := c.service.GetFoodPortionByID(uint(id), viewer)
+	if err != nil {
+		writePortionError(ctx, err, "Failed to retrieve food portion")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, portion)
+}
+
+// GetFoodPortionsByFoodID godoc
+// @Summary      Get food portions by food ID
+// @Description  Retrieve the household measures of a food. Mass units (g, kg, mg, oz, lb) and "serving" are always available in addition.
+// @Tags         food_portion
+// @Produce      json
+// @Param        foodId  path      int  true  "Food ID"
+// @Success      200  {array}   models.FoodPortion  "Food portions retrieved successfully"
+// @Failure      400  {object}  map[string]string   "Invalid food ID format"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      404  {object}  map[string]string   "Food not found"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /food-portions/food/{foodId} [get]
+func (c *FoodPortionController) GetFoodPortionsByFoodID(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	foodID, err := strconv.ParseUint(ctx.Param("foodId"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid food ID format"})
+		return
+	}
+
+	portions, err := c.service.GetFoodPortionsByFoodID(uint(foodID), viewer)
+	if err != nil {
+		writePortionError(ctx, err, "Failed to retrieve food portions")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, portions)
+}
+
+// UpdateFoodPortion godoc
+// @Summary      Update food portion
+// @Description  Update a food portion. Items already logged keep the grams resolved when they were logged.
+// @Tags         food_portion
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int                 true  "Food portion ID"
+// @Param        food_portion  body      models.FoodPortion  true  "Updated food portion data"
+// @Success      200  {object}  models.FoodPortion  "Food portion updated successfully"
+// @Failure      400  {object}  map[string]string   "Invalid ID or request body"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
+// @Failure      403  {object}  map[string]string   "Forbidden — not the owner of the food"
+// @Failure      404  {object}  map[string]string   "Food portion not found"
+// @Failure      500  {object}  map[string]string   "Internal server error"
+// @Security     BearerAuth
+// @Router       /food-portions/{id} [put]
+func (c *FoodPortionController) UpdateFoodPortion(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var portion models.FoodPortion
+	if err := ctx.ShouldBindJSON(&portion); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	portion.ID = uint(id)
+	if err := c.service.UpdateFoodPortion(&portion, viewer); err != nil {
+		writePortionError(ctx, err, "Failed to update food portion")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, portion)
+}
+
+// DeleteFoodPortion godoc
+// @Summary      Delete food portion
+// @Description  Delete a food portion by ID
+// @Tags         food_portion
+// @Produce      json
+// @Param        id  path      int  true  "Food portion ID"
+// @Success      200  {object}  map[string]string  "Food portion deleted successfully"
+// @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — not the owner of the food"
+// @Failure      404  {object}  map[string]string  "Food portion not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /food-portions/{id} [delete]
+func (c *FoodPortionController) DeleteFoodPortion(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := c.service.DeleteFoodPortion(uint(id), viewer); err != nil {
+		writePortionError(ctx, err, "Failed to delete food portion")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Food portion deleted successfully"})
+}
@@ -0,0 +1,16 @@
+package models
+
+// FoodPortion represents the food_portion table in the database. A portion is a
+// household measure of a food, such as a cup or a slice, and its weight in grams.
+type FoodPortion struct {
+	ID          uint    `gorm:"primaryKey;column:id" json:"id"`
+	FoodID      uint    `gorm:"column:food_id;not null;uniqueIndex:idx_food_portion_food_unit,priority:1" json:"food_id"`
+	Unit        string  `gorm:"column:unit;type:varchar(32);not null;uniqueIndex:idx_food_portion_food_unit,priority:2" json:"unit"`
+	Description string  `gorm:"column:description;not null;default:''" json:"description"`
+	GramWeight  float64 `gorm:"column:gram_weight;not null" json:"gram_weight"`
+}
+
+// TableName specifies the table name for the FoodPortion model
+func (FoodPortion) TableName() string {
+	return "food_portion"
+}
@@ -0,0 +1,59 @@
+package repository
+
+import (
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/food_portion/models"
+	"gorm.io/gorm"
+)
+
+// FoodPortionRepository handles all database operations for the FoodPortion model
+type FoodPortionRepository struct {
+	db *gorm.DB
+}
+
+// NewFoodPortionRepository creates a new food portion repository instance
+func NewFoodPortionRepository(db *gorm.DB) *FoodPortionRepository {
+	return &FoodPortionRepository{db: db}
+}
+
+// Create adds a new food portion record to the database
+func (r *FoodPortionRepository) Create(portion *models.FoodPortion) error {
+	return database.TranslateError(r.db.Create(portion).Error)
+}
+
+// GetByID retrieves a food portion by its ID
+func (r *FoodPortionRepository) GetByID(id uint) (*models.FoodPortion, error) {
+	var portion models.FoodPortion
+	err := r.db.Where("id = ?", id).First(&portion).Error
+	if err != nil {
+		return nil, err
+	}
+	return &portion, nil
+}
+
+// GetByFoodID retrieves the portions of a food
+func (r *FoodPortionRepository) GetByFoodID(foodID uint) ([]models.FoodPortion, error) {
+	var portions []models.FoodPortion
+	err := r.db.Where("food_id = ?", foodID).Order("id").Find(&portions).Error
+	return portions, err
+}
+
+// GetByFoodIDs retrieves the portions of several foods in a single query
+func (r *FoodPortionRepository) GetByFoodIDs(foodIDs []uint) ([]models.FoodPortion, error) {
+	var portions []models.FoodPortion
+	if len(foodIDs) == 0 {
+		return portions, nil
+	}
+	err := r.db.Where("food_id IN ?", foodIDs).Find(&portions).Error
+	return portions, err
+}
+
+// Update updates a food portion record
+func (r *FoodPortionRepository) Update(portion *models.FoodPortion) error {
+	return database.TranslateError(r.db.Save(portion).Error)
+}
+
+// Delete removes a food portion record
+func (r *FoodPortionRepository) Delete(id uint) error {
+	return database.TranslateError(r.db.Delete(&models.FoodPortion{}, id).Error)
+}
@@ -0,0 +1,28 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	"github.com/momokapoolz/caloriesapp/food_portion/controllers"
+	"github.com/momokapoolz/caloriesapp/food_portion/repository"
+	"github.com/momokapoolz/caloriesapp/food_portion/services"
+	"gorm.io/gorm"
+)
+
+// SetupFoodPortionRoutes initializes food portion routes
+func SetupFoodPortionRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	foodPortionService := services.NewFoodPortionService(repository.NewFoodPortionRepository(db), foodRepo.NewFoodRepository(db))
+	foodPortionController := controllers.NewFoodPortionController(foodPortionService)
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	foodPortionRoutes := router.Group("/food-portions", authMiddleware.RequireAuth())
+	{
+		foodPortionRoutes.POST("/", foodPortionController.CreateFoodPortion)
+		foodPortionRoutes.GET("/:id", foodPortionController.GetFoodPortion)
+		foodPortionRoutes.GET("/food/:foodId", foodPortionController.GetFoodPortionsByFoodID)
+		foodPortionRoutes.PUT("/:id", foodPortionController.UpdateFoodPortion)
+		foodPortionRoutes.DELETE("/:id", foodPortionController.DeleteFoodPortion)
+	}
+}
@@ -0,0 +1,142 @@
+package services
+
+import (
+	"errors"
+	"strings"
+
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	"github.com/momokapoolz/caloriesapp/food_portion/models"
+	"github.com/momokapoolz/caloriesapp/food_portion/repository"
+)
+
+// Error definitions
+var (
+	ErrFoodNotFound          = errors.New("food not found")
+	ErrUnknownUnit           = errors.New("unknown unit")
+	ErrInvalidAmount         = errors.New("amount must be greater than zero")
+	ErrReservedUnit          = errors.New("unit is available for every food and cannot name a portion")
+	ErrPortionNotAccessible  = errors.New("not allowed to modify the portions of this food")
+	ErrInvalidPortionRequest = errors.New("portion needs a unit and a positive gram weight")
+)
+
+// FoodPortionService handles business logic for food portion operations
+type FoodPortionService struct {
+	repo     *repository.FoodPortionRepository
+	foodRepo *foodRepo.FoodRepository
+}
+
+// NewFoodPortionService creates a new food portion service instance
+func NewFoodPortionService(repo *repository.FoodPortionRepository, foodRepo *foodRepo.FoodRepository) *FoodPortionService {
+	return &FoodPortionService{repo: repo, foodRepo: foodRepo}
+}
+
+// CreateFoodPortion adds a portion to a food the viewer may modify
+func (s *FoodPortionService) CreateFoodPortion(portion *models.FoodPortion, viewer foodModels.FoodViewer) error {
+	if err := s.checkPortion(portion, viewer); err != nil {
+		return err
+	}
+	return s.repo.Create(portion)
+}
+
+// GetFoodPortionByID retrieves a portion of a food the viewer may see
+func (s *FoodPortionService) GetFoodPortionByID(id uint, viewer foodModels.FoodViewer) (*models.FoodPortion, error) {
+	portion, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.foodRepo.GetVisibleByID(portion.FoodID, viewer); err != nil {
+		return nil, err
+	}
+	return portion, nil
+}
+
+// GetFoodPortionsByFoodID retrieves the portions of a food the viewer may see
+func (s *FoodPortionService) GetFoodPortionsByFoodID(foodID uint, viewer foodModels.FoodViewer) ([]models.FoodPortion, error) {
+	if _, err := s.foodRepo.GetVisibleByID(foodID, viewer); err != nil {
+		return nil, err
+	}
+	return s.repo.GetByFoodID(foodID)
+}
+
+// UpdateFoodPortion updates a portion of a food the viewer may modify. Logged items keep
+// the grams resolved when they were logged.
+func (s *FoodPortionService) UpdateFoodPortion(portion *models.FoodPortion, viewer foodModels.FoodViewer) error {
+	existing, err := s.repo.GetByID(portion.ID)
+	if err != nil {
+		return err
+	}
+	if err := s.checkFood(existing.FoodID, viewer); err != nil {
+		return err
+	}
+	if err := s.checkPortion(portion, viewer); err != nil {
+		return err
+	}
+	return s.repo.Update(portion)
+}
+
+// DeleteFoodPortion removes a portion of a food the viewer may modify
+func (s *FoodPortionService) DeleteFoodPortion(id uint, viewer foodModels.FoodViewer) error {
+	portion, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if err := s.checkFood(portion.FoodID, viewer); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// NewGramResolver loads the foods and portions needed to convert amounts of the given foods into grams.
+// Only the foods the viewer may see are loaded; the resolver reports the others as missing.
+func (s *FoodPortionService) NewGramResolver(foodIDs []uint, viewer foodModels.FoodViewer) (*GramResolver, error) {
+	foods, err := s.foodRepo.GetVisibleByIDs(foodIDs, viewer)
+	if err != nil {
+		return nil, err
+	}
+	portions, err := s.repo.GetByFoodIDs(foodIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	resolver := &GramResolver{
+		foods:    make(map[uint]foodModels.Food, len(foods)),
+		portions: make(map[uint]map[string]float64, len(foods)),
+	}
+	for _, food := range foods {
+		resolver.foods[food.ID] = food
+	}
+	for _, portion := range portions {
+		if resolver.portions[portion.FoodID] == nil {
+			resolver.portions[portion.FoodID] = make(map[string]float64)
+		}
+		resolver.portions[portion.FoodID][portion.Unit] = portion.GramWeight
+	}
+	return resolver, nil
+}
+
+// checkPortion validates a portion and checks that the viewer may modify its food
+func (s *FoodPortionService) checkPortion(portion *models.FoodPortion, viewer foodModels.FoodViewer) error {
+	portion.Unit = NormalizeUnit(portion.Unit)
+	portion.Description = strings.TrimSpace(portion.Description)
+	if portion.Unit == "" || portion.GramWeight <= 0 {
+		return ErrInvalidPortionRequest
+	}
+	if isBuiltinUnit(portion.Unit) {
+		return ErrReservedUnit
+	}
+	return s.checkFood(portion.FoodID, viewer)
+}
+
+// checkFood checks that the viewer may change the portions of a food, under the same
+// rules as the food itself
+func (s *FoodPortionService) checkFood(foodID uint, viewer foodModels.FoodViewer) error {
+	food, err := s.foodRepo.GetVisibleByID(foodID, viewer)
+	if err != nil {
+		return err
+	}
+	if !viewer.CanModify(food) {
+		return ErrPortionNotAccessible
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"fmt"
+
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+)
+
+// GramResolver converts amounts of foods in any unit into grams. It is loaded once
+// for a set of foods, so a whole meal is resolved with a constant number of queries.
+type GramResolver struct {
+	foods    map[uint]foodModels.Food
+	portions map[uint]map[string]float64
+}
+
+// HasFood reports whether a food was found when the resolver was loaded
+func (r *GramResolver) HasFood(foodID uint) bool {
+	_, ok := r.foods[foodID]
+	return ok
+}
+
+// Grams returns the weight of amount units of a food. Mass units apply to every food,
+// "serving" uses the food's serving size and other units name one of its portions.
+func (r *GramResolver) Grams(foodID uint, amount float64, unit string) (float64, error) {
+	if amount <= 0 {
+		return 0, ErrInvalidAmount
+	}
+
+	unit = NormalizeUnit(unit)
+	if grams, ok := massUnits[unit]; ok {
+		return amount * grams, nil
+	}
+
+	food, ok := r.foods[foodID]
+	if !ok {
+		return 0, fmt.Errorf("%w: %d", ErrFoodNotFound, foodID)
+	}
+	if unit == UnitServing {
+		return amount * food.ServingSizeGram, nil
+	}
+	if grams, ok := r.portions[foodID][unit]; ok {
+		return amount * grams, nil
+	}
+	return 0, fmt.Errorf("%w: %q for food %d", ErrUnknownUnit, unit, foodID)
+}
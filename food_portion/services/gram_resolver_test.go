@@ -0,0 +1,56 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+)
+
+func TestGramResolver(t *testing.T) {
+	resolver := &GramResolver{
+		foods: map[uint]foodModels.Food{
+			1: {ID: 1, Name: "Milk", ServingSizeGram: 244},
+		},
+		portions: map[uint]map[string]float64{
+			1: {"cup": 244, "tbsp": 15.2},
+		},
+	}
+
+	cases := []struct {
+		amount float64
+		unit   string
+		want   float64
+	}{
+		{1.5, "cups", 366},
+		{2, "Tablespoons", 30.4},
+		{2, "serving", 488},
+		{8, "oz", 226.796185},
+	}
+	for _, c := range cases {
+		got, err := resolver.Grams(1, c.amount, c.unit)
+		if err != nil {
+			t.Fatalf("Grams(%v, %q): unexpected error: %v", c.amount, c.unit, err)
+		}
+		if math.Abs(got-c.want) > 1e-9 {
+			t.Errorf("Grams(%v, %q) = %v, want %v", c.amount, c.unit, got, c.want)
+		}
+	}
+
+	if _, err := resolver.Grams(1, 1, "slice"); !errors.Is(err, ErrUnknownUnit) {
+		t.Fatalf("expected ErrUnknownUnit for a portion the food lacks, got %v", err)
+	}
+	if _, err := resolver.Grams(1, 0, "cup"); !errors.Is(err, ErrInvalidAmount) {
+		t.Fatalf("expected ErrInvalidAmount, got %v", err)
+	}
+	if _, err := resolver.Grams(2, 1, "serving"); !errors.Is(err, ErrFoodNotFound) {
+		t.Fatalf("expected ErrFoodNotFound, got %v", err)
+	}
+	if got, err := resolver.Grams(2, 100, "g"); err != nil || got != 100 {
+		t.Fatalf("mass units must not need the food, got %v, %v", got, err)
+	}
+	if !resolver.HasFood(1) || resolver.HasFood(2) {
+		t.Fatalf("HasFood should report only the loaded food")
+	}
+}
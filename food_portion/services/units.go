@@ -0,0 +1,62 @@
+package services
+
+import (
+	"strings"
+)
+
+// Units every food can be logged in without a portion
+const (
+	// UnitGram logs an amount in grams
+	UnitGram = "g"
+	// UnitServing logs a number of servings of the food's serving_size_gram
+	UnitServing = "serving"
+)
+
+// massUnits holds the weight in grams of the mass units every food can be logged in
+var massUnits = map[string]float64{
+	UnitGram: 1,
+	"kg":     1000,
+	"mg":     0.001,
+	"oz":     28.349523125,
+	"lb":     453.59237,
+}
+
+// unitAliases maps spelled out and plural unit names onto their canonical name
+var unitAliases = map[string]string{
+	"gram":        UnitGram,
+	"grams":       UnitGram,
+	"gr":          UnitGram,
+	"kilogram":    "kg",
+	"kilograms":   "kg",
+	"milligram":   "mg",
+	"milligrams":  "mg",
+	"ounce":       "oz",
+	"ounces":      "oz",
+	"pound":       "lb",
+	"pounds":      "lb",
+	"lbs":         "lb",
+	"servings":    UnitServing,
+	"tablespoon":  "tbsp",
+	"tablespoons": "tbsp",
+	"teaspoon":    "tsp",
+	"teaspoons":   "tsp",
+	"cups":        "cup",
+	"slices":      "slice",
+	"pieces":      "piece",
+}
+
+// NormalizeUnit returns the canonical name of a unit, so "Tablespoons" and "tbsp"
+// select the same portion
+func NormalizeUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	if canonical, ok := unitAliases[unit]; ok {
+		return canonical
+	}
+	return unit
+}
+
+// isBuiltinUnit reports whether a unit is available for every food, so it cannot name a portion
+func isBuiltinUnit(unit string) bool {
+	_, ok := massUnits[unit]
+	return ok || unit == UnitServing
+}
@@ -0,0 +1,24 @@
+package helpers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/database"
+)
+
+// WriteConstraintError answers a write the database rejected for breaking a constraint: 409 when
+// it conflicts with existing records, 422 when it carries a missing reference or an invalid
+// value. It reports whether err was such a violation.
+func WriteConstraintError(ctx *gin.Context, err error) bool {
+	switch {
+	case errors.Is(err, database.ErrDuplicate), errors.Is(err, database.ErrStillReferenced):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	case errors.Is(err, database.ErrReferenceNotFound), errors.Is(err, database.ErrInvalidValue):
+		ctx.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	default:
+		return false
+	}
+	return true
+}
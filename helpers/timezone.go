@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// DefaultTimezone is the timezone of users who have not picked one
+const DefaultTimezone = "UTC"
+
+// ErrInvalidTimezone is returned for a timezone that is not an IANA name such as "Europe/Paris"
+var ErrInvalidTimezone = errors.New("invalid timezone")
+
+// LoadTimezone resolves an IANA timezone name. "Local" is rejected: it names the server's zone,
+// which says nothing about the user's.
+func LoadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %q", ErrInvalidTimezone, name)
+	}
+	return loc, nil
+}
+
+// DayStart returns the instant the calendar date of date begins in loc; only the year, month and
+// day of date are read. Where a DST change skips midnight the day begins at the change.
+func DayStart(date time.Time, loc *time.Location) time.Time {
+	year, month, day := date.Date()
+	start := time.Date(year, month, day, 0, 0, 0, 0, loc)
+	if start.Day() != day {
+		// time.Date resolved the skipped midnight with the offset before the change, which lands on
+		// the previous evening; the change itself happens at that offset's midnight
+		hour, min, sec := start.Clock()
+		start = start.Add(24*time.Hour - time.Duration(hour)*time.Hour - time.Duration(min)*time.Minute - time.Duration(sec)*time.Second)
+	}
+	return start
+}
+
+// DayBounds returns the instants the calendar date of date begins and ends at in loc. The day is
+// not always 24 hours long: across a DST change it lasts 23 or 25.
+func DayBounds(date time.Time, loc *time.Location) (time.Time, time.Time) {
+	return DayStart(date, loc), DayStart(date.AddDate(0, 0, 1), loc)
+}
+
+// DateIn formats the calendar date t falls on in loc as YYYY-MM-DD
+func DateIn(t time.Time, loc *time.Location) string {
+	return t.In(loc).Format("2006-01-02")
+}
@@ -0,0 +1,58 @@
+package helpers
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDayBounds(t *testing.T) {
+	cases := []struct {
+		zone   string
+		date   string
+		start  string
+		length time.Duration
+	}{
+		{"UTC", "2024-06-01", "2024-06-01T00:00:00Z", 24 * time.Hour},
+		{"Europe/Paris", "2024-03-31", "2024-03-31T00:00:00+01:00", 23 * time.Hour},
+		{"Europe/Paris", "2024-10-27", "2024-10-27T00:00:00+02:00", 25 * time.Hour},
+		{"America/New_York", "2024-11-03", "2024-11-03T00:00:00-04:00", 25 * time.Hour},
+		// Santiago skips midnight when DST starts, so the day begins at 01:00
+		{"America/Santiago", "2022-09-11", "2022-09-11T01:00:00-03:00", 23 * time.Hour},
+	}
+	for _, tc := range cases {
+		loc, err := LoadTimezone(tc.zone)
+		if err != nil {
+			t.Fatalf("LoadTimezone(%q): %v", tc.zone, err)
+		}
+		date, _ := time.Parse("2006-01-02", tc.date)
+		want, _ := time.Parse(time.RFC3339, tc.start)
+
+		start, end := DayBounds(date, loc)
+		if !start.Equal(want) {
+			t.Errorf("%s %s: starts at %s, want %s", tc.zone, tc.date, start, want)
+		}
+		if got := end.Sub(start); got != tc.length {
+			t.Errorf("%s %s: lasts %s, want %s", tc.zone, tc.date, got, tc.length)
+		}
+		if DateIn(start, loc) != tc.date || DateIn(end.Add(-time.Nanosecond), loc) != tc.date {
+			t.Errorf("%s %s: bounds fall outside the day", tc.zone, tc.date)
+		}
+	}
+}
+
+func TestDateIn(t *testing.T) {
+	tokyo, _ := LoadTimezone("Asia/Tokyo")
+	lateSnack := time.Date(2024, 5, 1, 23, 30, 0, 0, time.UTC)
+	if got := DateIn(lateSnack, tokyo); got != "2024-05-02" {
+		t.Errorf("DateIn = %s, want 2024-05-02", got)
+	}
+}
+
+func TestLoadTimezoneRejectsUnknownZones(t *testing.T) {
+	for _, name := range []string{"", "Local", "Mars/Olympus_Mons"} {
+		if _, err := LoadTimezone(name); !errors.Is(err, ErrInvalidTimezone) {
+			t.Errorf("LoadTimezone(%q) = %v, want ErrInvalidTimezone", name, err)
+		}
+	}
+}
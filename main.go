@@ -33,6 +33,14 @@ func main() {
 		log.Println("No .env file found, using environment variables")
 	}
 
+	// Maintenance subcommands run against the database and exit without starting the server
+	if len(os.Args) > 1 {
+		if err := runCommand(os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s failed: %v", os.Args[1], err)
+		}
+		return
+	}
+
 	// INIT SENTRY
 	err := sentry.Init(sentry.ClientOptions{
 		Dsn:              os.Getenv("SENTRY_DSN"),
@@ -48,6 +56,9 @@ func main() {
 
 	log.Println("Connecting to PostgreSQL...")
 	db := database.ConnectDatabase()
+	if err := database.RequireMigrated(db); err != nil {
+		log.Fatalf("Refusing to start: %v", err)
+	}
 	user_database.ConnectDatabase()
 
 	// Create router
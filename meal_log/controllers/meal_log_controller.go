@@ -1,6 +1,8 @@
 package controllers
 
 import (
+	"errors"
+	"io"
 	"net/http"
 	"strconv"
 	"time"
@@ -12,6 +14,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/momokapoolz/caloriesapp/helpers"
 	"github.com/momokapoolz/caloriesapp/meal_log/services"
+	mealLogItemsServices "github.com/momokapoolz/caloriesapp/meal_log_items/services"
 )
 
 // MealLogController handles HTTP requests for meal log operations
@@ -24,9 +27,29 @@ func NewMealLogController(service *services.MealLogService) *MealLogController {
 	return &MealLogController{service: service}
 }
 
+// writeMealLogError maps meal log service errors to responses; fallback is used for unexpected errors
+func writeMealLogError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, mealLogItemsServices.ErrInvalidItem):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, mealLogItemsServices.ErrUnauthorizedAccess):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to access this meal log"})
+	case errors.Is(err, mealLogItemsServices.ErrMealLogNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Meal log not found"})
+	case errors.Is(err, services.ErrNoMealsOnDate):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
 // CreateMealLog godoc
 // @Summary      Create meal log
-// @Description  Create a new meal log with items for the authenticated user
+// @Description  Create a new meal log with items for the authenticated user, consumed at consumed_at (default: now)
 // @Tags         meal_log
 // @Accept       json
 // @Produce      json
@@ -52,6 +75,13 @@ func (c *MealLogController) CreateMealLog(ctx *gin.Context) {
 
 	mealLogWithItems, err := c.service.CreateMealLogComprehensive(userClaims.UserID, req)
 	if err != nil {
+		if errors.Is(err, mealLogItemsServices.ErrInvalidItem) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
@@ -120,7 +150,7 @@ func (c *MealLogController) GetMealLogsByUserID(ctx *gin.Context) {
 
 // GetMealLogsByUserIDAndDate godoc
 // @Summary      Get meal logs by date
-// @Description  Retrieve all meal logs for the authenticated user on a specific date
+// @Description  Retrieve all meal logs the authenticated user consumed on a specific date in their timezone
 // @Tags         meal_log
 // @Produce      json
 // @Param        date  path  string  true  "Date in YYYY-MM-DD format"
@@ -157,7 +187,7 @@ func (c *MealLogController) GetMealLogsByUserIDAndDate(ctx *gin.Context) {
 
 // GetMealLogsByUserIDAndDateRange godoc
 // @Summary      Get meal logs by date range
-// @Description  Retrieve all meal logs for the authenticated user within a date range
+// @Description  Retrieve all meal logs the authenticated user consumed within a date range in their timezone
 // @Tags         meal_log
 // @Produce      json
 // @Param        startDate  query  string  true  "Start date in YYYY-MM-DD format"
@@ -196,8 +226,6 @@ func (c *MealLogController) GetMealLogsByUserIDAndDateRange(ctx *gin.Context) {
 		return
 	}
 
-	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, endDate.Location())
-
 	mealLogs, err := c.service.GetMealLogsByUserIDAndDateRange(userID, startDate, endDate)
 	if err != nil {
 		helpers.LogError(err)
@@ -210,12 +238,12 @@ func (c *MealLogController) GetMealLogsByUserIDAndDateRange(ctx *gin.Context) {
 
 // UpdateMealLog godoc
 // @Summary      Update a meal log
-// @Description  Update the meal type of an existing meal log by ID (ownership required)
+// @Description  Update the meal type and the consumption time of an existing meal log by ID (ownership required)
 // @Tags         meal_log
 // @Accept       json
 // @Produce      json
 // @Param        id        path  int                      true  "Meal log ID"
-// @Param        meal_log  body  object{meal_type=string} true  "Updated meal log data"
+// @Param        meal_log  body  object{meal_type=string,consumed_at=string} true  "Updated meal log data"
 // @Success      200  {object}  dto.CreateMealLogRequestDTO  "Meal log updated successfully"
 // @Failure      400  {object}  map[string]string            "Invalid ID or request body"
 // @Failure      401  {object}  map[string]string            "Unauthorized"
@@ -239,7 +267,7 @@ func (c *MealLogController) UpdateMealLog(ctx *gin.Context) {
 		return
 	}
 
-	// First, get the existing meal log to preserve created_at
+	// First, get the existing meal log to preserve the fields the request cannot change
 	existingMealLog, err := c.service.GetMealLogByID(uint(id))
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{"error": "Meal log not found"})
@@ -254,7 +282,8 @@ func (c *MealLogController) UpdateMealLog(ctx *gin.Context) {
 
 	// Create update request struct to only accept specific fields
 	var updateRequest struct {
-		MealType string `json:"meal_type"`
+		MealType   string     `json:"meal_type"`
+		ConsumedAt *time.Time `json:"consumed_at"`
 	}
 
 	if err := ctx.ShouldBindJSON(&updateRequest); err != nil {
@@ -262,10 +291,16 @@ func (c *MealLogController) UpdateMealLog(ctx *gin.Context) {
 		return
 	}
 
-	// Update only the meal_type, preserve other fields
+	// Update only the meal_type and, when given, consumed_at; preserve other fields
 	existingMealLog.MealType = updateRequest.MealType
+	if updateRequest.ConsumedAt != nil {
+		existingMealLog.ConsumedAt = *updateRequest.ConsumedAt
+	}
 
 	if err := c.service.UpdateMealLog(existingMealLog); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update meal log"})
 		return
@@ -309,6 +344,9 @@ func (c *MealLogController) DeleteMealLog(ctx *gin.Context) {
 	}
 
 	if err := c.service.DeleteMealLog(uint(id)); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete meal log"})
 		return
@@ -316,3 +354,144 @@ func (c *MealLogController) DeleteMealLog(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, gin.H{"message": "Meal log deleted successfully"})
 }
+
+// CopyMealLog godoc
+// @Summary      Copy a meal log
+// @Description  Log a meal again with copies of its items on another date (default: today in the user's timezone) at the same time of day, optionally as another meal type
+// @Tags         meal_log
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                        true   "Meal log ID"
+// @Param        copy  body  dto.CopyMealLogRequestDTO  false  "Target date (YYYY-MM-DD) and meal type"
+// @Success      201  {object}  models.MealLogWithItems  "Meal log copied successfully"
+// @Failure      400  {object}  map[string]string        "Invalid ID, request body or date"
+// @Failure      401  {object}  map[string]string        "Unauthorized"
+// @Failure      403  {object}  map[string]string        "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string        "Meal log not found"
+// @Failure      500  {object}  map[string]string        "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-logs/{id}/copy [post]
+func (c *MealLogController) CopyMealLog(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	// The body is optional: without one the meal is copied to today as the same meal type
+	var req dto.CopyMealLogRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	var date time.Time
+	if req.Date != "" {
+		if date, err = time.Parse("2006-01-02", req.Date); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+			return
+		}
+	}
+
+	copied, err := c.service.CopyMealLog(claims.UserID, uint(id), date, req.MealType)
+	if err != nil {
+		writeMealLogError(ctx, err, "Failed to copy meal log")
+		return
+	}
+	ctx.JSON(http.StatusCreated, copied)
+}
+
+// CopyDay godoc
+// @Summary      Copy a day of meals
+// @Description  Log every meal of from_date again on to_date at the same times of day, with dates in the user's timezone
+// @Tags         meal_log
+// @Accept       json
+// @Produce      json
+// @Param        copy  body  dto.CopyDayRequestDTO  true  "Source and target dates (YYYY-MM-DD)"
+// @Success      201  {array}   models.MealLogWithItems  "Meals copied successfully"
+// @Failure      400  {object}  map[string]string        "Invalid request body or date"
+// @Failure      401  {object}  map[string]string        "Unauthorized"
+// @Failure      404  {object}  map[string]string        "No meals logged on from_date"
+// @Failure      500  {object}  map[string]string        "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-logs/copy-day [post]
+func (c *MealLogController) CopyDay(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.CopyDayRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	fromDate, err := time.Parse("2006-01-02", req.FromDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid from_date format. Use YYYY-MM-DD"})
+		return
+	}
+	toDate, err := time.Parse("2006-01-02", req.ToDate)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid to_date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	copies, err := c.service.CopyDay(claims.UserID, fromDate, toDate)
+	if err != nil {
+		writeMealLogError(ctx, err, "Failed to copy meals")
+		return
+	}
+	ctx.JSON(http.StatusCreated, copies)
+}
+
+// MoveMealLogItems godoc
+// @Summary      Move items between meal logs
+// @Description  Move items of a meal log to another meal log of the same user; either every item moves or none does
+// @Tags         meal_log
+// @Accept       json
+// @Produce      json
+// @Param        id    path  int                             true  "Source meal log ID"
+// @Param        move  body  dto.MoveMealLogItemsRequestDTO  true  "Items to move and the target meal log"
+// @Success      200  {object}  models.MealLogWithItems  "Target meal log with its items"
+// @Failure      400  {object}  map[string]string        "Invalid ID or request body, or an item not in the source meal log"
+// @Failure      401  {object}  map[string]string        "Unauthorized"
+// @Failure      403  {object}  map[string]string        "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string        "Meal log not found"
+// @Failure      500  {object}  map[string]string        "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-logs/{id}/move-items [post]
+func (c *MealLogController) MoveMealLogItems(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	var req dto.MoveMealLogItemsRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body"})
+		return
+	}
+
+	target, err := c.service.MoveMealLogItems(claims.UserID, uint(id), req.TargetMealLogID, req.ItemIDs)
+	if err != nil {
+		writeMealLogError(ctx, err, "Failed to move meal log items")
+		return
+	}
+	ctx.JSON(http.StatusOK, target)
+}
@@ -10,8 +10,10 @@ type MealLog struct {
 	ID        uint      `gorm:"primaryKey;column:id" json:"id"`
 	UserID    uint      `gorm:"column:user_id;not null" json:"user_id"`
 	CreatedAt time.Time `gorm:"column:created_at;not null" json:"created_at"`
-	MealType  string    `gorm:"column:meal_type;not null" json:"meal_type"`
-	Note      string    `gorm:"column:note" json:"note"`
+	// ConsumedAt is when the meal was eaten, which decides the diary day it counts towards
+	ConsumedAt time.Time `gorm:"column:consumed_at;not null" json:"consumed_at"`
+	MealType   string    `gorm:"column:meal_type;not null" json:"meal_type"`
+	Note       string    `gorm:"column:note" json:"note"`
 }
 
 // MealLogWithItems represents a meal log with its associated items
@@ -2,7 +2,10 @@ package repository
 
 import (
 	"time"
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/helpers"
 	"github.com/momokapoolz/caloriesapp/meal_log/models"
+	userModels "github.com/momokapoolz/caloriesapp/user/models"
 	"gorm.io/gorm"
 )
 
@@ -16,9 +19,14 @@ func NewMealLogRepository(db *gorm.DB) *MealLogRepository {
 	return &MealLogRepository{db: db}
 }
 
+// WithTx returns a repository running its queries in the transaction tx
+func (r *MealLogRepository) WithTx(tx *gorm.DB) *MealLogRepository {
+	return &MealLogRepository{db: tx}
+}
+
 // Create adds a new meal log record to the database
 func (r *MealLogRepository) Create(mealLog *models.MealLog) error {
-	return r.db.Create(mealLog).Error
+	return database.TranslateError(r.db.Create(mealLog).Error)
 }
 
 // GetByID retrieves a meal log by its ID
@@ -38,30 +46,44 @@ func (r *MealLogRepository) GetByUserID(userID uint) ([]models.MealLog, error) {
 	return mealLogs, err
 }
 
-// GetByUserIDAndDate retrieves meal logs for a specific user on a specific date
-func (r *MealLogRepository) GetByUserIDAndDate(userID uint, date time.Time) ([]models.MealLog, error) {
-	// Format the date to match the date portion only
-	startDate := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
-	endDate := startDate.Add(24 * time.Hour)
+// GetByUserIDAndDate retrieves the meal logs a user consumed on the calendar date of date in loc
+func (r *MealLogRepository) GetByUserIDAndDate(userID uint, date time.Time, loc *time.Location) ([]models.MealLog, error) {
+	return r.GetByUserIDAndDateRange(userID, date, date, loc)
+}
+
+// GetByUserIDAndDateRange retrieves the meal logs a user consumed from the start of the calendar
+// date of startDate to the end of the calendar date of endDate in loc, in the order they were eaten
+func (r *MealLogRepository) GetByUserIDAndDateRange(userID uint, startDate, endDate time.Time, loc *time.Location) ([]models.MealLog, error) {
+	start := helpers.DayStart(startDate, loc)
+	_, end := helpers.DayBounds(endDate, loc)
 
 	var mealLogs []models.MealLog
-	err := r.db.Where("user_id = ? AND created_at >= ? AND created_at < ?", userID, startDate, endDate).Find(&mealLogs).Error
+	err := r.db.Where("user_id = ? AND consumed_at >= ? AND consumed_at < ?", userID, start, end).
+		Order("consumed_at, id").
+		Find(&mealLogs).Error
 	return mealLogs, err
 }
 
-// GetByUserIDAndDateRange retrieves meal logs for a specific user within a date range
-func (r *MealLogRepository) GetByUserIDAndDateRange(userID uint, startDate, endDate time.Time) ([]models.MealLog, error) {
-	var mealLogs []models.MealLog
-	err := r.db.Where("user_id = ? AND created_at >= ? AND created_at <= ?", userID, startDate, endDate).Find(&mealLogs).Error
-	return mealLogs, err
+// GetUserLocation returns the timezone the diary days of a user are counted in. A stored zone
+// that no longer loads falls back to the default one.
+func (r *MealLogRepository) GetUserLocation(userID uint) (*time.Location, error) {
+	var user userModels.User
+	if err := r.db.Select("timezone").Where("id = ?", userID).First(&user).Error; err != nil {
+		return nil, err
+	}
+	loc, err := helpers.LoadTimezone(user.Timezone)
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
 }
 
 // Update updates a meal log record
 func (r *MealLogRepository) Update(mealLog *models.MealLog) error {
-	return r.db.Save(mealLog).Error
+	return database.TranslateError(r.db.Save(mealLog).Error)
 }
 
 // Delete removes a meal log record
 func (r *MealLogRepository) Delete(id uint) error {
-	return r.db.Delete(&models.MealLog{}, id).Error
+	return database.TranslateError(r.db.Delete(&models.MealLog{}, id).Error)
 } 
\ No newline at end of file
@@ -3,11 +3,19 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/database"
 	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	foodPortionRepo "github.com/momokapoolz/caloriesapp/food_portion/repository"
+	foodPortionServices "github.com/momokapoolz/caloriesapp/food_portion/services"
 	"github.com/momokapoolz/caloriesapp/meal_log/controllers"
 	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
 	"github.com/momokapoolz/caloriesapp/meal_log/services"
 	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
 	"gorm.io/gorm"
 )
 
@@ -16,7 +24,15 @@ func SetupMealLogRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	mealLogRepository := mealLogRepo.NewMealLogRepository(db)
 	mealLogItemsRepository := mealLogItemsRepo.NewMealLogItemRepository(db)
 	foodRepository := foodRepo.NewFoodRepository(db)
-	mealLogService := services.NewMealLogService(mealLogRepository, mealLogItemsRepository, foodRepository)
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepository,
+		foodRepository,
+		foodNutrientsRepo.NewFoodNutrientRepository(db),
+		nutrientRepo.NewNutrientRepository(db),
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(dailyNutritionRepo.NewDailyNutritionRepository(db), mealLogRepository, engine)
+	portions := foodPortionServices.NewFoodPortionService(foodPortionRepo.NewFoodPortionRepository(db), foodRepository)
+	mealLogService := services.NewMealLogService(database.NewUnitOfWork(db), mealLogRepository, mealLogItemsRepository, foodRepository, portions, rollup)
 	mealLogController := controllers.NewMealLogController(mealLogService)
 
 	authMiddleware := auth.NewAuthMiddleware()
@@ -30,5 +46,8 @@ func SetupMealLogRoutes(router *gin.RouterGroup, db *gorm.DB) {
 		mealLogRoutes.GET("/user/date-range", mealLogController.GetMealLogsByUserIDAndDateRange)
 		mealLogRoutes.PUT("/:id", mealLogController.UpdateMealLog)
 		mealLogRoutes.DELETE("/:id", mealLogController.DeleteMealLog)
+		mealLogRoutes.POST("/copy-day", mealLogController.CopyDay)
+		mealLogRoutes.POST("/:id/copy", mealLogController.CopyMealLog)
+		mealLogRoutes.POST("/:id/move-items", mealLogController.MoveMealLogItems)
 	}
 }
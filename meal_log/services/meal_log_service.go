@@ -1,82 +1,125 @@
 package services
 
 import (
+	"errors"
+	"fmt"
 	"time"
 
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/database"
 	"github.com/momokapoolz/caloriesapp/dto"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
 	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodPortionServices "github.com/momokapoolz/caloriesapp/food_portion/services"
 	"github.com/momokapoolz/caloriesapp/helpers"
 	"github.com/momokapoolz/caloriesapp/meal_log/models"
 	"github.com/momokapoolz/caloriesapp/meal_log/repository"
 	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
 	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
 	mealLogItemsServices "github.com/momokapoolz/caloriesapp/meal_log_items/services"
+	"gorm.io/gorm"
 )
 
+// ErrNoMealsOnDate is returned when copying a diary date the user logged no meal on
+var ErrNoMealsOnDate = errors.New("no meals logged on the date")
+
 // MealLogService handles business logic for meal log operations
 type MealLogService struct {
+	uow                *database.UnitOfWork
 	repo               *repository.MealLogRepository
 	mealLogItemsRepo   *mealLogItemsRepo.MealLogItemRepository
+	foodRepo           *foodRepo.FoodRepository
 	mealLogItemService *mealLogItemsServices.MealLogItemService
+	rollup             *dailyNutritionServices.DailyNutritionService
 }
 
 // NewMealLogService creates a new meal log service instance
-func NewMealLogService(repo *repository.MealLogRepository, mealLogItemsRepo *mealLogItemsRepo.MealLogItemRepository, foodRepository *foodRepo.FoodRepository) *MealLogService {
-	mealLogItemService := mealLogItemsServices.NewMealLogItemService(mealLogItemsRepo, foodRepository)
+func NewMealLogService(uow *database.UnitOfWork, repo *repository.MealLogRepository, mealLogItemsRepo *mealLogItemsRepo.MealLogItemRepository, foodRepo *foodRepo.FoodRepository, portions *foodPortionServices.FoodPortionService, rollup *dailyNutritionServices.DailyNutritionService) *MealLogService {
+	mealLogItemService := mealLogItemsServices.NewMealLogItemService(mealLogItemsRepo, portions, rollup)
 	return &MealLogService{
+		uow:                uow,
 		repo:               repo,
 		mealLogItemsRepo:   mealLogItemsRepo,
+		foodRepo:           foodRepo,
 		mealLogItemService: mealLogItemService,
+		rollup:             rollup,
 	}
 }
 
-// CreateMealLog creates a new meal log record
+// CreateMealLog creates a new meal log record, consumed now unless ConsumedAt is set
 func (s *MealLogService) CreateMealLog(mealLog *models.MealLog) error {
-	return s.repo.Create(mealLog)
+	if mealLog.ConsumedAt.IsZero() {
+		mealLog.ConsumedAt = time.Now()
+	}
+	s.markStale(mealLog.UserID, mealLog.ConsumedAt)
+	if err := s.repo.Create(mealLog); err != nil {
+		return err
+	}
+
+	s.refreshRollup(mealLog.UserID, mealLog.ConsumedAt)
+	return nil
 }
 
-// CreateMealLogComprehensive Create a FULL Meal log
+// CreateMealLogComprehensive creates a meal log with all its items in a single transaction:
+// when an item cannot be saved, the meal log is not saved either. The meal counts as consumed now
+// unless the request says when it was eaten.
 func (s *MealLogService) CreateMealLogComprehensive(userID uint, req dto.CreateMealLogRequestDTO) (*models.MealLogWithItems, error) {
-	// Step 1: Create meal log
+	now := time.Now()
 	mealLog := models.MealLog{
-		UserID:    userID,
-		MealType:  req.MealType,
-		Note:      req.Note,
-		CreatedAt: time.Now(),
+		UserID:     userID,
+		MealType:   req.MealType,
+		Note:       req.Note,
+		CreatedAt:  now,
+		ConsumedAt: now,
 	}
-
-	if err := s.CreateMealLog(&mealLog); err != nil {
-		helpers.LogError(err)
-		return nil, err
+	if req.ConsumedAt != nil {
+		mealLog.ConsumedAt = *req.ConsumedAt
 	}
 
-	// Step 2: Create meal log items
+	mealLogItems := make([]mealLogItemsModels.MealLogItem, 0, len(req.Items))
 	for _, item := range req.Items {
-		mealLogItem := mealLogItemsModels.MealLogItem{
-			MealLogID:     mealLog.ID,
+		mealLogItems = append(mealLogItems, mealLogItemsModels.MealLogItem{
 			FoodID:        item.FoodID,
 			Quantity:      item.Quantity,
+			Unit:          item.Unit,
+			Amount:        item.Amount,
 			QuantityGrams: item.QuantityGrams,
-		}
-
-		if err := s.CreateMealLogItem(&mealLogItem); err != nil {
-			helpers.LogError(err)
-			return nil, err
-		}
+		})
+	}
+	// Resolve quantity_grams before the transaction, so it only holds locks while writing
+	viewer := foodModels.FoodViewer{UserID: userID}
+	if err := s.mealLogItemService.PrepareItems(mealLogItems, viewer); err != nil {
+		return nil, err
 	}
 
-	// Step 3: Return meal log with items
-	mealLogWithItems, err := s.GetMealLogWithItemsByID(mealLog.ID)
+	s.markStale(mealLog.UserID, mealLog.ConsumedAt)
+	err := s.uow.Do(func(tx *gorm.DB) error {
+		if err := requireFoods(s.foodRepo.WithTx(tx), mealLogItems, viewer); err != nil {
+			return err
+		}
+		if err := s.repo.WithTx(tx).Create(&mealLog); err != nil {
+			return err
+		}
+		if len(mealLogItems) == 0 {
+			return nil
+		}
+		for i := range mealLogItems {
+			mealLogItems[i].MealLogID = mealLog.ID
+		}
+		_, err := s.mealLogItemsRepo.WithTx(tx).CreateBatch(mealLogItems)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return mealLogWithItems, nil
+	s.refreshRollup(mealLog.UserID, mealLog.ConsumedAt)
+	return &models.MealLogWithItems{MealLog: mealLog, Items: mealLogItems}, nil
 }
 
-// CreateMealLogItem creates a new meal log item with automatic quantity calculation
-func (s *MealLogService) CreateMealLogItem(item *mealLogItemsModels.MealLogItem) error {
-	return s.mealLogItemService.CreateMealLogItem(item)
+// CreateMealLogItem creates a new meal log item of a food the viewer may see with automatic quantity calculation
+func (s *MealLogService) CreateMealLogItem(item *mealLogItemsModels.MealLogItem, viewer foodModels.FoodViewer) error {
+	return s.mealLogItemService.CreateMealLogItem(item, viewer)
 }
 
 // GetMealLogWithItemsByID retrieves a meal log with its items by ID
@@ -107,34 +150,321 @@ func (s *MealLogService) GetMealLogsByUserID(userID uint) ([]models.MealLog, err
 	return s.repo.GetByUserID(userID)
 }
 
-// GetMealLogsByUserIDAndDate retrieves meal logs for a specific user and date
+// GetMealLogsByUserIDAndDate retrieves the meal logs a user consumed on a calendar date in their timezone
 func (s *MealLogService) GetMealLogsByUserIDAndDate(userID uint, date time.Time) ([]models.MealLog, error) {
-	return s.repo.GetByUserIDAndDate(userID, date)
+	return s.GetMealLogsByUserIDAndDateRange(userID, date, date)
 }
 
-// GetMealLogsByUserIDAndDateRange retrieves meal logs for a specific user within a date range
+// GetMealLogsByUserIDAndDateRange retrieves the meal logs a user consumed between two calendar dates
+// (inclusive) in their timezone
 func (s *MealLogService) GetMealLogsByUserIDAndDateRange(userID uint, startDate, endDate time.Time) ([]models.MealLog, error) {
-	return s.repo.GetByUserIDAndDateRange(userID, startDate, endDate)
+	loc, err := s.repo.GetUserLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.GetByUserIDAndDateRange(userID, startDate, endDate, loc)
 }
 
-// UpdateMealLog updates a meal log record
+// UpdateMealLog updates a meal log record. When the meal moves to another consumption time, the
+// day it left is refreshed as well as the day it joined.
 func (s *MealLogService) UpdateMealLog(mealLog *models.MealLog) error {
-	return s.repo.Update(mealLog)
+	previous, err := s.repo.GetByID(mealLog.ID)
+	if err != nil {
+		return err
+	}
+	s.markStale(previous.UserID, previous.ConsumedAt)
+	if !previous.ConsumedAt.Equal(mealLog.ConsumedAt) {
+		s.markStale(mealLog.UserID, mealLog.ConsumedAt)
+	}
+	if err := s.repo.Update(mealLog); err != nil {
+		return err
+	}
+
+	s.refreshRollup(mealLog.UserID, mealLog.ConsumedAt)
+	if !previous.ConsumedAt.Equal(mealLog.ConsumedAt) {
+		s.refreshRollup(previous.UserID, previous.ConsumedAt)
+	}
+	return nil
 }
 
-// DeleteMealLog removes a meal log record and all its items
+// DeleteMealLog removes a meal log record and all its items in a single transaction
 func (s *MealLogService) DeleteMealLog(id uint) error {
-	// First delete all meal log items
-	if err := s.mealLogItemsRepo.DeleteByMealLogID(id); err != nil {
-		helpers.LogError(err)
+	mealLog, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	s.markStale(mealLog.UserID, mealLog.ConsumedAt)
+
+	err = s.uow.Do(func(tx *gorm.DB) error {
+		if err := s.mealLogItemsRepo.WithTx(tx).DeleteByMealLogID(id); err != nil {
+			return err
+		}
+		return s.repo.WithTx(tx).Delete(id)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.refreshRollup(mealLog.UserID, mealLog.ConsumedAt)
+	return nil
+}
+
+// CopyMealLog logs a meal of the user again, with copies of its items, on the calendar date of date
+// in the user's timezone at the time of day it was eaten. A zero date stands for the user's today
+// and an empty mealType keeps the meal type of the original.
+func (s *MealLogService) CopyMealLog(userID, mealLogID uint, date time.Time, mealType string) (*models.MealLogWithItems, error) {
+	loc, err := s.repo.GetUserLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+	if date.IsZero() {
+		date = time.Now().In(loc)
+	}
+	s.markStale(userID, helpers.DayStart(date, loc))
+
+	var copied *models.MealLogWithItems
+	err = s.uow.Do(func(tx *gorm.DB) error {
+		source, err := s.ownedMealLog(tx, userID, mealLogID)
+		if err != nil {
+			return err
+		}
+		items, err := s.mealLogItemsRepo.WithTx(tx).GetByMealLogID(source.ID)
+		if err != nil {
+			return err
+		}
+		if mealType == "" {
+			mealType = source.MealType
+		}
+		copied, err = s.copyMeal(tx, *source, items, sameTimeOn(source.ConsumedAt, date, loc), mealType)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshRollup(userID, copied.MealLog.ConsumedAt)
+	return copied, nil
+}
+
+// CopyDay logs every meal the user ate on the calendar date fromDate again on toDate, each at the
+// time of day it was eaten, with dates counted in the user's timezone
+func (s *MealLogService) CopyDay(userID uint, fromDate, toDate time.Time) ([]models.MealLogWithItems, error) {
+	loc, err := s.repo.GetUserLocation(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	s.markStale(userID, helpers.DayStart(toDate, loc))
+
+	var copies []models.MealLogWithItems
+	err = s.uow.Do(func(tx *gorm.DB) error {
+		mealLogs, err := s.repo.WithTx(tx).GetByUserIDAndDate(userID, fromDate, loc)
+		if err != nil {
+			return err
+		}
+		if len(mealLogs) == 0 {
+			return fmt.Errorf("%w: %s", ErrNoMealsOnDate, fromDate.Format("2006-01-02"))
+		}
+
+		mealLogIDs := make([]uint, 0, len(mealLogs))
+		for _, mealLog := range mealLogs {
+			mealLogIDs = append(mealLogIDs, mealLog.ID)
+		}
+		items, err := s.mealLogItemsRepo.WithTx(tx).GetByMealLogIDs(mealLogIDs)
+		if err != nil {
+			return err
+		}
+		itemsByMealLog := make(map[uint][]mealLogItemsModels.MealLogItem, len(mealLogs))
+		for _, item := range items {
+			itemsByMealLog[item.MealLogID] = append(itemsByMealLog[item.MealLogID], item)
+		}
+
+		copies = make([]models.MealLogWithItems, 0, len(mealLogs))
+		for _, mealLog := range mealLogs {
+			copied, err := s.copyMeal(tx, mealLog, itemsByMealLog[mealLog.ID], sameTimeOn(mealLog.ConsumedAt, toDate, loc), mealLog.MealType)
+			if err != nil {
+				return err
+			}
+			copies = append(copies, *copied)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshRollup(userID, copies[0].MealLog.ConsumedAt)
+	return copies, nil
+}
+
+// MoveMealLogItems moves items from one meal log of the user to another. Every item must belong to
+// the source meal log, otherwise none is moved. It returns the target meal log with all its items.
+func (s *MealLogService) MoveMealLogItems(userID, sourceID, targetID uint, itemIDs []uint) (*models.MealLogWithItems, error) {
+	if sourceID == targetID {
+		return nil, fmt.Errorf("%w: items are already in meal log %d", mealLogItemsServices.ErrInvalidItem, targetID)
+	}
+	itemIDs = uniqueIDs(itemIDs)
+	s.markMealLogStale(userID, sourceID)
+	s.markMealLogStale(userID, targetID)
+
+	var source, target *models.MealLog
+	var items []mealLogItemsModels.MealLogItem
+	err := s.uow.Do(func(tx *gorm.DB) error {
+		var err error
+		if source, err = s.ownedMealLog(tx, userID, sourceID); err != nil {
+			return err
+		}
+		if target, err = s.ownedMealLog(tx, userID, targetID); err != nil {
+			return err
+		}
+
+		itemsRepo := s.mealLogItemsRepo.WithTx(tx)
+		moved, err := itemsRepo.MoveToMealLog(itemIDs, sourceID, targetID)
+		if err != nil {
+			return err
+		}
+		if moved != int64(len(itemIDs)) {
+			return fmt.Errorf("%w: not every item of %v is in meal log %d", mealLogItemsServices.ErrInvalidItem, itemIDs, sourceID)
+		}
+
+		items, err = itemsRepo.GetByMealLogID(targetID)
 		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// Then delete the meal log
-	return s.repo.Delete(id)
+	s.refreshRollup(userID, target.ConsumedAt)
+	s.refreshRollup(userID, source.ConsumedAt)
+	return &models.MealLogWithItems{MealLog: *target, Items: items}, nil
 }
 
 // VerifyMealLogOwnership checks if the specified meal log belongs to the user
 func (s *MealLogService) VerifyMealLogOwnership(mealLogID, userID uint) error {
 	return s.mealLogItemService.VerifyMealLogOwnership(mealLogID, userID)
 }
+
+// markStale flags the day of a diary change as out of date before its rows are written, so readers
+// fall back to the raw rows until refreshRollup recomputes it. Failures are only logged.
+func (s *MealLogService) markStale(userID uint, consumedAt time.Time) {
+	if err := s.rollup.MarkDaysStale(userID, consumedAt); err != nil {
+		helpers.LogError(err)
+	}
+}
+
+// markMealLogStale flags the day of a meal log of the user as out of date before it changes.
+// Meal logs of other users are left alone; the change itself reports them.
+func (s *MealLogService) markMealLogStale(userID, mealLogID uint) {
+	mealLog, err := s.repo.GetByID(mealLogID)
+	if err != nil || mealLog.UserID != userID {
+		return
+	}
+	s.markStale(userID, mealLog.ConsumedAt)
+}
+
+// refreshRollup keeps the daily nutrition rollup in step after a diary change.
+// Failures are only logged: the day stays marked stale and readers fall back to the raw rows.
+func (s *MealLogService) refreshRollup(userID uint, consumedAt time.Time) {
+	if err := s.rollup.RefreshDay(userID, consumedAt); err != nil {
+		helpers.LogError(err)
+	}
+}
+
+// ownedMealLog reads a meal log in the transaction tx, checking that it belongs to the user
+func (s *MealLogService) ownedMealLog(tx *gorm.DB, userID, mealLogID uint) (*models.MealLog, error) {
+	mealLog, err := s.repo.WithTx(tx).GetByID(mealLogID)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, mealLogItemsServices.ErrMealLogNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	if mealLog.UserID != userID {
+		return nil, mealLogItemsServices.ErrUnauthorizedAccess
+	}
+	return mealLog, nil
+}
+
+// copyMeal creates in the transaction tx a meal log like source, with copies of its items, consumed
+// at consumedAt as mealType. Items of foods the user can no longer see are rejected.
+func (s *MealLogService) copyMeal(tx *gorm.DB, source models.MealLog, items []mealLogItemsModels.MealLogItem, consumedAt time.Time, mealType string) (*models.MealLogWithItems, error) {
+	mealLog := models.MealLog{
+		UserID:     source.UserID,
+		MealType:   mealType,
+		Note:       source.Note,
+		CreatedAt:  time.Now(),
+		ConsumedAt: consumedAt,
+	}
+	if err := requireFoods(s.foodRepo.WithTx(tx), items, foodModels.FoodViewer{UserID: source.UserID}); err != nil {
+		return nil, err
+	}
+	if err := s.repo.WithTx(tx).Create(&mealLog); err != nil {
+		return nil, err
+	}
+
+	copiedItems := make([]mealLogItemsModels.MealLogItem, 0, len(items))
+	for _, item := range items {
+		item.ID = 0
+		item.MealLogID = mealLog.ID
+		copiedItems = append(copiedItems, item)
+	}
+	if len(copiedItems) > 0 {
+		if _, err := s.mealLogItemsRepo.WithTx(tx).CreateBatch(copiedItems); err != nil {
+			return nil, err
+		}
+	}
+	return &models.MealLogWithItems{MealLog: mealLog, Items: copiedItems}, nil
+}
+
+// sameTimeOn returns the instant at the time of day of consumedAt in loc on the calendar date of date
+func sameTimeOn(consumedAt, date time.Time, loc *time.Location) time.Time {
+	clock := consumedAt.In(loc)
+	year, month, day := date.Date()
+	return time.Date(year, month, day, clock.Hour(), clock.Minute(), clock.Second(), clock.Nanosecond(), loc)
+}
+
+// uniqueIDs returns ids without repeats, in their first order
+func uniqueIDs(ids []uint) []uint {
+	seen := make(map[uint]bool, len(ids))
+	unique := make([]uint, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// requireFoods checks that every food the items log exists and is visible to the viewer, locking
+// the foods so they cannot be deleted before the transaction commits
+func requireFoods(foods *foodRepo.FoodRepository, items []mealLogItemsModels.MealLogItem, viewer foodModels.FoodViewer) error {
+	foodIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		foodIDs = append(foodIDs, item.FoodID)
+	}
+	found, err := foods.LockVisibleByIDs(foodIDs, viewer)
+	if err != nil {
+		return err
+	}
+	if missing := missingFoodIDs(foodIDs, found); len(missing) > 0 {
+		return fmt.Errorf("%w: %w: %v", mealLogItemsServices.ErrInvalidItem, foodPortionServices.ErrFoodNotFound, missing)
+	}
+	return nil
+}
+
+// missingFoodIDs lists the IDs among foodIDs that no food in found has, each once
+func missingFoodIDs(foodIDs []uint, found []foodModels.Food) []uint {
+	exists := make(map[uint]bool, len(found))
+	for _, food := range found {
+		exists[food.ID] = true
+	}
+	var missing []uint
+	for _, id := range foodIDs {
+		if !exists[id] {
+			missing = append(missing, id)
+			exists[id] = true
+		}
+	}
+	return missing
+}
@@ -0,0 +1,41 @@
+package services
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+)
+
+func TestMissingFoodIDs(t *testing.T) {
+	found := []foodModels.Food{{ID: 1}, {ID: 3}}
+
+	if missing := missingFoodIDs([]uint{1, 3, 1}, found); len(missing) != 0 {
+		t.Errorf("every food exists, got missing %v", missing)
+	}
+	if missing := missingFoodIDs([]uint{4, 1, 2, 4}, found); !reflect.DeepEqual(missing, []uint{4, 2}) {
+		t.Errorf("missing = %v, want [4 2] in request order without repeats", missing)
+	}
+	if missing := missingFoodIDs(nil, nil); len(missing) != 0 {
+		t.Errorf("no items, got missing %v", missing)
+	}
+}
+
+func TestSameTimeOn(t *testing.T) {
+	paris, _ := time.LoadLocation("Europe/Paris")
+	// Breakfast at 08:30 in Paris in winter, copied to a summer date, stays at 08:30 Paris time
+	breakfast := time.Date(2024, 1, 15, 7, 30, 0, 0, time.UTC)
+	date := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	got := sameTimeOn(breakfast, date, paris)
+	if want := time.Date(2024, 7, 1, 6, 30, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("sameTimeOn = %s, want %s", got, want)
+	}
+}
+
+func TestUniqueIDs(t *testing.T) {
+	if got := uniqueIDs([]uint{3, 1, 3, 2, 1}); !reflect.DeepEqual(got, []uint{3, 1, 2}) {
+		t.Errorf("uniqueIDs = %v, want [3 1 2]", got)
+	}
+}
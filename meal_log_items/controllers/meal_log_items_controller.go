@@ -8,6 +8,7 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/momokapoolz/caloriesapp/auth"
 	"github.com/momokapoolz/caloriesapp/dto"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
 	"github.com/momokapoolz/caloriesapp/helpers"
 	"github.com/momokapoolz/caloriesapp/meal_log_items/models"
 	"github.com/momokapoolz/caloriesapp/meal_log_items/services"
@@ -24,24 +25,38 @@ func NewMealLogItemController(service *services.MealLogItemService) *MealLogItem
 
 // CreateMealLogItem godoc
 // @Summary      Create meal log item
-// @Description  Create a new meal log item record
+// @Description  Create a new meal log item record. The item records a unit (g, oz, serving or one of the food's portions such as cup or slice) and a fractional amount; quantity_grams is resolved from them. Items with only quantity are read as servings.
 // @Tags         meal_log_item
 // @Accept       json
 // @Produce      json
 // @Param        meal_log_item  body      models.MealLogItem  true  "Meal log item data"
 // @Success      201  {object}  models.MealLogItem  "Meal log item created successfully"
 // @Failure      400  {object}  map[string]string   "Invalid request body"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
 // @Failure      500  {object}  map[string]string   "Internal server error"
 // @Security     BearerAuth
 // @Router       /meal-log-items/ [post]
 func (c *MealLogItemController) CreateMealLogItem(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	var item models.MealLogItem
 	if err := ctx.ShouldBindJSON(&item); err != nil {
 		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 		return
 	}
 
-	if err := c.service.CreateMealLogItem(&item); err != nil {
+	if err := c.service.CreateMealLogItem(&item, foodModels.FoodViewer{UserID: userClaims.UserID}); err != nil {
+		if errors.Is(err, services.ErrInvalidItem) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create meal log item"})
 		return
@@ -147,10 +162,17 @@ func (c *MealLogItemController) GetMealLogItemsByFoodID(ctx *gin.Context) {
 // @Param        meal_log_item  body  models.MealLogItem true  "Updated meal log item data"
 // @Success      200  {object}  models.MealLogItem  "Meal log item updated successfully"
 // @Failure      400  {object}  map[string]string   "Invalid ID or request body"
+// @Failure      401  {object}  map[string]string   "Unauthorized"
 // @Failure      500  {object}  map[string]string   "Internal server error"
 // @Security     BearerAuth
 // @Router       /meal-log-items/{id} [put]
 func (c *MealLogItemController) UpdateMealLogItem(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
 	idStr := ctx.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 32)
 	if err != nil {
@@ -165,7 +187,14 @@ func (c *MealLogItemController) UpdateMealLogItem(ctx *gin.Context) {
 	}
 
 	item.ID = uint(id)
-	if err := c.service.UpdateMealLogItem(&item); err != nil {
+	if err := c.service.UpdateMealLogItem(&item, foodModels.FoodViewer{UserID: userClaims.UserID}); err != nil {
+		if errors.Is(err, services.ErrInvalidItem) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update meal log item"})
 		return
@@ -194,6 +223,9 @@ func (c *MealLogItemController) DeleteMealLogItem(ctx *gin.Context) {
 	}
 
 	if err := c.service.DeleteMealLogItem(uint(id)); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete meal log item"})
 		return
@@ -222,6 +254,9 @@ func (c *MealLogItemController) DeleteMealLogItemsByMealLogID(ctx *gin.Context)
 	}
 
 	if err := c.service.DeleteMealLogItemsByMealLogID(uint(mealLogID)); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete meal log items"})
 		return
@@ -292,13 +327,22 @@ func (c *MealLogItemController) AddItemsToMealLog(ctx *gin.Context) {
 			MealLogID:     uint(mealLogID),
 			FoodID:        item.FoodID,
 			Quantity:      item.Quantity,
+			Unit:          item.Unit,
+			Amount:        item.Amount,
 			QuantityGrams: item.QuantityGrams,
 		})
 	}
 
 	// Call service to add items
-	createdItems, err := c.service.AddItemsToMealLog(uint(mealLogID), mealLogItems)
+	createdItems, err := c.service.AddItemsToMealLog(uint(mealLogID), mealLogItems, foodModels.FoodViewer{UserID: userClaims.UserID})
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidItem) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to add items to meal log: " + err.Error()})
 		return
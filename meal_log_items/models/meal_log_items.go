@@ -6,6 +6,8 @@ type MealLogItem struct {
 	MealLogID     uint    `gorm:"column:meal_log_id;not null" json:"meal_log_id"`
 	FoodID        uint    `gorm:"column:food_id;not null" json:"food_id"`
 	Quantity      uint    `gorm:"column:quantity;not null" json:"quantity"`
+	Unit          string  `gorm:"column:unit;type:varchar(32);not null;default:'serving'" json:"unit"`
+	Amount        float64 `gorm:"column:amount;not null;default:0" json:"amount"`
 	QuantityGrams float64 `gorm:"column:quantity_grams;not null" json:"quantity_grams"`
 }
 
@@ -3,6 +3,7 @@ package repository
 import (
 	"errors"
 
+	"github.com/momokapoolz/caloriesapp/database"
 	"github.com/momokapoolz/caloriesapp/meal_log_items/models"
 	"gorm.io/gorm"
 )
@@ -17,9 +18,14 @@ func NewMealLogItemRepository(db *gorm.DB) *MealLogItemRepository {
 	return &MealLogItemRepository{db: db}
 }
 
+// WithTx returns a repository running its queries in the transaction tx
+func (r *MealLogItemRepository) WithTx(tx *gorm.DB) *MealLogItemRepository {
+	return &MealLogItemRepository{db: tx}
+}
+
 // Create adds a new meal log item to the database
 func (r *MealLogItemRepository) Create(item *models.MealLogItem) error {
-	return r.db.Create(item).Error
+	return database.TranslateError(r.db.Create(item).Error)
 }
 
 // GetByID retrieves a meal log item by its ID
@@ -39,6 +45,16 @@ func (r *MealLogItemRepository) GetByMealLogID(mealLogID uint) ([]models.MealLog
 	return items, err
 }
 
+// GetByMealLogIDs retrieves all items for several meal logs in a single query
+func (r *MealLogItemRepository) GetByMealLogIDs(mealLogIDs []uint) ([]models.MealLogItem, error) {
+	var items []models.MealLogItem
+	if len(mealLogIDs) == 0 {
+		return items, nil
+	}
+	err := r.db.Where("meal_log_id IN ?", mealLogIDs).Order("id").Find(&items).Error
+	return items, err
+}
+
 // GetByFoodID retrieves all meal log items for a specific food
 func (r *MealLogItemRepository) GetByFoodID(foodID uint) ([]models.MealLogItem, error) {
 	var items []models.MealLogItem
@@ -48,17 +64,26 @@ func (r *MealLogItemRepository) GetByFoodID(foodID uint) ([]models.MealLogItem,
 
 // Update updates a meal log item
 func (r *MealLogItemRepository) Update(item *models.MealLogItem) error {
-	return r.db.Save(item).Error
+	return database.TranslateError(r.db.Save(item).Error)
 }
 
 // Delete removes a meal log item
 func (r *MealLogItemRepository) Delete(id uint) error {
-	return r.db.Delete(&models.MealLogItem{}, id).Error
+	return database.TranslateError(r.db.Delete(&models.MealLogItem{}, id).Error)
 }
 
 // DeleteByMealLogID removes all items for a specific meal log
 func (r *MealLogItemRepository) DeleteByMealLogID(mealLogID uint) error {
-	return r.db.Where("meal_log_id = ?", mealLogID).Delete(&models.MealLogItem{}).Error
+	return database.TranslateError(r.db.Where("meal_log_id = ?", mealLogID).Delete(&models.MealLogItem{}).Error)
+}
+
+// MoveToMealLog moves the items among itemIDs that belong to one meal log to another, returning
+// how many were moved
+func (r *MealLogItemRepository) MoveToMealLog(itemIDs []uint, fromMealLogID, toMealLogID uint) (int64, error) {
+	result := r.db.Model(&models.MealLogItem{}).
+		Where("id IN ? AND meal_log_id = ?", itemIDs, fromMealLogID).
+		Update("meal_log_id", toMealLogID)
+	return result.RowsAffected, database.TranslateError(result.Error)
 }
 
 // CreateBatch adds multiple meal log items to the database in a single transaction
@@ -73,7 +98,7 @@ func (r *MealLogItemRepository) CreateBatch(items []models.MealLogItem) ([]model
 	})
 
 	if err != nil {
-		return nil, err
+		return nil, database.TranslateError(err)
 	}
 
 	return items, nil
@@ -3,10 +3,18 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
 	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	foodPortionRepo "github.com/momokapoolz/caloriesapp/food_portion/repository"
+	foodPortionServices "github.com/momokapoolz/caloriesapp/food_portion/services"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
 	"github.com/momokapoolz/caloriesapp/meal_log_items/controllers"
 	"github.com/momokapoolz/caloriesapp/meal_log_items/repository"
 	"github.com/momokapoolz/caloriesapp/meal_log_items/services"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
 	"gorm.io/gorm"
 )
 
@@ -14,7 +22,15 @@ import (
 func SetupMealLogItemRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	mealLogItemRepo := repository.NewMealLogItemRepository(db)
 	foodRepository := foodRepo.NewFoodRepository(db)
-	mealLogItemService := services.NewMealLogItemService(mealLogItemRepo, foodRepository)
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemRepo,
+		foodRepository,
+		foodNutrientsRepo.NewFoodNutrientRepository(db),
+		nutrientRepo.NewNutrientRepository(db),
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(dailyNutritionRepo.NewDailyNutritionRepository(db), mealLogRepo.NewMealLogRepository(db), engine)
+	portions := foodPortionServices.NewFoodPortionService(foodPortionRepo.NewFoodPortionRepository(db), foodRepository)
+	mealLogItemService := services.NewMealLogItemService(mealLogItemRepo, portions, rollup)
 	mealLogItemController := controllers.NewMealLogItemController(mealLogItemService)
 
 	authMiddleware := auth.NewAuthMiddleware()
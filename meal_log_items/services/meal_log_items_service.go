@@ -4,7 +4,9 @@ import (
 	"errors"
 	"fmt"
 
-	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodPortionServices "github.com/momokapoolz/caloriesapp/food_portion/services"
 	"github.com/momokapoolz/caloriesapp/helpers"
 	"github.com/momokapoolz/caloriesapp/meal_log_items/models"
 	"github.com/momokapoolz/caloriesapp/meal_log_items/repository"
@@ -14,29 +16,38 @@ import (
 var (
 	ErrMealLogNotFound    = errors.New("meal log not found")
 	ErrUnauthorizedAccess = errors.New("unauthorized access to meal log")
+	ErrInvalidItem        = errors.New("invalid meal log item")
 )
 
 // MealLogItemService handles business logic for meal log item operations
 type MealLogItemService struct {
 	repo     *repository.MealLogItemRepository
-	foodRepo *foodRepo.FoodRepository
+	portions *foodPortionServices.FoodPortionService
+	rollup   *dailyNutritionServices.DailyNutritionService
 }
 
 // NewMealLogItemService creates a new meal log item service instance
-func NewMealLogItemService(repo *repository.MealLogItemRepository, foodRepo *foodRepo.FoodRepository) *MealLogItemService {
+func NewMealLogItemService(repo *repository.MealLogItemRepository, portions *foodPortionServices.FoodPortionService, rollup *dailyNutritionServices.DailyNutritionService) *MealLogItemService {
 	return &MealLogItemService{
 		repo:     repo,
-		foodRepo: foodRepo,
+		portions: portions,
+		rollup:   rollup,
 	}
 }
 
-// CreateMealLogItem creates a new meal log item with automatic quantity_grams calculation
-func (s *MealLogItemService) CreateMealLogItem(item *models.MealLogItem) error {
-	// Auto-calculate QuantityGrams based on food serving size and quantity
-	if err := s.calculateQuantityGrams(item); err != nil {
-		helpers.LogError(err)
+// CreateMealLogItem creates a new meal log item of a food the viewer may see, with automatic
+// quantity_grams calculation
+func (s *MealLogItemService) CreateMealLogItem(item *models.MealLogItem, viewer foodModels.FoodViewer) error {
+	// Resolve QuantityGrams from the unit and amount of the item
+	if err := s.calculateQuantityGrams(viewer, item); err != nil {
+		return err
+	}
+	s.markStale(item.MealLogID)
+	if err := s.repo.Create(item); err != nil {
+		return err
 	}
-	return s.repo.Create(item)
+	s.refreshRollup(item.MealLogID)
+	return nil
 }
 
 // GetMealLogItemByID retrieves a meal log item by ID
@@ -54,71 +65,159 @@ func (s *MealLogItemService) GetMealLogItemsByFoodID(foodID uint) ([]models.Meal
 	return s.repo.GetByFoodID(foodID)
 }
 
-// UpdateMealLogItem updates a meal log item with automatic quantity_grams calculation
-func (s *MealLogItemService) UpdateMealLogItem(item *models.MealLogItem) error {
-	// Auto-calculate QuantityGrams based on food serving size and quantity
-	if err := s.calculateQuantityGrams(item); err != nil {
-		helpers.LogError(err)
+// UpdateMealLogItem updates a meal log item to a food the viewer may see, with automatic
+// quantity_grams calculation
+func (s *MealLogItemService) UpdateMealLogItem(item *models.MealLogItem, viewer foodModels.FoodViewer) error {
+	// Resolve QuantityGrams from the unit and amount of the item
+	if err := s.calculateQuantityGrams(viewer, item); err != nil {
+		return err
+	}
+
+	existing, err := s.repo.GetByID(item.ID)
+	if err != nil {
+		return err
+	}
+
+	s.markStale(item.MealLogID)
+	if existing.MealLogID != item.MealLogID {
+		s.markStale(existing.MealLogID)
+	}
+	if err := s.repo.Update(item); err != nil {
+		return err
+	}
+
+	s.refreshRollup(item.MealLogID)
+	if existing.MealLogID != item.MealLogID {
+		s.refreshRollup(existing.MealLogID)
 	}
-	return s.repo.Update(item)
+	return nil
 }
 
 // DeleteMealLogItem removes a meal log item
 func (s *MealLogItemService) DeleteMealLogItem(id uint) error {
-	return s.repo.Delete(id)
+	item, err := s.repo.GetByID(id)
+	if err != nil {
+		return err
+	}
+
+	s.markStale(item.MealLogID)
+	if err := s.repo.Delete(id); err != nil {
+		return err
+	}
+
+	s.refreshRollup(item.MealLogID)
+	return nil
 }
 
 // DeleteMealLogItemsByMealLogID removes all items for a specific meal log
 func (s *MealLogItemService) DeleteMealLogItemsByMealLogID(mealLogID uint) error {
-	return s.repo.DeleteByMealLogID(mealLogID)
+	s.markStale(mealLogID)
+	if err := s.repo.DeleteByMealLogID(mealLogID); err != nil {
+		return err
+	}
+
+	s.refreshRollup(mealLogID)
+	return nil
 }
 
-// AddItemsToMealLog adds multiple items to an existing meal log with automatic calculation
-func (s *MealLogItemService) AddItemsToMealLog(mealLogID uint, items []models.MealLogItem) ([]models.MealLogItem, error) {
+// AddItemsToMealLog adds multiple items of foods the viewer may see to an existing meal log with
+// automatic calculation
+func (s *MealLogItemService) AddItemsToMealLog(mealLogID uint, items []models.MealLogItem, viewer foodModels.FoodViewer) ([]models.MealLogItem, error) {
 	// Validate that all items have the same meal log ID
+	pending := make([]*models.MealLogItem, 0, len(items))
 	for i := range items {
 		if items[i].MealLogID != mealLogID {
 			return nil, errors.New("all items must have the same meal log ID")
 		}
+		pending = append(pending, &items[i])
+	}
 
-		// Auto-calculate QuantityGrams for each item
-		if err := s.calculateQuantityGrams(&items[i]); err != nil {
-			helpers.LogError(err)
-		}
+	// Resolve QuantityGrams for all items at once
+	if err := s.calculateQuantityGrams(viewer, pending...); err != nil {
+		return nil, err
 	}
 
 	// Call repository method to add items in a transaction
-	return s.repo.CreateBatch(items)
+	s.markStale(mealLogID)
+	created, err := s.repo.CreateBatch(items)
+	if err != nil {
+		return nil, err
+	}
+
+	s.refreshRollup(mealLogID)
+	return created, nil
 }
 
-// calculateQuantityGrams automatically calculates the quantity_grams based on food serving size
-func (s *MealLogItemService) calculateQuantityGrams(item *models.MealLogItem) error {
-	// Get food information to access serving size
-	food, err := s.foodRepo.GetByID(item.FoodID)
-	if err != nil {
-		return fmt.Errorf("failed to get food info: %w", err)
+// PrepareItems validates items of foods the viewer may see and resolves their quantity_grams without
+// saving them, for callers that save them as part of a larger transaction
+func (s *MealLogItemService) PrepareItems(items []models.MealLogItem, viewer foodModels.FoodViewer) error {
+	pending := make([]*models.MealLogItem, 0, len(items))
+	for i := range items {
+		pending = append(pending, &items[i])
+	}
+	return s.calculateQuantityGrams(viewer, pending...)
+}
+
+// markStale flags the day of a meal log as out of date before its items are written, so readers
+// fall back to the raw rows until refreshRollup recomputes it. Failures are only logged.
+func (s *MealLogItemService) markStale(mealLogID uint) {
+	if err := s.rollup.MarkMealLogStale(mealLogID); err != nil {
+		helpers.LogError(err)
 	}
+}
 
-	// Calculate total grams: quantity * serving_size_gram
-	calculatedGrams := float64(item.Quantity) * food.ServingSizeGram
+// refreshRollup keeps the daily nutrition rollup in step after items of a meal log changed.
+// Failures are only logged: the day stays marked stale and readers fall back to the raw rows.
+func (s *MealLogItemService) refreshRollup(mealLogID uint) {
+	if err := s.rollup.RefreshMealLog(mealLogID); err != nil {
+		helpers.LogError(err)
+	}
+}
 
-	// Use calculated grams if:
-	// 1. QuantityGrams is 0 (not provided)
-	// 2. Or if the provided value seems incorrect (simple heuristic)
-	if item.QuantityGrams == 0 || s.shouldRecalculateGrams(item, calculatedGrams) {
-		item.QuantityGrams = calculatedGrams
+// calculateQuantityGrams resolves quantity_grams from the unit and amount of each item,
+// using the food's serving size or one of its portions. Unknown units and foods that are missing
+// or hidden from the viewer are reported instead of guessed, whatever the unit.
+func (s *MealLogItemService) calculateQuantityGrams(viewer foodModels.FoodViewer, items ...*models.MealLogItem) error {
+	foodIDs := make([]uint, 0, len(items))
+	for _, item := range items {
+		applyLegacyAmount(item)
+		foodIDs = append(foodIDs, item.FoodID)
 	}
 
+	resolver, err := s.portions.NewGramResolver(foodIDs, viewer)
+	if err != nil {
+		return fmt.Errorf("failed to load food portions: %w", err)
+	}
+
+	for _, item := range items {
+		// Mass units convert without the food, so its existence is checked on its own
+		if !resolver.HasFood(item.FoodID) {
+			return fmt.Errorf("%w: %w: %d", ErrInvalidItem, foodPortionServices.ErrFoodNotFound, item.FoodID)
+		}
+		grams, err := resolver.Grams(item.FoodID, item.Amount, item.Unit)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidItem, err)
+		}
+		item.Unit = foodPortionServices.NormalizeUnit(item.Unit)
+		item.QuantityGrams = grams
+	}
 	return nil
 }
 
-// shouldRecalculateGrams determines if we should override the provided QuantityGrams
-func (s *MealLogItemService) shouldRecalculateGrams(item *models.MealLogItem, calculatedGrams float64) bool {
-	// If quantity > 1 but grams seems like single serving, recalculate
-	// Allow 10% tolerance for measurement variations
-	tolerance := calculatedGrams * 0.1
-	return item.Quantity > 1 &&
-		item.QuantityGrams < (calculatedGrams-tolerance)
+// applyLegacyAmount fills unit and amount for clients that only send quantity and
+// quantity_grams: grams are taken as given, otherwise a quantity counts servings.
+func applyLegacyAmount(item *models.MealLogItem) {
+	switch {
+	case item.Unit != "":
+	case item.QuantityGrams > 0:
+		item.Unit = foodPortionServices.UnitGram
+		item.Amount = item.QuantityGrams
+	case item.Amount > 0:
+		item.Unit = foodPortionServices.UnitServing
+	case item.Quantity > 0:
+		item.Unit = foodPortionServices.UnitServing
+		item.Amount = float64(item.Quantity)
+	}
 }
 
 // VerifyMealLogOwnership checks if the specified meal log belongs to the user
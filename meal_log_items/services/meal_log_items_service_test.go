@@ -0,0 +1,34 @@
+package services
+
+import (
+	"testing"
+
+	"github.com/momokapoolz/caloriesapp/meal_log_items/models"
+)
+
+func TestApplyLegacyAmount(t *testing.T) {
+	tests := []struct {
+		name   string
+		item   models.MealLogItem
+		unit   string
+		amount float64
+	}{
+		{"explicit unit", models.MealLogItem{Quantity: 1, Unit: "cup", Amount: 2, QuantityGrams: 250}, "cup", 2},
+		// The grams are what the client weighed; the quantity only counted one portion of them
+		{"grams with quantity", models.MealLogItem{Quantity: 1, QuantityGrams: 250}, "g", 250},
+		{"grams only", models.MealLogItem{QuantityGrams: 80}, "g", 80},
+		{"amount only", models.MealLogItem{Amount: 1.5}, "serving", 1.5},
+		{"quantity only", models.MealLogItem{Quantity: 2}, "serving", 2},
+	}
+
+	for _, tc := range tests {
+		item := tc.item
+		applyLegacyAmount(&item)
+		if item.Unit != tc.unit || item.Amount != tc.amount {
+			t.Errorf("%s: got %g %s, want %g %s", tc.name, item.Amount, item.Unit, tc.amount, tc.unit)
+		}
+		if item.QuantityGrams != tc.item.QuantityGrams {
+			t.Errorf("%s: quantity_grams changed from %g to %g", tc.name, tc.item.QuantityGrams, item.QuantityGrams)
+		}
+	}
+}
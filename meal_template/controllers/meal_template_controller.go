@@ -0,0 +1,346 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	mealLogItemsServices "github.com/momokapoolz/caloriesapp/meal_log_items/services"
+	"github.com/momokapoolz/caloriesapp/meal_template/services"
+	"gorm.io/gorm"
+)
+
+// MealTemplateController handles HTTP requests for meal templates
+type MealTemplateController struct {
+	service *services.MealTemplateService
+}
+
+// NewMealTemplateController creates a new meal template controller instance
+func NewMealTemplateController(service *services.MealTemplateService) *MealTemplateController {
+	return &MealTemplateController{service: service}
+}
+
+// writeTemplateError maps meal template service errors to responses; fallback is used for unexpected errors
+func writeTemplateError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalidTemplateItem), errors.Is(err, mealLogItemsServices.ErrInvalidItem),
+		errors.Is(err, services.ErrPrivateFoodShared):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrInvalidVisibility):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Visibility must be private or shared"})
+	case errors.Is(err, services.ErrInvalidScale):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Scale must be greater than 0"})
+	case errors.Is(err, services.ErrEmptyTemplate):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A meal template needs at least one item"})
+	case errors.Is(err, services.ErrTemplateNotAccessible):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to modify this meal template"})
+	case errors.Is(err, mealLogItemsServices.ErrUnauthorizedAccess):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to access this meal log"})
+	case errors.Is(err, mealLogItemsServices.ErrMealLogNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Meal log not found"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Meal template not found"})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// parseID parses a positive integer path parameter
+func parseID(ctx *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateTemplate godoc
+// @Summary      Create meal template
+// @Description  Save a named meal of foods with amounts in any unit of the food, to log it again in one request. Shared templates are visible to every user and may only use shared or verified foods.
+// @Tags         meal_template
+// @Accept       json
+// @Produce      json
+// @Param        template  body      dto.MealTemplateRequestDTO   true  "Meal template data"
+// @Success      201       {object}  dto.MealTemplateResponseDTO  "Meal template created successfully"
+// @Failure      400       {object}  map[string]string            "Invalid request body, unknown food or unit"
+// @Failure      401       {object}  map[string]string            "Unauthorized"
+// @Failure      500       {object}  map[string]string            "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-templates/ [post]
+func (c *MealTemplateController) CreateTemplate(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.MealTemplateRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := c.service.CreateTemplate(claims.UserID, req)
+	if err != nil {
+		writeTemplateError(ctx, err, "Failed to create meal template")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, template)
+}
+
+// CreateTemplateFromMealLog godoc
+// @Summary      Save a meal log as a template
+// @Description  Save the meal type and items of a meal log of the authenticated user as a new meal template
+// @Tags         meal_template
+// @Accept       json
+// @Produce      json
+// @Param        template  body      dto.MealTemplateFromMealLogRequestDTO  true  "Meal log and template name"
+// @Success      201       {object}  dto.MealTemplateResponseDTO            "Meal template created successfully"
+// @Failure      400       {object}  map[string]string                      "Invalid request body"
+// @Failure      401       {object}  map[string]string                      "Unauthorized"
+// @Failure      403       {object}  map[string]string                      "Forbidden — not the owner of the meal log"
+// @Failure      404       {object}  map[string]string                      "Meal log not found"
+// @Failure      500       {object}  map[string]string                      "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-templates/from-meal-log [post]
+func (c *MealTemplateController) CreateTemplateFromMealLog(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.MealTemplateFromMealLogRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := c.service.CreateTemplateFromMealLog(claims.UserID, req)
+	if err != nil {
+		writeTemplateError(ctx, err, "Failed to create meal template")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, template)
+}
+
+// GetTemplates godoc
+// @Summary      Get user's meal templates
+// @Description  Retrieve every meal template created by the authenticated user
+// @Tags         meal_template
+// @Produce      json
+// @Success      200  {array}   dto.MealTemplateResponseDTO  "Meal templates retrieved successfully"
+// @Failure      401  {object}  map[string]string            "Unauthorized"
+// @Failure      500  {object}  map[string]string            "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-templates/ [get]
+func (c *MealTemplateController) GetTemplates(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	templates, err := c.service.GetTemplatesByUser(claims.UserID)
+	if err != nil {
+		writeTemplateError(ctx, err, "Failed to retrieve meal templates")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, templates)
+}
+
+// GetSharedTemplates godoc
+// @Summary      Get shared meal templates
+// @Description  Retrieve the meal templates other users shared
+// @Tags         meal_template
+// @Produce      json
+// @Success      200  {array}   dto.MealTemplateResponseDTO  "Meal templates retrieved successfully"
+// @Failure      401  {object}  map[string]string            "Unauthorized"
+// @Failure      500  {object}  map[string]string            "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-templates/shared [get]
+func (c *MealTemplateController) GetSharedTemplates(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	templates, err := c.service.GetSharedTemplates(claims.UserID)
+	if err != nil {
+		writeTemplateError(ctx, err, "Failed to retrieve meal templates")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate godoc
+// @Summary      Get a meal template
+// @Description  Retrieve a meal template of the user, or a shared one, with its items and nutrition multiplied by scale
+// @Tags         meal_template
+// @Produce      json
+// @Param        id     path      int      true   "Meal template ID"
+// @Param        scale  query     number   false  "Multiplier of every amount (default 1)"
+// @Success      200    {object}  dto.MealTemplateResponseDTO  "Meal template retrieved successfully"
+// @Failure      400    {object}  map[string]string            "Invalid ID or scale"
+// @Failure      401    {object}  map[string]string            "Unauthorized"
+// @Failure      404    {object}  map[string]string            "Meal template not found"
+// @Failure      500    {object}  map[string]string            "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-templates/{id} [get]
+func (c *MealTemplateController) GetTemplate(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	scale, err := strconv.ParseFloat(ctx.DefaultQuery("scale", "1"), 64)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid scale"})
+		return
+	}
+
+	template, err := c.service.GetTemplate(id, claims.UserID, scale)
+	if err != nil {
+		writeTemplateError(ctx, err, "Failed to retrieve meal template")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate godoc
+// @Summary      Update meal template
+// @Description  Replace the name, meal type, visibility and items of a meal template
+// @Tags         meal_template
+// @Accept       json
+// @Produce      json
+// @Param        id        path      int                          true  "Meal template ID"
+// @Param        template  body      dto.MealTemplateRequestDTO   true  "Meal template data"
+// @Success      200       {object}  dto.MealTemplateResponseDTO  "Meal template updated successfully"
+// @Failure      400       {object}  map[string]string            "Invalid ID, request body, food or unit"
+// @Failure      401       {object}  map[string]string            "Unauthorized"
+// @Failure      403       {object}  map[string]string            "Forbidden — not the owner"
+// @Failure      404       {object}  map[string]string            "Meal template not found"
+// @Failure      500       {object}  map[string]string            "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-templates/{id} [put]
+func (c *MealTemplateController) UpdateTemplate(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.MealTemplateRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	template, err := c.service.UpdateTemplate(id, claims.UserID, req)
+	if err != nil {
+		writeTemplateError(ctx, err, "Failed to update meal template")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate godoc
+// @Summary      Delete meal template
+// @Description  Delete a meal template of the authenticated user; meals logged from it are kept
+// @Tags         meal_template
+// @Produce      json
+// @Param        id   path      int                true  "Meal template ID"
+// @Success      200  {object}  map[string]string  "Meal template deleted successfully"
+// @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string  "Meal template not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-templates/{id} [delete]
+func (c *MealTemplateController) DeleteTemplate(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.service.DeleteTemplate(id, claims.UserID); err != nil {
+		writeTemplateError(ctx, err, "Failed to delete meal template")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Meal template deleted successfully"})
+}
+
+// LogTemplate godoc
+// @Summary      Log a meal template
+// @Description  Log a meal template of the user, or a shared one, as a new meal with every amount multiplied by scale (default 1). The meal type defaults to the template's and consumed_at to now.
+// @Tags         meal_template
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int                            true   "Meal template ID"
+// @Param        log  body      dto.LogMealTemplateRequestDTO  false  "Scale, meal type, note and consumption time"
+// @Success      201  {object}  models.MealLogWithItems        "Meal logged successfully"
+// @Failure      400  {object}  map[string]string              "Invalid ID, request body or scale, or a food no longer visible"
+// @Failure      401  {object}  map[string]string              "Unauthorized"
+// @Failure      404  {object}  map[string]string              "Meal template not found"
+// @Failure      500  {object}  map[string]string              "Internal server error"
+// @Security     BearerAuth
+// @Router       /meal-templates/{id}/log [post]
+func (c *MealTemplateController) LogTemplate(ctx *gin.Context) {
+	claims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	// The body is optional: without one the template is logged as saved, now
+	var req dto.LogMealTemplateRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	mealLog, err := c.service.LogTemplate(id, claims.UserID, req)
+	if err != nil {
+		writeTemplateError(ctx, err, "Failed to log meal template")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, mealLog)
+}
@@ -0,0 +1,51 @@
+package models
+
+import (
+	"time"
+)
+
+// Meal template visibility levels
+const (
+	// VisibilityPrivate templates are only visible to their owner
+	VisibilityPrivate = "private"
+	// VisibilityShared templates are visible to, and can be logged by, every user
+	VisibilityShared = "shared"
+)
+
+// MealTemplate represents the meal_template table in the database. A template is a
+// saved meal that is logged again in one request.
+type MealTemplate struct {
+	ID         uint      `gorm:"primaryKey;column:id" json:"id"`
+	UserID     uint      `gorm:"column:user_id;not null;index:idx_meal_template_user" json:"user_id"`
+	Name       string    `gorm:"column:name;not null" json:"name"`
+	MealType   string    `gorm:"column:meal_type;not null;default:''" json:"meal_type"`
+	Visibility string    `gorm:"column:visibility;type:varchar(16);not null;default:'private';index:idx_meal_template_visibility" json:"visibility"`
+	CreatedAt  time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt  time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+}
+
+// TableName specifies the table name for the MealTemplate model
+func (MealTemplate) TableName() string {
+	return "meal_template"
+}
+
+// MealTemplateItem represents the meal_template_items table in the database. Each line
+// holds an amount of a food in the unit the user picked, like a meal log item.
+type MealTemplateItem struct {
+	ID             uint    `gorm:"primaryKey;column:id" json:"id"`
+	MealTemplateID uint    `gorm:"column:meal_template_id;not null;index:idx_meal_template_items_template" json:"meal_template_id"`
+	FoodID         uint    `gorm:"column:food_id;not null;index:idx_meal_template_items_food" json:"food_id"`
+	Unit           string  `gorm:"column:unit;type:varchar(32);not null;default:'serving'" json:"unit"`
+	Amount         float64 `gorm:"column:amount;not null" json:"amount"`
+	QuantityGrams  float64 `gorm:"column:quantity_grams;not null" json:"quantity_grams"`
+}
+
+// TableName specifies the table name for the MealTemplateItem model
+func (MealTemplateItem) TableName() string {
+	return "meal_template_items"
+}
+
+// CanView reports whether the user may see and log the template
+func (t *MealTemplate) CanView(userID uint) bool {
+	return t.UserID == userID || t.Visibility == VisibilityShared
+}
@@ -0,0 +1,104 @@
+package repository
+
+import (
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/meal_template/models"
+	"gorm.io/gorm"
+)
+
+// MealTemplateRepository handles all database operations for meal templates and their items
+type MealTemplateRepository struct {
+	db *gorm.DB
+}
+
+// NewMealTemplateRepository creates a new meal template repository instance
+func NewMealTemplateRepository(db *gorm.DB) *MealTemplateRepository {
+	return &MealTemplateRepository{db: db}
+}
+
+// Create adds a template together with its items
+func (r *MealTemplateRepository) Create(template *models.MealTemplate, items []models.MealTemplateItem) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(template).Error; err != nil {
+			return err
+		}
+		return createItems(tx, template.ID, items)
+	}))
+}
+
+// GetByID retrieves a template by its ID
+func (r *MealTemplateRepository) GetByID(id uint) (*models.MealTemplate, error) {
+	var template models.MealTemplate
+	err := r.db.Where("id = ?", id).First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
+// GetByUserID retrieves all templates created by a user
+func (r *MealTemplateRepository) GetByUserID(userID uint) ([]models.MealTemplate, error) {
+	var templates []models.MealTemplate
+	err := r.db.Where("user_id = ?", userID).Order("name, id").Find(&templates).Error
+	return templates, err
+}
+
+// GetShared retrieves the templates other users shared with everyone
+func (r *MealTemplateRepository) GetShared(excludeUserID uint) ([]models.MealTemplate, error) {
+	var templates []models.MealTemplate
+	err := r.db.Where("visibility = ? AND user_id <> ?", models.VisibilityShared, excludeUserID).
+		Order("name, id").Find(&templates).Error
+	return templates, err
+}
+
+// GetItems retrieves the items of a template
+func (r *MealTemplateRepository) GetItems(templateID uint) ([]models.MealTemplateItem, error) {
+	var items []models.MealTemplateItem
+	err := r.db.Where("meal_template_id = ?", templateID).Order("id").Find(&items).Error
+	return items, err
+}
+
+// GetItemsByTemplateIDs retrieves the items of several templates in a single query
+func (r *MealTemplateRepository) GetItemsByTemplateIDs(templateIDs []uint) ([]models.MealTemplateItem, error) {
+	var items []models.MealTemplateItem
+	if len(templateIDs) == 0 {
+		return items, nil
+	}
+	err := r.db.Where("meal_template_id IN ?", templateIDs).Order("id").Find(&items).Error
+	return items, err
+}
+
+// Update saves a template and replaces every one of its items
+func (r *MealTemplateRepository) Update(template *models.MealTemplate, items []models.MealTemplateItem) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(template).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("meal_template_id = ?", template.ID).Delete(&models.MealTemplateItem{}).Error; err != nil {
+			return err
+		}
+		return createItems(tx, template.ID, items)
+	}))
+}
+
+// Delete removes a template and its items
+func (r *MealTemplateRepository) Delete(id uint) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("meal_template_id = ?", id).Delete(&models.MealTemplateItem{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.MealTemplate{}, id).Error
+	}))
+}
+
+// createItems inserts the items of a template
+func createItems(tx *gorm.DB, templateID uint, items []models.MealTemplateItem) error {
+	if len(items) == 0 {
+		return nil
+	}
+	for i := range items {
+		items[i].ID = 0
+		items[i].MealTemplateID = templateID
+	}
+	return tx.Create(&items).Error
+}
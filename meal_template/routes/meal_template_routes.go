@@ -0,0 +1,54 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/database"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	foodPortionRepo "github.com/momokapoolz/caloriesapp/food_portion/repository"
+	foodPortionServices "github.com/momokapoolz/caloriesapp/food_portion/services"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogServices "github.com/momokapoolz/caloriesapp/meal_log/services"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	"github.com/momokapoolz/caloriesapp/meal_template/controllers"
+	"github.com/momokapoolz/caloriesapp/meal_template/repository"
+	"github.com/momokapoolz/caloriesapp/meal_template/services"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	"gorm.io/gorm"
+)
+
+// SetupMealTemplateRoutes initializes meal template routes
+func SetupMealTemplateRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	mealLogRepository := mealLogRepo.NewMealLogRepository(db)
+	mealLogItemsRepository := mealLogItemsRepo.NewMealLogItemRepository(db)
+	foodRepository := foodRepo.NewFoodRepository(db)
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepository,
+		foodRepository,
+		foodNutrientsRepo.NewFoodNutrientRepository(db),
+		nutrientRepo.NewNutrientRepository(db),
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(dailyNutritionRepo.NewDailyNutritionRepository(db), mealLogRepository, engine)
+	portions := foodPortionServices.NewFoodPortionService(foodPortionRepo.NewFoodPortionRepository(db), foodRepository)
+	mealLogService := mealLogServices.NewMealLogService(database.NewUnitOfWork(db), mealLogRepository, mealLogItemsRepository, foodRepository, portions, rollup)
+	templateService := services.NewMealTemplateService(repository.NewMealTemplateRepository(db), foodRepository, portions, engine, mealLogService)
+	templateController := controllers.NewMealTemplateController(templateService)
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	templateRoutes := router.Group("/meal-templates", authMiddleware.RequireAuth())
+	{
+		templateRoutes.POST("/", templateController.CreateTemplate)
+		templateRoutes.GET("/", templateController.GetTemplates)
+		templateRoutes.GET("/shared", templateController.GetSharedTemplates)
+		templateRoutes.POST("/from-meal-log", templateController.CreateTemplateFromMealLog)
+		templateRoutes.GET("/:id", templateController.GetTemplate)
+		templateRoutes.PUT("/:id", templateController.UpdateTemplate)
+		templateRoutes.DELETE("/:id", templateController.DeleteTemplate)
+		templateRoutes.POST("/:id/log", templateController.LogTemplate)
+	}
+}
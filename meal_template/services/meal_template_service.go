@@ -0,0 +1,429 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodPortionServices "github.com/momokapoolz/caloriesapp/food_portion/services"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogServices "github.com/momokapoolz/caloriesapp/meal_log/services"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	"github.com/momokapoolz/caloriesapp/meal_template/models"
+	"github.com/momokapoolz/caloriesapp/meal_template/repository"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	"gorm.io/gorm"
+)
+
+// Error definitions
+var (
+	// ErrTemplateNotAccessible is returned when the user may see a template but not change it
+	ErrTemplateNotAccessible = errors.New("not allowed to modify this meal template")
+	// ErrInvalidVisibility is returned for visibilities other than private and shared
+	ErrInvalidVisibility = errors.New("invalid visibility")
+	// ErrInvalidTemplateItem is returned for items naming an unknown food or unit
+	ErrInvalidTemplateItem = errors.New("invalid meal template item")
+	// ErrPrivateFoodShared is returned when sharing a template that uses private foods
+	ErrPrivateFoodShared = errors.New("a shared meal template can only use shared or verified foods")
+	// ErrInvalidScale is returned for scales that are not greater than 0
+	ErrInvalidScale = errors.New("scale must be greater than 0")
+	// ErrEmptyTemplate is returned when saving a template without items
+	ErrEmptyTemplate = errors.New("a meal template needs at least one item")
+)
+
+// MealTemplateService handles business logic for meal templates. Template nutrition is
+// computed by the nutrition engine, and a template is logged through the meal log service,
+// so both agree with the numbers of logged meals.
+type MealTemplateService struct {
+	repo     *repository.MealTemplateRepository
+	foodRepo *foodRepo.FoodRepository
+	portions *foodPortionServices.FoodPortionService
+	engine   *nutritionEngine.NutritionEngine
+	mealLogs *mealLogServices.MealLogService
+}
+
+// NewMealTemplateService creates a new meal template service instance
+func NewMealTemplateService(
+	repo *repository.MealTemplateRepository,
+	foodRepo *foodRepo.FoodRepository,
+	portions *foodPortionServices.FoodPortionService,
+	engine *nutritionEngine.NutritionEngine,
+	mealLogs *mealLogServices.MealLogService,
+) *MealTemplateService {
+	return &MealTemplateService{
+		repo:     repo,
+		foodRepo: foodRepo,
+		portions: portions,
+		engine:   engine,
+		mealLogs: mealLogs,
+	}
+}
+
+// CreateTemplate creates a template owned by the user
+func (s *MealTemplateService) CreateTemplate(userID uint, req dto.MealTemplateRequestDTO) (*dto.MealTemplateResponseDTO, error) {
+	if req.Visibility == "" {
+		req.Visibility = models.VisibilityPrivate
+	}
+	items, err := s.checkItems(userID, req.Visibility, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	template := &models.MealTemplate{
+		UserID:     userID,
+		Name:       req.Name,
+		MealType:   req.MealType,
+		Visibility: req.Visibility,
+	}
+	if err := s.repo.Create(template, items); err != nil {
+		return nil, err
+	}
+	return s.toResponse(template, items, 1)
+}
+
+// CreateTemplateFromMealLog saves a meal the user logged as a template, named name and with the
+// meal type and items of the meal
+func (s *MealTemplateService) CreateTemplateFromMealLog(userID uint, req dto.MealTemplateFromMealLogRequestDTO) (*dto.MealTemplateResponseDTO, error) {
+	if err := s.mealLogs.VerifyMealLogOwnership(req.MealLogID, userID); err != nil {
+		return nil, err
+	}
+	mealLog, err := s.mealLogs.GetMealLogWithItemsByID(req.MealLogID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.CreateTemplate(userID, dto.MealTemplateRequestDTO{
+		Name:       req.Name,
+		MealType:   mealLog.MealLog.MealType,
+		Visibility: req.Visibility,
+		Items:      itemLines(mealLog.Items),
+	})
+}
+
+// GetTemplate retrieves a template the user may see, with amounts and nutrition multiplied by scale
+func (s *MealTemplateService) GetTemplate(id, userID uint, scale float64) (*dto.MealTemplateResponseDTO, error) {
+	if scale <= 0 {
+		return nil, ErrInvalidScale
+	}
+	template, err := s.loadTemplate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetItems(template.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(template, items, scale)
+}
+
+// GetTemplatesByUser retrieves every template created by the user
+func (s *MealTemplateService) GetTemplatesByUser(userID uint) ([]dto.MealTemplateResponseDTO, error) {
+	templates, err := s.repo.GetByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponses(templates)
+}
+
+// GetSharedTemplates retrieves the templates other users shared
+func (s *MealTemplateService) GetSharedTemplates(userID uint) ([]dto.MealTemplateResponseDTO, error) {
+	templates, err := s.repo.GetShared(userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponses(templates)
+}
+
+// UpdateTemplate replaces the name, meal type, visibility and items of a template of the user
+func (s *MealTemplateService) UpdateTemplate(id, userID uint, req dto.MealTemplateRequestDTO) (*dto.MealTemplateResponseDTO, error) {
+	template, err := s.modifiableTemplate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Visibility == "" {
+		req.Visibility = template.Visibility
+	}
+	items, err := s.checkItems(userID, req.Visibility, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	template.Name = req.Name
+	template.MealType = req.MealType
+	template.Visibility = req.Visibility
+	if err := s.repo.Update(template, items); err != nil {
+		return nil, err
+	}
+	return s.toResponse(template, items, 1)
+}
+
+// DeleteTemplate removes a template of the user. Meals logged from it are kept.
+func (s *MealTemplateService) DeleteTemplate(id, userID uint) error {
+	if _, err := s.modifiableTemplate(id, userID); err != nil {
+		return err
+	}
+	return s.repo.Delete(id)
+}
+
+// LogTemplate logs a template the user may see as a new meal of the user, with every amount
+// multiplied by the scale of req
+func (s *MealTemplateService) LogTemplate(id, userID uint, req dto.LogMealTemplateRequestDTO) (*mealLogModels.MealLogWithItems, error) {
+	if req.Scale == 0 {
+		req.Scale = 1
+	}
+	if req.Scale < 0 {
+		return nil, ErrInvalidScale
+	}
+	template, err := s.loadTemplate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	items, err := s.repo.GetItems(template.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	// A food may have turned private since the template was shared
+	if _, err := s.visibleFoods(userID, items); err != nil {
+		return nil, err
+	}
+
+	mealLog := dto.CreateMealLogRequestDTO{
+		MealType:   template.MealType,
+		Note:       req.Note,
+		ConsumedAt: req.ConsumedAt,
+		Items:      make([]dto.MealLogItemDTO, 0, len(items)),
+	}
+	if req.MealType != "" {
+		mealLog.MealType = req.MealType
+	}
+	for _, item := range scaleItems(items, req.Scale) {
+		mealLog.Items = append(mealLog.Items, dto.MealLogItemDTO{FoodID: item.FoodID, Unit: item.Unit, Amount: item.Amount})
+	}
+	return s.mealLogs.CreateMealLogComprehensive(userID, mealLog)
+}
+
+// checkItems validates the visibility and items of a template saved by the user and resolves the
+// weight of each item. Every food must be visible to the user, and to everyone when it is shared.
+func (s *MealTemplateService) checkItems(userID uint, visibility string, lines []dto.MealTemplateItemRequestDTO) ([]models.MealTemplateItem, error) {
+	if visibility != models.VisibilityPrivate && visibility != models.VisibilityShared {
+		return nil, ErrInvalidVisibility
+	}
+	if len(lines) == 0 {
+		return nil, ErrEmptyTemplate
+	}
+
+	items := make([]models.MealTemplateItem, 0, len(lines))
+	for _, line := range lines {
+		unit := line.Unit
+		if unit == "" {
+			unit = foodPortionServices.UnitServing
+		}
+		items = append(items, models.MealTemplateItem{FoodID: line.FoodID, Unit: foodPortionServices.NormalizeUnit(unit), Amount: line.Amount})
+	}
+
+	foods, err := s.visibleFoods(userID, items)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkShareable(visibility, foods); err != nil {
+		return nil, err
+	}
+
+	resolver, err := s.portions.NewGramResolver(foodIDs(items), foodModels.FoodViewer{UserID: userID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load food portions: %w", err)
+	}
+	for i := range items {
+		grams, err := resolver.Grams(items[i].FoodID, items[i].Amount, items[i].Unit)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidTemplateItem, err)
+		}
+		items[i].QuantityGrams = grams
+	}
+	return items, nil
+}
+
+// visibleFoods loads the foods of items, checking that the user may see every one of them
+func (s *MealTemplateService) visibleFoods(userID uint, items []models.MealTemplateItem) ([]foodModels.Food, error) {
+	ids := foodIDs(items)
+	foods, err := s.foodRepo.GetVisibleByIDs(ids, foodModels.FoodViewer{UserID: userID})
+	if err != nil {
+		return nil, err
+	}
+	found := make(map[uint]bool, len(foods))
+	for _, food := range foods {
+		found[food.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return nil, fmt.Errorf("%w: %w: %d", ErrInvalidTemplateItem, foodPortionServices.ErrFoodNotFound, id)
+		}
+	}
+	return foods, nil
+}
+
+// loadTemplate retrieves a template if the user may see it; other users' private templates are
+// reported as not found
+func (s *MealTemplateService) loadTemplate(id, userID uint) (*models.MealTemplate, error) {
+	template, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if !template.CanView(userID) {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return template, nil
+}
+
+// modifiableTemplate loads a template and checks that the user owns it
+func (s *MealTemplateService) modifiableTemplate(id, userID uint) (*models.MealTemplate, error) {
+	template, err := s.loadTemplate(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if template.UserID != userID {
+		return nil, ErrTemplateNotAccessible
+	}
+	return template, nil
+}
+
+// toResponses builds the responses of templates at their saved amounts
+func (s *MealTemplateService) toResponses(templates []models.MealTemplate) ([]dto.MealTemplateResponseDTO, error) {
+	templateIDs := make([]uint, 0, len(templates))
+	for _, template := range templates {
+		templateIDs = append(templateIDs, template.ID)
+	}
+	items, err := s.repo.GetItemsByTemplateIDs(templateIDs)
+	if err != nil {
+		return nil, err
+	}
+	itemsByTemplate := make(map[uint][]models.MealTemplateItem, len(templates))
+	for _, item := range items {
+		itemsByTemplate[item.MealTemplateID] = append(itemsByTemplate[item.MealTemplateID], item)
+	}
+
+	responses := make([]dto.MealTemplateResponseDTO, 0, len(templates))
+	for i := range templates {
+		response, err := s.toResponse(&templates[i], itemsByTemplate[templates[i].ID], 1)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// toResponse builds the response of a template with its items multiplied by scale, and their
+// nutrition computed by the nutrition engine as if the template were logged
+func (s *MealTemplateService) toResponse(template *models.MealTemplate, items []models.MealTemplateItem, scale float64) (*dto.MealTemplateResponseDTO, error) {
+	scaled := scaleItems(items, scale)
+	mealItems := make([]mealLogItemsModels.MealLogItem, 0, len(scaled))
+	for _, item := range scaled {
+		mealItems = append(mealItems, mealLogItemsModels.MealLogItem{FoodID: item.FoodID, Unit: item.Unit, Amount: item.Amount, QuantityGrams: item.QuantityGrams})
+	}
+	report, err := s.engine.CalculateItems(mealItems)
+	if err != nil {
+		return nil, err
+	}
+	meal := report.Meals[0]
+
+	response := &dto.MealTemplateResponseDTO{
+		ID:            template.ID,
+		OwnerID:       template.UserID,
+		Name:          template.Name,
+		MealType:      template.MealType,
+		Visibility:    template.Visibility,
+		Scale:         scale,
+		TotalCalories: report.Amount(meal.Nutrients, nutrientModels.CodeEnergy),
+		Items:         make([]dto.MealTemplateItemResponseDTO, 0, len(scaled)),
+		Nutrition:     nutritionList(report, meal.Nutrients),
+		CreatedAt:     template.CreatedAt,
+		UpdatedAt:     template.UpdatedAt,
+	}
+	for i, item := range scaled {
+		response.Items = append(response.Items, dto.MealTemplateItemResponseDTO{
+			ID:            item.ID,
+			FoodID:        item.FoodID,
+			FoodName:      meal.Items[i].FoodName,
+			Unit:          item.Unit,
+			Amount:        item.Amount,
+			QuantityGrams: item.QuantityGrams,
+		})
+	}
+	return response, nil
+}
+
+// checkShareable checks that a template of the given visibility only uses foods every user can see
+func checkShareable(visibility string, foods []foodModels.Food) error {
+	if visibility != models.VisibilityShared {
+		return nil
+	}
+	for _, food := range foods {
+		if food.Visibility == foodModels.VisibilityPrivate {
+			return fmt.Errorf("%w: food %d is private", ErrPrivateFoodShared, food.ID)
+		}
+	}
+	return nil
+}
+
+// scaleItems returns copies of items with their amount and weight multiplied by scale
+func scaleItems(items []models.MealTemplateItem, scale float64) []models.MealTemplateItem {
+	scaled := make([]models.MealTemplateItem, len(items))
+	for i, item := range items {
+		item.Amount *= scale
+		item.QuantityGrams *= scale
+		scaled[i] = item
+	}
+	return scaled
+}
+
+// itemLines turns the items of a logged meal into template item lines. Items logged before units
+// were recorded keep their weight in grams.
+func itemLines(items []mealLogItemsModels.MealLogItem) []dto.MealTemplateItemRequestDTO {
+	lines := make([]dto.MealTemplateItemRequestDTO, 0, len(items))
+	for _, item := range items {
+		line := dto.MealTemplateItemRequestDTO{FoodID: item.FoodID, Unit: item.Unit, Amount: item.Amount}
+		if item.Amount <= 0 {
+			line.Unit, line.Amount = foodPortionServices.UnitGram, item.QuantityGrams
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// foodIDs lists the distinct foods of items
+func foodIDs(items []models.MealTemplateItem) []uint {
+	ids := make([]uint, 0, len(items))
+	seen := make(map[uint]bool, len(items))
+	for _, item := range items {
+		if !seen[item.FoodID] {
+			seen[item.FoodID] = true
+			ids = append(ids, item.FoodID)
+		}
+	}
+	return ids
+}
+
+// nutritionList lists the nutrients of totals ordered by nutrient ID
+func nutritionList(report *nutritionEngine.NutritionReport, totals nutritionEngine.NutrientTotals) []dto.MicronutrientDTO {
+	nutrition := make([]dto.MicronutrientDTO, 0, len(totals))
+	for nutrientID, amount := range totals {
+		nutrient, ok := report.Nutrient(nutrientID)
+		if !ok {
+			continue
+		}
+		nutrition = append(nutrition, dto.MicronutrientDTO{
+			NutrientID:   nutrientID,
+			NutrientName: nutrient.Name,
+			Amount:       amount,
+			Unit:         nutrient.Unit,
+		})
+	}
+	sort.Slice(nutrition, func(i, j int) bool { return nutrition[i].NutrientID < nutrition[j].NutrientID })
+	return nutrition
+}
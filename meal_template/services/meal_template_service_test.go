@@ -0,0 +1,57 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	"github.com/momokapoolz/caloriesapp/meal_template/models"
+)
+
+func TestScaleItems(t *testing.T) {
+	items := []models.MealTemplateItem{{ID: 1, FoodID: 4, Unit: "cup", Amount: 1, QuantityGrams: 240}}
+
+	scaled := scaleItems(items, 1.5)
+	if scaled[0].Amount != 1.5 || scaled[0].QuantityGrams != 360 || scaled[0].Unit != "cup" {
+		t.Errorf("scaled item = %+v, want 1.5 cup of 360 g", scaled[0])
+	}
+	if items[0].Amount != 1 {
+		t.Errorf("scaling changed the saved item: %+v", items[0])
+	}
+}
+
+func TestCheckShareable(t *testing.T) {
+	foods := []foodModels.Food{
+		{ID: 1, Visibility: foodModels.VisibilityVerified},
+		{ID: 2, Visibility: foodModels.VisibilityPrivate},
+	}
+
+	if err := checkShareable(models.VisibilityPrivate, foods); err != nil {
+		t.Errorf("a private template may use private foods, got %v", err)
+	}
+	if err := checkShareable(models.VisibilityShared, foods); !errors.Is(err, ErrPrivateFoodShared) {
+		t.Errorf("checkShareable = %v, want ErrPrivateFoodShared", err)
+	}
+	if err := checkShareable(models.VisibilityShared, foods[:1]); err != nil {
+		t.Errorf("a shared template may use verified foods, got %v", err)
+	}
+}
+
+func TestItemLines(t *testing.T) {
+	lines := itemLines([]mealLogItemsModels.MealLogItem{
+		{FoodID: 1, Unit: "slice", Amount: 2, QuantityGrams: 60},
+		// Logged before units were recorded
+		{FoodID: 2, QuantityGrams: 150},
+	})
+
+	want := []dto.MealTemplateItemRequestDTO{
+		{FoodID: 1, Unit: "slice", Amount: 2},
+		{FoodID: 2, Unit: "g", Amount: 150},
+	}
+	if !reflect.DeepEqual(lines, want) {
+		t.Errorf("itemLines = %+v, want %+v", lines, want)
+	}
+}
@@ -41,6 +41,9 @@ func (c *NutrientController) CreateNutrient(ctx *gin.Context) {
 	}
 
 	if err := c.service.CreateNutrient(&nutrient); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create nutrient"})
 		return
@@ -149,6 +152,9 @@ func (c *NutrientController) UpdateNutrient(ctx *gin.Context) {
 
 	nutrient.ID = uint(id)
 	if err := c.service.UpdateNutrient(&nutrient); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update nutrient"})
 		return
@@ -165,6 +171,7 @@ func (c *NutrientController) UpdateNutrient(ctx *gin.Context) {
 // @Param        id  path  int  true  "Nutrient ID"
 // @Success      200  {object}  map[string]string  "Nutrient deleted successfully"
 // @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      409  {object}  map[string]string  "Nutrient is still used by foods or targets"
 // @Failure      500  {object}  map[string]string  "Internal server error"
 // @Security     BearerAuth
 // @Router       /nutrients/{id} [delete]
@@ -177,6 +184,9 @@ func (c *NutrientController) DeleteNutrient(ctx *gin.Context) {
 	}
 
 	if err := c.service.DeleteNutrient(uint(id)); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete nutrient"})
 		return
@@ -266,9 +276,6 @@ func (c *NutrientController) GetUserNutritionByDateRange(ctx *gin.Context) {
 		return
 	}
 
-	// Extend end date to include the full day
-	endDate = time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 23, 59, 59, 999999999, endDate.Location())
-
 	// Get nutrition summary
 	summary, err := c.service.CalculateUserNutritionByDateRange(userClaims.UserID, startDate, endDate)
 	if err != nil {
@@ -298,11 +305,8 @@ func (c *NutrientController) GetUserCurrentNutrition(ctx *gin.Context) {
 		return
 	}
 
-	// Use today's date
-	today := time.Now().Truncate(24 * time.Hour)
-
-	// Get nutrition summary
-	summary, err := c.service.CalculateUserNutritionByDate(userClaims.UserID, today)
+	// Get nutrition summary for today in the user's timezone
+	summary, err := c.service.CalculateUserNutritionToday(userClaims.UserID)
 	if err != nil {
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to calculate nutrition"})
@@ -1,7 +1,23 @@
 package models
 
+// Stable nutrient codes. Services resolve nutrients through these codes
+// instead of relying on database IDs, which differ between environments.
+const (
+	CodeEnergy       = "energy"
+	CodeProtein      = "protein"
+	CodeFat          = "fat"
+	CodeCarbohydrate = "carbohydrate"
+	CodeFiber        = "fiber"
+	CodeCholesterol  = "cholesterol"
+	CodeVitaminA     = "vitamin_a"
+	CodeVitaminB12   = "vitamin_b12"
+	CodeCalcium      = "calcium"
+	CodeIron         = "iron"
+)
+
 type Nutrient struct {
 	ID       uint   `gorm:"primaryKey;column:id" json:"id"`
+	Code     string `gorm:"column:code;type:varchar(64);not null;default:'';uniqueIndex:idx_nutrient_code,where:code <> ''" json:"code"`
 	Name     string `gorm:"column:name;not null" json:"name"`
 	Category string `gorm:"column:category;not null" json:"category"`
 	Unit     string `gorm:"column:unit;not null;default:g" json:"unit"`
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"github.com/momokapoolz/caloriesapp/database"
 	"github.com/momokapoolz/caloriesapp/nutrient/models"
 	"gorm.io/gorm"
 )
@@ -14,7 +15,7 @@ func NewNutrientRepository(db *gorm.DB) *NutrientRepository {
 }
 
 func (r *NutrientRepository) Create(nutrient *models.Nutrient) error {
-	return r.db.Create(nutrient).Error
+	return database.TranslateError(r.db.Create(nutrient).Error)
 }
 
 func (r *NutrientRepository) GetByID(id uint) (*models.Nutrient, error) {
@@ -39,9 +40,9 @@ func (r *NutrientRepository) GetByCategory(category string) ([]models.Nutrient,
 }
 
 func (r *NutrientRepository) Update(nutrient *models.Nutrient) error {
-	return r.db.Save(nutrient).Error
+	return database.TranslateError(r.db.Save(nutrient).Error)
 }
 
 func (r *NutrientRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Nutrient{}, id).Error
+	return database.TranslateError(r.db.Delete(&models.Nutrient{}, id).Error)
 }
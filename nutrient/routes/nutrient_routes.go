@@ -3,6 +3,8 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
 	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
 	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
 	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
@@ -10,6 +12,12 @@ import (
 	"github.com/momokapoolz/caloriesapp/nutrient/controllers"
 	"github.com/momokapoolz/caloriesapp/nutrient/repository"
 	"github.com/momokapoolz/caloriesapp/nutrient/services"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	referenceIntakeServices "github.com/momokapoolz/caloriesapp/reference_intake/services"
+	targetsRepository "github.com/momokapoolz/caloriesapp/targets/repository"
+	targetsServices "github.com/momokapoolz/caloriesapp/targets/services"
+	userRepository "github.com/momokapoolz/caloriesapp/user/repository"
+	userBiometricsRepository "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
 	"gorm.io/gorm"
 )
 
@@ -21,13 +29,45 @@ func SetupNutrientRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	foodRepository := foodRepo.NewFoodRepository(db)
 	foodNutrientsRepository := foodNutrientsRepo.NewFoodNutrientRepository(db)
 
-	// Initialize service with all repositories
-	nutrientService := services.NewNutrientService(
-		nutrientRepo,
-		mealLogRepository,
+	// Initialize the shared nutrition engine
+	engine := nutritionEngine.NewNutritionEngine(
 		mealLogItemsRepository,
 		foodRepository,
 		foodNutrientsRepository,
+		nutrientRepo,
+	)
+
+	// Initialize the daily nutrition rollup
+	rollup := dailyNutritionServices.NewDailyNutritionService(
+		dailyNutritionRepo.NewDailyNutritionRepository(db),
+		mealLogRepository,
+		engine,
+	)
+
+	// Initialize the reference intakes from the bundled dataset
+	intakes := referenceIntakeServices.NewReferenceIntakeService(
+		referenceIntakeServices.DefaultTable(),
+		userRepository.NewUserRepository(),
+	)
+
+	// Initialize the user's nutrition targets
+	biometricRepo := userBiometricsRepository.NewUserBiometricRepository(db)
+	targets := targetsServices.NewTargetsService(
+		userRepository.NewUserRepository(),
+		biometricRepo,
+		targetsRepository.NewTargetSetRepository(db),
+		nutrientRepo,
+		targetsServices.NewAdaptiveTDEEService(biometricRepo, mealLogRepository, engine, rollup),
+	)
+
+	// Initialize service
+	nutrientService := services.NewNutrientService(
+		nutrientRepo,
+		mealLogRepository,
+		engine,
+		rollup,
+		intakes,
+		targets,
 	)
 
 	// Initialize controller
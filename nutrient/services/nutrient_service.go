@@ -3,51 +3,68 @@ package services
 import (
 	"fmt"
 	"github.com/momokapoolz/caloriesapp/helpers"
+	"math"
+	"sort"
 	"time"
 
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
 	"github.com/momokapoolz/caloriesapp/dto"
-	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
-	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
 	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
-	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
 	"github.com/momokapoolz/caloriesapp/nutrient/models"
 	"github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	referenceIntakeServices "github.com/momokapoolz/caloriesapp/reference_intake/services"
+	targetsServices "github.com/momokapoolz/caloriesapp/targets/services"
 )
 
-const (
-	EnergyNutrientID       = 1
-	ProteinNutrientID      = 2
-	FatNutrientID          = 3
-	CarbohydrateNutrientID = 4
-	FiberNutrientID        = 5
-	CholesterolNutrientID  = 6
-	Vitamin_A_NutrientID   = 7
-	Vitamin_B12_NutrientID = 8
-	CalciumNutrientID      = 9
-	IronNutrientID         = 10
-)
+// macroBreakdownCodes lists the nutrients reported in MacroNutrientBreakDown;
+// every other nutrient is reported in MicroNutrientBreakDown
+var macroBreakdownCodes = map[string]bool{
+	models.CodeEnergy:       true,
+	models.CodeProtein:      true,
+	models.CodeFat:          true,
+	models.CodeCarbohydrate: true,
+	models.CodeFiber:        true,
+	models.CodeCholesterol:  true,
+	models.CodeVitaminA:     true,
+	models.CodeVitaminB12:   true,
+	models.CodeCalcium:      true,
+	models.CodeIron:         true,
+}
+
+// mealBreakdownCodes lists the nutrients reported per meal in MealBreakdown
+var mealBreakdownCodes = []string{
+	models.CodeEnergy,
+	models.CodeProtein,
+	models.CodeCarbohydrate,
+	models.CodeFat,
+}
 
 type NutrientService struct {
-	repo              *repository.NutrientRepository
-	mealLogRepo       *mealLogRepo.MealLogRepository
-	mealLogItemsRepo  *mealLogItemsRepo.MealLogItemRepository
-	foodRepo          *foodRepo.FoodRepository
-	foodNutrientsRepo *foodNutrientsRepo.FoodNutrientRepository
+	repo        *repository.NutrientRepository
+	mealLogRepo *mealLogRepo.MealLogRepository
+	engine      *nutritionEngine.NutritionEngine
+	rollup      *dailyNutritionServices.DailyNutritionService
+	intakes     *referenceIntakeServices.ReferenceIntakeService
+	targets     *targetsServices.TargetsService
 }
 
 func NewNutrientService(
 	repo *repository.NutrientRepository,
 	mealLogRepo *mealLogRepo.MealLogRepository,
-	mealLogItemsRepo *mealLogItemsRepo.MealLogItemRepository,
-	foodRepo *foodRepo.FoodRepository,
-	foodNutrientsRepo *foodNutrientsRepo.FoodNutrientRepository,
+	engine *nutritionEngine.NutritionEngine,
+	rollup *dailyNutritionServices.DailyNutritionService,
+	intakes *referenceIntakeServices.ReferenceIntakeService,
+	targets *targetsServices.TargetsService,
 ) *NutrientService {
 	return &NutrientService{
-		repo:              repo,
-		mealLogRepo:       mealLogRepo,
-		mealLogItemsRepo:  mealLogItemsRepo,
-		foodRepo:          foodRepo,
-		foodNutrientsRepo: foodNutrientsRepo,
+		repo:        repo,
+		mealLogRepo: mealLogRepo,
+		engine:      engine,
+		rollup:      rollup,
+		intakes:     intakes,
+		targets:     targets,
 	}
 }
 
@@ -75,94 +92,125 @@ func (s *NutrientService) DeleteNutrient(id uint) error {
 	return s.repo.Delete(id)
 }
 
-// CalculateUserNutritionByDateRange calculates nutrition for a user within a date range
+// CalculateUserNutritionByDateRange calculates nutrition for a user between two calendar dates
+// (inclusive) in the user's timezone. Range and daily totals come from the rollup when it is fresh,
+// so the meals are only calculated for the macros of the meal breakdown; otherwise everything is
+// calculated from the raw rows.
 func (s *NutrientService) CalculateUserNutritionByDateRange(userID uint, startDate, endDate time.Time) (*dto.NutritionSummaryDTO, error) {
-	mealLogs, err := s.mealLogRepo.GetByUserIDAndDateRange(userID, startDate, endDate)
+	loc, mealLogs, err := s.getMealLogs(userID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, fresh, err := s.rollup.GetFreshReport(userID, startDate, endDate)
 	if err != nil {
 		helpers.LogError(err)
-		return nil, fmt.Errorf("failed to get meal logs: %w", err)
+	}
+	if !fresh {
+		report, err := s.engine.Calculate(mealLogs, loc)
+		if err != nil {
+			helpers.LogError(err)
+			return nil, fmt.Errorf("failed to calculate nutrition: %w", err)
+		}
+		return s.buildSummary(userID, startDate, endDate, loc, report, report), nil
 	}
 
-	summary := &dto.NutritionSummaryDTO{
-		UserID:                 userID,
-		DateRange:              fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
-		MacroNutrientBreakDown: []dto.MacronutrientBreakdownDTO{},
-		MicroNutrientBreakDown: []dto.MicronutrientDTO{},
-		MealBreakdown:          []dto.MealNutritionDTO{},
+	meals, err := s.engine.CalculateMeals(mealLogs, loc, mealBreakdownCodes...)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to calculate nutrition: %w", err)
 	}
+	return s.buildSummary(userID, startDate, endDate, loc, totals, meals), nil
+}
 
-	totalNutrients := make(map[uint]float64) // nutrient_id -> total amount
+// CalculateUserNutritionReport calculates nutrition for a user between two calendar dates (inclusive)
+// in the user's timezone and also returns the per-item, per-meal and per-day report the summary was
+// built from. Its callers list every nutrient of every item, so it is always calculated from the raw rows.
+func (s *NutrientService) CalculateUserNutritionReport(userID uint, startDate, endDate time.Time) (*dto.NutritionSummaryDTO, *nutritionEngine.NutritionReport, error) {
+	loc, mealLogs, err := s.getMealLogs(userID, startDate, endDate)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	// Process each meal log
-	for _, mealLog := range mealLogs {
-		mealNutrition, foodCount, err := s.calculateMealNutrition(mealLog.ID)
-		if err != nil {
-			continue // Skip meals with calculation errors
-		}
+	report, err := s.engine.Calculate(mealLogs, loc)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, nil, fmt.Errorf("failed to calculate nutrition: %w", err)
+	}
 
-		// Add to meal breakdown
-		summary.MealBreakdown = append(summary.MealBreakdown, dto.MealNutritionDTO{
-			MealLogID:    mealLog.ID,
-			MealType:     mealLog.MealType,
-			Date:         mealLog.CreatedAt.Format("2006-01-02"),
-			Calories:     mealNutrition[EnergyNutrientID],
-			Protein:      mealNutrition[ProteinNutrientID],
-			Carbohydrate: mealNutrition[CarbohydrateNutrientID],
-			Fat:          mealNutrition[FatNutrientID],
-			FoodCount:    foodCount,
-		})
+	return s.buildSummary(userID, startDate, endDate, loc, report, report), report, nil
+}
 
-		// Add to total nutrients
-		for nutrientID, amount := range mealNutrition {
-			totalNutrients[nutrientID] += amount
-		}
+// getMealLogs retrieves the user's timezone and the meal logs consumed between two calendar dates in it
+func (s *NutrientService) getMealLogs(userID uint, startDate, endDate time.Time) (*time.Location, []mealLogModels.MealLog, error) {
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, nil, fmt.Errorf("failed to get user timezone: %w", err)
 	}
 
-	// Set total calories
-	summary.TotalCalories = totalNutrients[EnergyNutrientID]
-
-	// Create macro nutrient breakdown
-	macroBreakdown := dto.MacronutrientBreakdownDTO{
-		Energy:       totalNutrients[EnergyNutrientID],
-		Protein:      totalNutrients[ProteinNutrientID],
-		Fat:          totalNutrients[FatNutrientID],
-		Carbohydrate: totalNutrients[CarbohydrateNutrientID],
-		Fiber:        totalNutrients[FiberNutrientID],
-		Cholesterol:  totalNutrients[CholesterolNutrientID],
-		Vitamin_A:    totalNutrients[Vitamin_A_NutrientID],
-		Vitamin_B12:  totalNutrients[Vitamin_B12_NutrientID],
-		Calcium:      totalNutrients[CalciumNutrientID],
-		Iron:         totalNutrients[IronNutrientID],
+	mealLogs, err := s.mealLogRepo.GetByUserIDAndDateRange(userID, startDate, endDate, loc)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, nil, fmt.Errorf("failed to get meal logs: %w", err)
 	}
-	summary.MacroNutrientBreakDown = append(summary.MacroNutrientBreakDown, macroBreakdown)
 
-	// Build micronutrient breakdown for other nutrients
-	for nutrientID, amount := range totalNutrients {
-		// Skip the nutrients already included in macro breakdown
-		if s.isMacroNutrient(nutrientID) {
-			continue
-		}
+	return loc, mealLogs, nil
+}
 
-		nutrient, err := s.repo.GetByID(nutrientID)
-		if err != nil {
-			continue
-		}
+// buildSummary builds the nutrition summary of a date range from a report holding its range and
+// daily totals and a report holding its meals, which may be the same
+func (s *NutrientService) buildSummary(
+	userID uint,
+	startDate, endDate time.Time,
+	loc *time.Location,
+	totals *nutritionEngine.NutritionReport,
+	report *nutritionEngine.NutritionReport,
+) *dto.NutritionSummaryDTO {
+	summary := &dto.NutritionSummaryDTO{
+		UserID:                 userID,
+		DateRange:              fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		TotalCalories:          totals.Amount(totals.Totals, models.CodeEnergy),
+		MacroNutrientBreakDown: []dto.MacronutrientBreakdownDTO{s.buildMacroBreakdown(totals, totals.Totals)},
+		MicroNutrientBreakDown: s.buildMicroBreakdown(totals, totals.Totals),
+		MealBreakdown:          make([]dto.MealNutritionDTO, 0, len(report.Meals)),
+		DailyBreakdown:         make([]dto.DailyNutritionDTO, 0, len(totals.Days)),
+	}
 
-		summary.MicroNutrientBreakDown = append(summary.MicroNutrientBreakDown, dto.MicronutrientDTO{
-			NutrientID:   nutrientID,
-			NutrientName: nutrient.Name,
-			Amount:       amount,
-			Unit:         nutrient.Unit,
+	dayTotals := make([]nutritionEngine.NutrientTotals, 0, len(totals.Days))
+	for _, day := range totals.Days {
+		dayTotals = append(dayTotals, day.Nutrients)
+		summary.DailyBreakdown = append(summary.DailyBreakdown, dto.DailyNutritionDTO{
+			Date:         day.Date,
+			Calories:     totals.Amount(day.Nutrients, models.CodeEnergy),
+			Protein:      totals.Amount(day.Nutrients, models.CodeProtein),
+			Carbohydrate: totals.Amount(day.Nutrients, models.CodeCarbohydrate),
+			Fat:          totals.Amount(day.Nutrients, models.CodeFat),
 		})
 	}
 
-	return summary, nil
+	for _, meal := range report.Meals {
+		summary.MealBreakdown = append(summary.MealBreakdown, dto.MealNutritionDTO{
+			MealLogID:    meal.MealLog.ID,
+			MealType:     meal.MealLog.MealType,
+			Date:         helpers.DateIn(meal.MealLog.ConsumedAt, loc),
+			Calories:     report.Amount(meal.Nutrients, models.CodeEnergy),
+			Protein:      report.Amount(meal.Nutrients, models.CodeProtein),
+			Carbohydrate: report.Amount(meal.Nutrients, models.CodeCarbohydrate),
+			Fat:          report.Amount(meal.Nutrients, models.CodeFat),
+			FoodCount:    len(meal.Items),
+		})
+	}
+
+	summary.ReferenceIntakes = s.buildReferenceIntakes(userID, totals, totals.Totals, dayTotals, daysInRange(startDate, endDate))
+	summary.Targets = s.buildTargetStatus(userID, totals)
+
+	return summary
 }
 
-// CalculateUserNutritionByDate calculates nutrition for a user on a specific date
+// CalculateUserNutritionByDate calculates nutrition for a user on a calendar date in the user's timezone
 func (s *NutrientService) CalculateUserNutritionByDate(userID uint, date time.Time) (*dto.NutritionSummaryDTO, error) {
-	endDate := date.Add(24 * time.Hour)
-	summary, err := s.CalculateUserNutritionByDateRange(userID, date, endDate)
+	summary, err := s.CalculateUserNutritionByDateRange(userID, date, date)
 	if err != nil {
 		helpers.LogError(err)
 		return nil, err
@@ -171,49 +219,178 @@ func (s *NutrientService) CalculateUserNutritionByDate(userID uint, date time.Ti
 	return summary, nil
 }
 
-// calculateMealNutrition calculates total nutrition for a specific meal
-func (s *NutrientService) calculateMealNutrition(mealLogID uint) (map[uint]float64, int, error) {
-	// Get all meal log items for this meal
-	items, err := s.mealLogItemsRepo.GetByMealLogID(mealLogID)
+// CalculateUserNutritionToday calculates nutrition for a user on the current date in the user's timezone
+func (s *NutrientService) CalculateUserNutritionToday(userID uint) (*dto.NutritionSummaryDTO, error) {
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	return s.CalculateUserNutritionByDate(userID, time.Now().In(loc))
+}
+
+// daysInRange counts the calendar days a range touches
+func daysInRange(startDate, endDate time.Time) int {
+	start := time.Date(startDate.Year(), startDate.Month(), startDate.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(endDate.Year(), endDate.Month(), endDate.Day(), 0, 0, 0, 0, time.UTC)
+	days := int(end.Sub(start).Hours()/24) + 1
+	if days < 1 {
+		return 1
+	}
+	return days
+}
+
+// buildReferenceIntakes compares totals with the user's reference intakes. The daily average
+// over dayCount days is compared with the daily target, and each of days on its own with the
+// upper limit. Reference intakes only annotate the response, so failing to load them is logged.
+func (s *NutrientService) buildReferenceIntakes(
+	userID uint,
+	report *nutritionEngine.NutritionReport,
+	totals nutritionEngine.NutrientTotals,
+	days []nutritionEngine.NutrientTotals,
+	dayCount int,
+) []dto.NutrientAdequacyDTO {
+	adequacy := []dto.NutrientAdequacyDTO{}
+	intakes, err := s.intakes.ForUser(userID)
 	if err != nil {
 		helpers.LogError(err)
-		return nil, 0, fmt.Errorf("failed to get meal log items: %w", err)
+		return adequacy
 	}
 
-	nutrition := make(map[uint]float64)
-	foodCount := len(items)
+	for code, intake := range intakes {
+		nutrientID, ok := report.NutrientIDByCode(code)
+		if !ok {
+			continue
+		}
+		nutrient, _ := report.Nutrient(nutrientID)
+		intake, ok := referenceIntakeServices.InUnit(intake, nutrient.Unit)
+		if !ok {
+			continue
+		}
 
-	// Process each food item
-	for _, item := range items {
-		// Get all nutrients for this food
-		foodNutrients, err := s.foodNutrientsRepo.GetByFoodID(item.FoodID)
-		if err != nil {
-			continue // Skip foods with no nutrient data
+		entry := dto.NutrientAdequacyDTO{
+			NutrientID:         nutrientID,
+			NutrientCode:       code,
+			NutrientName:       nutrient.Name,
+			Unit:               nutrient.Unit,
+			Amount:             totals[nutrientID],
+			AverageDailyAmount: totals[nutrientID] / float64(dayCount),
+			EAR:                intake.EAR,
+			Target:             intake.Target(),
+			TargetType:         intake.TargetType(),
+			UpperLimit:         intake.UL,
+		}
+		if entry.Target > 0 {
+			entry.PercentOfTarget = math.Round(entry.AverageDailyAmount/entry.Target*1000) / 10
 		}
+		if intake.UL > 0 {
+			for _, day := range days {
+				if day[nutrientID] > intake.UL {
+					entry.DaysOverUpperLimit++
+				}
+			}
+			entry.OverUpperLimit = entry.DaysOverUpperLimit > 0
+		}
+		adequacy = append(adequacy, entry)
+	}
+
+	sort.Slice(adequacy, func(i, j int) bool {
+		return adequacy[i].NutrientID < adequacy[j].NutrientID
+	})
+	return adequacy
+}
 
-		// Calculate nutrition based on quantity consumed
-		for _, foodNutrient := range foodNutrients {
-			// Convert amount per 100g to amount for consumed quantity
-			actualAmount := (foodNutrient.AmountPer100g / 100.0) * item.QuantityGrams
-			nutrition[foodNutrient.NutrientID] += actualAmount
+// buildTargetStatus scores every logged day against the targets that applied to the user on that
+// day, so changing targets does not rescore the past. Min and Max report the targets of the last
+// scored day. Targets only annotate the response, so failing to load them is logged.
+func (s *NutrientService) buildTargetStatus(userID uint, report *nutritionEngine.NutritionReport) []dto.NutrientTargetStatusDTO {
+	statuses := []dto.NutrientTargetStatusDTO{}
+	dates := make([]string, 0, len(report.Days))
+	for _, day := range report.Days {
+		dates = append(dates, day.Date)
+	}
+	targets, err := s.targets.ForDates(userID, dates)
+	if err != nil {
+		helpers.LogError(err)
+		return statuses
+	}
+
+	byNutrient := make(map[uint]*dto.NutrientTargetStatusDTO)
+	for _, day := range report.Days {
+		for nutrientID, bounds := range targetsServices.Ranges(targets[day.Date], report.NutrientIDByCode) {
+			nutrient, ok := report.Nutrient(nutrientID)
+			if !ok {
+				continue
+			}
+			status, ok := byNutrient[nutrientID]
+			if !ok {
+				status = &dto.NutrientTargetStatusDTO{
+					NutrientID:   nutrientID,
+					NutrientCode: nutrient.Code,
+					NutrientName: nutrient.Name,
+					Unit:         nutrient.Unit,
+				}
+				byNutrient[nutrientID] = status
+			}
+			status.Min, status.Max = bounds.Min, bounds.Max
+
+			amount := day.Nutrients[nutrientID]
+			status.AverageDailyAmount += amount
+			status.DaysScored++
+			switch {
+			case bounds.Min != nil && amount < *bounds.Min:
+				status.DaysBelow++
+			case bounds.Max != nil && amount > *bounds.Max:
+				status.DaysAbove++
+			default:
+				status.DaysWithin++
+			}
 		}
 	}
 
-	return nutrition, foodCount, nil
+	for _, status := range byNutrient {
+		status.AverageDailyAmount /= float64(status.DaysScored)
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool {
+		return statuses[i].NutrientID < statuses[j].NutrientID
+	})
+	return statuses
 }
 
-// isMacroNutrient checks if a nutrient ID is a macronutrient
-func (s *NutrientService) isMacroNutrient(nutrientID uint) bool {
-	return nutrientID == EnergyNutrientID ||
-		nutrientID == ProteinNutrientID ||
-		nutrientID == FatNutrientID ||
-		nutrientID == CarbohydrateNutrientID ||
-		nutrientID == FiberNutrientID ||
-		nutrientID == CholesterolNutrientID ||
-		nutrientID == Vitamin_A_NutrientID ||
-		nutrientID == Vitamin_B12_NutrientID ||
-		nutrientID == CalciumNutrientID ||
-		nutrientID == IronNutrientID
+// buildMacroBreakdown maps the headline nutrients of totals onto the macro breakdown DTO
+func (s *NutrientService) buildMacroBreakdown(report *nutritionEngine.NutritionReport, totals nutritionEngine.NutrientTotals) dto.MacronutrientBreakdownDTO {
+	return dto.MacronutrientBreakdownDTO{
+		Energy:       report.Amount(totals, models.CodeEnergy),
+		Protein:      report.Amount(totals, models.CodeProtein),
+		Fat:          report.Amount(totals, models.CodeFat),
+		Carbohydrate: report.Amount(totals, models.CodeCarbohydrate),
+		Fiber:        report.Amount(totals, models.CodeFiber),
+		Cholesterol:  report.Amount(totals, models.CodeCholesterol),
+		Vitamin_A:    report.Amount(totals, models.CodeVitaminA),
+		Vitamin_B12:  report.Amount(totals, models.CodeVitaminB12),
+		Calcium:      report.Amount(totals, models.CodeCalcium),
+		Iron:         report.Amount(totals, models.CodeIron),
+	}
+}
+
+// buildMicroBreakdown lists every nutrient of totals that is not part of the macro breakdown
+func (s *NutrientService) buildMicroBreakdown(report *nutritionEngine.NutritionReport, totals nutritionEngine.NutrientTotals) []dto.MicronutrientDTO {
+	micro := []dto.MicronutrientDTO{}
+	for nutrientID, amount := range totals {
+		nutrient, ok := report.Nutrient(nutrientID)
+		if !ok || macroBreakdownCodes[nutrient.Code] {
+			continue
+		}
+
+		micro = append(micro, dto.MicronutrientDTO{
+			NutrientID:   nutrientID,
+			NutrientName: nutrient.Name,
+			Amount:       amount,
+			Unit:         nutrient.Unit,
+		})
+	}
+	return micro
 }
 
 // CalculateMealNutrition calculates nutrition for a specific meal log with user validation
@@ -228,59 +405,30 @@ func (s *NutrientService) CalculateMealNutrition(mealLogID uint, userID uint) (*
 		return nil, fmt.Errorf("access denied: meal log does not belong to user")
 	}
 
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+
 	// Calculate nutrition for this meal
-	mealNutrients, foodCount, err := s.calculateMealNutrition(mealLogID)
+	report, err := s.engine.Calculate([]mealLogModels.MealLog{*mealLog}, loc)
 	if err != nil {
 		helpers.LogError(err)
 		return nil, fmt.Errorf("failed to calculate meal nutrition: %w", err)
 	}
+	meal := report.Meals[0]
 
 	// Build the detailed response
-	response := &dto.MealNutritionDetailDTO{
+	return &dto.MealNutritionDetailDTO{
 		MealLogID:              mealLogID,
 		UserID:                 userID,
 		MealType:               mealLog.MealType,
-		Date:                   mealLog.CreatedAt.Format("2006-01-02"),
-		TotalCalories:          mealNutrients[EnergyNutrientID],
-		FoodCount:              foodCount,
-		MacroNutrientBreakDown: []dto.MacronutrientBreakdownDTO{},
-		MicroNutrientBreakDown: []dto.MicronutrientDTO{},
-	}
-
-	// Create macro nutrient breakdown
-	macroBreakdown := dto.MacronutrientBreakdownDTO{
-		Energy:       mealNutrients[EnergyNutrientID],
-		Protein:      mealNutrients[ProteinNutrientID],
-		Fat:          mealNutrients[FatNutrientID],
-		Carbohydrate: mealNutrients[CarbohydrateNutrientID],
-		Fiber:        mealNutrients[FiberNutrientID],
-		Cholesterol:  mealNutrients[CholesterolNutrientID],
-		Vitamin_A:    mealNutrients[Vitamin_A_NutrientID],
-		Vitamin_B12:  mealNutrients[Vitamin_B12_NutrientID],
-		Calcium:      mealNutrients[CalciumNutrientID],
-		Iron:         mealNutrients[IronNutrientID],
-	}
-	response.MacroNutrientBreakDown = append(response.MacroNutrientBreakDown, macroBreakdown)
-
-	// Build micronutrient breakdown for other nutrients
-	for nutrientID, amount := range mealNutrients {
-		// Skip the nutrients already included in macro breakdown
-		if s.isMacroNutrient(nutrientID) {
-			continue
-		}
-
-		nutrient, err := s.repo.GetByID(nutrientID)
-		if err != nil {
-			continue
-		}
-
-		response.MicroNutrientBreakDown = append(response.MicroNutrientBreakDown, dto.MicronutrientDTO{
-			NutrientID:   nutrientID,
-			NutrientName: nutrient.Name,
-			Amount:       amount,
-			Unit:         nutrient.Unit,
-		})
-	}
-
-	return response, nil
+		Date:                   helpers.DateIn(mealLog.ConsumedAt, loc),
+		TotalCalories:          report.Amount(meal.Nutrients, models.CodeEnergy),
+		FoodCount:              len(meal.Items),
+		MacroNutrientBreakDown: []dto.MacronutrientBreakdownDTO{s.buildMacroBreakdown(report, meal.Nutrients)},
+		MicroNutrientBreakDown: s.buildMicroBreakdown(report, meal.Nutrients),
+		ReferenceIntakes:       s.buildReferenceIntakes(userID, report, meal.Nutrients, []nutritionEngine.NutrientTotals{meal.Nutrients}, 1),
+	}, nil
 }
@@ -0,0 +1,305 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+)
+
+// NutrientTotals maps a nutrient ID to the consumed amount in the nutrient's unit
+type NutrientTotals map[uint]float64
+
+// add accumulates every amount of other into t
+func (t NutrientTotals) add(other NutrientTotals) {
+	for nutrientID, amount := range other {
+		t[nutrientID] += amount
+	}
+}
+
+// ItemNutrition holds the nutrients contributed by a single meal log item
+type ItemNutrition struct {
+	Item      mealLogItemsModels.MealLogItem
+	FoodName  string
+	Nutrients NutrientTotals
+}
+
+// MealNutrition holds the nutrients of a meal log and each of its items
+type MealNutrition struct {
+	MealLog   mealLogModels.MealLog
+	Items     []ItemNutrition
+	Nutrients NutrientTotals
+}
+
+// DayNutrition holds the nutrients of all meal logs consumed on the same date in the user's timezone
+type DayNutrition struct {
+	Date       string
+	MealLogIDs []uint
+	Nutrients  NutrientTotals
+}
+
+// NutritionReport is the result of a single calculation pass over a set of meal logs
+type NutritionReport struct {
+	Meals  []MealNutrition
+	Days   []DayNutrition
+	Totals NutrientTotals
+	// Location is the timezone the meals were grouped into days in, nil for a report built from days
+	Location *time.Location
+
+	nutrients map[uint]nutrientModels.Nutrient
+	codes     map[string]uint
+}
+
+// Nutrient returns the nutrient definition for an ID present in the report
+func (r *NutritionReport) Nutrient(id uint) (nutrientModels.Nutrient, bool) {
+	nutrient, ok := r.nutrients[id]
+	return nutrient, ok
+}
+
+// NutrientIDByCode resolves a stable nutrient code to its database ID
+func (r *NutritionReport) NutrientIDByCode(code string) (uint, bool) {
+	id, ok := r.codes[code]
+	return id, ok
+}
+
+// Amount returns the amount of the nutrient identified by code within totals
+func (r *NutritionReport) Amount(totals NutrientTotals, code string) float64 {
+	id, ok := r.codes[code]
+	if !ok {
+		return 0
+	}
+	return totals[id]
+}
+
+// NutritionEngine computes nutrient totals for meal logs. It is the single
+// place where consumed grams are turned into nutrient amounts, so every
+// endpoint reporting intake agrees on the numbers.
+type NutritionEngine struct {
+	mealLogItemsRepo  *mealLogItemsRepo.MealLogItemRepository
+	foodRepo          *foodRepo.FoodRepository
+	foodNutrientsRepo *foodNutrientsRepo.FoodNutrientRepository
+	nutrientRepo      *nutrientRepo.NutrientRepository
+}
+
+// NewNutritionEngine creates a new nutrition engine instance
+func NewNutritionEngine(
+	mealLogItemsRepo *mealLogItemsRepo.MealLogItemRepository,
+	foodRepo *foodRepo.FoodRepository,
+	foodNutrientsRepo *foodNutrientsRepo.FoodNutrientRepository,
+	nutrientRepo *nutrientRepo.NutrientRepository,
+) *NutritionEngine {
+	return &NutritionEngine{
+		mealLogItemsRepo:  mealLogItemsRepo,
+		foodRepo:          foodRepo,
+		foodNutrientsRepo: foodNutrientsRepo,
+		nutrientRepo:      nutrientRepo,
+	}
+}
+
+// Calculate returns per-item, per-meal and per-day nutrient totals for the given meal logs, with
+// days counted in loc. It issues a constant number of queries regardless of how many meal logs are
+// passed in.
+func (e *NutritionEngine) Calculate(mealLogs []mealLogModels.MealLog, loc *time.Location) (*NutritionReport, error) {
+	mealLogIDs := make([]uint, 0, len(mealLogs))
+	for _, mealLog := range mealLogs {
+		mealLogIDs = append(mealLogIDs, mealLog.ID)
+	}
+
+	items, err := e.mealLogItemsRepo.GetByMealLogIDs(mealLogIDs)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get meal log items: %w", err)
+	}
+
+	return e.calculateItems(mealLogs, loc, items, nil)
+}
+
+// CalculateMeals is Calculate restricted to the nutrients identified by codes, for responses that read
+// the day totals from the daily nutrition rollup and only need a few amounts per meal and item. Only
+// the composition rows of those nutrients are loaded, and the totals of the report hold nothing else.
+func (e *NutritionEngine) CalculateMeals(mealLogs []mealLogModels.MealLog, loc *time.Location, codes ...string) (*NutritionReport, error) {
+	if len(mealLogs) == 0 {
+		return e.ReportFromDays(nil)
+	}
+
+	mealLogIDs := make([]uint, 0, len(mealLogs))
+	for _, mealLog := range mealLogs {
+		mealLogIDs = append(mealLogIDs, mealLog.ID)
+	}
+
+	items, err := e.mealLogItemsRepo.GetByMealLogIDs(mealLogIDs)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get meal log items: %w", err)
+	}
+
+	return e.calculateItems(mealLogs, loc, items, codes)
+}
+
+// CalculateItems returns the nutrients of items that were not logged, such as the lines of a meal
+// template, as a report holding a single meal. Only the food and quantity_grams of the items are read.
+func (e *NutritionEngine) CalculateItems(items []mealLogItemsModels.MealLogItem) (*NutritionReport, error) {
+	unlogged := make([]mealLogItemsModels.MealLogItem, len(items))
+	for i, item := range items {
+		item.MealLogID = 0
+		unlogged[i] = item
+	}
+	return e.calculateItems([]mealLogModels.MealLog{{}}, time.UTC, unlogged, nil)
+}
+
+// calculateItems loads the foods, nutrient profiles and nutrients needed for the items of mealLogs
+// and builds the report. A non-empty codes limits the profiles to the nutrients it identifies.
+func (e *NutritionEngine) calculateItems(mealLogs []mealLogModels.MealLog, loc *time.Location, items []mealLogItemsModels.MealLogItem, codes []string) (*NutritionReport, error) {
+	nutrients, err := e.nutrientRepo.GetAll()
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get nutrients: %w", err)
+	}
+
+	var nutrientIDs []uint
+	if len(codes) > 0 {
+		wanted := make(map[string]bool, len(codes))
+		for _, code := range codes {
+			wanted[code] = true
+		}
+		for _, nutrient := range nutrients {
+			if wanted[nutrient.Code] {
+				nutrientIDs = append(nutrientIDs, nutrient.ID)
+			}
+		}
+	}
+
+	itemsByMealLog := make(map[uint][]mealLogItemsModels.MealLogItem, len(mealLogs))
+	foodIDs := make([]uint, 0, len(items))
+	seenFoods := make(map[uint]bool)
+	for _, item := range items {
+		itemsByMealLog[item.MealLogID] = append(itemsByMealLog[item.MealLogID], item)
+		if !seenFoods[item.FoodID] {
+			seenFoods[item.FoodID] = true
+			foodIDs = append(foodIDs, item.FoodID)
+		}
+	}
+
+	foodRows, err := e.foodRepo.GetByIDs(foodIDs)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get foods: %w", err)
+	}
+	foods := make(map[uint]foodModels.Food, len(foodRows))
+	for _, food := range foodRows {
+		foods[food.ID] = food
+	}
+
+	var foodNutrients []foodNutrientsModels.FoodNutrient
+	if len(codes) > 0 {
+		foodNutrients, err = e.foodNutrientsRepo.GetByFoodIDsAndNutrientIDs(foodIDs, nutrientIDs)
+	} else {
+		foodNutrients, err = e.foodNutrientsRepo.GetByFoodIDs(foodIDs)
+	}
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get food nutrients: %w", err)
+	}
+	profiles := make(map[uint][]foodNutrientsModels.FoodNutrient, len(foodIDs))
+	for _, foodNutrient := range foodNutrients {
+		profiles[foodNutrient.FoodID] = append(profiles[foodNutrient.FoodID], foodNutrient)
+	}
+
+	return BuildReport(mealLogs, loc, itemsByMealLog, foods, profiles, nutrients), nil
+}
+
+// BuildReport aggregates already loaded rows into a NutritionReport without touching the database.
+// Meals are grouped into the days they were consumed on in loc.
+func BuildReport(
+	mealLogs []mealLogModels.MealLog,
+	loc *time.Location,
+	itemsByMealLog map[uint][]mealLogItemsModels.MealLogItem,
+	foods map[uint]foodModels.Food,
+	profiles map[uint][]foodNutrientsModels.FoodNutrient,
+	nutrients []nutrientModels.Nutrient,
+) *NutritionReport {
+	report := &NutritionReport{
+		Meals:     make([]MealNutrition, 0, len(mealLogs)),
+		Days:      []DayNutrition{},
+		Totals:    NutrientTotals{},
+		Location:  loc,
+		nutrients: make(map[uint]nutrientModels.Nutrient, len(nutrients)),
+		codes:     make(map[string]uint, len(nutrients)),
+	}
+
+	for _, nutrient := range nutrients {
+		report.nutrients[nutrient.ID] = nutrient
+		if nutrient.Code != "" {
+			report.codes[nutrient.Code] = nutrient.ID
+		}
+	}
+
+	dayIndex := make(map[string]int)
+
+	for _, mealLog := range mealLogs {
+		items := itemsByMealLog[mealLog.ID]
+		meal := MealNutrition{
+			MealLog:   mealLog,
+			Items:     make([]ItemNutrition, 0, len(items)),
+			Nutrients: NutrientTotals{},
+		}
+
+		for _, item := range items {
+			itemNutrition := ItemNutrition{
+				Item:      item,
+				FoodName:  foods[item.FoodID].Name,
+				Nutrients: NutrientTotals{},
+			}
+
+			// Convert amount per 100g to amount for consumed quantity
+			for _, foodNutrient := range profiles[item.FoodID] {
+				itemNutrition.Nutrients[foodNutrient.NutrientID] += (foodNutrient.AmountPer100g / 100.0) * item.QuantityGrams
+			}
+
+			meal.Nutrients.add(itemNutrition.Nutrients)
+			meal.Items = append(meal.Items, itemNutrition)
+		}
+
+		date := helpers.DateIn(mealLog.ConsumedAt, loc)
+		idx, ok := dayIndex[date]
+		if !ok {
+			idx = len(report.Days)
+			dayIndex[date] = idx
+			report.Days = append(report.Days, DayNutrition{Date: date, Nutrients: NutrientTotals{}})
+		}
+		report.Days[idx].MealLogIDs = append(report.Days[idx].MealLogIDs, mealLog.ID)
+		report.Days[idx].Nutrients.add(meal.Nutrients)
+
+		report.Totals.add(meal.Nutrients)
+		report.Meals = append(report.Meals, meal)
+	}
+
+	return report
+}
+
+// ReportFromDays builds a report from precomputed per-day totals, such as the daily nutrition rollup.
+// The returned report carries no meal level detail.
+func (e *NutritionEngine) ReportFromDays(days []DayNutrition) (*NutritionReport, error) {
+	nutrients, err := e.nutrientRepo.GetAll()
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get nutrients: %w", err)
+	}
+
+	report := BuildReport(nil, nil, nil, nil, nil, nutrients)
+	for _, day := range days {
+		report.Days = append(report.Days, day)
+		report.Totals.add(day.Nutrients)
+	}
+
+	return report, nil
+}
@@ -0,0 +1,248 @@
+package services
+
+import (
+	"fmt"
+	"os"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/database"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	mealLogModels "github.com/momokapoolz/caloriesapp/meal_log/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	userModels "github.com/momokapoolz/caloriesapp/user/models"
+	"gorm.io/gorm"
+)
+
+const (
+	benchMealsPerDay  = 4
+	benchItemsPerMeal = 5
+	benchFoods        = 40
+	benchNutrients    = 30
+)
+
+// syntheticDiary builds an in-memory diary spanning the given number of days
+func syntheticDiary(days int) (
+	[]mealLogModels.MealLog,
+	map[uint][]mealLogItemsModels.MealLogItem,
+	map[uint]foodModels.Food,
+	map[uint][]foodNutrientsModels.FoodNutrient,
+	[]nutrientModels.Nutrient,
+) {
+	nutrients := make([]nutrientModels.Nutrient, 0, benchNutrients)
+	for i := 1; i <= benchNutrients; i++ {
+		nutrients = append(nutrients, nutrientModels.Nutrient{ID: uint(i), Code: fmt.Sprintf("n%d", i), Unit: "g"})
+	}
+	nutrients[0].Code = nutrientModels.CodeEnergy
+
+	foods := make(map[uint]foodModels.Food, benchFoods)
+	profiles := make(map[uint][]foodNutrientsModels.FoodNutrient, benchFoods)
+	for f := 1; f <= benchFoods; f++ {
+		foods[uint(f)] = foodModels.Food{ID: uint(f), Name: fmt.Sprintf("food %d", f), ServingSizeGram: 100}
+		for _, nutrient := range nutrients {
+			profiles[uint(f)] = append(profiles[uint(f)], foodNutrientsModels.FoodNutrient{
+				FoodID:        uint(f),
+				NutrientID:    nutrient.ID,
+				AmountPer100g: float64(f),
+			})
+		}
+	}
+
+	start := time.Date(2024, 1, 1, 8, 0, 0, 0, time.UTC)
+	var mealLogs []mealLogModels.MealLog
+	itemsByMealLog := make(map[uint][]mealLogItemsModels.MealLogItem)
+	var itemID uint
+	for d := 0; d < days; d++ {
+		for m := 0; m < benchMealsPerDay; m++ {
+			mealLog := mealLogModels.MealLog{
+				ID:         uint(len(mealLogs) + 1),
+				UserID:     1,
+				ConsumedAt: start.AddDate(0, 0, d).Add(time.Duration(m) * 4 * time.Hour),
+				MealType:   "meal",
+			}
+			mealLogs = append(mealLogs, mealLog)
+			for i := 0; i < benchItemsPerMeal; i++ {
+				itemID++
+				itemsByMealLog[mealLog.ID] = append(itemsByMealLog[mealLog.ID], mealLogItemsModels.MealLogItem{
+					ID:            itemID,
+					MealLogID:     mealLog.ID,
+					FoodID:        uint(int(itemID)%benchFoods + 1),
+					Quantity:      1,
+					QuantityGrams: 150,
+				})
+			}
+		}
+	}
+
+	return mealLogs, itemsByMealLog, foods, profiles, nutrients
+}
+
+func TestBuildReportTotalsAgree(t *testing.T) {
+	mealLogs, items, foods, profiles, nutrients := syntheticDiary(3)
+	report := BuildReport(mealLogs, time.UTC, items, foods, profiles, nutrients)
+
+	if len(report.Days) != 3 {
+		t.Fatalf("expected 3 days, got %d", len(report.Days))
+	}
+
+	var daySum, mealSum float64
+	for _, day := range report.Days {
+		daySum += report.Amount(day.Nutrients, nutrientModels.CodeEnergy)
+	}
+	for _, meal := range report.Meals {
+		mealSum += report.Amount(meal.Nutrients, nutrientModels.CodeEnergy)
+	}
+	total := report.Amount(report.Totals, nutrientModels.CodeEnergy)
+
+	if total == 0 || daySum != total || mealSum != total {
+		t.Fatalf("totals disagree: total=%v days=%v meals=%v", total, daySum, mealSum)
+	}
+}
+
+func TestBuildReportCountsDaysInTimezone(t *testing.T) {
+	_, items, foods, profiles, nutrients := syntheticDiary(1)
+	mealLogs := []mealLogModels.MealLog{
+		{ID: 1, UserID: 1, ConsumedAt: time.Date(2024, 5, 1, 14, 30, 0, 0, time.UTC), MealType: "dinner"},
+		{ID: 2, UserID: 1, ConsumedAt: time.Date(2024, 5, 1, 15, 30, 0, 0, time.UTC), MealType: "snack"},
+	}
+
+	if report := BuildReport(mealLogs, time.UTC, items, foods, profiles, nutrients); len(report.Days) != 1 {
+		t.Fatalf("in UTC both meals are on one day, got %d days", len(report.Days))
+	}
+
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	if err != nil {
+		t.Fatalf("LoadLocation: %v", err)
+	}
+	report := BuildReport(mealLogs, tokyo, items, foods, profiles, nutrients)
+	if len(report.Days) != 2 || report.Days[0].Date != "2024-05-01" || report.Days[1].Date != "2024-05-02" {
+		t.Fatalf("in Tokyo the snack is past midnight, got days %+v", report.Days)
+	}
+}
+
+func BenchmarkBuildReport30Days(b *testing.B) {
+	mealLogs, items, foods, profiles, nutrients := syntheticDiary(30)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		BuildReport(mealLogs, time.UTC, items, foods, profiles, nutrients)
+	}
+}
+
+// BenchmarkCalculateQueries runs the engine against a real database and reports
+// the number of queries per calculation. The count must not grow with the range.
+// Skipped unless a database is configured through the usual environment variables.
+func BenchmarkCalculateQueries(b *testing.B) {
+	if os.Getenv("POSTGRES_DB_CONNECTION_STRING") == "" && os.Getenv("DB_HOST") == "" {
+		b.Skip("no database configured")
+	}
+
+	db := database.ConnectDatabase()
+
+	var queries int64
+	if err := db.Callback().Query().After("gorm:query").Register("bench:count_queries", func(*gorm.DB) {
+		atomic.AddInt64(&queries, 1)
+	}); err != nil {
+		b.Fatalf("failed to register query counter: %v", err)
+	}
+
+	var baseline int64
+	for _, days := range []int{1, 7, 30} {
+		b.Run(fmt.Sprintf("days=%d", days), func(b *testing.B) {
+			tx := db.Begin()
+			defer tx.Rollback()
+
+			mealLogs := seedDiary(b, tx, days)
+			engine := NewNutritionEngine(
+				mealLogItemsRepo.NewMealLogItemRepository(tx),
+				foodRepo.NewFoodRepository(tx),
+				foodNutrientsRepo.NewFoodNutrientRepository(tx),
+				nutrientRepo.NewNutrientRepository(tx),
+			)
+
+			atomic.StoreInt64(&queries, 0)
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := engine.Calculate(mealLogs, time.UTC); err != nil {
+					b.Fatalf("calculate failed: %v", err)
+				}
+			}
+			b.StopTimer()
+
+			perOp := atomic.LoadInt64(&queries) / int64(b.N)
+			b.ReportMetric(float64(perOp), "queries/op")
+			if baseline == 0 {
+				baseline = perOp
+			} else if perOp != baseline {
+				b.Fatalf("query count grew with range: %d queries for %d days, %d for 1 day", perOp, days, baseline)
+			}
+		})
+	}
+}
+
+// seedDiary inserts a diary of the given length inside tx and returns its meal logs
+func seedDiary(b *testing.B, tx *gorm.DB, days int) []mealLogModels.MealLog {
+	b.Helper()
+
+	var nutrients []nutrientModels.Nutrient
+	if err := tx.Find(&nutrients).Error; err != nil || len(nutrients) == 0 {
+		b.Fatalf("benchmark requires seeded nutrients: %v", err)
+	}
+
+	foods := make([]foodModels.Food, benchFoods)
+	for f := range foods {
+		foods[f] = foodModels.Food{Name: fmt.Sprintf("bench food %d", f), ServingSizeGram: 100, Source: "bench"}
+		if err := tx.Create(&foods[f]).Error; err != nil {
+			b.Fatalf("failed to seed food: %v", err)
+		}
+		for _, nutrient := range nutrients {
+			fn := foodNutrientsModels.FoodNutrient{FoodID: foods[f].ID, NutrientID: nutrient.ID, AmountPer100g: float64(f + 1)}
+			if err := tx.Create(&fn).Error; err != nil {
+				b.Fatalf("failed to seed food nutrient: %v", err)
+			}
+		}
+	}
+
+	// meal_log.user_id references "User", so the diary needs an owner
+	user := userModels.User{
+		Name:      "bench",
+		Email:     fmt.Sprintf("bench-%d@example.invalid", time.Now().UnixNano()),
+		Gender:    "other",
+		Role:      "user",
+		CreatedAt: time.Now(),
+	}
+	if err := tx.Create(&user).Error; err != nil {
+		b.Fatalf("failed to seed user: %v", err)
+	}
+
+	start := time.Now().AddDate(0, 0, -days)
+	var mealLogs []mealLogModels.MealLog
+	for d := 0; d < days; d++ {
+		for m := 0; m < benchMealsPerDay; m++ {
+			mealLog := mealLogModels.MealLog{UserID: user.ID, ConsumedAt: start.AddDate(0, 0, d), MealType: "bench"}
+			if err := tx.Create(&mealLog).Error; err != nil {
+				b.Fatalf("failed to seed meal log: %v", err)
+			}
+			for i := 0; i < benchItemsPerMeal; i++ {
+				item := mealLogItemsModels.MealLogItem{
+					MealLogID:     mealLog.ID,
+					FoodID:        foods[(d+m+i)%len(foods)].ID,
+					Quantity:      1,
+					QuantityGrams: 150,
+				}
+				if err := tx.Create(&item).Error; err != nil {
+					b.Fatalf("failed to seed meal log item: %v", err)
+				}
+			}
+			mealLogs = append(mealLogs, mealLog)
+		}
+	}
+
+	return mealLogs
+}
@@ -0,0 +1,364 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/dto"
+	foodServices "github.com/momokapoolz/caloriesapp/food/services"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	"github.com/momokapoolz/caloriesapp/recipe/services"
+	"gorm.io/gorm"
+)
+
+// RecipeController handles HTTP requests for recipe operations
+type RecipeController struct {
+	service *services.RecipeService
+}
+
+// NewRecipeController creates a new recipe controller instance
+func NewRecipeController(service *services.RecipeService) *RecipeController {
+	return &RecipeController{service: service}
+}
+
+// writeRecipeError maps recipe service errors to responses; fallback is used for unexpected errors
+func writeRecipeError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrIngredientNotFound):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Ingredient food not found"})
+	case errors.Is(err, services.ErrIngredientIsRecipe):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A recipe cannot contain itself"})
+	case errors.Is(err, services.ErrIngredientCycle):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "The ingredient recipe already contains this recipe"})
+	case errors.Is(err, services.ErrLastIngredient):
+		ctx.JSON(http.StatusConflict, gin.H{"error": "A recipe without a cooked weight needs at least one ingredient"})
+	case errors.Is(err, foodServices.ErrInvalidVisibility):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Visibility must be private, shared or verified"})
+	case errors.Is(err, foodServices.ErrVerificationNotAllowed):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "Only admins can verify foods"})
+	case errors.Is(err, services.ErrIngredientNotListed):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Ingredient not found"})
+	case errors.Is(err, services.ErrRecipeNotAccessible):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to modify this recipe"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Recipe not found"})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// parseID parses a positive integer path parameter
+func parseID(ctx *gin.Context, name string) (uint, bool) {
+	id, err := strconv.ParseUint(ctx.Param(name), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return 0, false
+	}
+	return uint(id), true
+}
+
+// CreateRecipe godoc
+// @Summary      Create recipe
+// @Description  Create a recipe from ingredient lines in grams. The recipe is backed by a food whose nutrient profile is derived from the ingredients and spread over the cooked weight (or the raw weight when none is given), so it can be logged in meal log items by its food_id. One serving weighs the yield divided by servings.
+// @Tags         recipe
+// @Accept       json
+// @Produce      json
+// @Param        recipe  body      dto.RecipeRequestDTO   true  "Recipe data"
+// @Success      201     {object}  dto.RecipeResponseDTO  "Recipe created successfully"
+// @Failure      400     {object}  map[string]string      "Invalid request body or unknown ingredient"
+// @Failure      401     {object}  map[string]string      "Unauthorized"
+// @Failure      403     {object}  map[string]string      "Only admins can verify foods"
+// @Failure      500     {object}  map[string]string      "Internal server error"
+// @Security     BearerAuth
+// @Router       /recipes/ [post]
+func (c *RecipeController) CreateRecipe(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.RecipeRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipe, err := c.service.CreateRecipe(viewer, req)
+	if err != nil {
+		writeRecipeError(ctx, err, "Failed to create recipe")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, recipe)
+}
+
+// GetRecipes godoc
+// @Summary      Get user's recipes
+// @Description  Retrieve every recipe created by the authenticated user
+// @Tags         recipe
+// @Produce      json
+// @Success      200  {array}   dto.RecipeResponseDTO  "Recipes retrieved successfully"
+// @Failure      401  {object}  map[string]string      "Unauthorized"
+// @Failure      500  {object}  map[string]string      "Internal server error"
+// @Security     BearerAuth
+// @Router       /recipes/ [get]
+func (c *RecipeController) GetRecipes(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	recipes, err := c.service.GetRecipesByUser(viewer)
+	if err != nil {
+		writeRecipeError(ctx, err, "Failed to retrieve recipes")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipes)
+}
+
+// GetRecipe godoc
+// @Summary      Get a recipe
+// @Description  Retrieve a recipe with its ingredients and per-serving nutrition. Recipes follow the visibility of their food.
+// @Tags         recipe
+// @Produce      json
+// @Param        id   path      int                    true  "Recipe ID"
+// @Success      200  {object}  dto.RecipeResponseDTO  "Recipe retrieved successfully"
+// @Failure      400  {object}  map[string]string      "Invalid ID format"
+// @Failure      401  {object}  map[string]string      "Unauthorized"
+// @Failure      404  {object}  map[string]string      "Recipe not found"
+// @Failure      500  {object}  map[string]string      "Internal server error"
+// @Security     BearerAuth
+// @Router       /recipes/{id} [get]
+func (c *RecipeController) GetRecipe(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	recipe, err := c.service.GetRecipe(id, viewer)
+	if err != nil {
+		writeRecipeError(ctx, err, "Failed to retrieve recipe")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+// UpdateRecipe godoc
+// @Summary      Update recipe
+// @Description  Replace the name, servings, cooked weight, visibility and ingredients of a recipe and recompute its nutrition
+// @Tags         recipe
+// @Accept       json
+// @Produce      json
+// @Param        id      path      int                    true  "Recipe ID"
+// @Param        recipe  body      dto.RecipeRequestDTO   true  "Recipe data"
+// @Success      200     {object}  dto.RecipeResponseDTO  "Recipe updated successfully"
+// @Failure      400     {object}  map[string]string      "Invalid ID, request body or ingredient"
+// @Failure      401     {object}  map[string]string      "Unauthorized"
+// @Failure      403     {object}  map[string]string      "Forbidden — not the owner"
+// @Failure      404     {object}  map[string]string      "Recipe not found"
+// @Failure      500     {object}  map[string]string      "Internal server error"
+// @Security     BearerAuth
+// @Router       /recipes/{id} [put]
+func (c *RecipeController) UpdateRecipe(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.RecipeRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipe, err := c.service.UpdateRecipe(id, viewer, req)
+	if err != nil {
+		writeRecipeError(ctx, err, "Failed to update recipe")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+// DeleteRecipe godoc
+// @Summary      Delete recipe
+// @Description  Delete a recipe. Its food is kept with the last computed nutrition while meal logs or other recipes still use it.
+// @Tags         recipe
+// @Produce      json
+// @Param        id   path      int                true  "Recipe ID"
+// @Success      200  {object}  map[string]string  "Recipe deleted successfully"
+// @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string  "Recipe not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /recipes/{id} [delete]
+func (c *RecipeController) DeleteRecipe(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	if err := c.service.DeleteRecipe(id, viewer); err != nil {
+		writeRecipeError(ctx, err, "Failed to delete recipe")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Recipe deleted successfully"})
+}
+
+// AddIngredient godoc
+// @Summary      Add recipe ingredient
+// @Description  Add an ingredient line to a recipe and recompute its nutrition
+// @Tags         recipe
+// @Accept       json
+// @Produce      json
+// @Param        id          path      int                             true  "Recipe ID"
+// @Param        ingredient  body      dto.RecipeIngredientRequestDTO  true  "Ingredient data"
+// @Success      201         {object}  dto.RecipeResponseDTO           "Ingredient added successfully"
+// @Failure      400         {object}  map[string]string               "Invalid ID, request body or ingredient"
+// @Failure      401         {object}  map[string]string               "Unauthorized"
+// @Failure      403         {object}  map[string]string               "Forbidden — not the owner"
+// @Failure      404         {object}  map[string]string               "Recipe not found"
+// @Failure      500         {object}  map[string]string               "Internal server error"
+// @Security     BearerAuth
+// @Router       /recipes/{id}/ingredients [post]
+func (c *RecipeController) AddIngredient(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+
+	var req dto.RecipeIngredientRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipe, err := c.service.AddIngredient(id, viewer, req)
+	if err != nil {
+		writeRecipeError(ctx, err, "Failed to add ingredient")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, recipe)
+}
+
+// UpdateIngredient godoc
+// @Summary      Update recipe ingredient
+// @Description  Change the food or weight of an ingredient line and recompute the recipe's nutrition
+// @Tags         recipe
+// @Accept       json
+// @Produce      json
+// @Param        id            path      int                             true  "Recipe ID"
+// @Param        ingredientId  path      int                             true  "Ingredient ID"
+// @Param        ingredient    body      dto.RecipeIngredientRequestDTO  true  "Ingredient data"
+// @Success      200           {object}  dto.RecipeResponseDTO           "Ingredient updated successfully"
+// @Failure      400           {object}  map[string]string               "Invalid ID, request body or ingredient"
+// @Failure      401           {object}  map[string]string               "Unauthorized"
+// @Failure      403           {object}  map[string]string               "Forbidden — not the owner"
+// @Failure      404           {object}  map[string]string               "Recipe or ingredient not found"
+// @Failure      500           {object}  map[string]string               "Internal server error"
+// @Security     BearerAuth
+// @Router       /recipes/{id}/ingredients/{ingredientId} [put]
+func (c *RecipeController) UpdateIngredient(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+	ingredientID, ok := parseID(ctx, "ingredientId")
+	if !ok {
+		return
+	}
+
+	var req dto.RecipeIngredientRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	recipe, err := c.service.UpdateIngredient(id, ingredientID, viewer, req)
+	if err != nil {
+		writeRecipeError(ctx, err, "Failed to update ingredient")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+// DeleteIngredient godoc
+// @Summary      Delete recipe ingredient
+// @Description  Remove an ingredient line and recompute the recipe's nutrition
+// @Tags         recipe
+// @Produce      json
+// @Param        id            path      int                    true  "Recipe ID"
+// @Param        ingredientId  path      int                    true  "Ingredient ID"
+// @Success      200           {object}  dto.RecipeResponseDTO  "Ingredient deleted successfully"
+// @Failure      400           {object}  map[string]string      "Invalid ID format"
+// @Failure      401           {object}  map[string]string      "Unauthorized"
+// @Failure      403           {object}  map[string]string      "Forbidden — not the owner"
+// @Failure      404           {object}  map[string]string      "Recipe or ingredient not found"
+// @Failure      409           {object}  map[string]string      "Last ingredient of a recipe without a cooked weight"
+// @Failure      500           {object}  map[string]string      "Internal server error"
+// @Security     BearerAuth
+// @Router       /recipes/{id}/ingredients/{ingredientId} [delete]
+func (c *RecipeController) DeleteIngredient(ctx *gin.Context) {
+	viewer, ok := auth.GetFoodViewer(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+	id, ok := parseID(ctx, "id")
+	if !ok {
+		return
+	}
+	ingredientID, ok := parseID(ctx, "ingredientId")
+	if !ok {
+		return
+	}
+
+	recipe, err := c.service.DeleteIngredient(id, ingredientID, viewer)
+	if err != nil {
+		writeRecipeError(ctx, err, "Failed to delete ingredient")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipe)
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+)
+
+// SourceRecipe is the source of the food rows that stand for recipes
+const SourceRecipe = "recipe"
+
+// Recipe represents the recipe table in the database. Every recipe is backed by a
+// food row holding its name, visibility and derived nutrient profile, so a recipe
+// is searched and logged in meal_log_items like any other food.
+type Recipe struct {
+	ID               uint      `gorm:"primaryKey;column:id" json:"id"`
+	UserID           uint      `gorm:"column:user_id;not null;index:idx_recipe_user" json:"user_id"`
+	FoodID           uint      `gorm:"column:food_id;not null;uniqueIndex:idx_recipe_food" json:"food_id"`
+	Servings         float64   `gorm:"column:servings;not null" json:"servings"`
+	CookedWeightGram float64   `gorm:"column:cooked_weight_gram;not null;default:0" json:"cooked_weight_gram"`
+	CreatedAt        time.Time `gorm:"column:created_at;not null" json:"created_at"`
+	UpdatedAt        time.Time `gorm:"column:updated_at;not null" json:"updated_at"`
+}
+
+// TableName specifies the table name for the Recipe model
+func (Recipe) TableName() string {
+	return "recipe"
+}
+
+// RecipeIngredient represents the recipe_ingredients table in the database.
+// Each line adds a raw weight of a food to a recipe.
+type RecipeIngredient struct {
+	ID       uint    `gorm:"primaryKey;column:id" json:"id"`
+	RecipeID uint    `gorm:"column:recipe_id;not null;index:idx_recipe_ingredients_recipe" json:"recipe_id"`
+	FoodID   uint    `gorm:"column:food_id;not null;index:idx_recipe_ingredients_food" json:"food_id"`
+	Grams    float64 `gorm:"column:grams;not null" json:"grams"`
+}
+
+// TableName specifies the table name for the RecipeIngredient model
+func (RecipeIngredient) TableName() string {
+	return "recipe_ingredients"
+}
@@ -0,0 +1,190 @@
+package repository
+
+import (
+	"github.com/momokapoolz/caloriesapp/database"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	foodPortionModels "github.com/momokapoolz/caloriesapp/food_portion/models"
+	mealLogItemsModels "github.com/momokapoolz/caloriesapp/meal_log_items/models"
+	mealTemplateModels "github.com/momokapoolz/caloriesapp/meal_template/models"
+	"github.com/momokapoolz/caloriesapp/recipe/models"
+	"gorm.io/gorm"
+)
+
+// RecipeRepository handles all database operations for recipes and their ingredients
+type RecipeRepository struct {
+	db *gorm.DB
+}
+
+// NewRecipeRepository creates a new recipe repository instance
+func NewRecipeRepository(db *gorm.DB) *RecipeRepository {
+	return &RecipeRepository{db: db}
+}
+
+// Create adds a recipe together with the food row backing it and its ingredients
+func (r *RecipeRepository) Create(recipe *models.Recipe, food *foodModels.Food, ingredients []models.RecipeIngredient) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(food).Error; err != nil {
+			return err
+		}
+		recipe.FoodID = food.ID
+		if err := tx.Create(recipe).Error; err != nil {
+			return err
+		}
+		return createIngredients(tx, recipe.ID, ingredients)
+	}))
+}
+
+// GetByID retrieves a recipe by its ID
+func (r *RecipeRepository) GetByID(id uint) (*models.Recipe, error) {
+	var recipe models.Recipe
+	err := r.db.Where("id = ?", id).First(&recipe).Error
+	if err != nil {
+		return nil, err
+	}
+	return &recipe, nil
+}
+
+// GetByUserID retrieves all recipes created by a user
+func (r *RecipeRepository) GetByUserID(userID uint) ([]models.Recipe, error) {
+	var recipes []models.Recipe
+	err := r.db.Where("user_id = ?", userID).Order("id").Find(&recipes).Error
+	return recipes, err
+}
+
+// GetByIngredientFoodIDs retrieves the recipes that use any of the given foods as an ingredient
+func (r *RecipeRepository) GetByIngredientFoodIDs(foodIDs []uint) ([]models.Recipe, error) {
+	var recipes []models.Recipe
+	err := r.db.Where("id IN (?)", r.db.Model(&models.RecipeIngredient{}).Select("recipe_id").Where("food_id IN ?", foodIDs)).
+		Order("id").Find(&recipes).Error
+	return recipes, err
+}
+
+// GetFoodIDsByIngredientFoodIDs retrieves the foods backing the recipes that use any
+// of the given foods as an ingredient
+func (r *RecipeRepository) GetFoodIDsByIngredientFoodIDs(foodIDs []uint) ([]uint, error) {
+	var recipeFoodIDs []uint
+	if len(foodIDs) == 0 {
+		return recipeFoodIDs, nil
+	}
+	err := r.db.Model(&models.Recipe{}).
+		Where("id IN (?)", r.db.Model(&models.RecipeIngredient{}).Select("recipe_id").Where("food_id IN ?", foodIDs)).
+		Distinct().Pluck("food_id", &recipeFoodIDs).Error
+	return recipeFoodIDs, err
+}
+
+// GetIngredients retrieves the ingredient lines of a recipe
+func (r *RecipeRepository) GetIngredients(recipeID uint) ([]models.RecipeIngredient, error) {
+	var ingredients []models.RecipeIngredient
+	err := r.db.Where("recipe_id = ?", recipeID).Order("id").Find(&ingredients).Error
+	return ingredients, err
+}
+
+// GetIngredientByID retrieves an ingredient line by its ID
+func (r *RecipeRepository) GetIngredientByID(id uint) (*models.RecipeIngredient, error) {
+	var ingredient models.RecipeIngredient
+	err := r.db.Where("id = ?", id).First(&ingredient).Error
+	if err != nil {
+		return nil, err
+	}
+	return &ingredient, nil
+}
+
+// Update saves a recipe and the food backing it. A non-nil ingredients slice
+// replaces every ingredient line of the recipe.
+func (r *RecipeRepository) Update(recipe *models.Recipe, food *foodModels.Food, ingredients []models.RecipeIngredient) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(recipe).Error; err != nil {
+			return err
+		}
+		if err := tx.Save(food).Error; err != nil {
+			return err
+		}
+		if ingredients == nil {
+			return nil
+		}
+		if err := tx.Where("recipe_id = ?", recipe.ID).Delete(&models.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		return createIngredients(tx, recipe.ID, ingredients)
+	}))
+}
+
+// CreateIngredient adds an ingredient line to a recipe
+func (r *RecipeRepository) CreateIngredient(ingredient *models.RecipeIngredient) error {
+	return database.TranslateError(r.db.Create(ingredient).Error)
+}
+
+// UpdateIngredient updates an ingredient line
+func (r *RecipeRepository) UpdateIngredient(ingredient *models.RecipeIngredient) error {
+	return database.TranslateError(r.db.Save(ingredient).Error)
+}
+
+// DeleteIngredient removes an ingredient line
+func (r *RecipeRepository) DeleteIngredient(id uint) error {
+	return database.TranslateError(r.db.Delete(&models.RecipeIngredient{}, id).Error)
+}
+
+// SaveProfile replaces the nutrient profile of the food backing a recipe and its serving size
+func (r *RecipeRepository) SaveProfile(foodID uint, servingSizeGram float64, nutrients []foodNutrientsModels.FoodNutrient) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&foodModels.Food{}).Where("id = ?", foodID).Update("serving_size_gram", servingSizeGram).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("food_id = ?", foodID).Delete(&foodNutrientsModels.FoodNutrient{}).Error; err != nil {
+			return err
+		}
+		if len(nutrients) == 0 {
+			return nil
+		}
+		return tx.Create(&nutrients).Error
+	}))
+}
+
+// Delete removes a recipe and its ingredients. The food backing the recipe is removed
+// too unless meal logs, other recipes or meal templates still refer to it; it then keeps
+// its last profile.
+func (r *RecipeRepository) Delete(recipe *models.Recipe) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("recipe_id = ?", recipe.ID).Delete(&models.RecipeIngredient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.Recipe{}, recipe.ID).Error; err != nil {
+			return err
+		}
+
+		var logged, used, saved int64
+		if err := tx.Model(&mealLogItemsModels.MealLogItem{}).Where("food_id = ?", recipe.FoodID).Count(&logged).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&models.RecipeIngredient{}).Where("food_id = ?", recipe.FoodID).Count(&used).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(&mealTemplateModels.MealTemplateItem{}).Where("food_id = ?", recipe.FoodID).Count(&saved).Error; err != nil {
+			return err
+		}
+		if logged > 0 || used > 0 || saved > 0 {
+			return nil
+		}
+
+		if err := tx.Where("food_id = ?", recipe.FoodID).Delete(&foodNutrientsModels.FoodNutrient{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("food_id = ?", recipe.FoodID).Delete(&foodPortionModels.FoodPortion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&foodModels.Food{}, recipe.FoodID).Error
+	}))
+}
+
+// createIngredients inserts the ingredient lines of a recipe
+func createIngredients(tx *gorm.DB, recipeID uint, ingredients []models.RecipeIngredient) error {
+	if len(ingredients) == 0 {
+		return nil
+	}
+	for i := range ingredients {
+		ingredients[i].ID = 0
+		ingredients[i].RecipeID = recipeID
+	}
+	return tx.Create(&ingredients).Error
+}
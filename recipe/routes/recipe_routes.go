@@ -0,0 +1,48 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	"github.com/momokapoolz/caloriesapp/recipe/controllers"
+	"github.com/momokapoolz/caloriesapp/recipe/repository"
+	"github.com/momokapoolz/caloriesapp/recipe/services"
+	"gorm.io/gorm"
+)
+
+// SetupRecipeRoutes initializes recipe routes
+func SetupRecipeRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	foodRepository := foodRepo.NewFoodRepository(db)
+	foodNutrientRepository := foodNutrientsRepo.NewFoodNutrientRepository(db)
+	nutrientRepository := nutrientRepo.NewNutrientRepository(db)
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepo.NewMealLogItemRepository(db),
+		foodRepository,
+		foodNutrientRepository,
+		nutrientRepository,
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(dailyNutritionRepo.NewDailyNutritionRepository(db), mealLogRepo.NewMealLogRepository(db), engine)
+	recipeService := services.NewRecipeService(repository.NewRecipeRepository(db), foodRepository, foodNutrientRepository, nutrientRepository, rollup)
+	recipeController := controllers.NewRecipeController(recipeService)
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	recipeRoutes := router.Group("/recipes", authMiddleware.RequireAuth())
+	{
+		recipeRoutes.POST("/", recipeController.CreateRecipe)
+		recipeRoutes.GET("/", recipeController.GetRecipes)
+		recipeRoutes.GET("/:id", recipeController.GetRecipe)
+		recipeRoutes.PUT("/:id", recipeController.UpdateRecipe)
+		recipeRoutes.DELETE("/:id", recipeController.DeleteRecipe)
+		recipeRoutes.POST("/:id/ingredients", recipeController.AddIngredient)
+		recipeRoutes.PUT("/:id/ingredients/:ingredientId", recipeController.UpdateIngredient)
+		recipeRoutes.DELETE("/:id/ingredients/:ingredientId", recipeController.DeleteIngredient)
+	}
+}
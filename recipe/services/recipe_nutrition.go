@@ -0,0 +1,48 @@
+package services
+
+import (
+	"sort"
+
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	"github.com/momokapoolz/caloriesapp/recipe/models"
+)
+
+// recipeYield returns the weight the nutrients of a recipe are spread over: the
+// cooked weight when it was weighed, otherwise the raw weight of the ingredients
+func recipeYield(ingredients []models.RecipeIngredient, cookedWeightGram float64) float64 {
+	if cookedWeightGram > 0 {
+		return cookedWeightGram
+	}
+	var raw float64
+	for _, ingredient := range ingredients {
+		raw += ingredient.Grams
+	}
+	return raw
+}
+
+// recipeProfile derives the per 100 g nutrient profile of a recipe from the profiles
+// of its ingredient foods, keyed by food ID. Nutrient amounts are summed over the raw
+// ingredient weights and spread over the yield, so water lost in cooking concentrates
+// the finished dish. Rows are ordered by nutrient ID.
+func recipeProfile(ingredients []models.RecipeIngredient, profiles map[uint][]foodNutrientsModels.FoodNutrient, yield float64) []foodNutrientsModels.FoodNutrient {
+	if yield <= 0 {
+		return nil
+	}
+
+	totals := make(map[uint]float64)
+	for _, ingredient := range ingredients {
+		for _, foodNutrient := range profiles[ingredient.FoodID] {
+			totals[foodNutrient.NutrientID] += foodNutrient.AmountPer100g / 100.0 * ingredient.Grams
+		}
+	}
+
+	profile := make([]foodNutrientsModels.FoodNutrient, 0, len(totals))
+	for nutrientID, amount := range totals {
+		profile = append(profile, foodNutrientsModels.FoodNutrient{
+			NutrientID:    nutrientID,
+			AmountPer100g: amount / yield * 100.0,
+		})
+	}
+	sort.Slice(profile, func(i, j int) bool { return profile[i].NutrientID < profile[j].NutrientID })
+	return profile
+}
@@ -0,0 +1,38 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	"github.com/momokapoolz/caloriesapp/recipe/models"
+)
+
+func TestRecipeProfile(t *testing.T) {
+	// 200 g of rice at 7 g protein and 100 g of chicken at 31 g protein per 100 g
+	ingredients := []models.RecipeIngredient{
+		{FoodID: 1, Grams: 200},
+		{FoodID: 2, Grams: 100},
+	}
+	profiles := map[uint][]foodNutrientsModels.FoodNutrient{
+		1: {{FoodID: 1, NutrientID: 10, AmountPer100g: 7}, {FoodID: 1, NutrientID: 11, AmountPer100g: 360}},
+		2: {{FoodID: 2, NutrientID: 10, AmountPer100g: 31}},
+	}
+
+	// Cooking absorbs water: the dish weighs 450 g and holds 45 g of protein
+	profile := recipeProfile(ingredients, profiles, recipeYield(ingredients, 450))
+	if len(profile) != 2 || profile[0].NutrientID != 10 || profile[1].NutrientID != 11 {
+		t.Fatalf("unexpected profile: %+v", profile)
+	}
+	if got := profile[0].AmountPer100g; math.Abs(got-10) > 1e-9 {
+		t.Fatalf("expected 10 g protein per 100 g cooked, got %v", got)
+	}
+	if got := profile[1].AmountPer100g; math.Abs(got-160) > 1e-9 {
+		t.Fatalf("expected 160 kcal per 100 g cooked, got %v", got)
+	}
+
+	// Without a cooked weight the raw weight of the ingredients is the yield
+	if yield := recipeYield(ingredients, 0); yield != 300 {
+		t.Fatalf("expected a raw yield of 300 g, got %v", yield)
+	}
+}
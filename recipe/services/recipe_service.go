@@ -0,0 +1,490 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/dto"
+	foodModels "github.com/momokapoolz/caloriesapp/food/models"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodServices "github.com/momokapoolz/caloriesapp/food/services"
+	foodNutrientsModels "github.com/momokapoolz/caloriesapp/food_nutrients/models"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	"github.com/momokapoolz/caloriesapp/recipe/models"
+	"github.com/momokapoolz/caloriesapp/recipe/repository"
+	"gorm.io/gorm"
+)
+
+// Error definitions
+var (
+	ErrRecipeNotAccessible = errors.New("not allowed to modify this recipe")
+	ErrIngredientNotFound  = errors.New("ingredient food not found")
+	ErrIngredientIsRecipe  = errors.New("a recipe cannot contain itself")
+	ErrIngredientNotListed = errors.New("ingredient not found in recipe")
+	ErrIngredientCycle     = errors.New("ingredient recipe already contains this recipe")
+	ErrLastIngredient      = errors.New("a recipe without a cooked weight needs at least one ingredient")
+)
+
+// RecipeService handles business logic for recipes. The nutrient profile of a recipe
+// is stored on the food backing it and recomputed whenever an ingredient changes.
+type RecipeService struct {
+	repo             *repository.RecipeRepository
+	foodRepo         *foodRepo.FoodRepository
+	foodNutrientRepo *foodNutrientsRepo.FoodNutrientRepository
+	nutrientRepo     *nutrientRepo.NutrientRepository
+	rollup           *dailyNutritionServices.DailyNutritionService
+}
+
+// NewRecipeService creates a new recipe service instance
+func NewRecipeService(
+	repo *repository.RecipeRepository,
+	foodRepo *foodRepo.FoodRepository,
+	foodNutrientRepo *foodNutrientsRepo.FoodNutrientRepository,
+	nutrientRepo *nutrientRepo.NutrientRepository,
+	rollup *dailyNutritionServices.DailyNutritionService,
+) *RecipeService {
+	return &RecipeService{
+		repo:             repo,
+		foodRepo:         foodRepo,
+		foodNutrientRepo: foodNutrientRepo,
+		nutrientRepo:     nutrientRepo,
+		rollup:           rollup,
+	}
+}
+
+// CreateRecipe creates a recipe owned by the viewer, together with the food that
+// represents it, and computes its nutrient profile
+func (s *RecipeService) CreateRecipe(viewer foodModels.FoodViewer, req dto.RecipeRequestDTO) (*dto.RecipeResponseDTO, error) {
+	if req.Visibility == "" {
+		req.Visibility = foodModels.VisibilityPrivate
+	}
+	if err := foodServices.ValidateVisibility(req.Visibility, viewer); err != nil {
+		return nil, err
+	}
+	ingredients, err := s.checkIngredients(0, req.Ingredients, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	food := &foodModels.Food{
+		Name:            req.Name,
+		ServingSizeGram: recipeYield(ingredients, req.CookedWeightGram) / req.Servings,
+		Source:          models.SourceRecipe,
+		OwnerID:         &viewer.UserID,
+		Visibility:      req.Visibility,
+	}
+	recipe := &models.Recipe{
+		UserID:           viewer.UserID,
+		Servings:         req.Servings,
+		CookedWeightGram: req.CookedWeightGram,
+	}
+	if err := s.repo.Create(recipe, food, ingredients); err != nil {
+		return nil, err
+	}
+
+	if err := s.recompute(recipe, map[uint]bool{}); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(recipe.ID, viewer)
+}
+
+// GetRecipe retrieves a recipe if the viewer may see the food backing it
+func (s *RecipeService) GetRecipe(id uint, viewer foodModels.FoodViewer) (*dto.RecipeResponseDTO, error) {
+	recipe, food, err := s.loadRecipe(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+	return s.toResponse(recipe, food)
+}
+
+// GetRecipesByUser retrieves every recipe created by the viewer
+func (s *RecipeService) GetRecipesByUser(viewer foodModels.FoodViewer) ([]dto.RecipeResponseDTO, error) {
+	recipes, err := s.repo.GetByUserID(viewer.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.RecipeResponseDTO, 0, len(recipes))
+	for i := range recipes {
+		food, err := s.foodRepo.GetByID(recipes[i].FoodID)
+		if err != nil {
+			return nil, err
+		}
+		response, err := s.toResponse(&recipes[i], food)
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, *response)
+	}
+	return responses, nil
+}
+
+// UpdateRecipe replaces the name, yield, visibility and ingredients of a recipe
+// and recomputes its nutrient profile
+func (s *RecipeService) UpdateRecipe(id uint, viewer foodModels.FoodViewer, req dto.RecipeRequestDTO) (*dto.RecipeResponseDTO, error) {
+	recipe, food, err := s.modifiableRecipe(id, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if req.Visibility == "" {
+		req.Visibility = food.Visibility
+	}
+	if err := foodServices.ValidateVisibility(req.Visibility, viewer); err != nil {
+		return nil, err
+	}
+	ingredients, err := s.checkIngredients(recipe.FoodID, req.Ingredients, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe.Servings = req.Servings
+	recipe.CookedWeightGram = req.CookedWeightGram
+	food.Name = req.Name
+	food.Visibility = req.Visibility
+	if err := s.repo.Update(recipe, food, ingredients); err != nil {
+		return nil, err
+	}
+
+	if err := s.recompute(recipe, map[uint]bool{}); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(recipe.ID, viewer)
+}
+
+// DeleteRecipe removes a recipe the viewer may modify
+func (s *RecipeService) DeleteRecipe(id uint, viewer foodModels.FoodViewer) error {
+	recipe, _, err := s.modifiableRecipe(id, viewer)
+	if err != nil {
+		return err
+	}
+	return s.repo.Delete(recipe)
+}
+
+// AddIngredient adds an ingredient line to a recipe and recomputes its profile
+func (s *RecipeService) AddIngredient(recipeID uint, viewer foodModels.FoodViewer, req dto.RecipeIngredientRequestDTO) (*dto.RecipeResponseDTO, error) {
+	recipe, _, err := s.modifiableRecipe(recipeID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	ingredients, err := s.checkIngredients(recipe.FoodID, []dto.RecipeIngredientRequestDTO{req}, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	ingredient := ingredients[0]
+	ingredient.RecipeID = recipe.ID
+	if err := s.repo.CreateIngredient(&ingredient); err != nil {
+		return nil, err
+	}
+
+	if err := s.recompute(recipe, map[uint]bool{}); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(recipe.ID, viewer)
+}
+
+// UpdateIngredient changes the food or weight of an ingredient line and recomputes the recipe
+func (s *RecipeService) UpdateIngredient(recipeID, ingredientID uint, viewer foodModels.FoodViewer, req dto.RecipeIngredientRequestDTO) (*dto.RecipeResponseDTO, error) {
+	recipe, ingredient, err := s.modifiableIngredient(recipeID, ingredientID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := s.checkIngredients(recipe.FoodID, []dto.RecipeIngredientRequestDTO{req}, viewer); err != nil {
+		return nil, err
+	}
+
+	ingredient.FoodID = req.FoodID
+	ingredient.Grams = req.Grams
+	if err := s.repo.UpdateIngredient(ingredient); err != nil {
+		return nil, err
+	}
+
+	if err := s.recompute(recipe, map[uint]bool{}); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(recipe.ID, viewer)
+}
+
+// DeleteIngredient removes an ingredient line and recomputes the recipe
+func (s *RecipeService) DeleteIngredient(recipeID, ingredientID uint, viewer foodModels.FoodViewer) (*dto.RecipeResponseDTO, error) {
+	recipe, ingredient, err := s.modifiableIngredient(recipeID, ingredientID, viewer)
+	if err != nil {
+		return nil, err
+	}
+	// Without a cooked weight the yield is the sum of the raw weights, so the serving
+	// size would drop to zero
+	if recipe.CookedWeightGram == 0 {
+		ingredients, err := s.repo.GetIngredients(recipe.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(ingredients) <= 1 {
+			return nil, ErrLastIngredient
+		}
+	}
+	if err := s.repo.DeleteIngredient(ingredient.ID); err != nil {
+		return nil, err
+	}
+
+	if err := s.recompute(recipe, map[uint]bool{}); err != nil {
+		return nil, err
+	}
+	return s.GetRecipe(recipe.ID, viewer)
+}
+
+// RefreshFoods recomputes every recipe using any of the foods as an ingredient, after
+// their nutrient profiles changed. A recipe using several of them is recomputed once.
+func (s *RecipeService) RefreshFoods(foodIDs ...uint) error {
+	if len(foodIDs) == 0 {
+		return nil
+	}
+	recipes, err := s.repo.GetByIngredientFoodIDs(foodIDs)
+	if err != nil {
+		return err
+	}
+
+	visited := map[uint]bool{}
+	for i := range recipes {
+		if visited[recipes[i].ID] {
+			continue
+		}
+		if err := s.recompute(&recipes[i], visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recompute derives the nutrient profile and serving size of a recipe from its
+// ingredients and stores them on the recipe food. Recipes using the recipe as an
+// ingredient are recomputed in turn; visited guards against cycles.
+func (s *RecipeService) recompute(recipe *models.Recipe, visited map[uint]bool) error {
+	visited[recipe.ID] = true
+
+	ingredients, err := s.repo.GetIngredients(recipe.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get recipe ingredients: %w", err)
+	}
+
+	foodIDs := make([]uint, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		foodIDs = append(foodIDs, ingredient.FoodID)
+	}
+	foodNutrients, err := s.foodNutrientRepo.GetByFoodIDs(foodIDs)
+	if err != nil {
+		return fmt.Errorf("failed to get ingredient nutrients: %w", err)
+	}
+	profiles := make(map[uint][]foodNutrientsModels.FoodNutrient, len(foodIDs))
+	for _, foodNutrient := range foodNutrients {
+		profiles[foodNutrient.FoodID] = append(profiles[foodNutrient.FoodID], foodNutrient)
+	}
+
+	yield := recipeYield(ingredients, recipe.CookedWeightGram)
+	profile := recipeProfile(ingredients, profiles, yield)
+	for i := range profile {
+		profile[i].FoodID = recipe.FoodID
+	}
+	// Days logging the recipe are served from the raw rows until they are recomputed below
+	if err := s.rollup.MarkFoodsStale(recipe.FoodID); err != nil {
+		return err
+	}
+	if err := s.repo.SaveProfile(recipe.FoodID, yield/recipe.Servings, profile); err != nil {
+		return fmt.Errorf("failed to save recipe nutrients: %w", err)
+	}
+
+	// Logged servings of the recipe now carry other nutrients
+	if err := s.rollup.RefreshFoods(recipe.FoodID); err != nil {
+		helpers.LogError(err)
+	}
+
+	parents, err := s.repo.GetByIngredientFoodIDs([]uint{recipe.FoodID})
+	if err != nil {
+		return fmt.Errorf("failed to get recipes using recipe %d: %w", recipe.ID, err)
+	}
+	for i := range parents {
+		if visited[parents[i].ID] {
+			continue
+		}
+		if err := s.recompute(&parents[i], visited); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIngredients validates ingredient lines: every food must be visible to the
+// viewer and a recipe cannot use its own food, directly or through another recipe
+func (s *RecipeService) checkIngredients(recipeFoodID uint, lines []dto.RecipeIngredientRequestDTO, viewer foodModels.FoodViewer) ([]models.RecipeIngredient, error) {
+	ingredients := make([]models.RecipeIngredient, 0, len(lines))
+	foodIDs := make([]uint, 0, len(lines))
+	seen := make(map[uint]bool, len(lines))
+	for _, line := range lines {
+		if recipeFoodID != 0 && line.FoodID == recipeFoodID {
+			return nil, ErrIngredientIsRecipe
+		}
+		ingredients = append(ingredients, models.RecipeIngredient{FoodID: line.FoodID, Grams: line.Grams})
+		if !seen[line.FoodID] {
+			seen[line.FoodID] = true
+			foodIDs = append(foodIDs, line.FoodID)
+		}
+	}
+
+	foods, err := s.foodRepo.GetVisibleByIDs(foodIDs, viewer)
+	if err != nil {
+		return nil, err
+	}
+	if len(foods) != len(foodIDs) {
+		return nil, ErrIngredientNotFound
+	}
+	if recipeFoodID != 0 {
+		if err := s.checkCycle(recipeFoodID, seen); err != nil {
+			return nil, err
+		}
+	}
+	return ingredients, nil
+}
+
+// checkCycle walks up from a recipe food through every recipe that contains it,
+// directly or indirectly, and rejects ingredient foods found on the way: adding one
+// would make the recipe contain itself
+func (s *RecipeService) checkCycle(recipeFoodID uint, ingredientFoodIDs map[uint]bool) error {
+	visited := map[uint]bool{recipeFoodID: true}
+	frontier := []uint{recipeFoodID}
+	for len(frontier) > 0 {
+		parents, err := s.repo.GetFoodIDsByIngredientFoodIDs(frontier)
+		if err != nil {
+			return fmt.Errorf("failed to get recipes using recipe food %d: %w", recipeFoodID, err)
+		}
+		var next []uint
+		for _, parent := range parents {
+			if ingredientFoodIDs[parent] {
+				return ErrIngredientCycle
+			}
+			if !visited[parent] {
+				visited[parent] = true
+				next = append(next, parent)
+			}
+		}
+		frontier = next
+	}
+	return nil
+}
+
+// loadRecipe retrieves a recipe and its food if the viewer may see the food
+func (s *RecipeService) loadRecipe(id uint, viewer foodModels.FoodViewer) (*models.Recipe, *foodModels.Food, error) {
+	recipe, err := s.repo.GetByID(id)
+	if err != nil {
+		return nil, nil, err
+	}
+	food, err := s.foodRepo.GetVisibleByID(recipe.FoodID, viewer)
+	if err != nil {
+		return nil, nil, err
+	}
+	return recipe, food, nil
+}
+
+// modifiableRecipe loads a recipe and checks that the viewer may change it, under
+// the same rules as the food backing it
+func (s *RecipeService) modifiableRecipe(id uint, viewer foodModels.FoodViewer) (*models.Recipe, *foodModels.Food, error) {
+	recipe, food, err := s.loadRecipe(id, viewer)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !viewer.CanModify(food) {
+		return nil, nil, ErrRecipeNotAccessible
+	}
+	return recipe, food, nil
+}
+
+// modifiableIngredient loads an ingredient line of a recipe the viewer may change
+func (s *RecipeService) modifiableIngredient(recipeID, ingredientID uint, viewer foodModels.FoodViewer) (*models.Recipe, *models.RecipeIngredient, error) {
+	recipe, _, err := s.modifiableRecipe(recipeID, viewer)
+	if err != nil {
+		return nil, nil, err
+	}
+	ingredient, err := s.repo.GetIngredientByID(ingredientID)
+	if errors.Is(err, gorm.ErrRecordNotFound) || (err == nil && ingredient.RecipeID != recipe.ID) {
+		return nil, nil, ErrIngredientNotListed
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return recipe, ingredient, nil
+}
+
+// toResponse builds the response of a recipe, with the nutrition of one serving
+// taken from the stored profile of the recipe food
+func (s *RecipeService) toResponse(recipe *models.Recipe, food *foodModels.Food) (*dto.RecipeResponseDTO, error) {
+	ingredients, err := s.repo.GetIngredients(recipe.ID)
+	if err != nil {
+		return nil, err
+	}
+	foodIDs := make([]uint, 0, len(ingredients))
+	for _, ingredient := range ingredients {
+		foodIDs = append(foodIDs, ingredient.FoodID)
+	}
+	ingredientFoods, err := s.foodRepo.GetByIDs(foodIDs)
+	if err != nil {
+		return nil, err
+	}
+	foodNames := make(map[uint]string, len(ingredientFoods))
+	for _, ingredientFood := range ingredientFoods {
+		foodNames[ingredientFood.ID] = ingredientFood.Name
+	}
+
+	profile, err := s.foodNutrientRepo.GetByFoodID(recipe.FoodID)
+	if err != nil {
+		return nil, err
+	}
+	nutrients, err := s.nutrientRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	nutrientsByID := make(map[uint]nutrientModels.Nutrient, len(nutrients))
+	for _, nutrient := range nutrients {
+		nutrientsByID[nutrient.ID] = nutrient
+	}
+
+	response := &dto.RecipeResponseDTO{
+		ID:                  recipe.ID,
+		FoodID:              recipe.FoodID,
+		Name:                food.Name,
+		OwnerID:             recipe.UserID,
+		Visibility:          food.Visibility,
+		Servings:            recipe.Servings,
+		CookedWeightGram:    recipe.CookedWeightGram,
+		ServingSizeGram:     food.ServingSizeGram,
+		Ingredients:         make([]dto.RecipeIngredientResponseDTO, 0, len(ingredients)),
+		NutritionPerServing: make([]dto.MicronutrientDTO, 0, len(profile)),
+	}
+	for _, ingredient := range ingredients {
+		response.Ingredients = append(response.Ingredients, dto.RecipeIngredientResponseDTO{
+			ID:       ingredient.ID,
+			FoodID:   ingredient.FoodID,
+			FoodName: foodNames[ingredient.FoodID],
+			Grams:    ingredient.Grams,
+		})
+	}
+
+	sort.Slice(profile, func(i, j int) bool { return profile[i].NutrientID < profile[j].NutrientID })
+	for _, foodNutrient := range profile {
+		nutrient := nutrientsByID[foodNutrient.NutrientID]
+		amount := foodNutrient.AmountPer100g / 100.0 * food.ServingSizeGram
+		if nutrient.Code == nutrientModels.CodeEnergy {
+			response.CaloriesPerServing = amount
+		}
+		response.NutritionPerServing = append(response.NutritionPerServing, dto.MicronutrientDTO{
+			NutrientID:   foodNutrient.NutrientID,
+			NutrientName: nutrient.Name,
+			Amount:       amount,
+			Unit:         nutrient.Unit,
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,71 @@
+package models
+
+// Sexes and life stages used to key reference intakes
+const (
+	SexMale   = "male"
+	SexFemale = "female"
+
+	LifeStageGeneral   = ""
+	LifeStagePregnancy = "pregnancy"
+	LifeStageLactation = "lactation"
+)
+
+// ReferenceIntake holds the Dietary Reference Intakes of a nutrient for one sex, age band
+// and life stage. Values are daily amounts in Unit; a zero value means the reference is
+// not established. An empty Sex applies to both sexes and AgeMax 0 leaves the band open.
+type ReferenceIntake struct {
+	NutrientCode string  `json:"nutrient_code"`
+	Unit         string  `json:"unit"`
+	Sex          string  `json:"sex"`
+	AgeMin       int64   `json:"age_min"`
+	AgeMax       int64   `json:"age_max"`
+	LifeStage    string  `json:"life_stage"`
+	EAR          float64 `json:"ear"`
+	RDA          float64 `json:"rda"`
+	AI           float64 `json:"ai"`
+	UL           float64 `json:"ul"`
+}
+
+// Target returns the daily amount to aim for: the RDA, or the AI when no RDA is established
+func (r ReferenceIntake) Target() float64 {
+	if r.RDA > 0 {
+		return r.RDA
+	}
+	return r.AI
+}
+
+// TargetType names the reference used as target: "rda", "ai" or "" when neither is established
+func (r ReferenceIntake) TargetType() string {
+	switch {
+	case r.RDA > 0:
+		return "rda"
+	case r.AI > 0:
+		return "ai"
+	default:
+		return ""
+	}
+}
+
+// Matches reports whether the reference applies to a person of the given sex and age
+func (r ReferenceIntake) Matches(sex string, age int64) bool {
+	if r.Sex != "" && r.Sex != sex {
+		return false
+	}
+	return age >= r.AgeMin && (r.AgeMax == 0 || age <= r.AgeMax)
+}
+
+// IsLifeStage reports whether stage is one of the life stages reference intakes are keyed by
+func IsLifeStage(stage string) bool {
+	switch stage {
+	case LifeStageGeneral, LifeStagePregnancy, LifeStageLactation:
+		return true
+	}
+	return false
+}
+
+// Profile describes the person reference intakes are chosen for
+type Profile struct {
+	Sex       string
+	Age       int64
+	LifeStage string
+}
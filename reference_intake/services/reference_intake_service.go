@@ -0,0 +1,35 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/momokapoolz/caloriesapp/reference_intake/models"
+	userRepository "github.com/momokapoolz/caloriesapp/user/repository"
+)
+
+// ReferenceIntakeService chooses the reference intakes that apply to a user
+type ReferenceIntakeService struct {
+	table    *Table
+	userRepo *userRepository.UserRepository
+}
+
+// NewReferenceIntakeService creates a new reference intake service instance
+func NewReferenceIntakeService(table *Table, userRepo *userRepository.UserRepository) *ReferenceIntakeService {
+	return &ReferenceIntakeService{
+		table:    table,
+		userRepo: userRepo,
+	}
+}
+
+// ForUser returns the reference intakes for the age, gender and life stage of a user, keyed by nutrient code
+func (s *ReferenceIntakeService) ForUser(userID uint) (map[string]models.ReferenceIntake, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+	return s.table.Lookup(models.Profile{
+		Sex:       NormalizeSex(user.Gender),
+		Age:       user.Age,
+		LifeStage: user.LifeStage,
+	}), nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"bytes"
+	_ "embed"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/momokapoolz/caloriesapp/reference_intake/models"
+)
+
+// bundledIntakes is the Dietary Reference Intakes dataset shipped with the application.
+// Values follow the Institute of Medicine DRI tables for ages one and up.
+//
+//go:embed data/reference_intakes.csv
+var bundledIntakes []byte
+
+var (
+	defaultTable     *Table
+	defaultTableOnce sync.Once
+)
+
+// Table is a loaded reference intake dataset
+type Table struct {
+	rows []models.ReferenceIntake
+}
+
+// DefaultTable returns the bundled dataset, parsed once. The file is part of the
+// binary, so failing to parse it is a programming error and panics.
+func DefaultTable() *Table {
+	defaultTableOnce.Do(func() {
+		table, err := LoadTable(bytes.NewReader(bundledIntakes))
+		if err != nil {
+			panic(fmt.Sprintf("invalid bundled reference intakes: %v", err))
+		}
+		defaultTable = table
+	})
+	return defaultTable
+}
+
+// LoadTable reads a reference intake dataset from CSV with the columns
+// nutrient, unit, sex, age_min, age_max, life_stage, ear, rda, ai and ul.
+// Empty values are left unset.
+func LoadTable(r io.Reader) (*Table, error) {
+	reader := csv.NewReader(r)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, column := range header {
+		columns[strings.TrimSpace(column)] = i
+	}
+	for _, column := range []string{"nutrient", "unit", "sex", "age_min", "age_max", "life_stage", "ear", "rda", "ai", "ul"} {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("missing column %q", column)
+		}
+	}
+
+	table := &Table{}
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return table, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+		numbers := make(map[string]float64, 6)
+		for _, name := range []string{"age_min", "age_max", "ear", "rda", "ai", "ul"} {
+			if value := field(name); value != "" {
+				number, err := strconv.ParseFloat(value, 64)
+				if err != nil || number < 0 {
+					return nil, fmt.Errorf("line %d: invalid %s %q", line, name, value)
+				}
+				numbers[name] = number
+			}
+		}
+
+		intake := models.ReferenceIntake{
+			NutrientCode: field("nutrient"),
+			Unit:         field("unit"),
+			Sex:          NormalizeSex(field("sex")),
+			AgeMin:       int64(numbers["age_min"]),
+			AgeMax:       int64(numbers["age_max"]),
+			LifeStage:    strings.ToLower(field("life_stage")),
+			EAR:          numbers["ear"],
+			RDA:          numbers["rda"],
+			AI:           numbers["ai"],
+			UL:           numbers["ul"],
+		}
+		if intake.NutrientCode == "" || intake.Unit == "" {
+			return nil, fmt.Errorf("line %d: nutrient and unit are required", line)
+		}
+		if !models.IsLifeStage(intake.LifeStage) {
+			return nil, fmt.Errorf("line %d: unknown life stage %q", line, intake.LifeStage)
+		}
+		table.rows = append(table.rows, intake)
+	}
+}
+
+// Lookup returns the reference intakes that apply to a profile, keyed by nutrient code.
+// Pregnancy and lactation values replace the general ones where the dataset has them.
+func (t *Table) Lookup(profile models.Profile) map[string]models.ReferenceIntake {
+	intakes := make(map[string]models.ReferenceIntake)
+	for _, row := range t.rows {
+		if !row.Matches(profile.Sex, profile.Age) {
+			continue
+		}
+		if row.LifeStage != models.LifeStageGeneral && row.LifeStage != profile.LifeStage {
+			continue
+		}
+		if current, ok := intakes[row.NutrientCode]; ok && current.LifeStage != models.LifeStageGeneral {
+			continue
+		}
+		intakes[row.NutrientCode] = row
+	}
+	return intakes
+}
+
+// NormalizeSex maps the free-form gender of a user onto the sexes of the dataset.
+// Anything else maps to "", which only matches references shared by both sexes.
+func NormalizeSex(gender string) string {
+	switch strings.ToLower(strings.TrimSpace(gender)) {
+	case "male", "m", "man":
+		return models.SexMale
+	case "female", "f", "woman":
+		return models.SexFemale
+	default:
+		return ""
+	}
+}
+
+// ConvertAmount converts a reference amount into another mass unit. It reports
+// false when the units cannot be converted.
+func ConvertAmount(amount float64, from, to string) (float64, bool) {
+	from, to = normalizeMassUnit(from), normalizeMassUnit(to)
+	if from == to {
+		return amount, true
+	}
+
+	grams := map[string]float64{"g": 1, "mg": 1e-3, "mcg": 1e-6}
+	fromFactor, ok := grams[from]
+	if !ok {
+		return 0, false
+	}
+	toFactor, ok := grams[to]
+	if !ok {
+		return 0, false
+	}
+	return amount * fromFactor / toFactor, true
+}
+
+// normalizeMassUnit maps the spellings of microgram onto "mcg"
+func normalizeMassUnit(unit string) string {
+	unit = strings.ToLower(strings.TrimSpace(unit))
+	switch unit {
+	case "µg", "ug", "μg":
+		return "mcg"
+	default:
+		return unit
+	}
+}
+
+// InUnit returns the reference intake expressed in another unit. It reports false
+// when the units cannot be converted.
+func InUnit(intake models.ReferenceIntake, unit string) (models.ReferenceIntake, bool) {
+	factor, ok := ConvertAmount(1, intake.Unit, unit)
+	if !ok {
+		return intake, false
+	}
+	intake.Unit = unit
+	intake.EAR *= factor
+	intake.RDA *= factor
+	intake.AI *= factor
+	intake.UL *= factor
+	return intake, true
+}
@@ -0,0 +1,75 @@
+package services
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/momokapoolz/caloriesapp/reference_intake/models"
+)
+
+func TestDefaultTableLookup(t *testing.T) {
+	table := DefaultTable()
+
+	tests := []struct {
+		name    string
+		profile models.Profile
+		code    string
+		want    models.ReferenceIntake
+		found   bool
+	}{
+		{"adult man iron", models.Profile{Sex: models.SexMale, Age: 35}, "iron", models.ReferenceIntake{EAR: 6, RDA: 8, UL: 45}, true},
+		{"adult woman iron", models.Profile{Sex: models.SexFemale, Age: 35}, "iron", models.ReferenceIntake{EAR: 8.1, RDA: 18, UL: 45}, true},
+		{"older woman iron", models.Profile{Sex: models.SexFemale, Age: 60}, "iron", models.ReferenceIntake{EAR: 5, RDA: 8, UL: 45}, true},
+		{"pregnancy replaces general value", models.Profile{Sex: models.SexFemale, Age: 25, LifeStage: models.LifeStagePregnancy}, "iron", models.ReferenceIntake{EAR: 22, RDA: 27, UL: 45}, true},
+		{"pregnancy falls back to general value", models.Profile{Sex: models.SexFemale, Age: 25, LifeStage: models.LifeStagePregnancy}, "vitamin_d", models.ReferenceIntake{EAR: 10, RDA: 15, UL: 100}, true},
+		{"open age band", models.Profile{Sex: models.SexMale, Age: 90}, "vitamin_d", models.ReferenceIntake{EAR: 10, RDA: 20, UL: 100}, true},
+		{"shared by both sexes", models.Profile{Age: 40}, "sodium", models.ReferenceIntake{AI: 1500, UL: 2300}, true},
+		{"unknown sex has no sex specific values", models.Profile{Age: 40}, "iron", models.ReferenceIntake{}, false},
+		{"infants are not covered", models.Profile{Sex: models.SexMale, Age: 0}, "protein", models.ReferenceIntake{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := table.Lookup(tt.profile)[tt.code]
+			if ok != tt.found {
+				t.Fatalf("found = %v, want %v", ok, tt.found)
+			}
+			if !ok {
+				return
+			}
+			if got.EAR != tt.want.EAR || got.RDA != tt.want.RDA || got.AI != tt.want.AI || got.UL != tt.want.UL {
+				t.Errorf("got EAR %v RDA %v AI %v UL %v, want EAR %v RDA %v AI %v UL %v",
+					got.EAR, got.RDA, got.AI, got.UL, tt.want.EAR, tt.want.RDA, tt.want.AI, tt.want.UL)
+			}
+		})
+	}
+}
+
+func TestLoadTableRejectsInvalidRows(t *testing.T) {
+	header := "nutrient,unit,sex,age_min,age_max,life_stage,ear,rda,ai,ul\n"
+	tests := map[string]string{
+		"missing column":     "nutrient,unit\niron,mg\n",
+		"negative value":     header + "iron,mg,,19,,,,-8,,\n",
+		"unknown life stage": header + "iron,mg,female,19,,menopause,,8,,\n",
+		"missing unit":       header + "iron,,,19,,,,8,,\n",
+	}
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := LoadTable(strings.NewReader(data)); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+}
+
+func TestConvertAmount(t *testing.T) {
+	if got, ok := ConvertAmount(1.5, "mg", "mcg"); !ok || got != 1500 {
+		t.Errorf("ConvertAmount(1.5, mg, mcg) = %v, %v", got, ok)
+	}
+	if got, ok := ConvertAmount(900, "µg", "mcg"); !ok || got != 900 {
+		t.Errorf("ConvertAmount(900, µg, mcg) = %v, %v", got, ok)
+	}
+	if _, ok := ConvertAmount(900, "mcg", "iu"); ok {
+		t.Error("expected micrograms to IU to be rejected")
+	}
+}
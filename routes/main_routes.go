@@ -2,13 +2,22 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	account_routes "github.com/momokapoolz/caloriesapp/account/routes"
 	"github.com/momokapoolz/caloriesapp/auth"
 	dashboard_routes "github.com/momokapoolz/caloriesapp/dashboard/routes"
+	diary_import_routes "github.com/momokapoolz/caloriesapp/diary_import/routes"
+	exercise_log_routes "github.com/momokapoolz/caloriesapp/exercise_log/routes"
+	export_routes "github.com/momokapoolz/caloriesapp/export/routes"
 	"github.com/momokapoolz/caloriesapp/food/routes"
+	food_import_routes "github.com/momokapoolz/caloriesapp/food_import/routes"
 	food_nutrients_routes "github.com/momokapoolz/caloriesapp/food_nutrients/routes"
+	food_portion_routes "github.com/momokapoolz/caloriesapp/food_portion/routes"
 	meal_log_routes "github.com/momokapoolz/caloriesapp/meal_log/routes"
 	meal_log_items_routes "github.com/momokapoolz/caloriesapp/meal_log_items/routes"
+	meal_template_routes "github.com/momokapoolz/caloriesapp/meal_template/routes"
 	nutrient_routes "github.com/momokapoolz/caloriesapp/nutrient/routes"
+	recipe_routes "github.com/momokapoolz/caloriesapp/recipe/routes"
+	targets_routes "github.com/momokapoolz/caloriesapp/targets/routes"
 	user_biometrics_routes "github.com/momokapoolz/caloriesapp/user_biometrics/routes"
 
 	"gorm.io/gorm"
@@ -32,12 +41,21 @@ func SetupRoutes(db *gorm.DB) *gin.Engine {
 	v1.GET("/health", healthHandler)
 
 	routes.SetupFoodRoutes(v1, db)
+	food_import_routes.SetupFoodImportRoutes(v1, db)
 	nutrient_routes.SetupNutrientRoutes(v1, db)
 	food_nutrients_routes.SetupFoodNutrientRoutes(v1, db)
+	food_portion_routes.SetupFoodPortionRoutes(v1, db)
 	meal_log_routes.SetupMealLogRoutes(v1, db)
 	meal_log_items_routes.SetupMealLogItemRoutes(v1, db)
+	meal_template_routes.SetupMealTemplateRoutes(v1, db)
+	diary_import_routes.SetupDiaryImportRoutes(v1, db)
+	export_routes.SetupExportRoutes(v1, db)
+	recipe_routes.SetupRecipeRoutes(v1, db)
 	user_biometrics_routes.SetupUserBiometricRoutes(v1, db)
+	exercise_log_routes.SetupExerciseLogRoutes(v1, db)
 	dashboard_routes.SetupDashboardRoutes(v1, db)
+	targets_routes.SetupTargetsRoutes(v1, db)
+	account_routes.SetupAccountRoutes(v1, db)
 
 	return router
 }
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	"github.com/momokapoolz/caloriesapp/targets/models"
+	"github.com/momokapoolz/caloriesapp/targets/services"
+	"gorm.io/gorm"
+)
+
+// TargetsController handles HTTP requests for nutrition targets
+type TargetsController struct {
+	service *services.TargetsService
+}
+
+// NewTargetsController creates a new targets controller instance
+func NewTargetsController(service *services.TargetsService) *TargetsController {
+	return &TargetsController{service: service}
+}
+
+// writeTargetsError maps targets service errors to responses; fallback is used for unexpected errors
+func writeTargetsError(ctx *gin.Context, err error, fallback string) {
+	if helpers.WriteConstraintError(ctx, err) {
+		return
+	}
+	switch {
+	case errors.Is(err, services.ErrInvalidTargets):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrNutrientNotFound), errors.Is(err, services.ErrInvalidLookback):
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, services.ErrIncompleteProfile):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "No targets: set weight, height and age in your profile or configure targets"})
+	case errors.Is(err, services.ErrTargetSetNotAccessible):
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "You are not allowed to modify this target set"})
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "Target set not found"})
+	default:
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": fallback})
+	}
+}
+
+// GetTargets godoc
+// @Summary      Get daily targets
+// @Description  Get the calorie, macro and nutrient range targets that apply on a date: the values computed from the profile, overlaid with the user's target set in effect on that date
+// @Tags         targets
+// @Produce      json
+// @Param        date  query     string  false  "Date in YYYY-MM-DD format (default: today)"
+// @Success      200   {object}  dto.NutritionTargetsDTO  "Targets retrieved successfully"
+// @Failure      400   {object}  map[string]string        "Invalid date format"
+// @Failure      401   {object}  map[string]string        "Unauthorized"
+// @Failure      404   {object}  map[string]string        "Profile incomplete and no targets configured"
+// @Failure      500   {object}  map[string]string        "Internal server error"
+// @Security     BearerAuth
+// @Router       /targets/ [get]
+func (c *TargetsController) GetTargets(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	date, err := time.Parse("2006-01-02", ctx.DefaultQuery("date", time.Now().Format("2006-01-02")))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid date format. Use YYYY-MM-DD"})
+		return
+	}
+
+	targets, err := c.service.GetDailyTargets(userClaims.UserID, date)
+	if err != nil {
+		writeTargetsError(ctx, err, "Failed to get targets")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, targets)
+}
+
+// GetTDEE godoc
+// @Summary      Estimate energy expenditure
+// @Description  Estimate the TDEE from the smoothed weight trend and the logged intake over rolling 14-day windows up to yesterday. The estimate is blended with the formula TDEE by its confidence, which grows with the days logged and weighed; computed calorie targets use the same blend over the default period.
+// @Tags         targets
+// @Produce      json
+// @Param        days  query     int  false  "Days to look back (14-180, default 56)"
+// @Success      200   {object}  dto.AdaptiveTDEEDTO  "TDEE estimated successfully"
+// @Failure      400   {object}  map[string]string    "Invalid days"
+// @Failure      401   {object}  map[string]string    "Unauthorized"
+// @Failure      500   {object}  map[string]string    "Internal server error"
+// @Security     BearerAuth
+// @Router       /targets/tdee [get]
+func (c *TargetsController) GetTDEE(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	days, err := strconv.Atoi(ctx.DefaultQuery("days", strconv.Itoa(services.DefaultAdaptiveLookbackDays)))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid days"})
+		return
+	}
+
+	estimate, err := c.service.GetTDEE(userClaims.UserID, days)
+	if err != nil {
+		writeTargetsError(ctx, err, "Failed to estimate TDEE")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, estimate)
+}
+
+// GetPresets godoc
+// @Summary      List macro presets
+// @Description  List the built-in macro splits, in percent of energy, that a target set can use
+// @Tags         targets
+// @Produce      json
+// @Success      200  {object}  map[string]models.MacroSplit  "Presets by name"
+// @Failure      401  {object}  map[string]string             "Unauthorized"
+// @Security     BearerAuth
+// @Router       /targets/presets [get]
+func (c *TargetsController) GetPresets(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, models.Presets)
+}
+
+// GetTargetSets godoc
+// @Summary      List target sets
+// @Description  List the target sets of the authenticated user, oldest effective date first
+// @Tags         targets
+// @Produce      json
+// @Success      200  {array}   models.TargetSet   "Target sets retrieved successfully"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /targets/sets [get]
+func (c *TargetsController) GetTargetSets(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	sets, err := c.service.GetTargetSets(userClaims.UserID)
+	if err != nil {
+		writeTargetsError(ctx, err, "Failed to get target sets")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sets)
+}
+
+// SaveTargetSet godoc
+// @Summary      Set targets
+// @Description  Set targets from a date on (default today), replacing a set with the same date. Days before keep the targets that applied then. Calories and macros left unset keep their computed values; macros are given as a preset (keto, high_protein, zone), custom percents of energy or grams. Nutrients take daily min/max ranges in the nutrient's unit.
+// @Tags         targets
+// @Accept       json
+// @Produce      json
+// @Param        targets  body      dto.TargetSetRequestDTO  true  "Targets"
+// @Success      200      {object}  models.TargetSet         "Targets saved successfully"
+// @Failure      400      {object}  map[string]string        "Invalid targets"
+// @Failure      401      {object}  map[string]string        "Unauthorized"
+// @Failure      500      {object}  map[string]string        "Internal server error"
+// @Security     BearerAuth
+// @Router       /targets/sets [put]
+func (c *TargetsController) SaveTargetSet(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	var req dto.TargetSetRequestDTO
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	set, err := c.service.SaveTargetSet(userClaims.UserID, req)
+	if err != nil {
+		writeTargetsError(ctx, err, "Failed to save targets")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, set)
+}
+
+// DeleteTargetSet godoc
+// @Summary      Delete target set
+// @Description  Delete a target set; the days it covered fall back to the previous set or the computed targets
+// @Tags         targets
+// @Produce      json
+// @Param        id  path      int  true  "Target set ID"
+// @Success      200  {object}  map[string]string  "Target set deleted successfully"
+// @Failure      400  {object}  map[string]string  "Invalid ID format"
+// @Failure      401  {object}  map[string]string  "Unauthorized"
+// @Failure      403  {object}  map[string]string  "Forbidden — not the owner"
+// @Failure      404  {object}  map[string]string  "Target set not found"
+// @Failure      500  {object}  map[string]string  "Internal server error"
+// @Security     BearerAuth
+// @Router       /targets/sets/{id} [delete]
+func (c *TargetsController) DeleteTargetSet(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Invalid ID format"})
+		return
+	}
+
+	if err := c.service.DeleteTargetSet(uint(id), userClaims.UserID); err != nil {
+		writeTargetsError(ctx, err, "Failed to delete target set")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Target set deleted successfully"})
+}
@@ -0,0 +1,72 @@
+package models
+
+import "time"
+
+// Macro presets, given as percent of energy
+const (
+	PresetCustom      = "custom"
+	PresetKeto        = "keto"
+	PresetHighProtein = "high_protein"
+	PresetZone        = "zone"
+)
+
+// MacroSplit is the share of energy, in percent, taken by each macronutrient
+type MacroSplit struct {
+	Protein      float64 `json:"protein"`
+	Carbohydrate float64 `json:"carbohydrate"`
+	Fat          float64 `json:"fat"`
+}
+
+// Presets lists the built-in macro splits
+var Presets = map[string]MacroSplit{
+	PresetKeto:        {Protein: 20, Carbohydrate: 5, Fat: 75},
+	PresetHighProtein: {Protein: 40, Carbohydrate: 30, Fat: 30},
+	PresetZone:        {Protein: 30, Carbohydrate: 40, Fat: 30},
+}
+
+// TargetSet represents the nutrient_target_set table in the database. A set holds the targets
+// a user chose and applies from EffectiveFrom until the next set, so past days keep being
+// scored against the targets of their time. Unset values fall back to the computed targets.
+type TargetSet struct {
+	ID                  uint             `gorm:"primaryKey;column:id" json:"id"`
+	UserID              uint             `gorm:"column:user_id;not null;uniqueIndex:idx_nutrient_target_set_user_date,priority:1" json:"user_id"`
+	EffectiveFrom       time.Time        `gorm:"column:effective_from;type:date;not null;uniqueIndex:idx_nutrient_target_set_user_date,priority:2" json:"effective_from"`
+	Calories            *float64         `gorm:"column:calories" json:"calories"`
+	Preset              string           `gorm:"column:preset;type:varchar(32);not null;default:''" json:"preset"`
+	ProteinPercent      *float64         `gorm:"column:protein_percent" json:"protein_percent"`
+	CarbohydratePercent *float64         `gorm:"column:carbohydrate_percent" json:"carbohydrate_percent"`
+	FatPercent          *float64         `gorm:"column:fat_percent" json:"fat_percent"`
+	ProteinGrams        *float64         `gorm:"column:protein_grams" json:"protein_grams"`
+	CarbohydrateGrams   *float64         `gorm:"column:carbohydrate_grams" json:"carbohydrate_grams"`
+	FatGrams            *float64         `gorm:"column:fat_grams" json:"fat_grams"`
+	CreatedAt           time.Time        `gorm:"column:created_at;not null" json:"created_at"`
+	Nutrients           []NutrientTarget `gorm:"foreignKey:TargetSetID" json:"nutrients"`
+}
+
+// TableName specifies the table name for the TargetSet model
+func (TargetSet) TableName() string {
+	return "nutrient_target_set"
+}
+
+// MacroSplit returns the percent-of-energy split of the set, if it has one
+func (s TargetSet) MacroSplit() (MacroSplit, bool) {
+	if s.ProteinPercent == nil || s.CarbohydratePercent == nil || s.FatPercent == nil {
+		return MacroSplit{}, false
+	}
+	return MacroSplit{Protein: *s.ProteinPercent, Carbohydrate: *s.CarbohydratePercent, Fat: *s.FatPercent}, true
+}
+
+// NutrientTarget represents the nutrient_target table in the database: a daily
+// min/max range for one nutrient, in the nutrient's unit. Either bound may be unset.
+type NutrientTarget struct {
+	ID          uint     `gorm:"primaryKey;column:id" json:"id"`
+	TargetSetID uint     `gorm:"column:target_set_id;not null;index:idx_nutrient_target_set" json:"target_set_id"`
+	NutrientID  uint     `gorm:"column:nutrient_id;not null" json:"nutrient_id"`
+	Min         *float64 `gorm:"column:min_amount" json:"min"`
+	Max         *float64 `gorm:"column:max_amount" json:"max"`
+}
+
+// TableName specifies the table name for the NutrientTarget model
+func (NutrientTarget) TableName() string {
+	return "nutrient_target"
+}
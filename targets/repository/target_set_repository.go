@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/targets/models"
+	"gorm.io/gorm"
+)
+
+// TargetSetRepository handles all database operations for user target sets and their nutrient ranges
+type TargetSetRepository struct {
+	db *gorm.DB
+}
+
+// NewTargetSetRepository creates a new target set repository instance
+func NewTargetSetRepository(db *gorm.DB) *TargetSetRepository {
+	return &TargetSetRepository{db: db}
+}
+
+// Save stores a target set with its nutrient ranges, replacing the set the user
+// already has for the same effective date
+func (r *TargetSetRepository) Save(set *models.TargetSet) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		var existing []models.TargetSet
+		if err := tx.Where("user_id = ? AND effective_from = ?", set.UserID, set.EffectiveFrom).Find(&existing).Error; err != nil {
+			return err
+		}
+		for _, old := range existing {
+			if err := deleteSet(tx, old.ID); err != nil {
+				return err
+			}
+		}
+
+		set.ID = 0
+		for i := range set.Nutrients {
+			set.Nutrients[i].ID = 0
+			set.Nutrients[i].TargetSetID = 0
+		}
+		return tx.Create(set).Error
+	}))
+}
+
+// GetByID retrieves a target set with its nutrient ranges
+func (r *TargetSetRepository) GetByID(id uint) (*models.TargetSet, error) {
+	var set models.TargetSet
+	err := r.db.Preload("Nutrients", orderByNutrient).Where("id = ?", id).First(&set).Error
+	if err != nil {
+		return nil, err
+	}
+	return &set, nil
+}
+
+// GetByUserID retrieves every target set of a user, oldest effective date first
+func (r *TargetSetRepository) GetByUserID(userID uint) ([]models.TargetSet, error) {
+	var sets []models.TargetSet
+	err := r.db.Preload("Nutrients", orderByNutrient).Where("user_id = ?", userID).Order("effective_from").Find(&sets).Error
+	return sets, err
+}
+
+// Delete removes a target set and its nutrient ranges
+func (r *TargetSetRepository) Delete(id uint) error {
+	return database.TranslateError(r.db.Transaction(func(tx *gorm.DB) error {
+		return deleteSet(tx, id)
+	}))
+}
+
+// deleteSet removes a target set and its nutrient ranges within a transaction
+func deleteSet(tx *gorm.DB, id uint) error {
+	if err := tx.Where("target_set_id = ?", id).Delete(&models.NutrientTarget{}).Error; err != nil {
+		return err
+	}
+	return tx.Delete(&models.TargetSet{}, id).Error
+}
+
+// orderByNutrient keeps preloaded nutrient ranges in a stable order
+func orderByNutrient(db *gorm.DB) *gorm.DB {
+	return db.Order("nutrient_id")
+}
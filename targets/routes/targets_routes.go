@@ -0,0 +1,61 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	"github.com/momokapoolz/caloriesapp/targets/controllers"
+	"github.com/momokapoolz/caloriesapp/targets/repository"
+	"github.com/momokapoolz/caloriesapp/targets/services"
+	userRepository "github.com/momokapoolz/caloriesapp/user/repository"
+	userBiometricsRepository "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+	"gorm.io/gorm"
+)
+
+// SetupTargetsRoutes initializes nutrition target routes
+func SetupTargetsRoutes(router *gin.RouterGroup, db *gorm.DB) {
+	nutrientRepository := nutrientRepo.NewNutrientRepository(db)
+	mealLogRepository := mealLogRepo.NewMealLogRepository(db)
+	biometricRepository := userBiometricsRepository.NewUserBiometricRepository(db)
+
+	// Initialize the shared nutrition engine and the daily rollup the intake history is read from
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepo.NewMealLogItemRepository(db),
+		foodRepo.NewFoodRepository(db),
+		foodNutrientsRepo.NewFoodNutrientRepository(db),
+		nutrientRepository,
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(
+		dailyNutritionRepo.NewDailyNutritionRepository(db),
+		mealLogRepository,
+		engine,
+	)
+
+	targetsService := services.NewTargetsService(
+		userRepository.NewUserRepository(),
+		biometricRepository,
+		repository.NewTargetSetRepository(db),
+		nutrientRepository,
+		services.NewAdaptiveTDEEService(biometricRepository, mealLogRepository, engine, rollup),
+	)
+	targetsController := controllers.NewTargetsController(targetsService)
+
+	authMiddleware := auth.NewAuthMiddleware()
+
+	targetsRoutes := router.Group("/targets", authMiddleware.RequireAuth())
+	{
+		targetsRoutes.GET("/", targetsController.GetTargets)
+		targetsRoutes.GET("/tdee", targetsController.GetTDEE)
+		targetsRoutes.GET("/presets", targetsController.GetPresets)
+		targetsRoutes.GET("/sets", targetsController.GetTargetSets)
+		targetsRoutes.PUT("/sets", targetsController.SaveTargetSet)
+		targetsRoutes.DELETE("/sets/:id", targetsController.DeleteTargetSet)
+	}
+}
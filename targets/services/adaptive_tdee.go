@@ -0,0 +1,231 @@
+package services
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+)
+
+// TDEE sources
+const (
+	TDEESourceFormula  = "formula"
+	TDEESourceAdaptive = "adaptive"
+)
+
+// KcalPerKg is the energy stored in or released from one kg of body weight change
+const KcalPerKg = 7700
+
+// Adaptive estimation settings. Windows of adaptiveWindowDays slide by adaptiveWindowStep over
+// the lookback period; the weight trend is warmed up over trendWarmupDays before it.
+const (
+	DefaultAdaptiveLookbackDays = 56
+	MinAdaptiveLookbackDays     = 14
+	MaxAdaptiveLookbackDays     = 180
+
+	adaptiveWindowDays = 14
+	adaptiveWindowStep = 7
+	trendWarmupDays    = 28
+
+	// trendSmoothing is the weight of a day's scale reading in the exponentially smoothed trend
+	trendSmoothing = 0.1
+	// weighInsForFullConfidence is the share of days with a weigh-in a window needs for full confidence
+	weighInsForFullConfidence = 0.5
+)
+
+// plausibleTDEE bounds the window estimates kept; anything outside points at logging gaps
+var plausibleTDEE = [2]float64{800, 7000}
+
+// WeightSample is a scale reading in kg
+type WeightSample struct {
+	Date time.Time
+	Kg   float64
+}
+
+// EstimateAdaptiveTDEE estimates the energy expenditure over the lookbackDays ending on end from
+// the intake logged per day (kcal keyed by YYYY-MM-DD; days missing were not logged) and weight
+// samples, which may reach back before the period to warm up the trend.
+//
+// Scale readings are interpolated between weigh-ins and smoothed exponentially into a trend, so
+// water swings do not read as fat gain or loss. Each window estimates the expenditure as the
+// average logged intake minus the trend change in kcal per day, with a confidence given by how
+// many days were logged and weighed. The result averages the windows by confidence, favouring
+// recent ones; Confidence is the average window confidence.
+func EstimateAdaptiveTDEE(intake map[string]float64, weights []WeightSample, end time.Time, lookbackDays int) *dto.AdaptiveTDEEDTO {
+	end = dayOf(end)
+	start := end.AddDate(0, 0, 1-lookbackDays)
+	estimate := &dto.AdaptiveTDEEDTO{
+		StartDate: start.Format("2006-01-02"),
+		EndDate:   end.Format("2006-01-02"),
+		Source:    TDEESourceFormula,
+		Windows:   []dto.TDEEWindowDTO{},
+	}
+
+	trend, weighedDays := weightTrend(weights, end)
+	if value, ok := trend[estimate.EndDate]; ok {
+		estimate.TrendWeightKg = value
+	}
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if intake[date] > 0 {
+			estimate.LoggedDays++
+		}
+		if weighedDays[date] {
+			estimate.WeighIns++
+		}
+	}
+
+	var weighted, totalWeight, confidence float64
+	windowCount := 0
+	for windowEnd := end; !windowEnd.Before(start.AddDate(0, 0, adaptiveWindowDays-1)); windowEnd = windowEnd.AddDate(0, 0, -adaptiveWindowStep) {
+		windowCount++
+		window, ok := estimateWindow(intake, trend, weighedDays, windowEnd)
+		if !ok {
+			continue
+		}
+		// Windows are visited newest first; the newest weighs the most
+		recency := 1 / float64(windowCount)
+		weighted += window.TDEE * window.Confidence * recency
+		totalWeight += window.Confidence * recency
+		confidence += window.Confidence
+		estimate.Windows = append(estimate.Windows, window)
+	}
+
+	sort.Slice(estimate.Windows, func(i, j int) bool {
+		return estimate.Windows[i].StartDate < estimate.Windows[j].StartDate
+	})
+	if totalWeight > 0 {
+		estimate.AdaptiveTDEE = weighted / totalWeight
+		estimate.Confidence = confidence / float64(windowCount)
+	}
+	return estimate
+}
+
+// estimateWindow estimates the expenditure over the adaptiveWindowDays ending on windowEnd. It reports
+// false when nothing was logged, the trend does not cover the window or the estimate is implausible.
+func estimateWindow(intake map[string]float64, trend map[string]float64, weighedDays map[string]bool, windowEnd time.Time) (dto.TDEEWindowDTO, bool) {
+	windowStart := windowEnd.AddDate(0, 0, 1-adaptiveWindowDays)
+	window := dto.TDEEWindowDTO{
+		StartDate: windowStart.Format("2006-01-02"),
+		EndDate:   windowEnd.Format("2006-01-02"),
+	}
+
+	before, ok := trend[windowStart.AddDate(0, 0, -1).Format("2006-01-02")]
+	if !ok {
+		return window, false
+	}
+	after, ok := trend[window.EndDate]
+	if !ok {
+		return window, false
+	}
+
+	var totalIntake float64
+	for day := windowStart; !day.After(windowEnd); day = day.AddDate(0, 0, 1) {
+		date := day.Format("2006-01-02")
+		if kcal := intake[date]; kcal > 0 {
+			totalIntake += kcal
+			window.LoggedDays++
+		}
+		if weighedDays[date] {
+			window.WeighIns++
+		}
+	}
+	if window.LoggedDays == 0 || window.WeighIns == 0 {
+		return window, false
+	}
+
+	window.AverageIntake = totalIntake / float64(window.LoggedDays)
+	window.TrendChangeKg = after - before
+	window.TDEE = window.AverageIntake - window.TrendChangeKg*KcalPerKg/adaptiveWindowDays
+	if window.TDEE < plausibleTDEE[0] || window.TDEE > plausibleTDEE[1] {
+		return window, false
+	}
+
+	logged := float64(window.LoggedDays) / adaptiveWindowDays
+	weighed := math.Min(float64(window.WeighIns)/(adaptiveWindowDays*weighInsForFullConfidence), 1)
+	window.Confidence = logged * weighed
+	return window, true
+}
+
+// weightTrend smooths weight samples into a daily trend up to end, keyed by YYYY-MM-DD, and
+// returns the days with a weigh-in. Days between weigh-ins are interpolated and days after the
+// last one hold its value; the trend starts at the first weigh-in.
+func weightTrend(samples []WeightSample, end time.Time) (map[string]float64, map[string]bool) {
+	trend := make(map[string]float64)
+	weighedDays := make(map[string]bool)
+
+	// Several readings on one day count as their average
+	daily := make(map[time.Time][]float64)
+	for _, sample := range samples {
+		if sample.Kg <= 0 {
+			continue
+		}
+		day := dayOf(sample.Date)
+		if day.After(end) {
+			continue
+		}
+		daily[day] = append(daily[day], sample.Kg)
+	}
+	if len(daily) == 0 {
+		return trend, weighedDays
+	}
+	days := make([]time.Time, 0, len(daily))
+	for day := range daily {
+		days = append(days, day)
+		weighedDays[day.Format("2006-01-02")] = true
+	}
+	sort.Slice(days, func(i, j int) bool { return days[i].Before(days[j]) })
+	reading := func(day time.Time) float64 {
+		var sum float64
+		for _, kg := range daily[day] {
+			sum += kg
+		}
+		return sum / float64(len(daily[day]))
+	}
+
+	value := reading(days[0])
+	next := 0
+	for day := days[0]; !day.After(end); day = day.AddDate(0, 0, 1) {
+		for next < len(days) && days[next].Before(day) {
+			next++
+		}
+		scale := reading(days[len(days)-1])
+		switch {
+		case next < len(days) && days[next].Equal(day):
+			scale = reading(day)
+		case next < len(days):
+			previous := days[next-1]
+			span := days[next].Sub(previous).Hours() / 24
+			elapsed := day.Sub(previous).Hours() / 24
+			scale = reading(previous) + (reading(days[next])-reading(previous))*elapsed/span
+		}
+		value += trendSmoothing * (scale - value)
+		trend[day.Format("2006-01-02")] = value
+	}
+	return trend, weighedDays
+}
+
+// BlendTDEE sets the TDEE of an estimate: the adaptive estimate weighted by its confidence against
+// the formula TDEE. Without a formula TDEE any adaptive estimate is used as is.
+func BlendTDEE(estimate *dto.AdaptiveTDEEDTO, formulaTDEE float64) {
+	estimate.FormulaTDEE = formulaTDEE
+	estimate.TDEE = formulaTDEE
+	estimate.Source = TDEESourceFormula
+	if estimate.Confidence <= 0 {
+		return
+	}
+
+	estimate.Source = TDEESourceAdaptive
+	if formulaTDEE <= 0 {
+		estimate.TDEE = estimate.AdaptiveTDEE
+		return
+	}
+	estimate.TDEE = estimate.Confidence*estimate.AdaptiveTDEE + (1-estimate.Confidence)*formulaTDEE
+}
+
+// dayOf returns the calendar date of t in its own location as a UTC midnight. Callers convert
+// times to the user's timezone first, so weigh-ins land on the same days as the logged intake.
+func dayOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
+	userBiometricsModels "github.com/momokapoolz/caloriesapp/user_biometrics/models"
+	userBiometricsRepo "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+)
+
+// AdaptiveTDEEService estimates a user's energy expenditure from the logged intake and weight history.
+// The latest estimate of each user is kept for the rest of the day, so the targets shown on every
+// dashboard load do not rescan the whole history.
+type AdaptiveTDEEService struct {
+	biometricRepo *userBiometricsRepo.UserBiometricRepository
+	mealLogRepo   *mealLogRepo.MealLogRepository
+	engine        *nutritionEngine.NutritionEngine
+	rollup        *dailyNutritionServices.DailyNutritionService
+
+	mu     sync.Mutex
+	cached map[uint]cachedEstimate
+}
+
+// cachedEstimate is the estimate of a user over the lookbackDays ending on end (YYYY-MM-DD)
+type cachedEstimate struct {
+	end          string
+	lookbackDays int
+	estimate     dto.AdaptiveTDEEDTO
+}
+
+// NewAdaptiveTDEEService creates a new adaptive TDEE service instance
+func NewAdaptiveTDEEService(
+	biometricRepo *userBiometricsRepo.UserBiometricRepository,
+	mealLogRepo *mealLogRepo.MealLogRepository,
+	engine *nutritionEngine.NutritionEngine,
+	rollup *dailyNutritionServices.DailyNutritionService,
+) *AdaptiveTDEEService {
+	return &AdaptiveTDEEService{
+		biometricRepo: biometricRepo,
+		mealLogRepo:   mealLogRepo,
+		engine:        engine,
+		rollup:        rollup,
+		cached:        make(map[uint]cachedEstimate),
+	}
+}
+
+// Estimate estimates the expenditure of a user over the lookbackDays up to yesterday, the last
+// complete day before now in the user's timezone. The returned estimate has no TDEE yet;
+// BlendTDEE sets it against the formula TDEE.
+func (s *AdaptiveTDEEService) Estimate(userID uint, now time.Time, lookbackDays int) (*dto.AdaptiveTDEEDTO, error) {
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	// Dates are calendar dates in the user's timezone, held as UTC midnights so they step by whole days
+	end := dayOf(now.In(loc)).AddDate(0, 0, -1)
+	start := end.AddDate(0, 0, 1-lookbackDays)
+
+	intake, err := s.dailyIntake(userID, start, end, loc)
+	if err != nil {
+		return nil, err
+	}
+	_, weighedUntil := helpers.DayBounds(end, loc)
+	weights, err := s.biometricRepo.GetByUserIDAndTypeAndDateRange(
+		userID,
+		userBiometricsModels.GetBiometricTypes().Weight,
+		helpers.DayStart(start.AddDate(0, 0, -trendWarmupDays), loc),
+		weighedUntil.Add(-time.Nanosecond),
+	)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get weight history: %w", err)
+	}
+
+	samples := make([]WeightSample, 0, len(weights))
+	for _, weight := range weights {
+		if kg, ok := weight.Kilograms(); ok {
+			samples = append(samples, WeightSample{Date: dayOf(weight.CreatedAt.In(loc)), Kg: kg})
+		}
+	}
+	estimate := EstimateAdaptiveTDEE(intake, samples, end, lookbackDays)
+
+	s.mu.Lock()
+	s.cached[userID] = cachedEstimate{end: estimate.EndDate, lookbackDays: lookbackDays, estimate: *estimate}
+	s.mu.Unlock()
+	return estimate, nil
+}
+
+// CachedEstimate returns the estimate Estimate would, reusing the one computed earlier in the
+// user's day. Meals and weigh-ins back-dated since then show up the next day, or on the TDEE endpoint.
+func (s *AdaptiveTDEEService) CachedEstimate(userID uint, now time.Time, lookbackDays int) (*dto.AdaptiveTDEEDTO, error) {
+	loc, err := s.mealLogRepo.GetUserLocation(userID)
+	if err != nil {
+		helpers.LogError(err)
+		return nil, fmt.Errorf("failed to get user timezone: %w", err)
+	}
+	end := dayOf(now.In(loc)).AddDate(0, 0, -1).Format("2006-01-02")
+
+	s.mu.Lock()
+	cached, ok := s.cached[userID]
+	s.mu.Unlock()
+	if ok && cached.end == end && cached.lookbackDays == lookbackDays {
+		// BlendTDEE fills in the copy, never the cached estimate
+		estimate := cached.estimate
+		return &estimate, nil
+	}
+	return s.Estimate(userID, now, lookbackDays)
+}
+
+// dailyIntake returns the logged energy per day between two dates, from the rollup when it is
+// fresh and from the raw rows otherwise
+func (s *AdaptiveTDEEService) dailyIntake(userID uint, start, end time.Time, loc *time.Location) (map[string]float64, error) {
+	report, fresh, err := s.rollup.GetFreshReport(userID, start, end)
+	if err != nil {
+		helpers.LogError(err)
+	}
+	if !fresh {
+		mealLogs, err := s.mealLogRepo.GetByUserIDAndDateRange(userID, start, end, loc)
+		if err != nil {
+			helpers.LogError(err)
+			return nil, fmt.Errorf("failed to get meal logs: %w", err)
+		}
+		if report, err = s.engine.Calculate(mealLogs, loc); err != nil {
+			return nil, fmt.Errorf("failed to calculate intake: %w", err)
+		}
+	}
+
+	intake := make(map[string]float64, len(report.Days))
+	for _, day := range report.Days {
+		intake[day.Date] = report.Amount(day.Nutrients, nutrientModels.CodeEnergy)
+	}
+	return intake, nil
+}
@@ -0,0 +1,139 @@
+package services
+
+import (
+	"testing"
+	"time"
+)
+
+// syntheticHistory logs intake kcal every day and a weigh-in every weighEvery days, with the
+// weight changing by kgPerWeek, over the lookback and the trend warm-up before it
+func syntheticHistory(end time.Time, intake, startKg, kgPerWeek float64, weighEvery int) (map[string]float64, []WeightSample) {
+	days := DefaultAdaptiveLookbackDays + trendWarmupDays
+	logged := make(map[string]float64)
+	var weights []WeightSample
+	for i := 0; i < days; i++ {
+		day := end.AddDate(0, 0, i-days+1)
+		logged[day.Format("2006-01-02")] = intake
+		if i%weighEvery == 0 {
+			// Morning readings swing by half a kilo around the real weight
+			noise := 0.5
+			if i%2 == 0 {
+				noise = -0.5
+			}
+			weights = append(weights, WeightSample{Date: day.Add(7 * time.Hour), Kg: startKg + kgPerWeek*float64(i)/7 + noise})
+		}
+	}
+	return logged, weights
+}
+
+func TestEstimateAdaptiveTDEE(t *testing.T) {
+	end := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	// Eating 2500 kcal while losing 0.5 kg a week burns 2500 + 0.5*7700/7 = 3050 kcal a day
+	intake, weights := syntheticHistory(end, 2500, 90, -0.5, 1)
+	estimate := EstimateAdaptiveTDEE(intake, weights, end, DefaultAdaptiveLookbackDays)
+	if estimate.StartDate != "2024-04-06" || estimate.EndDate != "2024-05-31" {
+		t.Errorf("period = %s to %s", estimate.StartDate, estimate.EndDate)
+	}
+	if len(estimate.Windows) != 7 {
+		t.Errorf("windows = %d, want 7", len(estimate.Windows))
+	}
+	if estimate.AdaptiveTDEE < 3000 || estimate.AdaptiveTDEE > 3100 {
+		t.Errorf("adaptive TDEE = %v, want about 3050", estimate.AdaptiveTDEE)
+	}
+	assertClose(t, "confidence with daily logs and weigh-ins", estimate.Confidence, 1)
+
+	// Weighing in every fourth day halves the confidence
+	intake, weights = syntheticHistory(end, 2500, 90, -0.5, 4)
+	sparse := EstimateAdaptiveTDEE(intake, weights, end, DefaultAdaptiveLookbackDays)
+	if sparse.Confidence < 0.4 || sparse.Confidence > 0.65 {
+		t.Errorf("confidence with sparse weigh-ins = %v", sparse.Confidence)
+	}
+	if sparse.AdaptiveTDEE < 2950 || sparse.AdaptiveTDEE > 3150 {
+		t.Errorf("adaptive TDEE with sparse weigh-ins = %v, want about 3050", sparse.AdaptiveTDEE)
+	}
+
+	// Stable weight on 2200 kcal means a 2200 kcal expenditure
+	intake, weights = syntheticHistory(end, 2200, 70, 0, 1)
+	stable := EstimateAdaptiveTDEE(intake, weights, end, DefaultAdaptiveLookbackDays)
+	if stable.AdaptiveTDEE < 2150 || stable.AdaptiveTDEE > 2250 {
+		t.Errorf("adaptive TDEE at stable weight = %v, want about 2200", stable.AdaptiveTDEE)
+	}
+	if stable.TrendWeightKg < 69.9 || stable.TrendWeightKg > 70.1 {
+		t.Errorf("trend weight = %v, want about 70 despite the daily swings", stable.TrendWeightKg)
+	}
+}
+
+func TestEstimateAdaptiveTDEEWithoutData(t *testing.T) {
+	end := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+
+	intake, _ := syntheticHistory(end, 2500, 90, 0, 1)
+	estimate := EstimateAdaptiveTDEE(intake, nil, end, DefaultAdaptiveLookbackDays)
+	if estimate.Confidence != 0 || estimate.AdaptiveTDEE != 0 || len(estimate.Windows) != 0 {
+		t.Errorf("estimate without weigh-ins = %+v", estimate)
+	}
+
+	BlendTDEE(estimate, 2400)
+	if estimate.Source != TDEESourceFormula || estimate.TDEE != 2400 {
+		t.Errorf("blend without confidence = %s %v, want the formula TDEE", estimate.Source, estimate.TDEE)
+	}
+}
+
+func TestBlendTDEE(t *testing.T) {
+	end := time.Date(2024, 5, 31, 0, 0, 0, 0, time.UTC)
+	intake, weights := syntheticHistory(end, 2500, 90, -0.5, 4)
+	estimate := EstimateAdaptiveTDEE(intake, weights, end, DefaultAdaptiveLookbackDays)
+
+	BlendTDEE(estimate, 2000)
+	want := estimate.Confidence*estimate.AdaptiveTDEE + (1-estimate.Confidence)*2000
+	if estimate.Source != TDEESourceAdaptive {
+		t.Errorf("source = %s, want adaptive", estimate.Source)
+	}
+	assertClose(t, "blended TDEE", estimate.TDEE, want)
+
+	BlendTDEE(estimate, 0)
+	assertClose(t, "TDEE without formula", estimate.TDEE, estimate.AdaptiveTDEE)
+}
+
+func TestWeightTrend(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 8, 0, 0, 0, time.UTC) }
+	trend, weighed := weightTrend([]WeightSample{
+		{Date: day(1), Kg: 80},
+		{Date: day(3), Kg: 82},
+		{Date: day(3), Kg: 84},
+	}, dayOf(day(5)))
+
+	if len(weighed) != 2 || !weighed["2024-01-03"] {
+		t.Errorf("weighed days = %v", weighed)
+	}
+	if _, ok := trend["2023-12-31"]; ok {
+		t.Error("trend must start at the first weigh-in")
+	}
+	assertClose(t, "day 1", trend["2024-01-01"], 80)
+	// Day 2 interpolates to 81.5 between 80 and the day 3 average of 83
+	assertClose(t, "day 2", trend["2024-01-02"], 80.15)
+	assertClose(t, "day 3", trend["2024-01-03"], 80.435)
+	if len(trend) != 5 {
+		t.Errorf("trend days = %d, want 5", len(trend))
+	}
+}
+
+func TestDayOfUserTimezone(t *testing.T) {
+	newYork, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skipf("timezone data unavailable: %v", err)
+	}
+
+	// An evening weigh-in in New York is already the next day in UTC
+	weighIn := time.Date(2024, 3, 9, 3, 30, 0, 0, time.UTC)
+	if got := dayOf(weighIn.In(newYork)).Format("2006-01-02"); got != "2024-03-08" {
+		t.Errorf("day in New York = %s, want 2024-03-08", got)
+	}
+	if got := dayOf(weighIn).Format("2006-01-02"); got != "2024-03-09" {
+		t.Errorf("day in UTC = %s, want 2024-03-09", got)
+	}
+	// Stepping across the DST change still moves by whole days
+	if got := dayOf(weighIn.In(newYork)).AddDate(0, 0, 2); !got.Equal(time.Date(2024, 3, 10, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("two days later = %v", got)
+	}
+}
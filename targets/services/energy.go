@@ -0,0 +1,121 @@
+package services
+
+import (
+	"math"
+	"strings"
+
+	referenceIntakeModels "github.com/momokapoolz/caloriesapp/reference_intake/models"
+)
+
+// BMR formulas
+const (
+	FormulaMifflinStJeor = "mifflin_st_jeor"
+	FormulaKatchMcArdle  = "katch_mcardle"
+)
+
+// Goals a user can pursue
+const (
+	GoalLose     = "lose"
+	GoalMaintain = "maintain"
+	GoalGain     = "gain"
+)
+
+// MinimumCalories is the lowest daily calorie target handed out, whatever the deficit
+const MinimumCalories = 1200
+
+// goalAdjustments is the share of TDEE added (surplus) or removed (deficit) per goal
+var goalAdjustments = map[string]float64{
+	GoalLose:     -0.20,
+	GoalMaintain: 0,
+	GoalGain:     0.10,
+}
+
+// proteinPerKg is the protein target in grams per kg of body weight per goal
+var proteinPerKg = map[string]float64{
+	GoalLose:     2.0,
+	GoalMaintain: 1.6,
+	GoalGain:     1.8,
+}
+
+// fatShare is the part of the calorie target covered by fat; carbohydrates fill the rest
+const fatShare = 0.25
+
+// MifflinStJeor estimates the basal metabolic rate in kcal/day. Without a known sex
+// the average of the male and female constants is used.
+func MifflinStJeor(sex string, weightKg, heightCm float64, age int64) float64 {
+	bmr := 10*weightKg + 6.25*heightCm - 5*float64(age)
+	switch sex {
+	case referenceIntakeModels.SexMale:
+		return bmr + 5
+	case referenceIntakeModels.SexFemale:
+		return bmr - 161
+	default:
+		return bmr - 78
+	}
+}
+
+// KatchMcArdle estimates the basal metabolic rate in kcal/day from lean body mass
+func KatchMcArdle(weightKg, bodyFatPercentage float64) float64 {
+	leanMass := weightKg * (1 - bodyFatPercentage/100)
+	return 370 + 21.6*leanMass
+}
+
+// activityFactors maps each activity level, normalized by normalizeActivityLevel, onto the TDEE
+// multiplier of the BMR
+var activityFactors = map[string]float64{
+	"sedentary":         1.2,
+	"light":             1.375,
+	"lightly active":    1.375,
+	"low":               1.375,
+	"moderate":          1.55,
+	"moderately active": 1.55,
+	"active":            1.55,
+	"very active":       1.725,
+	"high":              1.725,
+	"extra active":      1.9,
+	"extremely active":  1.9,
+	"athlete":           1.9,
+}
+
+// ActivityFactor maps an activity level such as "Lightly Active" or "very_active" onto the TDEE
+// multiplier of the BMR. Unknown levels count as sedentary.
+func ActivityFactor(level string) float64 {
+	if factor, ok := activityFactors[normalizeActivityLevel(level)]; ok {
+		return factor
+	}
+	return 1.2
+}
+
+// normalizeActivityLevel lower-cases level and separates its words with single spaces
+func normalizeActivityLevel(level string) string {
+	level = strings.NewReplacer("_", " ", "-", " ").Replace(strings.ToLower(level))
+	return strings.Join(strings.Fields(level), " ")
+}
+
+// NormalizeGoal maps a free-form goal such as "Lose weight" or "Muscle Gain" onto a goal.
+// Anything else means maintaining the current weight.
+func NormalizeGoal(goal string) string {
+	goal = strings.ToLower(goal)
+	switch {
+	case strings.Contains(goal, "lose"), strings.Contains(goal, "loss"), strings.Contains(goal, "cut"):
+		return GoalLose
+	case strings.Contains(goal, "gain"), strings.Contains(goal, "bulk"), strings.Contains(goal, "build"):
+		return GoalGain
+	default:
+		return GoalMaintain
+	}
+}
+
+// GoalCalories applies the deficit or surplus of a goal to the TDEE
+func GoalCalories(tdee float64, goal string) float64 {
+	return math.Max(tdee*(1+goalAdjustments[goal]), MinimumCalories)
+}
+
+// MacroGrams splits a calorie target into protein, carbohydrate and fat grams. Protein
+// follows body weight, fat a fixed share of the calories and carbohydrates the rest.
+func MacroGrams(calories, weightKg float64, goal string) (protein, carbohydrate, fat float64) {
+	protein = proteinPerKg[goal] * weightKg
+	fat = calories * fatShare / 9
+	carbohydrate = math.Max((calories-protein*4-fat*9)/4, 0)
+	return protein, carbohydrate, fat
+}
@@ -0,0 +1,66 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	referenceIntakeModels "github.com/momokapoolz/caloriesapp/reference_intake/models"
+)
+
+func assertClose(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	if math.Abs(got-want) > 0.01 {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func TestBMR(t *testing.T) {
+	assertClose(t, "male", MifflinStJeor(referenceIntakeModels.SexMale, 80, 180, 30), 1780)
+	assertClose(t, "female", MifflinStJeor(referenceIntakeModels.SexFemale, 60, 165, 30), 1320.25)
+	assertClose(t, "unknown sex", MifflinStJeor("", 70, 170, 40), 1484.5)
+	assertClose(t, "katch-mcardle", KatchMcArdle(80, 20), 1752.4)
+}
+
+func TestActivityFactorAndGoal(t *testing.T) {
+	factors := map[string]float64{
+		"Sedentary":         1.2,
+		"lightly active":    1.375,
+		"Moderately Active": 1.55,
+		"active":            1.55,
+		"very active":       1.725,
+		"very_active":       1.725,
+		"very light":        1.2,
+		"Extra Active":      1.9,
+		"":                  1.2,
+	}
+	for level, want := range factors {
+		assertClose(t, "ActivityFactor("+level+")", ActivityFactor(level), want)
+	}
+
+	goals := map[string]string{
+		"Lose weight":  GoalLose,
+		"weight loss":  GoalLose,
+		"Muscle Gain":  GoalGain,
+		"maintenance":  GoalMaintain,
+		"stay healthy": GoalMaintain,
+	}
+	for goal, want := range goals {
+		if got := NormalizeGoal(goal); got != want {
+			t.Errorf("NormalizeGoal(%q) = %q, want %q", goal, got, want)
+		}
+	}
+}
+
+func TestGoalCaloriesAndMacros(t *testing.T) {
+	assertClose(t, "deficit", GoalCalories(2500, GoalLose), 2000)
+	assertClose(t, "surplus", GoalCalories(2500, GoalGain), 2750)
+	assertClose(t, "minimum", GoalCalories(1300, GoalLose), MinimumCalories)
+
+	protein, carbohydrate, fat := MacroGrams(2000, 80, GoalLose)
+	assertClose(t, "protein", protein, 160)
+	assertClose(t, "fat", fat, 55.56)
+	assertClose(t, "carbohydrate", carbohydrate, 215)
+
+	_, carbohydrate, _ = MacroGrams(1200, 150, GoalLose)
+	assertClose(t, "carbohydrate floor", carbohydrate, 0)
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	"github.com/momokapoolz/caloriesapp/targets/models"
+)
+
+// Target sources
+const (
+	SourceComputed = "computed"
+	SourceUser     = "user"
+)
+
+// macroTolerance is the band around a single calorie or macro target within which a day is on target
+const macroTolerance = 0.10
+
+// splitTolerance is how far custom macro percents may add up away from 100
+const splitTolerance = 0.5
+
+// buildTargetSet validates a target set request and turns it into a set of userID.
+// The effective date defaults to today; knownNutrients holds the IDs of the nutrient rows.
+func buildTargetSet(userID uint, req dto.TargetSetRequestDTO, today time.Time, knownNutrients map[uint]bool) (*models.TargetSet, error) {
+	set := &models.TargetSet{
+		UserID:              userID,
+		EffectiveFrom:       time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC),
+		Calories:            req.Calories,
+		Preset:              req.Preset,
+		ProteinPercent:      req.ProteinPercent,
+		CarbohydratePercent: req.CarbohydratePercent,
+		FatPercent:          req.FatPercent,
+		ProteinGrams:        req.ProteinGrams,
+		CarbohydrateGrams:   req.CarbohydrateGrams,
+		FatGrams:            req.FatGrams,
+		CreatedAt:           time.Now(),
+	}
+	if req.EffectiveFrom != "" {
+		effectiveFrom, err := time.Parse("2006-01-02", req.EffectiveFrom)
+		if err != nil {
+			return nil, fmt.Errorf("%w: effective_from must use YYYY-MM-DD", ErrInvalidTargets)
+		}
+		set.EffectiveFrom = effectiveFrom
+	}
+
+	if set.Calories != nil && *set.Calories <= 0 {
+		return nil, fmt.Errorf("%w: calories must be positive", ErrInvalidTargets)
+	}
+
+	hasPercents := set.ProteinPercent != nil || set.CarbohydratePercent != nil || set.FatPercent != nil
+	hasGrams := set.ProteinGrams != nil || set.CarbohydrateGrams != nil || set.FatGrams != nil
+	if preset, ok := models.Presets[set.Preset]; ok {
+		if hasPercents {
+			return nil, fmt.Errorf("%w: preset %q sets the macro percents itself", ErrInvalidTargets, set.Preset)
+		}
+		set.ProteinPercent, set.CarbohydratePercent, set.FatPercent = &preset.Protein, &preset.Carbohydrate, &preset.Fat
+		hasPercents = true
+	} else if set.Preset == models.PresetCustom || (set.Preset == "" && hasPercents) {
+		if err := validateSplit(set); err != nil {
+			return nil, err
+		}
+		set.Preset = models.PresetCustom
+	} else if set.Preset != "" {
+		return nil, fmt.Errorf("%w: unknown preset %q", ErrInvalidTargets, set.Preset)
+	}
+	if hasPercents && hasGrams {
+		return nil, fmt.Errorf("%w: set macros either as percents of energy or in grams", ErrInvalidTargets)
+	}
+	for _, grams := range []*float64{set.ProteinGrams, set.CarbohydrateGrams, set.FatGrams} {
+		if grams != nil && *grams < 0 {
+			return nil, fmt.Errorf("%w: macro grams cannot be negative", ErrInvalidTargets)
+		}
+	}
+
+	seen := make(map[uint]bool, len(req.Nutrients))
+	for _, nutrient := range req.Nutrients {
+		if !knownNutrients[nutrient.NutrientID] {
+			return nil, fmt.Errorf("%w: %d", ErrNutrientNotFound, nutrient.NutrientID)
+		}
+		if seen[nutrient.NutrientID] {
+			return nil, fmt.Errorf("%w: nutrient %d is listed twice", ErrInvalidTargets, nutrient.NutrientID)
+		}
+		seen[nutrient.NutrientID] = true
+
+		switch {
+		case nutrient.Min == nil && nutrient.Max == nil:
+			return nil, fmt.Errorf("%w: nutrient %d needs a min or a max", ErrInvalidTargets, nutrient.NutrientID)
+		case (nutrient.Min != nil && *nutrient.Min < 0) || (nutrient.Max != nil && *nutrient.Max < 0):
+			return nil, fmt.Errorf("%w: nutrient %d has a negative bound", ErrInvalidTargets, nutrient.NutrientID)
+		case nutrient.Min != nil && nutrient.Max != nil && *nutrient.Min > *nutrient.Max:
+			return nil, fmt.Errorf("%w: nutrient %d has a min above its max", ErrInvalidTargets, nutrient.NutrientID)
+		}
+		set.Nutrients = append(set.Nutrients, models.NutrientTarget{
+			NutrientID: nutrient.NutrientID,
+			Min:        nutrient.Min,
+			Max:        nutrient.Max,
+		})
+	}
+	return set, nil
+}
+
+// validateSplit checks that custom macro percents are complete and add up to 100
+func validateSplit(set *models.TargetSet) error {
+	split, ok := set.MacroSplit()
+	if !ok {
+		return fmt.Errorf("%w: custom macros need protein, carbohydrate and fat percents", ErrInvalidTargets)
+	}
+	for _, percent := range []float64{split.Protein, split.Carbohydrate, split.Fat} {
+		if percent < 0 || percent > 100 {
+			return fmt.Errorf("%w: macro percents must be between 0 and 100", ErrInvalidTargets)
+		}
+	}
+	if total := split.Protein + split.Carbohydrate + split.Fat; math.Abs(total-100) > splitTolerance {
+		return fmt.Errorf("%w: macro percents add up to %g instead of 100", ErrInvalidTargets, total)
+	}
+	return nil
+}
+
+// effectiveSet returns the set in effect on a date formatted as YYYY-MM-DD: the latest
+// one starting on or before it. sets must be ordered by effective date.
+func effectiveSet(sets []models.TargetSet, date string) *models.TargetSet {
+	var effective *models.TargetSet
+	for i := range sets {
+		if sets[i].EffectiveFrom.Format("2006-01-02") > date {
+			break
+		}
+		effective = &sets[i]
+	}
+	return effective
+}
+
+// applyTargetSet overlays a target set on the computed targets. Either may be nil;
+// nil is returned when both are.
+func applyTargetSet(computed *dto.NutritionTargetsDTO, set *models.TargetSet) *dto.NutritionTargetsDTO {
+	if computed == nil && set == nil {
+		return nil
+	}
+	targets := dto.NutritionTargetsDTO{Source: SourceComputed}
+	if computed != nil {
+		targets = *computed
+	}
+	targets.Nutrients = []dto.NutrientRangeDTO{}
+	if set == nil {
+		return &targets
+	}
+
+	targets.Source = SourceUser
+	targets.TargetSetID = &set.ID
+	targets.Preset = set.Preset
+	if set.Calories != nil {
+		targets.Calories = *set.Calories
+		if targets.TDEE > 0 {
+			targets.GoalAdjustment = targets.Calories - targets.TDEE
+		}
+	}
+	if split, ok := set.MacroSplit(); ok {
+		targets.Protein = targets.Calories * split.Protein / 100 / 4
+		targets.Carbohydrate = targets.Calories * split.Carbohydrate / 100 / 4
+		targets.Fat = targets.Calories * split.Fat / 100 / 9
+	}
+	if set.ProteinGrams != nil {
+		targets.Protein = *set.ProteinGrams
+	}
+	if set.CarbohydrateGrams != nil {
+		targets.Carbohydrate = *set.CarbohydrateGrams
+	}
+	if set.FatGrams != nil {
+		targets.Fat = *set.FatGrams
+	}
+	for _, nutrient := range set.Nutrients {
+		targets.Nutrients = append(targets.Nutrients, dto.NutrientRangeDTO{
+			NutrientID: nutrient.NutrientID,
+			Min:        nutrient.Min,
+			Max:        nutrient.Max,
+		})
+	}
+	return &targets
+}
+
+// Ranges turns targets into daily ranges keyed by nutrient ID. The calorie and macro targets
+// become a band of 10% around their value; explicit nutrient ranges take precedence.
+func Ranges(targets *dto.NutritionTargetsDTO, nutrientIDByCode func(string) (uint, bool)) map[uint]dto.NutrientRangeDTO {
+	ranges := make(map[uint]dto.NutrientRangeDTO)
+	if targets == nil {
+		return ranges
+	}
+
+	values := map[string]float64{
+		nutrientModels.CodeEnergy:       targets.Calories,
+		nutrientModels.CodeProtein:      targets.Protein,
+		nutrientModels.CodeCarbohydrate: targets.Carbohydrate,
+		nutrientModels.CodeFat:          targets.Fat,
+	}
+	for code, value := range values {
+		nutrientID, ok := nutrientIDByCode(code)
+		if !ok || value <= 0 {
+			continue
+		}
+		low, high := value*(1-macroTolerance), value*(1+macroTolerance)
+		ranges[nutrientID] = dto.NutrientRangeDTO{NutrientID: nutrientID, Min: &low, Max: &high}
+	}
+	for _, nutrient := range targets.Nutrients {
+		ranges[nutrient.NutrientID] = nutrient
+	}
+	return ranges
+}
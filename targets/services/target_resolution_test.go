@@ -0,0 +1,159 @@
+package services
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	nutrientModels "github.com/momokapoolz/caloriesapp/nutrient/models"
+	"github.com/momokapoolz/caloriesapp/targets/models"
+)
+
+func float(v float64) *float64 {
+	return &v
+}
+
+func TestBuildTargetSet(t *testing.T) {
+	today := time.Date(2024, 3, 10, 15, 30, 0, 0, time.Local)
+	known := map[uint]bool{1: true, 2: true}
+
+	set, err := buildTargetSet(7, dto.TargetSetRequestDTO{Calories: float(2000), Preset: models.PresetKeto}, today, known)
+	if err != nil {
+		t.Fatalf("keto preset: %v", err)
+	}
+	if got := set.EffectiveFrom.Format("2006-01-02"); got != "2024-03-10" {
+		t.Errorf("effective from = %s, want today", got)
+	}
+	if split, ok := set.MacroSplit(); !ok || split != models.Presets[models.PresetKeto] {
+		t.Errorf("keto split = %+v, %v", split, ok)
+	}
+
+	set, err = buildTargetSet(7, dto.TargetSetRequestDTO{
+		ProteinPercent:      float(30),
+		CarbohydratePercent: float(45),
+		FatPercent:          float(25),
+	}, today, known)
+	if err != nil || set.Preset != models.PresetCustom {
+		t.Errorf("implied custom split = %+v, %v", set, err)
+	}
+
+	invalid := map[string]dto.TargetSetRequestDTO{
+		"bad date":            {EffectiveFrom: "10/03/2024"},
+		"zero calories":       {Calories: float(0)},
+		"unknown preset":      {Preset: "paleo"},
+		"preset with percent": {Preset: models.PresetZone, FatPercent: float(20)},
+		"incomplete custom":   {Preset: models.PresetCustom, ProteinPercent: float(30)},
+		"split not 100":       {ProteinPercent: float(30), CarbohydratePercent: float(30), FatPercent: float(30)},
+		"percents and grams":  {Preset: models.PresetZone, ProteinGrams: float(150)},
+		"negative grams":      {FatGrams: float(-1)},
+		"no bounds":           {Nutrients: []dto.NutrientTargetRequestDTO{{NutrientID: 1}}},
+		"min above max":       {Nutrients: []dto.NutrientTargetRequestDTO{{NutrientID: 1, Min: float(5), Max: float(4)}}},
+		"duplicate nutrient": {Nutrients: []dto.NutrientTargetRequestDTO{
+			{NutrientID: 1, Min: float(5)},
+			{NutrientID: 1, Max: float(9)},
+		}},
+	}
+	for name, req := range invalid {
+		if _, err := buildTargetSet(7, req, today, known); !errors.Is(err, ErrInvalidTargets) {
+			t.Errorf("%s: err = %v, want ErrInvalidTargets", name, err)
+		}
+	}
+
+	unknown := dto.TargetSetRequestDTO{Nutrients: []dto.NutrientTargetRequestDTO{{NutrientID: 3, Max: float(10)}}}
+	if _, err := buildTargetSet(7, unknown, today, known); !errors.Is(err, ErrNutrientNotFound) {
+		t.Errorf("unknown nutrient: err = %v, want ErrNutrientNotFound", err)
+	}
+}
+
+func TestEffectiveSet(t *testing.T) {
+	sets := []models.TargetSet{
+		{ID: 1, EffectiveFrom: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{ID: 2, EffectiveFrom: time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	cases := map[string]uint{
+		"2023-12-31": 0,
+		"2024-01-01": 1,
+		"2024-01-31": 1,
+		"2024-02-01": 2,
+		"2024-06-01": 2,
+	}
+	for date, want := range cases {
+		got := effectiveSet(sets, date)
+		switch {
+		case want == 0 && got != nil:
+			t.Errorf("%s: got set %d, want none", date, got.ID)
+		case want != 0 && (got == nil || got.ID != want):
+			t.Errorf("%s: got %+v, want set %d", date, got, want)
+		}
+	}
+}
+
+func TestApplyTargetSet(t *testing.T) {
+	if applyTargetSet(nil, nil) != nil {
+		t.Error("no computed targets and no set should give no targets")
+	}
+
+	computed := &dto.NutritionTargetsDTO{Source: SourceComputed, TDEE: 2500, Calories: 2000, Protein: 150, Carbohydrate: 200, Fat: 60}
+	targets := applyTargetSet(computed, nil)
+	if targets.Source != SourceComputed || targets.Calories != 2000 || targets.Nutrients == nil {
+		t.Errorf("computed only = %+v", targets)
+	}
+
+	set := &models.TargetSet{
+		ID:                  4,
+		Calories:            float(1800),
+		Preset:              models.PresetZone,
+		ProteinPercent:      float(30),
+		CarbohydratePercent: float(40),
+		FatPercent:          float(30),
+		Nutrients:           []models.NutrientTarget{{NutrientID: 9, Max: float(2300)}},
+	}
+	targets = applyTargetSet(computed, set)
+	if targets.Source != SourceUser || *targets.TargetSetID != 4 || targets.Preset != models.PresetZone {
+		t.Errorf("user targets = %+v", targets)
+	}
+	assertClose(t, "goal adjustment", targets.GoalAdjustment, -700)
+	assertClose(t, "protein", targets.Protein, 135)
+	assertClose(t, "carbohydrate", targets.Carbohydrate, 180)
+	assertClose(t, "fat", targets.Fat, 60)
+	if len(targets.Nutrients) != 1 || targets.Nutrients[0].NutrientID != 9 {
+		t.Errorf("nutrient ranges = %+v", targets.Nutrients)
+	}
+	if computed.Calories != 2000 {
+		t.Error("applying a set must not modify the computed targets")
+	}
+
+	targets = applyTargetSet(nil, &models.TargetSet{ProteinGrams: float(120)})
+	if targets.Source != SourceUser || targets.Protein != 120 || targets.Calories != 0 {
+		t.Errorf("set without computed targets = %+v", targets)
+	}
+}
+
+func TestRanges(t *testing.T) {
+	ids := map[string]uint{nutrientModels.CodeEnergy: 1, nutrientModels.CodeProtein: 2, nutrientModels.CodeFat: 4}
+	lookup := func(code string) (uint, bool) {
+		id, ok := ids[code]
+		return id, ok
+	}
+
+	ranges := Ranges(&dto.NutritionTargetsDTO{
+		Calories:     2000,
+		Protein:      150,
+		Carbohydrate: 200,
+		Fat:          0,
+		Nutrients:    []dto.NutrientRangeDTO{{NutrientID: 2, Min: float(160)}},
+	}, lookup)
+
+	if len(ranges) != 2 {
+		t.Fatalf("ranges = %+v, want energy and protein", ranges)
+	}
+	assertClose(t, "energy min", *ranges[1].Min, 1800)
+	assertClose(t, "energy max", *ranges[1].Max, 2200)
+	if protein := ranges[2]; *protein.Min != 160 || protein.Max != nil {
+		t.Errorf("explicit protein range = %+v", protein)
+	}
+	if len(Ranges(nil, lookup)) != 0 {
+		t.Error("nil targets should give no ranges")
+	}
+}
@@ -0,0 +1,221 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	referenceIntakeServices "github.com/momokapoolz/caloriesapp/reference_intake/services"
+	"github.com/momokapoolz/caloriesapp/targets/models"
+	"github.com/momokapoolz/caloriesapp/targets/repository"
+	userRepository "github.com/momokapoolz/caloriesapp/user/repository"
+	userBiometricsModels "github.com/momokapoolz/caloriesapp/user_biometrics/models"
+	userBiometricsRepo "github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+)
+
+// Error definitions
+var (
+	ErrIncompleteProfile      = errors.New("weight, height and age are required to compute targets")
+	ErrInvalidTargets         = errors.New("invalid targets")
+	ErrNutrientNotFound       = errors.New("nutrient not found")
+	ErrTargetSetNotAccessible = errors.New("target set belongs to another user")
+	ErrInvalidLookback        = errors.New("invalid lookback period")
+)
+
+// TargetsService computes daily calorie and macro targets from a user's profile,
+// activity level and goal, using the latest biometrics where they are recorded and the
+// expenditure measured from the weight trend as it becomes reliable, and overlays the
+// target sets the user configured
+type TargetsService struct {
+	userRepo      *userRepository.UserRepository
+	biometricRepo *userBiometricsRepo.UserBiometricRepository
+	setRepo       *repository.TargetSetRepository
+	nutrientRepo  *nutrientRepo.NutrientRepository
+	adaptive      *AdaptiveTDEEService
+}
+
+// NewTargetsService creates a new targets service instance
+func NewTargetsService(
+	userRepo *userRepository.UserRepository,
+	biometricRepo *userBiometricsRepo.UserBiometricRepository,
+	setRepo *repository.TargetSetRepository,
+	nutrientRepo *nutrientRepo.NutrientRepository,
+	adaptive *AdaptiveTDEEService,
+) *TargetsService {
+	return &TargetsService{
+		userRepo:      userRepo,
+		biometricRepo: biometricRepo,
+		setRepo:       setRepo,
+		nutrientRepo:  nutrientRepo,
+		adaptive:      adaptive,
+	}
+}
+
+// SaveTargetSet stores the targets a user sets from a date on, replacing a set with the same date
+func (s *TargetsService) SaveTargetSet(userID uint, req dto.TargetSetRequestDTO) (*models.TargetSet, error) {
+	nutrients, err := s.nutrientRepo.GetAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get nutrients: %w", err)
+	}
+	knownNutrients := make(map[uint]bool, len(nutrients))
+	for _, nutrient := range nutrients {
+		knownNutrients[nutrient.ID] = true
+	}
+
+	set, err := buildTargetSet(userID, req, time.Now(), knownNutrients)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.setRepo.Save(set); err != nil {
+		return nil, err
+	}
+	return set, nil
+}
+
+// GetTargetSets retrieves the target sets of a user, oldest effective date first
+func (s *TargetsService) GetTargetSets(userID uint) ([]models.TargetSet, error) {
+	return s.setRepo.GetByUserID(userID)
+}
+
+// DeleteTargetSet removes a target set of the user. Days it covered fall back to the previous set.
+func (s *TargetsService) DeleteTargetSet(id, userID uint) error {
+	set, err := s.setRepo.GetByID(id)
+	if err != nil {
+		return err
+	}
+	if set.UserID != userID {
+		return ErrTargetSetNotAccessible
+	}
+	return s.setRepo.Delete(id)
+}
+
+// GetDailyTargets returns the targets that apply to a user on a date
+func (s *TargetsService) GetDailyTargets(userID uint, date time.Time) (*dto.NutritionTargetsDTO, error) {
+	day := date.Format("2006-01-02")
+	targets, err := s.ForDates(userID, []string{day})
+	if err != nil {
+		return nil, err
+	}
+	if targets[day] == nil {
+		return nil, ErrIncompleteProfile
+	}
+	return targets[day], nil
+}
+
+// ForDates returns the targets that applied to a user on each date, formatted as YYYY-MM-DD.
+// Every date is resolved against the target set in effect on it, over today's computed targets.
+// Dates without any targets, because the profile is incomplete and no set applies, map to nil.
+func (s *TargetsService) ForDates(userID uint, dates []string) (map[string]*dto.NutritionTargetsDTO, error) {
+	computed, err := s.GetTargets(userID)
+	if err != nil && !errors.Is(err, ErrIncompleteProfile) {
+		return nil, err
+	}
+	sets, err := s.setRepo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get target sets: %w", err)
+	}
+
+	targets := make(map[string]*dto.NutritionTargetsDTO, len(dates))
+	for _, date := range dates {
+		targets[date] = applyTargetSet(computed, effectiveSet(sets, date))
+	}
+	return targets, nil
+}
+
+// GetTDEE estimates the energy expenditure of a user over the lookbackDays up to yesterday, the
+// last complete day in the user's timezone, blended with the formula TDEE of the profile when it
+// is complete. The estimate is always recomputed, and refreshes the one the targets reuse.
+func (s *TargetsService) GetTDEE(userID uint, lookbackDays int) (*dto.AdaptiveTDEEDTO, error) {
+	if lookbackDays < MinAdaptiveLookbackDays || lookbackDays > MaxAdaptiveLookbackDays {
+		return nil, fmt.Errorf("%w: days must be between %d and %d", ErrInvalidLookback, MinAdaptiveLookbackDays, MaxAdaptiveLookbackDays)
+	}
+
+	var formulaTDEE float64
+	targets, err := s.profileTargets(userID)
+	switch {
+	case err == nil:
+		formulaTDEE = targets.TDEE
+	case !errors.Is(err, ErrIncompleteProfile):
+		return nil, err
+	}
+
+	estimate, err := s.adaptive.Estimate(userID, time.Now(), lookbackDays)
+	if err != nil {
+		return nil, err
+	}
+	BlendTDEE(estimate, formulaTDEE)
+	return estimate, nil
+}
+
+// GetTargets computes the daily targets of a user without target sets. The TDEE is the formula
+// TDEE blended with the adaptive estimate by its confidence, and the goal turns it into a calorie
+// target with a deficit or surplus. The adaptive estimate is computed once per user and day; failing
+// to estimate it falls back to the formula.
+func (s *TargetsService) GetTargets(userID uint) (*dto.NutritionTargetsDTO, error) {
+	targets, err := s.profileTargets(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	targets.TDEESource = TDEESourceFormula
+	if estimate, err := s.adaptive.CachedEstimate(userID, time.Now(), DefaultAdaptiveLookbackDays); err != nil {
+		helpers.LogError(err)
+	} else {
+		BlendTDEE(estimate, targets.TDEE)
+		targets.TDEE = estimate.TDEE
+		targets.TDEESource = estimate.Source
+		targets.TDEEConfidence = estimate.Confidence
+	}
+
+	targets.Calories = GoalCalories(targets.TDEE, targets.Goal)
+	targets.GoalAdjustment = targets.Calories - targets.TDEE
+	targets.Protein, targets.Carbohydrate, targets.Fat = MacroGrams(targets.Calories, targets.WeightKg, targets.Goal)
+	targets.Nutrients = []dto.NutrientRangeDTO{}
+	return targets, nil
+}
+
+// profileTargets computes the BMR and formula TDEE of a user from the profile alone. The BMR uses
+// Katch-McArdle when a body fat percentage is recorded and Mifflin-St Jeor otherwise; the TDEE
+// scales it by the activity level.
+func (s *TargetsService) profileTargets(userID uint) (*dto.NutritionTargetsDTO, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	types := userBiometricsModels.GetBiometricTypes()
+	weightKg := user.Weight
+	if latest, err := s.biometricRepo.GetLatestByUserIDAndType(userID, types.Weight); err == nil {
+		if kg, ok := latest.Kilograms(); ok {
+			weightKg = kg
+		}
+	}
+	heightCm := user.Height
+	if latest, err := s.biometricRepo.GetLatestByUserIDAndType(userID, types.Height); err == nil {
+		if cm, ok := latest.Centimeters(); ok {
+			heightCm = cm
+		}
+	}
+	if weightKg <= 0 || heightCm <= 0 || user.Age <= 0 {
+		return nil, ErrIncompleteProfile
+	}
+
+	targets := &dto.NutritionTargetsDTO{
+		Source:         SourceComputed,
+		Formula:        FormulaMifflinStJeor,
+		BMR:            MifflinStJeor(referenceIntakeServices.NormalizeSex(user.Gender), weightKg, heightCm, user.Age),
+		ActivityFactor: ActivityFactor(user.ActivityLevel),
+		Goal:           NormalizeGoal(user.Goal),
+		WeightKg:       weightKg,
+	}
+	if latest, err := s.biometricRepo.GetLatestByUserIDAndType(userID, types.BodyFatPercentage); err == nil && latest.Value > 0 && latest.Value < 100 {
+		targets.Formula = FormulaKatchMcArdle
+		targets.BMR = KatchMcArdle(weightKg, latest.Value)
+	}
+
+	targets.TDEE = targets.BMR * targets.ActivityFactor
+	return targets, nil
+}
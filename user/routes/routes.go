@@ -3,7 +3,16 @@ package routes
 import (
 	"github.com/gin-gonic/gin"
 	"github.com/momokapoolz/caloriesapp/auth"
+	dailyNutritionRepo "github.com/momokapoolz/caloriesapp/daily_nutrition/repository"
+	dailyNutritionServices "github.com/momokapoolz/caloriesapp/daily_nutrition/services"
+	foodRepo "github.com/momokapoolz/caloriesapp/food/repository"
+	foodNutrientsRepo "github.com/momokapoolz/caloriesapp/food_nutrients/repository"
+	mealLogRepo "github.com/momokapoolz/caloriesapp/meal_log/repository"
+	mealLogItemsRepo "github.com/momokapoolz/caloriesapp/meal_log_items/repository"
+	nutrientRepo "github.com/momokapoolz/caloriesapp/nutrient/repository"
+	nutritionEngine "github.com/momokapoolz/caloriesapp/nutrition_engine/services"
 	"github.com/momokapoolz/caloriesapp/user/controllers"
+	"github.com/momokapoolz/caloriesapp/user/database"
 	"github.com/momokapoolz/caloriesapp/user/middleware"
 	"github.com/momokapoolz/caloriesapp/user/repository"
 	"github.com/momokapoolz/caloriesapp/user/services"
@@ -23,14 +32,27 @@ func SetupRoutes(rg *gin.RouterGroup) {
 	userRepo := repository.NewUserRepository()
 	passwordService := services.NewPasswordService(userRepo)
 
+	// The diary rollup is rebuilt when a user changes timezone
+	engine := nutritionEngine.NewNutritionEngine(
+		mealLogItemsRepo.NewMealLogItemRepository(database.DB),
+		foodRepo.NewFoodRepository(database.DB),
+		foodNutrientsRepo.NewFoodNutrientRepository(database.DB),
+		nutrientRepo.NewNutrientRepository(database.DB),
+	)
+	rollup := dailyNutritionServices.NewDailyNutritionService(
+		dailyNutritionRepo.NewDailyNutritionRepository(database.DB),
+		mealLogRepo.NewMealLogRepository(database.DB),
+		engine,
+	)
+
 	// Controllers
-	userController := controllers.NewUserController()
+	userController := controllers.NewUserController(rollup)
 	passwordController := controllers.NewPasswordController(passwordService)
 
 	// Auth routes: POST /login, /register, /refresh, /logout
 	SetupAuthRoutes(rg, authMiddleware)
 
-	// User profile routes: GET /profile, PUT /profile, DELETE /account
+	// User profile routes: GET /profile, PUT /profile. Account deletion lives in the account module.
 	userController.RegisterRoutes(rg, authMiddleware)
 
 	// Password update (for authenticated users)
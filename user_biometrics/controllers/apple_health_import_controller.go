@@ -0,0 +1,84 @@
+package controllers
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/helpers"
+	"github.com/momokapoolz/caloriesapp/user_biometrics/services"
+)
+
+// AppleHealthImportController handles HTTP requests for Apple Health imports
+type AppleHealthImportController struct {
+	service *services.AppleHealthImportService
+}
+
+// NewAppleHealthImportController creates a new Apple Health import controller instance
+func NewAppleHealthImportController(service *services.AppleHealthImportService) *AppleHealthImportController {
+	return &AppleHealthImportController{service: service}
+}
+
+// ImportAppleHealth godoc
+// @Summary      Import Apple Health export
+// @Description  Import the weight, height, body fat, BMI, waist circumference, blood pressure and resting heart rate records of an Apple Health export into the authenticated user's biometrics. Upload the export zip or its export.xml. Values are converted to kg, cm, %, mmHg and bpm; measurements already stored with the same type, time and value are skipped.
+// @Tags         user_biometric
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        file  formData  file                          true  "Apple Health export (.zip or export.xml)"
+// @Success      200   {object}  dto.BiometricImportResultDTO  "Import summary"
+// @Failure      400   {object}  map[string]string             "Missing or invalid export"
+// @Failure      401   {object}  map[string]string             "Unauthorized"
+// @Failure      500   {object}  map[string]string             "Internal server error"
+// @Security     BearerAuth
+// @Router       /user-biometrics/import/apple-health [post]
+func (c *AppleHealthImportController) ImportAppleHealth(ctx *gin.Context) {
+	userClaims, ok := auth.GetCurrentUser(ctx)
+	if !ok {
+		ctx.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized"})
+		return
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "A file upload is required"})
+		return
+	}
+	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
+	if ext != ".zip" && ext != ".xml" {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "Unsupported file type. Upload the export .zip or its export.xml"})
+		return
+	}
+
+	// Zip archives are read randomly, so the upload is stored on disk first
+	tmp, err := os.CreateTemp("", "apple-health-*"+ext)
+	if err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload"})
+		return
+	}
+	tmp.Close()
+	defer os.Remove(tmp.Name())
+	if err := ctx.SaveUploadedFile(fileHeader, tmp.Name()); err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to store upload"})
+		return
+	}
+
+	result, err := c.service.ImportPath(userClaims.UserID, tmp.Name())
+	if errors.Is(err, services.ErrInvalidHealthExport) {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	if err != nil {
+		helpers.LogError(err)
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to import biometrics"})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
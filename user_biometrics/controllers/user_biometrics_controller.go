@@ -45,6 +45,9 @@ func (c *UserBiometricController) CreateUserBiometric(ctx *gin.Context) {
 	}
 
 	if err := c.service.CreateUserBiometric(&biometric); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user biometric"})
 		return
@@ -256,6 +259,9 @@ func (c *UserBiometricController) UpdateUserBiometric(ctx *gin.Context) {
 
 	biometric.ID = uint(id)
 	if err := c.service.UpdateUserBiometric(&biometric); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update user biometric"})
 		return
@@ -284,6 +290,9 @@ func (c *UserBiometricController) DeleteUserBiometric(ctx *gin.Context) {
 	}
 
 	if err := c.service.DeleteUserBiometric(uint(id)); err != nil {
+		if helpers.WriteConstraintError(ctx, err) {
+			return
+		}
 		helpers.LogError(err)
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to delete user biometric"})
 		return
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
@@ -19,6 +20,34 @@ func (UserBiometric) TableName() string {
 	return "user_biometrics"
 }
 
+// Kilograms returns a weight biometric in kilograms. It reports false for units it does not know.
+func (b UserBiometric) Kilograms() (float64, bool) {
+	switch strings.ToLower(strings.TrimSpace(b.Unit)) {
+	case "kg", "kgs", "":
+		return b.Value, true
+	case "lb", "lbs":
+		return b.Value * 0.45359237, true
+	case "g":
+		return b.Value / 1000, true
+	default:
+		return 0, false
+	}
+}
+
+// Centimeters returns a height biometric in centimeters. It reports false for units it does not know.
+func (b UserBiometric) Centimeters() (float64, bool) {
+	switch strings.ToLower(strings.TrimSpace(b.Unit)) {
+	case "cm", "":
+		return b.Value, true
+	case "m":
+		return b.Value * 100, true
+	case "in":
+		return b.Value * 2.54, true
+	default:
+		return 0, false
+	}
+}
+
 // BiometricTypes contains constants for different biometric types
 type BiometricTypes struct {
 	Weight                 string
@@ -3,6 +3,7 @@ package repository
 import (
 	"time"
 
+	"github.com/momokapoolz/caloriesapp/database"
 	"github.com/momokapoolz/caloriesapp/user_biometrics/models"
 	"gorm.io/gorm"
 )
@@ -17,9 +18,22 @@ func NewUserBiometricRepository(db *gorm.DB) *UserBiometricRepository {
 	return &UserBiometricRepository{db: db}
 }
 
+// WithTx returns a repository running its queries in the transaction tx
+func (r *UserBiometricRepository) WithTx(tx *gorm.DB) *UserBiometricRepository {
+	return &UserBiometricRepository{db: tx}
+}
+
 // Create adds a new user biometric record to the database
 func (r *UserBiometricRepository) Create(biometric *models.UserBiometric) error {
-	return r.db.Create(biometric).Error
+	return database.TranslateError(r.db.Create(biometric).Error)
+}
+
+// CreateBatch adds user biometric records to the database in a single insert
+func (r *UserBiometricRepository) CreateBatch(biometrics []models.UserBiometric) error {
+	if len(biometrics) == 0 {
+		return nil
+	}
+	return database.TranslateError(r.db.Create(&biometrics).Error)
 }
 
 // GetByID retrieves a user biometric by its ID
@@ -65,12 +79,12 @@ func (r *UserBiometricRepository) GetLatestByUserIDAndType(userID uint, biometri
 
 // Update updates a user biometric record
 func (r *UserBiometricRepository) Update(biometric *models.UserBiometric) error {
-	return r.db.Save(biometric).Error
+	return database.TranslateError(r.db.Save(biometric).Error)
 }
 
 // Delete removes a user biometric record
 func (r *UserBiometricRepository) Delete(id uint) error {
-	return r.db.Delete(&models.UserBiometric{}, id).Error
+	return database.TranslateError(r.db.Delete(&models.UserBiometric{}, id).Error)
 }
 
 // GetLatestBiometricsByUserID retrieves the latest biometric for each type for a user
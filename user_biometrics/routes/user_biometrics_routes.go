@@ -2,6 +2,8 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/momokapoolz/caloriesapp/auth"
+	"github.com/momokapoolz/caloriesapp/database"
 	"github.com/momokapoolz/caloriesapp/user_biometrics/controllers"
 	"github.com/momokapoolz/caloriesapp/user_biometrics/repository"
 	"github.com/momokapoolz/caloriesapp/user_biometrics/services"
@@ -13,6 +15,9 @@ func SetupUserBiometricRoutes(router *gin.RouterGroup, db *gorm.DB) {
 	userBiometricRepo := repository.NewUserBiometricRepository(db)
 	userBiometricService := services.NewUserBiometricService(userBiometricRepo)
 	userBiometricController := controllers.NewUserBiometricController(userBiometricService)
+	appleHealthImportController := controllers.NewAppleHealthImportController(services.NewAppleHealthImportService(database.NewUnitOfWork(db), userBiometricRepo))
+
+	authMiddleware := auth.NewAuthMiddleware()
 
 	userBiometricRoutes := router.Group("/user-biometrics")
 	{
@@ -31,5 +36,7 @@ func SetupUserBiometricRoutes(router *gin.RouterGroup, db *gorm.DB) {
 		userBiometricRoutes.GET("/user/:userId/summary", userBiometricController.GetBiometricSummary)
 		userBiometricRoutes.GET("/user/:userId/types", userBiometricController.GetAvailableBiometricTypes)
 		userBiometricRoutes.GET("/types", userBiometricController.GetBiometricTypes)
+
+		userBiometricRoutes.POST("/import/apple-health", authMiddleware.RequireAuth(), appleHealthImportController.ImportAppleHealth)
 	}
 }
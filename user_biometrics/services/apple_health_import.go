@@ -0,0 +1,288 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/database"
+	"github.com/momokapoolz/caloriesapp/dto"
+	"github.com/momokapoolz/caloriesapp/user_biometrics/models"
+	"github.com/momokapoolz/caloriesapp/user_biometrics/repository"
+	"gorm.io/gorm"
+)
+
+// ErrInvalidHealthExport is returned for files that are not an Apple Health export
+var ErrInvalidHealthExport = errors.New("invalid Apple Health export")
+
+// appleHealthDateLayout is the layout of the dates in export.xml
+const appleHealthDateLayout = "2006-01-02 15:04:05 -0700"
+
+// appleHealthBatchSize is how many records are inserted at once
+const appleHealthBatchSize = 500
+
+// appleHealthType describes how a HealthKit quantity type is stored as a biometric
+type appleHealthType struct {
+	Type    string
+	Unit    string
+	Convert func(value float64, unit string) (float64, bool)
+}
+
+// appleHealthTypes maps the HealthKit quantity types onto biometric types
+var appleHealthTypes = func() map[string]appleHealthType {
+	types := models.GetBiometricTypes()
+	return map[string]appleHealthType{
+		"HKQuantityTypeIdentifierBodyMass":               {types.Weight, "kg", toKilograms},
+		"HKQuantityTypeIdentifierHeight":                 {types.Height, "cm", toCentimeters},
+		"HKQuantityTypeIdentifierBodyFatPercentage":      {types.BodyFatPercentage, "%", fractionToPercent},
+		"HKQuantityTypeIdentifierBodyMassIndex":          {types.BMI, "kg/m2", unitless},
+		"HKQuantityTypeIdentifierWaistCircumference":     {types.WaistCircumference, "cm", toCentimeters},
+		"HKQuantityTypeIdentifierBloodPressureSystolic":  {types.BloodPressureSystolic, "mmHg", toMillimetersOfMercury},
+		"HKQuantityTypeIdentifierBloodPressureDiastolic": {types.BloodPressureDiastolic, "mmHg", toMillimetersOfMercury},
+		"HKQuantityTypeIdentifierRestingHeartRate":       {types.RestingHeartRate, "bpm", toBeatsPerMinute},
+	}
+}()
+
+// AppleHealthImportService imports the body measurements of an Apple Health export into
+// user_biometrics. export.xml is streamed, so exports of hundreds of MB are never held in memory;
+// only a short key per stored or imported measurement is kept to skip duplicates.
+type AppleHealthImportService struct {
+	uow  *database.UnitOfWork
+	repo *repository.UserBiometricRepository
+}
+
+// NewAppleHealthImportService creates a new Apple Health import service instance
+func NewAppleHealthImportService(uow *database.UnitOfWork, repo *repository.UserBiometricRepository) *AppleHealthImportService {
+	return &AppleHealthImportService{uow: uow, repo: repo}
+}
+
+// ImportPath imports an export from disk: the zip written by the Health app, or its export.xml
+func (s *AppleHealthImportService) ImportPath(userID uint, filePath string) (*dto.BiometricImportResultDTO, error) {
+	if !strings.EqualFold(path.Ext(filePath), ".zip") {
+		file, err := os.Open(filePath)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return s.Import(userID, file)
+	}
+
+	archive, err := zip.OpenReader(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidHealthExport, err)
+	}
+	defer archive.Close()
+	for _, entry := range archive.File {
+		if path.Base(entry.Name) != "export.xml" {
+			continue
+		}
+		file, err := entry.Open()
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidHealthExport, err)
+		}
+		defer file.Close()
+		return s.Import(userID, file)
+	}
+	return nil, fmt.Errorf("%w: the archive has no export.xml", ErrInvalidHealthExport)
+}
+
+// Import reads an export.xml and stores its measurements for a user. Measurements already
+// stored with the same type, time and value, or repeated in the file, are skipped. The import is
+// saved in a single transaction, so an export that fails midway stores nothing.
+func (s *AppleHealthImportService) Import(userID uint, r io.Reader) (*dto.BiometricImportResultDTO, error) {
+	var result *dto.BiometricImportResultDTO
+	err := s.uow.Do(func(tx *gorm.DB) error {
+		var err error
+		result, err = importAppleHealth(s.repo.WithTx(tx), userID, r)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// importAppleHealth stores the measurements of an export.xml for a user through repo
+func importAppleHealth(repo *repository.UserBiometricRepository, userID uint, r io.Reader) (*dto.BiometricImportResultDTO, error) {
+	existing, err := repo.GetByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stored biometrics: %w", err)
+	}
+	seen := make(map[string]bool, len(existing))
+	for _, biometric := range existing {
+		seen[biometricKey(biometric)] = true
+	}
+
+	result := &dto.BiometricImportResultDTO{ByType: map[string]int{}}
+	batch := make([]models.UserBiometric, 0, appleHealthBatchSize)
+	flush := func() error {
+		if err := repo.CreateBatch(batch); err != nil {
+			return fmt.Errorf("failed to save biometrics: %w", err)
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	skipped, err := ReadAppleHealthExport(r, func(biometric models.UserBiometric) error {
+		biometric.UserID = userID
+		key := biometricKey(biometric)
+		if seen[key] {
+			result.Duplicates++
+			return nil
+		}
+		seen[key] = true
+
+		batch = append(batch, biometric)
+		result.Imported++
+		result.ByType[biometric.Type]++
+		if len(batch) == appleHealthBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	result.Skipped = skipped
+	return result, nil
+}
+
+// ReadAppleHealthExport streams the Record elements of an export.xml and calls fn with each
+// supported measurement converted to a biometric. It returns how many supported records were
+// skipped for an unknown unit, value or date.
+func ReadAppleHealthExport(r io.Reader, fn func(models.UserBiometric) error) (int, error) {
+	decoder := xml.NewDecoder(r)
+	skipped := 0
+	sawRoot := false
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return skipped, fmt.Errorf("%w: %w", ErrInvalidHealthExport, err)
+		}
+		element, ok := token.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if element.Name.Local == "HealthData" {
+			sawRoot = true
+			continue
+		}
+		if element.Name.Local != "Record" {
+			continue
+		}
+
+		attributes := make(map[string]string, len(element.Attr))
+		for _, attribute := range element.Attr {
+			attributes[attribute.Name.Local] = attribute.Value
+		}
+		healthType, ok := appleHealthTypes[attributes["type"]]
+		if !ok {
+			continue
+		}
+		biometric, ok := appleHealthBiometric(healthType, attributes)
+		if !ok {
+			skipped++
+			continue
+		}
+		if err := fn(biometric); err != nil {
+			return skipped, err
+		}
+	}
+	if !sawRoot {
+		return skipped, fmt.Errorf("%w: missing HealthData element", ErrInvalidHealthExport)
+	}
+	return skipped, nil
+}
+
+// appleHealthBiometric converts the attributes of a Record into a biometric, measured at its start date
+func appleHealthBiometric(healthType appleHealthType, attributes map[string]string) (models.UserBiometric, bool) {
+	value, err := strconv.ParseFloat(strings.TrimSpace(attributes["value"]), 64)
+	if err != nil || math.IsNaN(value) || value < 0 {
+		return models.UserBiometric{}, false
+	}
+	value, ok := healthType.Convert(value, strings.TrimSpace(attributes["unit"]))
+	if !ok {
+		return models.UserBiometric{}, false
+	}
+	measuredAt, err := time.Parse(appleHealthDateLayout, strings.TrimSpace(attributes["startDate"]))
+	if err != nil {
+		return models.UserBiometric{}, false
+	}
+	return models.UserBiometric{
+		CreatedAt: measuredAt.UTC(),
+		Type:      healthType.Type,
+		Value:     value,
+		Unit:      healthType.Unit,
+	}, true
+}
+
+// biometricKey identifies a measurement for duplicate detection
+func biometricKey(biometric models.UserBiometric) string {
+	return fmt.Sprintf("%s|%d|%.3f", biometric.Type, biometric.CreatedAt.Unix(), biometric.Value)
+}
+
+func toKilograms(value float64, unit string) (float64, bool) {
+	switch unit {
+	case "kg":
+		return value, true
+	case "g":
+		return value / 1000, true
+	case "lb":
+		return value * 0.45359237, true
+	case "st":
+		return value * 6.35029318, true
+	default:
+		return 0, false
+	}
+}
+
+func toCentimeters(value float64, unit string) (float64, bool) {
+	switch unit {
+	case "cm":
+		return value, true
+	case "m":
+		return value * 100, true
+	case "mm":
+		return value / 10, true
+	case "in":
+		return value * 2.54, true
+	case "ft":
+		return value * 30.48, true
+	default:
+		return 0, false
+	}
+}
+
+// fractionToPercent converts HealthKit percentages, which are stored as a fraction of one
+func fractionToPercent(value float64, unit string) (float64, bool) {
+	if unit != "%" || value > 1 {
+		return 0, false
+	}
+	return value * 100, true
+}
+
+func unitless(value float64, unit string) (float64, bool) {
+	return value, unit == "count" || unit == ""
+}
+
+func toMillimetersOfMercury(value float64, unit string) (float64, bool) {
+	return value, unit == "mmHg"
+}
+
+func toBeatsPerMinute(value float64, unit string) (float64, bool) {
+	return value, unit == "count/min"
+}
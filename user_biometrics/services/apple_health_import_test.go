@@ -0,0 +1,94 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/momokapoolz/caloriesapp/user_biometrics/models"
+)
+
+const sampleHealthExport = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE HealthData [
+<!ELEMENT HealthData (ExportDate,Me,(Record|Workout)*)>
+]>
+<HealthData locale="en_US">
+ <ExportDate value="2026-06-01 09:00:00 +0200"/>
+ <Me HKCharacteristicTypeIdentifierBiologicalSex="HKBiologicalSexFemale"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Scale" unit="lb" creationDate="2026-05-01 07:01:00 +0200" startDate="2026-05-01 07:00:00 +0200" endDate="2026-05-01 07:00:00 +0200" value="154.3"/>
+ <Record type="HKQuantityTypeIdentifierBodyFatPercentage" sourceName="Scale" unit="%" startDate="2026-05-01 07:00:00 +0200" endDate="2026-05-01 07:00:00 +0200" value="0.245"/>
+ <Record type="HKQuantityTypeIdentifierHeight" sourceName="Health" unit="ft" startDate="2026-01-10 10:00:00 +0100" endDate="2026-01-10 10:00:00 +0100" value="5.5"/>
+ <Record type="HKQuantityTypeIdentifierRestingHeartRate" sourceName="Watch" unit="count/min" startDate="2026-05-01 00:00:00 +0200" endDate="2026-05-01 23:59:59 +0200" value="58">
+  <MetadataEntry key="HKMetadataKeyHeartRateMotionContext" value="0"/>
+ </Record>
+ <Record type="HKQuantityTypeIdentifierStepCount" sourceName="Phone" unit="count" startDate="2026-05-01 08:00:00 +0200" endDate="2026-05-01 08:10:00 +0200" value="1200"/>
+ <Record type="HKQuantityTypeIdentifierBodyMass" sourceName="Scale" unit="oz" startDate="2026-05-02 07:00:00 +0200" endDate="2026-05-02 07:00:00 +0200" value="2460"/>
+ <Correlation type="HKCorrelationTypeIdentifierBloodPressure" startDate="2026-05-03 08:00:00 +0200" endDate="2026-05-03 08:00:00 +0200">
+  <Record type="HKQuantityTypeIdentifierBloodPressureSystolic" unit="mmHg" startDate="2026-05-03 08:00:00 +0200" endDate="2026-05-03 08:00:00 +0200" value="118"/>
+  <Record type="HKQuantityTypeIdentifierBloodPressureDiastolic" unit="mmHg" startDate="2026-05-03 08:00:00 +0200" endDate="2026-05-03 08:00:00 +0200" value="76"/>
+ </Correlation>
+</HealthData>`
+
+func TestReadAppleHealthExport(t *testing.T) {
+	var biometrics []models.UserBiometric
+	skipped, err := ReadAppleHealthExport(strings.NewReader(sampleHealthExport), func(biometric models.UserBiometric) error {
+		biometrics = append(biometrics, biometric)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("ReadAppleHealthExport: %v", err)
+	}
+	// The weight in ounces has no conversion; step counts are not biometrics
+	if skipped != 1 {
+		t.Errorf("skipped = %d, want 1", skipped)
+	}
+	if len(biometrics) != 6 {
+		t.Fatalf("got %d biometrics, want 6", len(biometrics))
+	}
+
+	want := []struct {
+		Type  string
+		Value float64
+		Unit  string
+	}{
+		{"weight", 69.989, "kg"},
+		{"body_fat_percentage", 24.5, "%"},
+		{"height", 167.64, "cm"},
+		{"resting_heart_rate", 58, "bpm"},
+		{"blood_pressure_systolic", 118, "mmHg"},
+		{"blood_pressure_diastolic", 76, "mmHg"},
+	}
+	for i, expected := range want {
+		got := biometrics[i]
+		if got.Type != expected.Type || got.Unit != expected.Unit || math.Abs(got.Value-expected.Value) > 0.001 {
+			t.Errorf("biometric %d = %s %v %s, want %s %v %s", i, got.Type, got.Value, got.Unit, expected.Type, expected.Value, expected.Unit)
+		}
+	}
+	if measuredAt := time.Date(2026, 5, 1, 5, 0, 0, 0, time.UTC); !biometrics[0].CreatedAt.Equal(measuredAt) {
+		t.Errorf("weight measured at %v, want %v", biometrics[0].CreatedAt, measuredAt)
+	}
+}
+
+func TestReadAppleHealthExportRejectsOtherFiles(t *testing.T) {
+	for _, data := range []string{`<gpx></gpx>`, `<HealthData><Record`} {
+		_, err := ReadAppleHealthExport(strings.NewReader(data), func(models.UserBiometric) error { return nil })
+		if !errors.Is(err, ErrInvalidHealthExport) {
+			t.Errorf("%q: error = %v, want ErrInvalidHealthExport", data, err)
+		}
+	}
+}
+
+func TestBiometricKeyMatchesStoredDuplicates(t *testing.T) {
+	measuredAt := time.Date(2026, 5, 1, 5, 0, 0, 0, time.UTC)
+	imported := models.UserBiometric{Type: "weight", Value: 69.98899, CreatedAt: measuredAt}
+	stored := models.UserBiometric{Type: "weight", Value: 69.989, CreatedAt: measuredAt.In(time.FixedZone("CEST", 7200))}
+	if biometricKey(imported) != biometricKey(stored) {
+		t.Errorf("keys differ: %q and %q", biometricKey(imported), biometricKey(stored))
+	}
+	stored.Type = "height"
+	if biometricKey(imported) == biometricKey(stored) {
+		t.Error("different types should not collide")
+	}
+}